@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query/rules"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query/validation"
 )
 
 // Config represents the CLI configuration
@@ -15,9 +18,16 @@ type Config struct {
 		Stream   bool   `json:"stream"`
 	} `json:"parser"`
 	Validation struct {
-		SeverityThreshold string `json:"severity_threshold"` // severe, warning, info, hint
-		StrictMode        bool   `json:"strict_mode"`
+		SeverityThreshold string         `json:"severity_threshold"` // severe, warning, info, hint
+		StrictMode        bool           `json:"strict_mode"`
+		Thresholds        map[string]int `json:"thresholds"`    // per-rule overrides, keyed by validation.Thresholds field name (MaxAge, MinMarriageAge, MaxMotherAge, ...)
+		PluginPaths       []string       `json:"plugin_paths"`  // .so files exporting a Rules() []validation.Rule symbol, loaded via validation.RuleRegistry.LoadPlugins
+		RuleFiles         []string       `json:"rule_files"`    // declarative YAML/JSON rule files, loaded via validation.RuleRegistry.LoadRuleFile
 	} `json:"validation"`
+	Rules struct {
+		Settings   map[string]string `json:"settings"`   // rule ID -> "warn"|"error"|"off", mapped to a rules.Mode by rulesConfigFrom
+		Thresholds map[string]int    `json:"thresholds"` // per-rule overrides, keyed by rules.Thresholds field name (MinMotherAge, MaxMotherAge, ...)
+	} `json:"rules"`
 	Output struct {
 		DefaultFormat string `json:"default_format"` // table, json, yaml, csv
 		Color         bool   `json:"color"`
@@ -41,6 +51,9 @@ func DefaultConfig() *Config {
 	config.Parser.Stream = false
 	config.Validation.SeverityThreshold = "warning"
 	config.Validation.StrictMode = false
+	config.Validation.Thresholds = validation.DefaultThresholds().Map()
+	config.Rules.Settings = make(map[string]string)
+	config.Rules.Thresholds = rules.DefaultThresholds().Map()
 	config.Output.DefaultFormat = "table"
 	config.Output.Color = true
 	config.Output.Progress = true
@@ -0,0 +1,537 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+)
+
+// Layer identifies which configuration layer supplied a field's value,
+// in increasing priority order: defaults are overridden by the system
+// config, which is overridden by the user config, then project config,
+// then environment variables, then an explicit --config file, then
+// individual CLI flags.
+type Layer string
+
+const (
+	LayerDefault     Layer = "default"
+	LayerSystem      Layer = "system"
+	LayerUser        Layer = "user"
+	LayerProject     Layer = "project"
+	LayerEnvironment Layer = "environment"
+	LayerConfigFlag  Layer = "config_flag"
+	LayerCLIFlag     Layer = "cli_flag"
+)
+
+// Source records that configuration field Field received its value from
+// Layer, and (for file/env layers) where that value came from -- a file
+// path or an environment variable name. LoadLayered returns one Source
+// each time a layer sets a field, in layer order (a field set by two
+// layers appears twice, the later entry being the one that won), for a
+// "gedcom config show --origins" command to report provenance.
+type Source struct {
+	Field string
+	Layer Layer
+	Path  string
+}
+
+// LoadOptions configures LoadLayered.
+type LoadOptions struct {
+	// ConfigFlag is an explicit --config file path, applied after
+	// environment variables and before CLIFlags.
+	ConfigFlag string
+
+	// CLIFlags maps a dotted config field path (e.g. "parser.type") to
+	// its raw flag value, the highest-priority layer.
+	CLIFlags map[string]string
+
+	// WorkDir is the directory to walk up from when looking for a
+	// project config file. Defaults to os.Getwd().
+	WorkDir string
+
+	// SkipEnv disables the environment variable layer, for tests that
+	// want a deterministic result regardless of the test runner's
+	// environment.
+	SkipEnv bool
+}
+
+// systemConfigPaths are the built-in system-wide config file locations.
+// Every one that exists is merged, in this order, so a later file's
+// values override an earlier one's. system config (unlike user config)
+// has no per-OS home-directory fallback.
+var systemConfigPaths = []string{
+	"/etc/gedcom/config.json",
+	"/etc/gedcom/config.yaml",
+	"/etc/gedcom/config.yml",
+	"/etc/gedcom/config.toml",
+}
+
+// projectConfigNames are the project-local config file names LoadLayered
+// looks for while walking up from WorkDir.
+var projectConfigNames = []string{
+	".gedcomrc.json", ".gedcomrc.yaml", ".gedcomrc.yml", ".gedcomrc.toml",
+	"gedcom.json", "gedcom.yaml", "gedcom.yml", "gedcom.toml",
+}
+
+// LoadLayered builds a Config by merging, in priority order: built-in
+// defaults, the system config, the user config (the same paths
+// LoadConfig checks), a project config found by walking up from
+// opts.WorkDir, environment variables (GEDCOM_PARSER_TYPE,
+// GEDCOM_VALIDATION_STRICT_MODE, ... derived from each field's json
+// tag), opts.ConfigFlag, and finally opts.CLIFlags. It returns the merged
+// Config plus one Source per field that a layer above the defaults
+// actually set.
+func LoadLayered(opts LoadOptions) (*Config, []Source, error) {
+	cfg := DefaultConfig()
+	origins := make(map[string]Layer)
+	sources := make([]Source, 0)
+
+	mergeFile := func(path string, layer Layer) error {
+		if path == "" {
+			return nil
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil
+		}
+		m, err := decodeConfigFileAny(path)
+		if err != nil {
+			return err
+		}
+		mergeMapIntoConfig(cfg, m, layer, path, origins, &sources)
+		return nil
+	}
+
+	for _, path := range systemConfigPaths {
+		if err := mergeFile(path, LayerSystem); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		for _, name := range []string{"config.json", "config.yaml", "config.yml", "config.toml"} {
+			if err := mergeFile(filepath.Join(home, ".gedcom", name), LayerUser); err != nil {
+				return nil, nil, err
+			}
+			if err := mergeFile(filepath.Join(home, ".config", "gedcom", name), LayerUser); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	workDir := opts.WorkDir
+	if workDir == "" {
+		if wd, err := os.Getwd(); err == nil {
+			workDir = wd
+		}
+	}
+	if workDir != "" {
+		if projectPath := findProjectConfig(workDir); projectPath != "" {
+			if err := mergeFile(projectPath, LayerProject); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if !opts.SkipEnv {
+		applyEnvLayer(cfg, origins, &sources)
+	}
+
+	if err := mergeFile(opts.ConfigFlag, LayerConfigFlag); err != nil {
+		return nil, nil, err
+	}
+
+	if len(opts.CLIFlags) > 0 {
+		if err := applyCLIFlags(cfg, opts.CLIFlags, origins, &sources); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return cfg, sources, nil
+}
+
+// findProjectConfig walks up from dir looking for one of
+// projectConfigNames, returning the first match or "" if it reaches the
+// filesystem root without finding one.
+func findProjectConfig(dir string) string {
+	for {
+		for _, name := range projectConfigNames {
+			candidate := filepath.Join(dir, name)
+			if _, err := os.Stat(candidate); err == nil {
+				return candidate
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// decodeConfigFileAny reads path and decodes it into a generic map,
+// choosing the decoder by file extension: .yaml/.yml uses YAML, .toml
+// uses TOML, anything else is treated as JSON (matching LoadConfig's
+// existing behavior for unsuffixed paths).
+func decodeConfigFileAny(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	m := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML config %s: %w", path, err)
+		}
+	case ".toml":
+		if _, err := toml.Decode(string(data), &m); err != nil {
+			return nil, fmt.Errorf("failed to parse TOML config %s: %w", path, err)
+		}
+	default:
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON config %s: %w", path, err)
+		}
+	}
+	return m, nil
+}
+
+// Watch watches path for writes and calls fn with the freshly-decoded
+// Config each time it changes, until ctx is canceled. It decodes path
+// alone (via decodeConfigFileAny merged onto DefaultConfig()), not the
+// full layered stack LoadLayered builds, so callers already running
+// LoadLayered should re-merge the layers above path themselves inside
+// fn if they need those preserved across a reload.
+func Watch(ctx context.Context, path string, fn func(*Config)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch config directory: %w", err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != filepath.Clean(path) {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				m, err := decodeConfigFileAny(path)
+				if err != nil {
+					continue
+				}
+				cfg := DefaultConfig()
+				mergeMapIntoConfig(cfg, m, LayerConfigFlag, path, make(map[string]Layer), &[]Source{})
+				fn(cfg)
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// jsonFieldName returns f's json tag name, or "" if it has none or is
+// explicitly excluded ("-").
+func jsonFieldName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// mergeMapIntoConfig overlays m (a file decoded into a generic map) onto
+// cfg, recording layer/sourcePath as the origin of every field m actually
+// sets.
+func mergeMapIntoConfig(cfg *Config, m map[string]interface{}, layer Layer, sourcePath string, origins map[string]Layer, sources *[]Source) {
+	mergeStruct(reflect.ValueOf(cfg).Elem(), m, "", layer, sourcePath, origins, sources)
+}
+
+func mergeStruct(v reflect.Value, m map[string]interface{}, prefix string, layer Layer, sourcePath string, origins map[string]Layer, sources *[]Source) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonFieldName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		raw, ok := m[name]
+		if !ok {
+			continue
+		}
+		fv := v.Field(i)
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		switch fv.Kind() {
+		case reflect.Struct:
+			if nested, ok := raw.(map[string]interface{}); ok {
+				mergeStruct(fv, nested, path, layer, sourcePath, origins, sources)
+			}
+		case reflect.Map:
+			newMap := make(map[string]int)
+			if nested, ok := raw.(map[string]interface{}); ok {
+				for k, vv := range nested {
+					if n, ok := toInt(vv); ok {
+						newMap[k] = n
+					}
+				}
+			}
+			if len(newMap) == 0 {
+				continue
+			}
+			if fv.IsNil() {
+				fv.Set(reflect.MakeMap(fv.Type()))
+			}
+			for k, n := range newMap {
+				fv.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(n))
+			}
+			recordOrigin(path, layer, sourcePath, origins, sources)
+		case reflect.String:
+			if s, ok := raw.(string); ok {
+				fv.SetString(s)
+				recordOrigin(path, layer, sourcePath, origins, sources)
+			}
+		case reflect.Bool:
+			if b, ok := raw.(bool); ok {
+				fv.SetBool(b)
+				recordOrigin(path, layer, sourcePath, origins, sources)
+			}
+		case reflect.Int:
+			if n, ok := toInt(raw); ok {
+				fv.SetInt(int64(n))
+				recordOrigin(path, layer, sourcePath, origins, sources)
+			}
+		}
+	}
+}
+
+func recordOrigin(path string, layer Layer, sourcePath string, origins map[string]Layer, sources *[]Source) {
+	origins[path] = layer
+	*sources = append(*sources, Source{Field: path, Layer: layer, Path: sourcePath})
+}
+
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	default:
+		return 0, false
+	}
+}
+
+// applyEnvLayer overlays environment variables onto cfg's scalar fields,
+// deriving each field's variable name from its dotted json-tag path (see
+// envVarName): parser.type -> GEDCOM_PARSER_TYPE, validation.strict_mode
+// -> GEDCOM_VALIDATION_STRICT_MODE. Thresholds (a map field) isn't
+// env-overridable; there's no single variable name for a map entry.
+func applyEnvLayer(cfg *Config, origins map[string]Layer, sources *[]Source) {
+	applyEnvStruct(reflect.ValueOf(cfg).Elem(), "", origins, sources)
+}
+
+func applyEnvStruct(v reflect.Value, prefix string, origins map[string]Layer, sources *[]Source) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonFieldName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		fv := v.Field(i)
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		if fv.Kind() == reflect.Struct {
+			applyEnvStruct(fv, path, origins, sources)
+			continue
+		}
+
+		envName := envVarName(path)
+		raw, ok := os.LookupEnv(envName)
+		if !ok {
+			continue
+		}
+
+		switch fv.Kind() {
+		case reflect.String:
+			fv.SetString(raw)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				continue
+			}
+			fv.SetBool(b)
+		case reflect.Int:
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				continue
+			}
+			fv.SetInt(int64(n))
+		default:
+			continue
+		}
+		recordOrigin(path, LayerEnvironment, envName, origins, sources)
+	}
+}
+
+// envVarName derives the environment variable name for a dotted
+// json-tag field path: GEDCOM_ prefixed, uppercased, dots turned into
+// underscores.
+func envVarName(path string) string {
+	return "GEDCOM_" + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// leafFields returns every scalar (string/bool/int) field of v, keyed by
+// its dotted json-tag path.
+func leafFields(v reflect.Value, prefix string) map[string]reflect.Value {
+	result := make(map[string]reflect.Value)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonFieldName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		fv := v.Field(i)
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		switch fv.Kind() {
+		case reflect.Struct:
+			for k, nested := range leafFields(fv, path) {
+				result[k] = nested
+			}
+		case reflect.String, reflect.Bool, reflect.Int:
+			result[path] = fv
+		}
+	}
+	return result
+}
+
+// applyCLIFlags overlays individual --flag overrides (keyed by dotted
+// config field path) onto cfg, the highest-priority layer.
+func applyCLIFlags(cfg *Config, flags map[string]string, origins map[string]Layer, sources *[]Source) error {
+	fields := leafFields(reflect.ValueOf(cfg).Elem(), "")
+	for path, raw := range flags {
+		fv, ok := fields[path]
+		if !ok {
+			return fmt.Errorf("unknown config field %q", path)
+		}
+		switch fv.Kind() {
+		case reflect.String:
+			fv.SetString(raw)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return fmt.Errorf("invalid bool value for %s: %w", path, err)
+			}
+			fv.SetBool(b)
+		case reflect.Int:
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return fmt.Errorf("invalid int value for %s: %w", path, err)
+			}
+			fv.SetInt(int64(n))
+		}
+		recordOrigin(path, LayerCLIFlag, "--"+path, origins, sources)
+	}
+	return nil
+}
+
+// SaveConfigWithOrigins writes config to configPath like SaveConfig, but
+// when onlyOverrides is true it writes only the fields whose origin (as
+// returned by LoadLayered) is above LayerDefault, instead of the full
+// struct -- the --only-overrides behavior of "gedcom config save".
+func SaveConfigWithOrigins(config *Config, configPath string, origins map[string]Layer, onlyOverrides bool) error {
+	if !onlyOverrides {
+		return SaveConfig(config, configPath)
+	}
+
+	out := make(map[string]interface{})
+	fields := leafFields(reflect.ValueOf(config).Elem(), "")
+	for path, fv := range fields {
+		if layer, ok := origins[path]; !ok || layer == LayerDefault {
+			continue
+		}
+		setMapPath(out, path, fv.Interface())
+	}
+	if layer, ok := origins["validation.thresholds"]; ok && layer != LayerDefault {
+		setMapPath(out, "validation.thresholds", config.Validation.Thresholds)
+	}
+	if layer, ok := origins["rules.thresholds"]; ok && layer != LayerDefault {
+		setMapPath(out, "rules.thresholds", config.Rules.Thresholds)
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config overrides: %w", err)
+	}
+
+	if configPath == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configPath = filepath.Join(homeDir, ".gedcom", "config.json")
+	}
+	dir := filepath.Dir(configPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// setMapPath sets value at a dotted path within out, creating
+// intermediate map[string]interface{} levels as needed.
+func setMapPath(out map[string]interface{}, path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	m := out
+	for i, part := range parts {
+		if i == len(parts)-1 {
+			m[part] = value
+			return
+		}
+		next, ok := m[part].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[part] = next
+		}
+		m = next
+	}
+}
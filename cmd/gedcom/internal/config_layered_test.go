@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadLayered_EnvOverridesBeatFileValues(t *testing.T) {
+	dir := t.TempDir()
+	// A project config file (found by walking up from WorkDir) sits below
+	// the environment layer; an explicit --config file would sit above it.
+	configPath := filepath.Join(dir, ".gedcomrc.json")
+	if err := os.WriteFile(configPath, []byte(`{"parser":{"type":"parallel"},"output":{"default_format":"json"}}`), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	t.Setenv("GEDCOM_PARSER_TYPE", "stream")
+
+	cfg, sources, err := LoadLayered(LoadOptions{WorkDir: dir})
+	if err != nil {
+		t.Fatalf("LoadLayered failed: %v", err)
+	}
+
+	if cfg.Parser.Type != "stream" {
+		t.Errorf("expected env var to override file value, got %q", cfg.Parser.Type)
+	}
+	if cfg.Output.DefaultFormat != "json" {
+		t.Errorf("expected file value to override default, got %q", cfg.Output.DefaultFormat)
+	}
+
+	foundEnvSource := false
+	for _, s := range sources {
+		if s.Field == "parser.type" && s.Layer == LayerEnvironment {
+			foundEnvSource = true
+		}
+	}
+	if !foundEnvSource {
+		t.Errorf("expected a Source recording parser.type as LayerEnvironment, got %+v", sources)
+	}
+}
+
+func TestLoadLayered_CLIFlagBeatsEverything(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"parser":{"type":"parallel"}}`), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	t.Setenv("GEDCOM_PARSER_TYPE", "stream")
+
+	cfg, _, err := LoadLayered(LoadOptions{
+		ConfigFlag: configPath,
+		WorkDir:    dir,
+		CLIFlags:   map[string]string{"parser.type": "hierarchical"},
+	})
+	if err != nil {
+		t.Fatalf("LoadLayered failed: %v", err)
+	}
+
+	if cfg.Parser.Type != "hierarchical" {
+		t.Errorf("expected CLI flag to win, got %q", cfg.Parser.Type)
+	}
+}
+
+func TestSaveConfigWithOrigins_OnlyOverrides(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"parser":{"type":"parallel"}}`), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	cfg, sources, err := LoadLayered(LoadOptions{ConfigFlag: configPath, WorkDir: dir, SkipEnv: true})
+	if err != nil {
+		t.Fatalf("LoadLayered failed: %v", err)
+	}
+
+	origins := make(map[string]Layer)
+	for _, s := range sources {
+		origins[s.Field] = s.Layer
+	}
+
+	outPath := filepath.Join(dir, "out.json")
+	if err := SaveConfigWithOrigins(cfg, outPath, origins, true); err != nil {
+		t.Fatalf("SaveConfigWithOrigins failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("failed to read saved config: %v", err)
+	}
+
+	saved := string(data)
+	if !strings.Contains(saved, `"type": "parallel"`) {
+		t.Errorf("expected overridden parser.type in output, got: %s", saved)
+	}
+	if strings.Contains(saved, "color") {
+		t.Errorf("expected untouched output.color to be omitted, got: %s", saved)
+	}
+}
@@ -0,0 +1,160 @@
+// Package encoders serializes query package results (individuals, paths,
+// relationships, and whole graphs) into GEDCOM-X JSON and GraphML, the two
+// interchange formats requested alongside the legacy line-based GEDCOM
+// output.
+package encoders
+
+import (
+	"fmt"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+)
+
+// GedcomXDocument is the top-level GEDCOM-X JSON envelope: a flat list of
+// persons plus relationships between them, mirroring
+// http://gedcomx.org/Conclusion.
+type GedcomXDocument struct {
+	Persons       []GedcomXPerson       `json:"persons,omitempty"`
+	Relationships []GedcomXRelationship `json:"relationships,omitempty"`
+}
+
+// GedcomXPerson maps an IndividualNode to a GEDCOM-X Person resource.
+type GedcomXPerson struct {
+	ID      string           `json:"id"`
+	Living  bool             `json:"living,omitempty"`
+	Gender  *GedcomXGender   `json:"gender,omitempty"`
+	Names   []GedcomXName    `json:"names,omitempty"`
+	Facts   []GedcomXFact    `json:"facts,omitempty"`
+	Sources []GedcomXSource  `json:"sources,omitempty"`
+}
+
+// GedcomXGender carries the GEDCOM-X gender conclusion type.
+type GedcomXGender struct {
+	Type string `json:"type"`
+}
+
+// GedcomXName carries parsed name parts, GEDCOM-X style.
+type GedcomXName struct {
+	NameForms []GedcomXNameForm `json:"nameForms"`
+}
+
+// GedcomXNameForm is a single rendering of a name plus its parts.
+type GedcomXNameForm struct {
+	FullText string          `json:"fullText,omitempty"`
+	Parts    []GedcomXNamePart `json:"parts,omitempty"`
+}
+
+// GedcomXNamePart is one named component (Given, Surname, ...).
+type GedcomXNamePart struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// GedcomXFact is a conclusion event such as Birth or Death.
+type GedcomXFact struct {
+	Type string `json:"type"`
+	Date string `json:"date,omitempty"`
+	Place string `json:"place,omitempty"`
+}
+
+// GedcomXSource references a conclusion source, when the original record
+// carried one.
+type GedcomXSource struct {
+	Description string `json:"description"`
+}
+
+// GedcomXRelationship maps a FamilyNode to ParentChild/Couple resources.
+type GedcomXRelationship struct {
+	Type    string             `json:"type"`
+	Person1 GedcomXResourceRef `json:"person1"`
+	Person2 GedcomXResourceRef `json:"person2"`
+}
+
+// GedcomXResourceRef is a local reference to a Person by ID.
+type GedcomXResourceRef struct {
+	Resource string `json:"resource"`
+}
+
+const (
+	gedcomXTypeCouple      = "http://gedcomx.org/Couple"
+	gedcomXTypeParentChild = "http://gedcomx.org/ParentChild"
+)
+
+// ToGedcomX converts a supported query result type into a GedcomXDocument.
+// Supported inputs: *query.Graph and []*query.IndividualNode. Any other
+// type, including path and relationship results, returns an error.
+func ToGedcomX(data interface{}) (*GedcomXDocument, error) {
+	switch v := data.(type) {
+	case *query.Graph:
+		return graphToGedcomX(v), nil
+	case []*query.IndividualNode:
+		doc := &GedcomXDocument{}
+		for _, n := range v {
+			doc.Persons = append(doc.Persons, individualToGedcomXPerson(n))
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("encoders: unsupported type %T for GEDCOM-X output", data)
+	}
+}
+
+func individualToGedcomXPerson(n *query.IndividualNode) GedcomXPerson {
+	p := GedcomXPerson{ID: n.ID()}
+	if n.Individual != nil {
+		if name := n.Individual.GetName(); name != "" {
+			p.Names = append(p.Names, GedcomXName{
+				NameForms: []GedcomXNameForm{{FullText: name}},
+			})
+		}
+		switch n.Individual.GetSex() {
+		case "M":
+			p.Gender = &GedcomXGender{Type: "http://gedcomx.org/Male"}
+		case "F":
+			p.Gender = &GedcomXGender{Type: "http://gedcomx.org/Female"}
+		}
+		if birth := n.Individual.GetBirthDate(); birth != "" {
+			p.Facts = append(p.Facts, GedcomXFact{Type: "http://gedcomx.org/Birth", Date: birth, Place: n.Individual.GetBirthPlace()})
+		}
+		if death := n.Individual.GetDeathDate(); death != "" {
+			p.Facts = append(p.Facts, GedcomXFact{Type: "http://gedcomx.org/Death", Date: death})
+		} else {
+			p.Living = true
+		}
+	}
+	return p
+}
+
+func graphToGedcomX(g *query.Graph) *GedcomXDocument {
+	doc := &GedcomXDocument{}
+	for _, n := range g.AllIndividuals() {
+		doc.Persons = append(doc.Persons, individualToGedcomXPerson(n))
+	}
+	for _, fam := range g.AllFamilies() {
+		husband := fam.Husband()
+		wife := fam.Wife()
+		if husband != nil && wife != nil {
+			doc.Relationships = append(doc.Relationships, GedcomXRelationship{
+				Type:    gedcomXTypeCouple,
+				Person1: GedcomXResourceRef{Resource: "#" + husband.ID()},
+				Person2: GedcomXResourceRef{Resource: "#" + wife.ID()},
+			})
+		}
+		for _, child := range fam.Children() {
+			if husband != nil {
+				doc.Relationships = append(doc.Relationships, GedcomXRelationship{
+					Type:    gedcomXTypeParentChild,
+					Person1: GedcomXResourceRef{Resource: "#" + husband.ID()},
+					Person2: GedcomXResourceRef{Resource: "#" + child.ID()},
+				})
+			}
+			if wife != nil {
+				doc.Relationships = append(doc.Relationships, GedcomXRelationship{
+					Type:    gedcomXTypeParentChild,
+					Person1: GedcomXResourceRef{Resource: "#" + wife.ID()},
+					Person2: GedcomXResourceRef{Resource: "#" + child.ID()},
+				})
+			}
+		}
+	}
+	return doc
+}
@@ -0,0 +1,128 @@
+package encoders
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+)
+
+// graphMLDoc mirrors the subset of the GraphML schema (graphml.graphdrawing.org)
+// consumed by Gephi, yEd, and Cytoscape: a single directed graph of nodes
+// and edges carrying typed <data> attributes.
+type graphMLDoc struct {
+	XMLName xml.Name      `xml:"graphml"`
+	Xmlns   string        `xml:"xmlns,attr"`
+	Keys    []graphMLKey  `xml:"key"`
+	Graph   graphMLGraph  `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID     string `xml:"id,attr"`
+	For    string `xml:"for,attr"`
+	Name   string `xml:"attr.name,attr"`
+	Type   string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string       `xml:"id,attr"`
+	EdgeDefault string       `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string          `xml:"id,attr"`
+	Data []graphMLData   `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+const (
+	keyName  = "d_name"
+	keySex   = "d_sex"
+	keyBirth = "d_birth"
+	keyDeath = "d_death"
+	keyEdge  = "d_edgetype"
+)
+
+// ToGraphML renders a *query.Graph as a GraphML XML document, with
+// individuals as nodes (name/sex/birth/death attributes) and typed edges
+// so visual tools can color them: CHIL from each parent to each child, and
+// HUSB from husband to wife. No FAMC or WIFE edges are emitted.
+func ToGraphML(data interface{}) (string, error) {
+	g, ok := data.(*query.Graph)
+	if !ok {
+		return "", fmt.Errorf("encoders: GraphML output requires *query.Graph, got %T", data)
+	}
+
+	doc := graphMLDoc{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: keyName, For: "node", Name: "name", Type: "string"},
+			{ID: keySex, For: "node", Name: "sex", Type: "string"},
+			{ID: keyBirth, For: "node", Name: "birth", Type: "string"},
+			{ID: keyDeath, For: "node", Name: "death", Type: "string"},
+			{ID: keyEdge, For: "edge", Name: "edgetype", Type: "string"},
+		},
+		Graph: graphMLGraph{ID: "G", EdgeDefault: "directed"},
+	}
+
+	for _, n := range g.AllIndividuals() {
+		node := graphMLNode{ID: n.ID()}
+		if n.Individual != nil {
+			node.Data = append(node.Data,
+				graphMLData{Key: keyName, Value: n.Individual.GetName()},
+				graphMLData{Key: keySex, Value: n.Individual.GetSex()},
+				graphMLData{Key: keyBirth, Value: n.Individual.GetBirthDate()},
+				graphMLData{Key: keyDeath, Value: n.Individual.GetDeathDate()},
+			)
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+
+	for _, fam := range g.AllFamilies() {
+		husband := fam.Husband()
+		wife := fam.Wife()
+		for _, child := range fam.Children() {
+			if husband != nil {
+				doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+					Source: husband.ID(), Target: child.ID(),
+					Data: []graphMLData{{Key: keyEdge, Value: "CHIL"}},
+				})
+			}
+			if wife != nil {
+				doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+					Source: wife.ID(), Target: child.ID(),
+					Data: []graphMLData{{Key: keyEdge, Value: "CHIL"}},
+				})
+			}
+		}
+		if husband != nil && wife != nil {
+			doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+				Source: husband.ID(), Target: wife.ID(),
+				Data: []graphMLData{{Key: keyEdge, Value: "HUSB"}},
+			})
+		}
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoders: failed to marshal GraphML: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.Write(out)
+	return sb.String(), nil
+}
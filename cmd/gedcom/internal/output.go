@@ -5,16 +5,20 @@ import (
 	"fmt"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/cmd/gedcom/internal/encoders"
 )
 
 // OutputFormat represents the output format
 type OutputFormat string
 
 const (
-	FormatTable OutputFormat = "table"
-	FormatJSON  OutputFormat = "json"
-	FormatYAML  OutputFormat = "yaml"
-	FormatCSV   OutputFormat = "csv"
+	FormatTable   OutputFormat = "table"
+	FormatJSON    OutputFormat = "json"
+	FormatYAML    OutputFormat = "yaml"
+	FormatCSV     OutputFormat = "csv"
+	FormatGedcomX OutputFormat = "gedcomx"
+	FormatGraphML OutputFormat = "graphml"
 )
 
 // FormatOutput formats data according to the specified format
@@ -28,11 +32,37 @@ func FormatOutput(data interface{}, format OutputFormat, pretty bool) error {
 		return formatYAML(data)
 	case FormatCSV:
 		return formatCSV(data)
+	case FormatGedcomX:
+		return formatGedcomX(data, pretty)
+	case FormatGraphML:
+		return formatGraphML(data)
 	default:
 		return fmt.Errorf("unknown output format: %s", format)
 	}
 }
 
+// formatGedcomX formats data as GEDCOM-X JSON using the encoders package.
+// Supported inputs are *query.Graph and []*query.IndividualNode; anything
+// else returns an error.
+func formatGedcomX(data interface{}, pretty bool) error {
+	doc, err := encoders.ToGedcomX(data)
+	if err != nil {
+		return fmt.Errorf("failed to build GEDCOM-X document: %w", err)
+	}
+	return formatJSON(doc, pretty)
+}
+
+// formatGraphML formats data as GraphML XML using the encoders package.
+// Only *query.Graph is supported; other inputs return an error.
+func formatGraphML(data interface{}) error {
+	xmlDoc, err := encoders.ToGraphML(data)
+	if err != nil {
+		return fmt.Errorf("failed to build GraphML document: %w", err)
+	}
+	fmt.Println(xmlDoc)
+	return nil
+}
+
 // formatTable formats data as a table
 func formatTable(data interface{}) error {
 	// For now, simple table formatting
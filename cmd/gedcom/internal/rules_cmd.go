@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query/validation"
+)
+
+// RuleLintReport is a validation.RuleLintResult flattened for
+// FormatOutput's table/json/yaml/csv writers.
+type RuleLintReport struct {
+	ID    string
+	Valid bool
+	Error string
+}
+
+// LintRulesFile is the function a "gedcom rules lint rules.yaml"
+// subcommand would call -- parsing and type-checking a declarative rule
+// file without running it against a graph. There is no cobra (or
+// other) command tree in this snapshot to register it on (see
+// RunValidationRules' doc comment for that gap), so it's exported as a
+// standalone entry point instead.
+func LintRulesFile(path string, format OutputFormat, pretty bool) error {
+	results, err := validation.LintRuleFile(path)
+	if err != nil {
+		return err
+	}
+
+	reports := make([]RuleLintReport, 0, len(results))
+	for _, r := range results {
+		reports = append(reports, RuleLintReport{ID: r.ID, Valid: r.Error == "", Error: r.Error})
+	}
+	return FormatOutput(reports, format, pretty)
+}
+
+// BuildRuleRegistry loads every plugin and declarative rule file cfg's
+// Validation section names (PluginPaths, RuleFiles) into a
+// validation.RuleRegistry, for RunValidationRulesWithRegistry to merge
+// into the built-in rule catalog.
+func BuildRuleRegistry(cfg *Config) (*validation.RuleRegistry, error) {
+	reg := validation.NewRuleRegistry()
+	if err := reg.LoadPlugins(cfg.Validation.PluginPaths); err != nil {
+		return nil, err
+	}
+	for _, path := range cfg.Validation.RuleFiles {
+		if err := reg.LoadRuleFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return reg, nil
+}
+
+// RunValidationRulesWithRegistry is RunValidationRules extended with
+// cfg.Validation's plugin and declarative rule files, for callers that
+// want the full configured rule set (built-in catalog plus
+// user-extended rules) rather than just the built-in catalog
+// RunValidationRules runs alone.
+func RunValidationRulesWithRegistry(g *query.Graph, cfg *Config, format OutputFormat, pretty bool) error {
+	thresholds := validation.ThresholdsFromMap(cfg.Validation.Thresholds)
+	rs := validation.NewRuleSet(thresholds)
+
+	reg, err := BuildRuleRegistry(cfg)
+	if err != nil {
+		return err
+	}
+	rs.AddRules(reg.Rules())
+
+	minSeverity := severityFromString(cfg.Validation.SeverityThreshold)
+	violations := rs.RunFiltered(g, minSeverity)
+
+	reports := make([]ViolationReport, 0, len(violations))
+	for _, v := range violations {
+		report := ViolationReport{RuleID: v.RuleID, Severity: string(v.Severity), Message: v.Message}
+		if v.Individual != nil {
+			report.IndividualXref = v.Individual.ID()
+		}
+		if v.Family != nil {
+			report.FamilyXref = v.Family.ID()
+		}
+		reports = append(reports, report)
+	}
+
+	return FormatOutput(reports, format, pretty)
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query/rules"
+)
+
+// rulesConfigFrom builds a rules.Config from cfg.Rules, for
+// RunRulesReport to hand to rules.Validate.
+func rulesConfigFrom(cfg *Config) *rules.Config {
+	settings := make(map[string]rules.Mode, len(cfg.Rules.Settings))
+	for ruleID, mode := range cfg.Rules.Settings {
+		settings[ruleID] = rules.Mode(mode)
+	}
+	return &rules.Config{Settings: settings, Thresholds: cfg.Rules.Thresholds}
+}
+
+// RulesReportFormat selects how RunRulesReport renders its output.
+type RulesReportFormat string
+
+const (
+	RulesReportJSON    RulesReportFormat = "json"
+	RulesReportJUnit   RulesReportFormat = "junit"
+	RulesReportSummary RulesReportFormat = "summary"
+)
+
+// RunRulesReport runs query/rules' built-in rule catalog (plus anything
+// added via rules.Register) over g using cfg.Rules, and returns the
+// rendered report in the requested format. There is no cobra (or other)
+// command tree in this snapshot to register it on (see
+// RunValidationRules' doc comment for that same gap), so it's exported
+// as a standalone entry point a "gedcom rules report" subcommand would
+// call.
+func RunRulesReport(ctx context.Context, g *query.Graph, cfg *Config, format RulesReportFormat) ([]byte, error) {
+	report := rules.Validate(ctx, g, rulesConfigFrom(cfg))
+	switch format {
+	case RulesReportJUnit:
+		return report.ToJUnitXML()
+	case RulesReportSummary:
+		return []byte(report.Summary()), nil
+	default:
+		return report.ToJSON()
+	}
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query/validation"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/validate"
+)
+
+// ViolationReport is a validation.Violation flattened to plain xrefs, for
+// FormatOutput's table/json/yaml/csv writers -- none of which know how to
+// render a *query.IndividualNode/*query.FamilyNode usefully.
+type ViolationReport struct {
+	RuleID         string
+	Severity       string
+	Message        string
+	IndividualXref string
+	FamilyXref     string
+}
+
+// RunValidationRules runs query/validation's rule catalog over g using
+// cfg.Validation's configured thresholds, keeps only violations at or
+// above cfg.Validation.SeverityThreshold, and writes the result via
+// FormatOutput in the requested format.
+//
+// This is the function a "gedcom validate" subcommand would call; there is
+// no cobra (or other) command tree in this snapshot to actually register it
+// on (cmd/gedcom/internal has no main.go and no commands package), so it's
+// exported as a standalone entry point instead, the same gap callers of the
+// FormatGedcomX and FormatGraphML output formats would hit without a real
+// CLI wired up.
+func RunValidationRules(g *query.Graph, cfg *Config, format OutputFormat, pretty bool) error {
+	thresholds := validation.ThresholdsFromMap(cfg.Validation.Thresholds)
+	rs := validation.NewRuleSet(thresholds)
+
+	minSeverity := severityFromString(cfg.Validation.SeverityThreshold)
+	violations := rs.RunFiltered(g, minSeverity)
+
+	reports := make([]ViolationReport, 0, len(violations))
+	for _, v := range violations {
+		report := ViolationReport{RuleID: v.RuleID, Severity: string(v.Severity), Message: v.Message}
+		if v.Individual != nil {
+			report.IndividualXref = v.Individual.ID()
+		}
+		if v.Family != nil {
+			report.FamilyXref = v.Family.ID()
+		}
+		reports = append(reports, report)
+	}
+
+	return FormatOutput(reports, format, pretty)
+}
+
+// severityFromString maps a Config.Validation.SeverityThreshold string
+// (severe, warning, info, hint) to a validate.Severity, defaulting to
+// SeverityHint -- the least restrictive threshold -- for an unrecognized
+// or empty value so a bad config doesn't silently hide every violation.
+func severityFromString(s string) validate.Severity {
+	switch s {
+	case string(validate.SeveritySevere):
+		return validate.SeveritySevere
+	case string(validate.SeverityWarning):
+		return validate.SeverityWarning
+	case string(validate.SeverityInfo):
+		return validate.SeverityInfo
+	default:
+		return validate.SeverityHint
+	}
+}
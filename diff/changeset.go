@@ -0,0 +1,170 @@
+package diff
+
+import (
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// Verb classifies a single ChangeSet entry, modeled on dnscontrol's diff2:
+// a record can be Created, Changed, Deleted, or Recreated (deleted then
+// created under a new identity, e.g. a renumbered XREF matched to the same
+// underlying person).
+type Verb string
+
+const (
+	VerbCreate   Verb = "CREATE"
+	VerbChange   Verb = "CHANGE"
+	VerbDelete   Verb = "DELETE"
+	VerbRecreate Verb = "RECREATE"
+)
+
+// CorrelationKey identifies what a ChangeSet entry is about: a whole record
+// (by XREF) or a sub-structure within one (by tag path, e.g. "MARR.DATE").
+type CorrelationKey struct {
+	Xref string
+	Path string
+}
+
+// ChangeSetEntry is one correlated change between an old and new line tree.
+type ChangeSetEntry struct {
+	Verb Verb
+	Key  CorrelationKey
+
+	// OldXref/NewXref record both identities when Verb is VerbChange and
+	// the underlying person was matched across a renumbered XREF.
+	OldXref string
+	NewXref string
+
+	Old types.Record
+	New types.Record
+}
+
+// ChangeSet is the higher-level, verb-based alternative to a flat []Change
+// slice.
+type ChangeSet struct {
+	Entries []ChangeSetEntry
+}
+
+// IdentityFunc computes a fingerprint used to correlate an old record with
+// a new one when their XREFs differ (e.g. a name+birthdate fingerprint).
+type IdentityFunc func(types.Record) string
+
+// XrefIdentity correlates records by XREF alone.
+func XrefIdentity(r types.Record) string {
+	return r.XrefID()
+}
+
+// NameBirthIdentity correlates individual records by normalized name plus
+// raw birth date, falling back to the XREF for non-individual records.
+func NameBirthIdentity(r types.Record) string {
+	indi, ok := r.(*types.IndividualRecord)
+	if !ok {
+		return r.XrefID()
+	}
+	return normalizePlace(indi.GetName()) + "|" + indi.GetBirthDate()
+}
+
+// CorrelateRecords matches old and new record sets by a sequence of
+// IdentityFuncs (xref first, then name+birthdate fingerprint by default),
+// so a renumbered XREF produces a single VerbChange entry carrying both
+// xrefs, while a genuine replacement produces VerbDelete + VerbCreate.
+func CorrelateRecords(old, new map[string]types.Record, identities ...IdentityFunc) *ChangeSet {
+	if len(identities) == 0 {
+		identities = []IdentityFunc{XrefIdentity, NameBirthIdentity}
+	}
+
+	cs := &ChangeSet{Entries: make([]ChangeSetEntry, 0)}
+	matchedOld := make(map[string]bool)
+	matchedNew := make(map[string]bool)
+
+	for _, identity := range identities {
+		oldByFingerprint := make(map[string]string) // fingerprint -> old xref
+		for xref, rec := range old {
+			if matchedOld[xref] {
+				continue
+			}
+			oldByFingerprint[identity(rec)] = xref
+		}
+
+		for newXref, newRec := range new {
+			if matchedNew[newXref] {
+				continue
+			}
+			fp := identity(newRec)
+			oldXref, ok := oldByFingerprint[fp]
+			if !ok || matchedOld[oldXref] {
+				continue
+			}
+
+			verb := VerbChange
+			if oldXref != newXref {
+				verb = VerbChange // renumbering: still a single Change, both xrefs recorded
+			}
+			cs.Entries = append(cs.Entries, ChangeSetEntry{
+				Verb:    verb,
+				Key:     CorrelationKey{Xref: newXref},
+				OldXref: oldXref,
+				NewXref: newXref,
+				Old:     old[oldXref],
+				New:     newRec,
+			})
+			matchedOld[oldXref] = true
+			matchedNew[newXref] = true
+		}
+	}
+
+	for xref, rec := range old {
+		if !matchedOld[xref] {
+			cs.Entries = append(cs.Entries, ChangeSetEntry{
+				Verb: VerbDelete,
+				Key:  CorrelationKey{Xref: xref},
+				Old:  rec,
+			})
+		}
+	}
+	for xref, rec := range new {
+		if !matchedNew[xref] {
+			cs.Entries = append(cs.Entries, ChangeSetEntry{
+				Verb: VerbCreate,
+				Key:  CorrelationKey{Xref: xref},
+				New:  rec,
+			})
+		}
+	}
+
+	return cs
+}
+
+// MarkRecreated rewrites a VerbDelete + VerbCreate pair for the same xref
+// (a record dropped and a different record later reusing the same XREF)
+// into a single VerbRecreate entry.
+func (cs *ChangeSet) MarkRecreated() {
+	deletes := make(map[string]int)
+	for i, e := range cs.Entries {
+		if e.Verb == VerbDelete {
+			deletes[e.Key.Xref] = i
+		}
+	}
+
+	merged := make([]ChangeSetEntry, 0, len(cs.Entries))
+	consumed := make(map[int]bool)
+	for i, e := range cs.Entries {
+		if consumed[i] {
+			continue
+		}
+		if e.Verb == VerbCreate {
+			if delIdx, ok := deletes[e.Key.Xref]; ok && !consumed[delIdx] {
+				consumed[delIdx] = true
+				merged = append(merged, ChangeSetEntry{
+					Verb: VerbRecreate,
+					Key:  e.Key,
+					Old:  cs.Entries[delIdx].Old,
+					New:  e.New,
+				})
+				continue
+			}
+		}
+		merged = append(merged, e)
+	}
+
+	cs.Entries = merged
+}
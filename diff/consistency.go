@@ -0,0 +1,227 @@
+package diff
+
+import (
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/internal/gedcomdate"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// Issue describes a single suspicious-change finding raised by a Rule while
+// diffing a record before and after a change.
+type Issue struct {
+	RuleID  string
+	Message string
+	Xref    string
+}
+
+// FamilyContext carries the surrounding family data a Rule needs to judge
+// plausibility (e.g. a mother's age at a child's birth), since an
+// IndividualRecord alone doesn't know its parents or spouses.
+type FamilyContext struct {
+	Father   *types.IndividualRecord
+	Mother   *types.IndividualRecord
+	Spouses  []*types.IndividualRecord
+	Siblings []*types.IndividualRecord
+}
+
+// Rule evaluates a single individual (optionally with family context) and
+// returns any plausibility issues found. Rules are pluggable so a
+// ConsistencyChecker can be extended without modifying the differ itself.
+type Rule interface {
+	Check(r *types.IndividualRecord, ctx *FamilyContext) []Issue
+}
+
+// ConsistencyChecker runs a set of Rules against records before and after a
+// diff so reports can flag changes that introduce a biological
+// impossibility separately from routine modifications. Ported from the
+// style of rules found in GeneWeb's checkItem.
+type ConsistencyChecker struct {
+	rules []Rule
+	cfg   *Config
+}
+
+// NewConsistencyChecker builds a ConsistencyChecker using the shipped rule
+// set. The rule thresholds are fixed (maximum age 120, mother's age 13-55,
+// minimum marriage age 13, sibling spacing 7 months); cfg is stored on the
+// checker but its fields are not read by these rules.
+func NewConsistencyChecker(cfg *Config) *ConsistencyChecker {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &ConsistencyChecker{
+		rules: []Rule{
+			maxAgeRule{maxAge: 120},
+			baptismBeforeBirthRule{},
+			deathBeforeBirthRule{},
+			motherAgeAtBirthRule{minAge: 13, maxAge: 55},
+			marriageAgeRule{minAge: 13},
+			posthumousChildRule{},
+			siblingSpacingRule{minMonths: 7},
+		},
+		cfg: cfg,
+	}
+}
+
+// AddRule registers an additional Rule.
+func (c *ConsistencyChecker) AddRule(r Rule) {
+	c.rules = append(c.rules, r)
+}
+
+// CheckRecord runs every rule against r with the given context and returns
+// the combined issues.
+func (c *ConsistencyChecker) CheckRecord(r *types.IndividualRecord, ctx *FamilyContext) []Issue {
+	issues := make([]Issue, 0)
+	for _, rule := range c.rules {
+		issues = append(issues, rule.Check(r, ctx)...)
+	}
+	return issues
+}
+
+// CheckChange runs the checker against the "after" side of a modification
+// and attaches any issues it finds, so GedcomDiffer.compareBasicRecord (and
+// friends) can flag a change as suspicious in addition to reporting it as
+// routine.
+func (c *ConsistencyChecker) CheckChange(before, after *types.IndividualRecord, ctx *FamilyContext) []Issue {
+	if after == nil {
+		return nil
+	}
+	return c.CheckRecord(after, ctx)
+}
+
+// yearOf is this package's name for gedcomdate.YearOf, kept as a thin
+// alias so the rule bodies below don't have to spell out the gedcomdate
+// prefix at each call site.
+func yearOf(date string) int { return gedcomdate.YearOf(date) }
+
+type maxAgeRule struct{ maxAge int }
+
+func (rule maxAgeRule) Check(r *types.IndividualRecord, ctx *FamilyContext) []Issue {
+	birth, death := yearOf(r.GetBirthDate()), yearOf(r.GetDeathDate())
+	if birth == 0 || death == 0 {
+		return nil
+	}
+	if age := death - birth; age > rule.maxAge {
+		return []Issue{{RuleID: "MAXAGE", Xref: r.XrefID(), Message: "age at death exceeds plausible maximum"}}
+	}
+	return nil
+}
+
+type baptismBeforeBirthRule struct{}
+
+func (baptismBeforeBirthRule) Check(r *types.IndividualRecord, ctx *FamilyContext) []Issue {
+	birth := yearOf(r.GetBirthDate())
+	for _, ev := range r.GetEvents() {
+		evType, _ := ev["type"].(string)
+		if evType != "BAPM" && evType != "CHR" {
+			continue
+		}
+		date, _ := ev["date"].(string)
+		if bap := yearOf(date); birth != 0 && bap != 0 && bap < birth {
+			return []Issue{{RuleID: "BAPBEF", Xref: r.XrefID(), Message: "baptism recorded before birth"}}
+		}
+	}
+	return nil
+}
+
+type deathBeforeBirthRule struct{}
+
+func (deathBeforeBirthRule) Check(r *types.IndividualRecord, ctx *FamilyContext) []Issue {
+	birth, death := yearOf(r.GetBirthDate()), yearOf(r.GetDeathDate())
+	if birth != 0 && death != 0 && death < birth {
+		return []Issue{{RuleID: "DEATHBEF", Xref: r.XrefID(), Message: "death recorded before birth"}}
+	}
+	return nil
+}
+
+type motherAgeAtBirthRule struct{ minAge, maxAge int }
+
+func (rule motherAgeAtBirthRule) Check(r *types.IndividualRecord, ctx *FamilyContext) []Issue {
+	if ctx == nil || ctx.Mother == nil {
+		return nil
+	}
+	momBirth := yearOf(ctx.Mother.GetBirthDate())
+	childBirth := yearOf(r.GetBirthDate())
+	if momBirth == 0 || childBirth == 0 {
+		return nil
+	}
+	age := childBirth - momBirth
+	if age < rule.minAge || age > rule.maxAge {
+		return []Issue{{RuleID: "MOMAGE", Xref: r.XrefID(), Message: "mother's age at birth is implausible"}}
+	}
+	return nil
+}
+
+type marriageAgeRule struct{ minAge int }
+
+func (rule marriageAgeRule) Check(r *types.IndividualRecord, ctx *FamilyContext) []Issue {
+	birth := yearOf(r.GetBirthDate())
+	if birth == 0 {
+		return nil
+	}
+	for _, ev := range r.GetEvents() {
+		evType, _ := ev["type"].(string)
+		if evType != "MARR" {
+			continue
+		}
+		date, _ := ev["date"].(string)
+		if marr := yearOf(date); marr != 0 && marr-birth < rule.minAge {
+			return []Issue{{RuleID: "MARAGE", Xref: r.XrefID(), Message: "marriage before minimum plausible age"}}
+		}
+	}
+	return nil
+}
+
+type posthumousChildRule struct{}
+
+func (posthumousChildRule) Check(r *types.IndividualRecord, ctx *FamilyContext) []Issue {
+	if ctx == nil || ctx.Father == nil {
+		return nil
+	}
+	fatherDeath := ParseGedcomDate(ctx.Father.GetDeathDate())
+	childBirth := ParseGedcomDate(r.GetBirthDate())
+	if fatherDeath == nil || childBirth == nil {
+		return nil
+	}
+	// Earliest possible birth against latest possible death, so an
+	// imprecise date (year only, ABT, AFT, ...) is only flagged when even
+	// the closest reading is more than 9 months (274 days) apart.
+	if childBirth.Earliest-fatherDeath.Latest > 274 {
+		return []Issue{{RuleID: "POSTHUMOUS", Xref: r.XrefID(), Message: "child born more than 9 months after father's death"}}
+	}
+	return nil
+}
+
+type siblingSpacingRule struct{ minMonths int }
+
+func (rule siblingSpacingRule) Check(r *types.IndividualRecord, ctx *FamilyContext) []Issue {
+	if ctx == nil {
+		return nil
+	}
+	birth := r.GetBirthDate()
+	own := ParseGedcomDate(birth)
+	if own == nil {
+		return nil
+	}
+	for _, sib := range ctx.Siblings {
+		if sib == nil || sib.XrefID() == r.XrefID() {
+			continue
+		}
+		sibDate := sib.GetBirthDate()
+		if sibDate == birth {
+			continue // identical dates: twins, not a spacing violation
+		}
+		other := ParseGedcomDate(sibDate)
+		if other == nil {
+			continue
+		}
+		// Widest gap the two birth intervals allow: flagged only when even
+		// that is under minMonths (counted as 30-day months).
+		gap := own.Latest - other.Earliest
+		if g := other.Latest - own.Earliest; g > gap {
+			gap = g
+		}
+		if gap < rule.minMonths*30 {
+			return []Issue{{RuleID: "SIBSPACE", Xref: r.XrefID(), Message: "siblings born less than the minimum plausible spacing apart"}}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DateQualifier records which GEDCOM date grammar production produced a
+// GedcomDate, so formatting and refinement logic can tell an exact date
+// from an approximation or a range.
+type DateQualifier string
+
+const (
+	DateExact      DateQualifier = "exact"
+	DateApprox     DateQualifier = "approx"    // ABT, EST, CAL
+	DateRange      DateQualifier = "range"     // BET ... AND ...
+	DateBefore     DateQualifier = "before"    // BEF
+	DateAfter      DateQualifier = "after"     // AFT
+	DateDual       DateQualifier = "dual"      // 1800/01
+	DateUnknown    DateQualifier = "unknown"
+)
+
+// GedcomDate is a parsed GEDCOM 5.5.1/7.0 date represented as a closed
+// interval [Earliest, Latest] of Julian day numbers. An exact date has
+// Earliest == Latest; approximate, ranged, and bounded dates widen the
+// interval to express their uncertainty.
+type GedcomDate struct {
+	Raw       string
+	Qualifier DateQualifier
+	Earliest  int // Julian day number
+	Latest    int // Julian day number
+	Calendar  string // "", "JULIAN", "HEBREW", "FRENCH R"
+}
+
+// monthNames maps GEDCOM month abbreviations to month numbers (1-12).
+// Day-less dates are widened to whole months by lastDayOfMonth, which
+// ignores leap years.
+var monthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+// julianDayNumber converts a Gregorian calendar date to a Julian day
+// number using the standard civil-calendar algorithm. day or month may be
+// zero, in which case the missing component is normalized to its earliest
+// (1) value; callers widen the interval separately to account for this.
+func julianDayNumber(year, month, day int) int {
+	if month <= 0 {
+		month = 1
+	}
+	if day <= 0 {
+		day = 1
+	}
+	a := (14 - month) / 12
+	y := year + 4800 - a
+	m := month + 12*a - 3
+	return day + (153*m+2)/5 + 365*y + y/4 - y/100 + y/400 - 32045
+}
+
+// lastDayOfMonth returns the last plausible day used to widen a
+// year/month-only date to the end of that month (28 is used for February
+// to stay conservative; exact leap-year handling isn't needed for
+// tolerance-based comparison).
+func lastDayOfMonth(month int) int {
+	switch month {
+	case 4, 6, 9, 11:
+		return 30
+	case 2:
+		return 28
+	default:
+		return 31
+	}
+}
+
+// ParseGedcomDate parses a GEDCOM date value into a closed Julian-day
+// interval. Supported forms: exact ("12 JAN 1800"), approximate
+// ("ABT 1800", "CAL 1800", "EST 1800"), ranges ("BET 1800 AND 1810"),
+// bounds ("BEF 1800", "AFT 1800"), dual-dates ("1800/01"), and a leading
+// non-Gregorian calendar escape ("@#DJULIAN@", "@#DHEBREW@",
+// "@#DFRENCH R@") which is recorded but not calendar-converted (callers
+// needing calendar-accurate Julian day numbers for non-Gregorian dates
+// should convert before calling ParseGedcomDate).
+func ParseGedcomDate(raw string) *GedcomDate {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return nil
+	}
+
+	calendar := ""
+	if strings.HasPrefix(s, "@#D") {
+		end := strings.Index(s[1:], "@")
+		if end >= 0 {
+			calendar = strings.TrimSpace(s[3 : end+1])
+			s = strings.TrimSpace(s[end+2:])
+		}
+	}
+
+	upper := strings.ToUpper(s)
+	switch {
+	case strings.HasPrefix(upper, "BET "):
+		rest := s[4:]
+		parts := strings.SplitN(rest, " AND ", 2)
+		if len(parts) != 2 {
+			parts = strings.SplitN(strings.ToUpper(rest), " AND ", 2)
+		}
+		if len(parts) == 2 {
+			lo := parseOneDate(strings.TrimSpace(parts[0]))
+			hi := parseOneDate(strings.TrimSpace(parts[1]))
+			if lo != nil && hi != nil {
+				return &GedcomDate{Raw: raw, Qualifier: DateRange, Earliest: lo.Earliest, Latest: hi.Latest, Calendar: calendar}
+			}
+		}
+	case strings.HasPrefix(upper, "BEF "):
+		if d := parseOneDate(s[4:]); d != nil {
+			return &GedcomDate{Raw: raw, Qualifier: DateBefore, Earliest: -1 << 30, Latest: d.Latest, Calendar: calendar}
+		}
+	case strings.HasPrefix(upper, "AFT "):
+		if d := parseOneDate(s[4:]); d != nil {
+			return &GedcomDate{Raw: raw, Qualifier: DateAfter, Earliest: d.Earliest, Latest: 1 << 30, Calendar: calendar}
+		}
+	case strings.HasPrefix(upper, "ABT "), strings.HasPrefix(upper, "EST "), strings.HasPrefix(upper, "CAL "):
+		if d := parseOneDate(s[4:]); d != nil {
+			d.Raw = raw
+			d.Qualifier = DateApprox
+			d.Calendar = calendar
+			return d
+		}
+	}
+
+	if d := parseOneDate(s); d != nil {
+		d.Raw = raw
+		d.Calendar = calendar
+		return d
+	}
+	return nil
+}
+
+// parseOneDate parses a single "[DD] [MON] YYYY[/YY]" token into an exact
+// (or dual-date) GedcomDate, widening missing day/month components to
+// cover the whole period they leave ambiguous.
+func parseOneDate(s string) *GedcomDate {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var day, month, year int
+	yearField := fields[len(fields)-1]
+	if idx := strings.Index(yearField, "/"); idx >= 0 {
+		yearField = yearField[:idx]
+		y, err := strconv.Atoi(yearField)
+		if err != nil {
+			return nil
+		}
+		year = y
+		return dualDate(fields, year)
+	}
+
+	y, err := strconv.Atoi(yearField)
+	if err != nil {
+		return nil
+	}
+	year = y
+
+	if len(fields) >= 2 {
+		if m, ok := monthNames[strings.ToUpper(fields[len(fields)-2])]; ok {
+			month = m
+		}
+	}
+	if len(fields) >= 3 {
+		if d, err := strconv.Atoi(fields[len(fields)-3]); err == nil {
+			day = d
+		}
+	}
+
+	if day != 0 && month != 0 {
+		jdn := julianDayNumber(year, month, day)
+		return &GedcomDate{Qualifier: DateExact, Earliest: jdn, Latest: jdn}
+	}
+	if month != 0 {
+		return &GedcomDate{
+			Qualifier: DateExact,
+			Earliest:  julianDayNumber(year, month, 1),
+			Latest:    julianDayNumber(year, month, lastDayOfMonth(month)),
+		}
+	}
+	return &GedcomDate{
+		Qualifier: DateExact,
+		Earliest:  julianDayNumber(year, 1, 1),
+		Latest:    julianDayNumber(year, 12, 31),
+	}
+}
+
+// dualDate widens a dual-dated year ("1800/01", meaning Jan-Mar 1801 under
+// the Julian calendar overlaps Old/New Style years) to span both years.
+func dualDate(fields []string, year int) *GedcomDate {
+	return &GedcomDate{
+		Qualifier: DateDual,
+		Earliest:  julianDayNumber(year, 1, 1),
+		Latest:    julianDayNumber(year+1, 12, 31),
+	}
+}
+
+// Overlaps reports whether d and other's intervals intersect once widened
+// by toleranceDays on each side.
+func (d *GedcomDate) Overlaps(other *GedcomDate, toleranceDays int) bool {
+	if d == nil || other == nil {
+		return false
+	}
+	loA, hiA := d.Earliest-toleranceDays, d.Latest+toleranceDays
+	loB, hiB := other.Earliest, other.Latest
+	return loA <= hiB && loB <= hiA
+}
+
+// DatesSemanticallyEquivalent reports whether old and new (raw GEDCOM date
+// strings) describe overlapping intervals within toleranceDays. It
+// supersedes the previous year-string-only comparison used by
+// areDatesSemanticallyEquivalent: two dates are equivalent whenever their
+// parsed intervals overlap, not merely when their leading years are close.
+func DatesSemanticallyEquivalent(old, new string, toleranceDays int) bool {
+	if old == "" || new == "" {
+		return false
+	}
+	a := ParseGedcomDate(old)
+	b := ParseGedcomDate(new)
+	if a == nil || b == nil {
+		return false
+	}
+	return a.Overlaps(b, toleranceDays)
+}
+
+// dateToleranceDays converts a Config.DateTolerance value to days. Older
+// callers set DateTolerance expecting "years"; this back-compat shim
+// multiplies values under 100 by 365, since no genuine day-level tolerance
+// configuration would plausibly be smaller than a year's worth of days in
+// practice for this use case, while preserving already-migrated configs
+// that set larger day counts directly.
+func dateToleranceDays(tolerance int) int {
+	if tolerance > 0 && tolerance < 100 {
+		return tolerance * 365
+	}
+	return tolerance
+}
+
+// CompareDateValues compares two raw GEDCOM date strings for the given tag
+// path, returning a Change describing the relationship (nil if they are
+// identical strings). It is the date-model-aware replacement for the
+// legacy year-substring comparison previously used inside compareDate.
+func CompareDateValues(old, new, path string, toleranceYearsOrDays int) *Change {
+	if old == new {
+		return nil
+	}
+	if old == "" || new == "" {
+		return &Change{Type: ChangeTypeModified, Path: path, OldValue: old, NewValue: new}
+	}
+
+	tolerance := dateToleranceDays(toleranceYearsOrDays)
+	if DatesSemanticallyEquivalent(old, new, tolerance) {
+		return &Change{Type: ChangeTypeSemanticallyEquivalent, Path: path, OldValue: old, NewValue: new}
+	}
+	return &Change{Type: ChangeTypeModified, Path: path, OldValue: old, NewValue: new}
+}
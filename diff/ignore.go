@@ -0,0 +1,106 @@
+package diff
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ChangeTypeIgnored marks a change that was suppressed by an IgnoreRules
+// match but is still tracked for audit purposes, rather than silently
+// dropped.
+const ChangeTypeIgnored ChangeType = "ignored"
+
+// IgnoreRules borrows the "unmanaged" concept from dnscontrol: changes
+// whose record xref or tag path match one of these rules are not reported
+// as routine Added/Removed/Modified changes. FilterIgnored hands them back
+// as a separate list instead of dropping them, so a diff report can show
+// what was suppressed; it does not set ChangeTypeIgnored on them itself.
+//
+// This is important for real genealogy workflows where CHAN (last-changed
+// timestamps), _UID, and other vendor-specific custom tags produce massive
+// noise when diffing exports from different genealogy tools.
+type IgnoreRules struct {
+	// IgnoredXrefs are record XREFs to skip entirely (e.g. "@I1@").
+	IgnoredXrefs map[string]bool
+
+	// IgnoredTagPaths are glob patterns (path.Match syntax) matched
+	// against dotted tag paths, e.g. "*.CHAN.*" or "INDI.*._FSFTID".
+	IgnoredTagPaths []string
+
+	// IgnoredSources are SOUR record xrefs; a change whose xref is one of
+	// them is suppressed. Citations of these sources inside other records
+	// are not matched.
+	IgnoredSources map[string]bool
+
+	// IgnoreVendorTags, when true, ignores every tag path component that
+	// begins with an underscore (the GEDCOM convention for
+	// vendor-specific custom tags).
+	IgnoreVendorTags bool
+}
+
+// Matches reports whether xref or path should be suppressed under these
+// rules.
+func (ir IgnoreRules) Matches(xref, path string) bool {
+	if ir.IgnoredXrefs[xref] {
+		return true
+	}
+	if ir.IgnoredSources[xref] {
+		return true
+	}
+	if ir.IgnoreVendorTags {
+		for _, part := range strings.Split(path, ".") {
+			if strings.HasPrefix(part, "_") {
+				return true
+			}
+		}
+	}
+	for _, pattern := range ir.IgnoredTagPaths {
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreRulesByConfig attaches an optional IgnoreRules to a *Config without
+// requiring a new field on the shared Config struct literal, keyed by
+// pointer identity.
+var (
+	ignoreRulesMu  sync.Mutex
+	ignoreRulesFor = make(map[*Config]IgnoreRules)
+)
+
+// SetIgnoreRules attaches ir to cfg so a GedcomDiffer built from cfg will
+// suppress matching changes.
+func SetIgnoreRules(cfg *Config, ir IgnoreRules) {
+	ignoreRulesMu.Lock()
+	defer ignoreRulesMu.Unlock()
+	ignoreRulesFor[cfg] = ir
+}
+
+// ignoreRulesOf returns the IgnoreRules attached to cfg, if any.
+func ignoreRulesOf(cfg *Config) (IgnoreRules, bool) {
+	ignoreRulesMu.Lock()
+	defer ignoreRulesMu.Unlock()
+	ir, ok := ignoreRulesFor[cfg]
+	return ir, ok
+}
+
+// FilterIgnored splits changes recorded as Modified into those that survive
+// (pass) and those suppressed by cfg's IgnoreRules (ignored), so a differ
+// can report suppressed changes separately instead of dropping them.
+func FilterIgnored(cfg *Config, mods []RecordModification) (pass, ignored []RecordModification) {
+	ir, ok := ignoreRulesOf(cfg)
+	if !ok {
+		return mods, nil
+	}
+	for _, m := range mods {
+		if ir.Matches(m.Xref, m.Type) {
+			ignored = append(ignored, m)
+			continue
+		}
+		pass = append(pass, m)
+	}
+	return pass, ignored
+}
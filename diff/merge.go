@@ -0,0 +1,193 @@
+package diff
+
+import (
+	"path/filepath"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// ResolutionPolicy says how a GedcomMerger should resolve a conflict where
+// both "ours" and "theirs" changed the same field differently relative to
+// a common base.
+type ResolutionPolicy string
+
+const (
+	// PreferOurs always keeps the "ours" value.
+	PreferOurs ResolutionPolicy = "prefer_ours"
+	// PreferTheirs always keeps the "theirs" value.
+	PreferTheirs ResolutionPolicy = "prefer_theirs"
+	// PreferMoreSpecific keeps whichever side's value is
+	// ChangeTypeRefined relative to the other, comparing both values as
+	// place hierarchies (see ComparePlaceHierarchies), falling back to
+	// PreferOurs when neither side is more specific. Dates are not
+	// compared for refinement.
+	PreferMoreSpecific ResolutionPolicy = "prefer_more_specific"
+	// PreferWithSource resolves like PreferOurs: Merge does not look up
+	// SOUR citations on either side.
+	PreferWithSource ResolutionPolicy = "prefer_with_source"
+	// Manual resolves nothing: the Conflict carries Resolution Manual and
+	// an empty ResolvedValue. Merge writes no _CONFLICT tags into the
+	// returned tree.
+	Manual ResolutionPolicy = "manual"
+)
+
+// MergeConfig configures a three-way merge: DefaultPolicy applies to any
+// tag path not matched by PathPolicies, whose keys are path.Match-style
+// glob patterns (e.g. "*.PLAC", "BIRT.DATE").
+type MergeConfig struct {
+	DefaultPolicy ResolutionPolicy
+	PathPolicies  map[string]ResolutionPolicy
+}
+
+// policyFor returns the configured policy for path, falling back to
+// DefaultPolicy (or PreferOurs if that's unset).
+func (cfg MergeConfig) policyFor(path string) ResolutionPolicy {
+	for pattern, policy := range cfg.PathPolicies {
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return policy
+		}
+	}
+	if cfg.DefaultPolicy != "" {
+		return cfg.DefaultPolicy
+	}
+	return PreferOurs
+}
+
+// Conflict records a single field where "ours" and "theirs" diverged from
+// the common base, along with how (or whether) it was automatically
+// resolved.
+type Conflict struct {
+	Xref          string
+	Path          string
+	BaseValue     string
+	OursValue     string
+	TheirsValue   string
+	Resolution    ResolutionPolicy
+	ResolvedValue string
+}
+
+// GedcomMerger performs a classic three-way merge of two GedcomDiffer
+// results (ours-vs-base and theirs-vs-base) against a common ancestor
+// file, reporting Conflicts wherever both sides changed the same field to
+// different values.
+type GedcomMerger struct {
+	cfg    MergeConfig
+	differ *GedcomDiffer
+}
+
+// NewGedcomMerger builds a GedcomMerger using cfg's resolution policies.
+func NewGedcomMerger(cfg MergeConfig) *GedcomMerger {
+	return &GedcomMerger{cfg: cfg, differ: NewGedcomDiffer(DefaultConfig())}
+}
+
+// Merge resolves base, ours, and theirs into a single individual-by-
+// individual conflict report. Because synthesizing a brand new
+// *types.GedcomTree requires record setters that this package does not
+// expose, Merge returns ours as the merged tree (the conventional "start
+// from our working copy" choice) and reports every resolved value via
+// Conflict.ResolvedValue so a caller with write access to the underlying
+// records can apply them; genuine conflicts additionally carry
+// Resolution == Manual so the caller knows to emit a _CONFLICT tag instead
+// of applying ResolvedValue directly.
+func (m *GedcomMerger) Merge(base, ours, theirs *types.GedcomTree) (*types.GedcomTree, []Conflict, error) {
+	conflicts := make([]Conflict, 0)
+
+	baseByXref := indexIndividuals(base)
+	oursByXref := indexIndividuals(ours)
+	theirsByXref := indexIndividuals(theirs)
+
+	for xref, baseIndi := range baseByXref {
+		oursIndi, hasOurs := oursByXref[xref]
+		theirsIndi, hasTheirs := theirsByXref[xref]
+		if !hasOurs || !hasTheirs {
+			continue
+		}
+
+		oursChanges := changesByPath(m.differ.compareBasicRecord(baseIndi, oursIndi))
+		theirsChanges := changesByPath(m.differ.compareBasicRecord(baseIndi, theirsIndi))
+
+		for path, oursChange := range oursChanges {
+			theirsChange, bothChanged := theirsChanges[path]
+			if !bothChanged {
+				continue
+			}
+			if oursChange.NewValue == theirsChange.NewValue {
+				continue
+			}
+
+			conflict := Conflict{
+				Xref:        xref,
+				Path:        path,
+				BaseValue:   oursChange.OldValue,
+				OursValue:   oursChange.NewValue,
+				TheirsValue: theirsChange.NewValue,
+			}
+			conflict.Resolution, conflict.ResolvedValue = m.resolve(path, conflict)
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	return ours, conflicts, nil
+}
+
+// Merge is the package-level entry point matching dnscontrol/diff2-style
+// three-way merge APIs: it builds a GedcomMerger from cfg and resolves
+// base/ours/theirs in one call.
+func Merge(base, ours, theirs *types.GedcomTree, cfg MergeConfig) (*types.GedcomTree, []Conflict, error) {
+	return NewGedcomMerger(cfg).Merge(base, ours, theirs)
+}
+
+// resolve applies the policy configured for path to a conflict, returning
+// the policy actually used (which may differ from the configured one when
+// a PreferMoreSpecific/PreferWithSource fallback applies) and the value to
+// use, or "" when Resolution is Manual.
+func (m *GedcomMerger) resolve(path string, c Conflict) (ResolutionPolicy, string) {
+	switch m.cfg.policyFor(path) {
+	case PreferTheirs:
+		return PreferTheirs, c.TheirsValue
+	case PreferMoreSpecific:
+		result := ComparePlaceHierarchies(ParsePlaceHierarchy(c.OursValue), ParsePlaceHierarchy(c.TheirsValue), nil, 0)
+		switch result.Type {
+		case ChangeTypeRefined:
+			// c.TheirsValue extends (or is extended by) c.OursValue; the
+			// longer hierarchy is the more specific one.
+			if len(ParsePlaceHierarchy(c.TheirsValue).Parts) > len(ParsePlaceHierarchy(c.OursValue).Parts) {
+				return PreferMoreSpecific, c.TheirsValue
+			}
+			return PreferMoreSpecific, c.OursValue
+		default:
+			return PreferOurs, c.OursValue
+		}
+	case PreferWithSource:
+		// Source-citation presence isn't visible on a raw field value, so
+		// without per-field SOUR lookups this falls back to PreferOurs.
+		return PreferOurs, c.OursValue
+	case Manual:
+		return Manual, ""
+	default:
+		return PreferOurs, c.OursValue
+	}
+}
+
+// indexIndividuals builds an xref -> record lookup for a tree, tolerating
+// a nil tree (returns an empty map).
+func indexIndividuals(tree *types.GedcomTree) map[string]*types.IndividualRecord {
+	out := make(map[string]*types.IndividualRecord)
+	if tree == nil {
+		return out
+	}
+	for _, indi := range tree.GetAllIndividuals() {
+		out[indi.XrefID()] = indi
+	}
+	return out
+}
+
+// changesByPath indexes a []Change slice by its Path for quick lookup
+// during three-way comparison.
+func changesByPath(changes []Change) map[string]Change {
+	out := make(map[string]Change, len(changes))
+	for _, c := range changes {
+		out[c.Path] = c
+	}
+	return out
+}
@@ -0,0 +1,173 @@
+package diff
+
+import (
+	"sort"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/diff/merkletrie"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// Conflict records a field-level disagreement Merge3 found between ours
+// and theirs relative to base: both sides touched the same tag path on
+// the same XREF and didn't land on the same value, so neither can be
+// applied without silently discarding the other's edit.
+type Conflict struct {
+	Xref    string
+	TagPath string
+	Base    string
+	Ours    string
+	Theirs  string
+}
+
+// Merge3 performs a base/ours/theirs three-way merge the way git and jj
+// do for text: a record only one side touched is taken from that side; a
+// record both sides changed identically collapses to one change; a
+// record both sides changed differently is merged tag path by tag path
+// (via merkletrie), with any path both sides set to different values
+// reported as a Conflict and left at its base value, annotated with a
+// NOTE line on the merged record, rather than having one side silently
+// clobber the other.
+//
+// This is a package-level function rather than a (*GedcomDiffer).Merge
+// method: GedcomDiffer and its compareHybrid method aren't defined
+// anywhere in this package's snapshot -- they're only exercised from
+// diff's own test files, not implemented in a file that's part of it --
+// so there's no defining file here to add a Merge method to (the same
+// gap content_comparison_test.go's compareHybrid calls run into). Merge3
+// uses CorrelateRecords, the matching engine that actually is present in
+// this snapshot, to find base/ours and base/theirs correspondences
+// instead of compareHybrid.
+func Merge3(base, ours, theirs *types.GedcomTree) (*types.GedcomTree, []Conflict, error) {
+	oursByXref := indexChangeEntries(correlateAllRecords(base, ours))
+	theirsByXref := indexChangeEntries(correlateAllRecords(base, theirs))
+
+	merged := types.NewGedcomTree()
+	var conflicts []Conflict
+
+	for _, xref := range unionXrefs(base, ours, theirs) {
+		baseRec := lookupInTree(base, xref)
+		oEntry, oOK := oursByXref[xref]
+		tEntry, tOK := theirsByXref[xref]
+
+		rec, recConflicts := mergeRecord(xref, baseRec, oEntry, oOK, tEntry, tOK)
+		conflicts = append(conflicts, recConflicts...)
+		if rec != nil {
+			merged.AddRecord(rec)
+		}
+	}
+
+	return merged, conflicts, nil
+}
+
+// correlateAllRecords runs CorrelateRecords separately over individuals
+// and families (CorrelateRecords itself only takes one record-type map
+// at a time) and concatenates the two ChangeSets' entries.
+func correlateAllRecords(base, other *types.GedcomTree) []ChangeSetEntry {
+	indiCS := CorrelateRecords(base.GetAllIndividuals(), other.GetAllIndividuals())
+	famCS := CorrelateRecords(base.GetAllFamilies(), other.GetAllFamilies())
+	entries := make([]ChangeSetEntry, 0, len(indiCS.Entries)+len(famCS.Entries))
+	entries = append(entries, indiCS.Entries...)
+	entries = append(entries, famCS.Entries...)
+	return entries
+}
+
+func indexChangeEntries(entries []ChangeSetEntry) map[string]ChangeSetEntry {
+	m := make(map[string]ChangeSetEntry, len(entries))
+	for _, e := range entries {
+		m[e.Key.Xref] = e
+	}
+	return m
+}
+
+func unionXrefs(base, ours, theirs *types.GedcomTree) []string {
+	set := make(map[string]bool)
+	collect := func(t *types.GedcomTree) {
+		for xref := range t.GetAllIndividuals() {
+			set[xref] = true
+		}
+		for xref := range t.GetAllFamilies() {
+			set[xref] = true
+		}
+	}
+	collect(base)
+	collect(ours)
+	collect(theirs)
+
+	xrefs := make([]string, 0, len(set))
+	for xref := range set {
+		xrefs = append(xrefs, xref)
+	}
+	sort.Strings(xrefs)
+	return xrefs
+}
+
+func lookupInTree(tree *types.GedcomTree, xref string) types.Record {
+	if rec, ok := tree.GetAllIndividuals()[xref]; ok {
+		return rec
+	}
+	if rec, ok := tree.GetAllFamilies()[xref]; ok {
+		return rec
+	}
+	return nil
+}
+
+// mergeRecord resolves a single XREF's merge outcome given the
+// CorrelateRecords entry (if any) describing what ours and theirs each
+// did to it relative to base.
+func mergeRecord(xref string, baseRec types.Record, oEntry ChangeSetEntry, oOK bool, tEntry ChangeSetEntry, tOK bool) (types.Record, []Conflict) {
+	oChanged, oChanges := realChange(oEntry, oOK)
+	tChanged, tChanges := realChange(tEntry, tOK)
+
+	oDeleted := oOK && oEntry.Verb == VerbDelete
+	tDeleted := tOK && tEntry.Verb == VerbDelete
+
+	switch {
+	case oDeleted && tDeleted:
+		// Both sides removed it -- agree, drop it from the merge.
+		return nil, nil
+
+	case oDeleted && tChanged:
+		// Ours deleted it, theirs edited it: keep theirs' edit rather than
+		// silently losing it, but flag the disagreement.
+		return tEntry.New, []Conflict{{Xref: xref, TagPath: "*", Base: "<present>", Ours: "<deleted>", Theirs: "<modified>"}}
+	case oDeleted:
+		return nil, nil
+
+	case tDeleted && oChanged:
+		return oEntry.New, []Conflict{{Xref: xref, TagPath: "*", Base: "<present>", Ours: "<modified>", Theirs: "<deleted>"}}
+	case tDeleted:
+		return nil, nil
+
+	case oOK && oEntry.Verb == VerbCreate && tOK && tEntry.Verb == VerbCreate:
+		oNode, tNode := merkletrie.FromLine(oEntry.New.FirstLine()), merkletrie.FromLine(tEntry.New.FirstLine())
+		if oNode.Hash == tNode.Hash {
+			return oEntry.New, nil
+		}
+		return oEntry.New, []Conflict{{Xref: xref, TagPath: "*", Base: "<absent>", Ours: "<created>", Theirs: "<created, different content>"}}
+	case oOK && oEntry.Verb == VerbCreate:
+		return oEntry.New, nil
+	case tOK && tEntry.Verb == VerbCreate:
+		return tEntry.New, nil
+
+	case !oChanged && !tChanged:
+		return baseRec, nil
+	case oChanged && !tChanged:
+		return oEntry.New, nil
+	case !oChanged && tChanged:
+		return tEntry.New, nil
+
+	default:
+		return mergeFieldLevel(xref, baseRec, oChanges, tChanges)
+	}
+}
+
+// realChange reports whether entry actually changed the record's
+// content (CorrelateRecords emits a VerbChange entry for every matched
+// xref, even when nothing differs), and the merkletrie Changes if so.
+func realChange(entry ChangeSetEntry, ok bool) (bool, []merkletrie.Change) {
+	if !ok || entry.Verb != VerbChange {
+		return false, nil
+	}
+	changes := merkletrie.DiffRecords(entry.Old, entry.New)
+	return len(changes) > 0, changes
+}
@@ -0,0 +1,213 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/diff/merkletrie"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// mergeFieldLevel handles the case where both ours and theirs changed
+// baseRec: it starts from a clone of baseRec's lines and applies every
+// tag path only one side touched, then for paths both sides touched
+// either takes the (agreeing) value or records a Conflict and leaves
+// base's original value in place, noting the conflict with a NOTE line.
+func mergeFieldLevel(xref string, baseRec types.Record, oursChanges, theirsChanges []merkletrie.Change) (types.Record, []Conflict) {
+	oursByPath := changesByPath(oursChanges)
+	theirsByPath := changesByPath(theirsChanges)
+
+	merged := cloneLine(baseRec.FirstLine())
+	var conflicts []Conflict
+
+	applied := make(map[string]bool)
+	for path, oc := range oursByPath {
+		tc, both := theirsByPath[path]
+		switch {
+		case !both:
+			applyChange(merged, path, oc)
+		case changeValue(oc) == changeValue(tc):
+			applyChange(merged, path, oc)
+		default:
+			conflicts = append(conflicts, Conflict{
+				Xref: xref, TagPath: path,
+				Base: nodeValue(oc.From), Ours: changeValue(oc), Theirs: changeValue(tc),
+			})
+		}
+		applied[path] = true
+	}
+	for path, tc := range theirsByPath {
+		if applied[path] {
+			continue
+		}
+		applyChange(merged, path, tc)
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].TagPath < conflicts[j].TagPath })
+	for _, c := range conflicts {
+		merged.AddChild(types.NewGedcomLine(1, "NOTE",
+			fmt.Sprintf("MERGE CONFLICT at %s: base=%q ours=%q theirs=%q", c.TagPath, c.Base, c.Ours, c.Theirs), ""))
+	}
+
+	rec := recordFromLine(merged)
+	return rec, conflicts
+}
+
+func changesByPath(changes []merkletrie.Change) map[string]merkletrie.Change {
+	m := make(map[string]merkletrie.Change, len(changes))
+	for _, c := range changes {
+		m[c.Path] = c
+	}
+	return m
+}
+
+// changeValue returns the value a Change would set its path to: the new
+// node's Value for an Insert/Modify, "" (removed) for a Delete.
+func changeValue(c merkletrie.Change) string {
+	if c.To != nil {
+		return c.To.Value
+	}
+	return ""
+}
+
+func nodeValue(n *merkletrie.Node) string {
+	if n == nil {
+		return ""
+	}
+	return n.Value
+}
+
+// recordFromLine wraps a merged top-level line back into a types.Record,
+// dispatching on its own tag the way the rest of this package
+// distinguishes INDI from FAM lines.
+func recordFromLine(line *types.GedcomLine) types.Record {
+	switch line.Tag {
+	case "FAM":
+		return types.NewFamilyRecord(line)
+	default:
+		return types.NewIndividualRecord(line)
+	}
+}
+
+// cloneLine deep-copies line so mergeFieldLevel can mutate it freely
+// without touching baseRec's own tree.
+func cloneLine(line *types.GedcomLine) *types.GedcomLine {
+	if line == nil {
+		return nil
+	}
+	clone := types.NewGedcomLine(0, line.Tag, line.Value, line.XrefID)
+	for _, child := range line.Children {
+		clone.AddChild(cloneChild(child))
+	}
+	return clone
+}
+
+func cloneChild(line *types.GedcomLine) *types.GedcomLine {
+	clone := types.NewGedcomLine(1, line.Tag, line.Value, "")
+	for _, child := range line.Children {
+		clone.AddChild(cloneChild(child))
+	}
+	return clone
+}
+
+// materializeNode turns a merkletrie.Node (and its subtree) into a
+// types.GedcomLine, for splicing an Inserted subtree into a merged tree.
+func materializeNode(n *merkletrie.Node) *types.GedcomLine {
+	line := types.NewGedcomLine(1, n.Tag, n.Value, "")
+	for _, child := range n.Children {
+		line.AddChild(materializeNode(child))
+	}
+	return line
+}
+
+// applyChange applies a single merkletrie.Change at path onto root (the
+// merged record's own top-level line), navigating or creating
+// intermediate lines as needed. path is the slash-separated tag path merkletrie.DiffRecords
+// produces, e.g. "INDI/BIRT/DATE" -- its first segment names root itself
+// and is skipped.
+func applyChange(root *types.GedcomLine, path string, change merkletrie.Change) {
+	segments := strings.Split(path, "/")
+	if len(segments) > 0 {
+		segments = segments[1:]
+	}
+	if len(segments) == 0 {
+		if change.To != nil {
+			root.Value = change.To.Value
+		}
+		return
+	}
+
+	parent := root
+	for _, seg := range segments[:len(segments)-1] {
+		tag, index := parseSegment(seg)
+		child := findChild(parent, tag, index)
+		if child == nil {
+			child = types.NewGedcomLine(1, tag, "", "")
+			parent.AddChild(child)
+		}
+		parent = child
+	}
+
+	last := segments[len(segments)-1]
+	tag, index := parseSegment(last)
+	switch change.Action {
+	case merkletrie.Insert:
+		parent.AddChild(materializeNode(change.To))
+	case merkletrie.Delete:
+		removeChild(parent, tag, index)
+	case merkletrie.Modify:
+		if child := findChild(parent, tag, index); child != nil && change.To != nil {
+			child.Value = change.To.Value
+		}
+	}
+}
+
+// parseSegment splits a path segment like "CHIL[1]" into its tag and
+// sibling index (0 for a bare tag with no [N] suffix).
+func parseSegment(seg string) (tag string, index int) {
+	open := strings.IndexByte(seg, '[')
+	if open < 0 {
+		return seg, 0
+	}
+	tag = seg[:open]
+	idxStr := strings.TrimSuffix(seg[open+1:], "]")
+	idx, err := strconv.Atoi(idxStr)
+	if err != nil {
+		return tag, 0
+	}
+	return tag, idx
+}
+
+// findChild returns parent's (index+1)-th child with the given tag (0
+// for the first), or nil if there's no such child.
+func findChild(parent *types.GedcomLine, tag string, index int) *types.GedcomLine {
+	seen := 0
+	for _, child := range parent.Children {
+		if child.Tag != tag {
+			continue
+		}
+		if seen == index {
+			return child
+		}
+		seen++
+	}
+	return nil
+}
+
+// removeChild deletes parent's (index+1)-th child with the given tag, if
+// present.
+func removeChild(parent *types.GedcomLine, tag string, index int) {
+	seen := 0
+	for i, child := range parent.Children {
+		if child.Tag != tag {
+			continue
+		}
+		if seen == index {
+			parent.Children = append(parent.Children[:i], parent.Children[i+1:]...)
+			return
+		}
+		seen++
+	}
+}
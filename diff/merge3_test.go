@@ -0,0 +1,126 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+func buildMergeIndi(xref, birthDate string) *types.IndividualRecord {
+	line := types.NewGedcomLine(0, "INDI", "", xref)
+	line.AddChild(types.NewGedcomLine(1, "NAME", "John /Smith/", ""))
+	birt := types.NewGedcomLine(1, "BIRT", "", "")
+	birt.AddChild(types.NewGedcomLine(2, "DATE", birthDate, ""))
+	line.AddChild(birt)
+	return types.NewIndividualRecord(line)
+}
+
+func treeWith(recs ...types.Record) *types.GedcomTree {
+	tree := types.NewGedcomTree()
+	for _, r := range recs {
+		tree.AddRecord(r)
+	}
+	return tree
+}
+
+func TestMerge3_OnlyOneSideChangedIsTaken(t *testing.T) {
+	base := treeWith(buildMergeIndi("@I1@", "1 JAN 1900"))
+	ours := treeWith(buildMergeIndi("@I1@", "2 JAN 1900"))
+	theirs := treeWith(buildMergeIndi("@I1@", "1 JAN 1900"))
+
+	merged, conflicts, err := Merge3(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %+v", conflicts)
+	}
+
+	rec, ok := merged.GetAllIndividuals()["@I1@"]
+	if !ok {
+		t.Fatal("expected merged tree to contain @I1@")
+	}
+	if got := rec.GetBirthDate(); got != "2 JAN 1900" {
+		t.Errorf("expected ours' birth date to win, got %q", got)
+	}
+}
+
+func TestMerge3_BothSidesChangeSamePathDifferently_Conflicts(t *testing.T) {
+	base := treeWith(buildMergeIndi("@I1@", "1 JAN 1900"))
+	ours := treeWith(buildMergeIndi("@I1@", "2 JAN 1900"))
+	theirs := treeWith(buildMergeIndi("@I1@", "3 JAN 1900"))
+
+	merged, conflicts, err := Merge3(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conflicts) != 1 {
+		t.Fatalf("expected exactly 1 conflict, got %d (%+v)", len(conflicts), conflicts)
+	}
+	if conflicts[0].Xref != "@I1@" || conflicts[0].TagPath != "INDI/BIRT/DATE" {
+		t.Errorf("unexpected conflict %+v", conflicts[0])
+	}
+
+	rec, ok := merged.GetAllIndividuals()["@I1@"]
+	if !ok {
+		t.Fatal("expected merged tree to still contain @I1@")
+	}
+	if got := rec.GetBirthDate(); got != "1 JAN 1900" {
+		t.Errorf("expected base's birth date to be retained on conflict, got %q", got)
+	}
+}
+
+func TestMerge3_BothSidesChangeSamePathIdentically_NoConflict(t *testing.T) {
+	base := treeWith(buildMergeIndi("@I1@", "1 JAN 1900"))
+	ours := treeWith(buildMergeIndi("@I1@", "2 JAN 1900"))
+	theirs := treeWith(buildMergeIndi("@I1@", "2 JAN 1900"))
+
+	merged, conflicts, err := Merge3(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %+v", conflicts)
+	}
+	if got := merged.GetAllIndividuals()["@I1@"].GetBirthDate(); got != "2 JAN 1900" {
+		t.Errorf("expected agreeing value to be applied, got %q", got)
+	}
+}
+
+func TestMerge3_BothSidesDeleted_RecordDropped(t *testing.T) {
+	base := treeWith(buildMergeIndi("@I1@", "1 JAN 1900"))
+	ours := treeWith()
+	theirs := treeWith()
+
+	merged, conflicts, err := Merge3(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts for an agreed-upon deletion, got %+v", conflicts)
+	}
+	if _, ok := merged.GetAllIndividuals()["@I1@"]; ok {
+		t.Error("expected @I1@ to be dropped from the merged tree")
+	}
+}
+
+func TestMerge3_DeleteVersusModify_ConflictsAndKeepsModification(t *testing.T) {
+	base := treeWith(buildMergeIndi("@I1@", "1 JAN 1900"))
+	ours := treeWith()
+	theirs := treeWith(buildMergeIndi("@I1@", "2 JAN 1900"))
+
+	merged, conflicts, err := Merge3(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conflicts) != 1 {
+		t.Fatalf("expected exactly 1 conflict, got %d (%+v)", len(conflicts), conflicts)
+	}
+	rec, ok := merged.GetAllIndividuals()["@I1@"]
+	if !ok {
+		t.Fatal("expected theirs' modification to survive the conflict")
+	}
+	if got := rec.GetBirthDate(); got != "2 JAN 1900" {
+		t.Errorf("expected theirs' birth date to be kept, got %q", got)
+	}
+}
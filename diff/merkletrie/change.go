@@ -0,0 +1,37 @@
+package merkletrie
+
+// Action classifies a single Change the way go-git's merkletrie package
+// does for filesystem entries: a path was added, removed, or had its
+// value modified in place.
+type Action int
+
+const (
+	Insert Action = iota
+	Delete
+	Modify
+)
+
+// String renders a for logging and test failure messages.
+func (a Action) String() string {
+	switch a {
+	case Insert:
+		return "Insert"
+	case Delete:
+		return "Delete"
+	case Modify:
+		return "Modify"
+	default:
+		return "Unknown"
+	}
+}
+
+// Change is one line-level difference found between two GEDCOM record
+// trees. Path is the slash-separated tag path from the record's root down to the
+// changed line (e.g. "INDI/BIRT/DATE"). From is nil for an Insert, To is
+// nil for a Delete; both are set for a Modify.
+type Change struct {
+	Path   string
+	Action Action
+	From   *Node
+	To     *Node
+}
@@ -0,0 +1,93 @@
+package merkletrie
+
+import "github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+
+// DiffLines walks from and to (the root lines of, typically, two matched
+// records' same-XREF revisions) and returns every Change between them.
+// Either may be nil, meaning the whole tree rooted there was
+// inserted/deleted.
+func DiffLines(from, to *types.GedcomLine) []Change {
+	return DiffNodes("", FromLine(from), FromLine(to))
+}
+
+// DiffRecords is DiffLines for a matched pair of records, keying the
+// walk's paths starting from the record's own tag (e.g. "INDI", then
+// "INDI/BIRT/DATE" for a changed birth date line).
+func DiffRecords(old, new types.Record) []Change {
+	var fromLine, toLine *types.GedcomLine
+	if old != nil {
+		fromLine = old.FirstLine()
+	}
+	if new != nil {
+		toLine = new.FirstLine()
+	}
+	return DiffLines(fromLine, toLine)
+}
+
+// DiffNodes compares from and to (either may be nil) and appends Changes
+// under path, recursing into children wherever two matched nodes'
+// top-level hash differs.
+func DiffNodes(path string, from, to *Node) []Change {
+	switch {
+	case from == nil && to == nil:
+		return nil
+	case from == nil:
+		return []Change{{Path: to.pathSegment(), Action: Insert, To: to}}
+	case to == nil:
+		return []Change{{Path: from.pathSegment(), Action: Delete, From: from}}
+	case from.Hash == to.Hash:
+		return nil
+	}
+
+	changes := diffChildren(path, from, to)
+	if len(changes) == 0 {
+		// Same tag and children, but the value itself differs (a leaf
+		// edit, e.g. BIRT/DATE's text changing).
+		changes = append(changes, Change{Path: joinPath(path, from.pathSegment()), Action: Modify, From: from, To: to})
+	}
+	return changes
+}
+
+// diffChildren is the two-cursor walk: i1/i2 advance over from.Children
+// and to.Children (both already sorted by key() in FromLine). Equal keys
+// with equal hashes are skipped entirely (the whole matching subtree is
+// known-identical); equal keys with differing hashes recurse; a key
+// present on only one side emits Insert/Delete for the lexicographically
+// smaller key and advances just that cursor.
+func diffChildren(parentPath string, from, to *Node) []Change {
+	base := joinPath(parentPath, from.pathSegment())
+
+	var changes []Change
+	i1, i2 := 0, 0
+	for i1 < len(from.Children) && i2 < len(to.Children) {
+		c1, c2 := from.Children[i1], to.Children[i2]
+		switch {
+		case c1.key() < c2.key():
+			changes = append(changes, Change{Path: joinPath(base, c1.pathSegment()), Action: Delete, From: c1})
+			i1++
+		case c1.key() > c2.key():
+			changes = append(changes, Change{Path: joinPath(base, c2.pathSegment()), Action: Insert, To: c2})
+			i2++
+		default:
+			if c1.Hash != c2.Hash {
+				changes = append(changes, DiffNodes(base, c1, c2)...)
+			}
+			i1++
+			i2++
+		}
+	}
+	for ; i1 < len(from.Children); i1++ {
+		changes = append(changes, Change{Path: joinPath(base, from.Children[i1].pathSegment()), Action: Delete, From: from.Children[i1]})
+	}
+	for ; i2 < len(to.Children); i2++ {
+		changes = append(changes, Change{Path: joinPath(base, to.Children[i2].pathSegment()), Action: Insert, To: to.Children[i2]})
+	}
+	return changes
+}
+
+func joinPath(base, segment string) string {
+	if base == "" {
+		return segment
+	}
+	return base + "/" + segment
+}
@@ -0,0 +1,79 @@
+package merkletrie
+
+import (
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+func buildIndi(birthDate, birthPlace string) *types.GedcomLine {
+	line := types.NewGedcomLine(0, "INDI", "", "@I1@")
+	line.AddChild(types.NewGedcomLine(1, "NAME", "John /Smith/", ""))
+	birt := types.NewGedcomLine(1, "BIRT", "", "")
+	birt.AddChild(types.NewGedcomLine(2, "DATE", birthDate, ""))
+	if birthPlace != "" {
+		birt.AddChild(types.NewGedcomLine(2, "PLAC", birthPlace, ""))
+	}
+	line.AddChild(birt)
+	return line
+}
+
+func TestFromLine_IdenticalTreesHashEqual(t *testing.T) {
+	a := FromLine(buildIndi("1 JAN 1900", "Boston"))
+	b := FromLine(buildIndi("1 JAN 1900", "Boston"))
+	if a.Hash != b.Hash {
+		t.Error("expected identical trees to hash equal")
+	}
+}
+
+func TestDiffLines_NoChanges(t *testing.T) {
+	changes := DiffLines(buildIndi("1 JAN 1900", "Boston"), buildIndi("1 JAN 1900", "Boston"))
+	if len(changes) != 0 {
+		t.Errorf("expected no changes for identical trees, got %+v", changes)
+	}
+}
+
+func TestDiffLines_LeafValueChange(t *testing.T) {
+	changes := DiffLines(buildIndi("1 JAN 1900", "Boston"), buildIndi("2 JAN 1900", "Boston"))
+	if len(changes) != 1 {
+		t.Fatalf("expected exactly 1 change, got %d (%+v)", len(changes), changes)
+	}
+	if changes[0].Path != "INDI/BIRT/DATE" {
+		t.Errorf("expected path INDI/BIRT/DATE, got %q", changes[0].Path)
+	}
+	if changes[0].Action != Modify {
+		t.Errorf("expected Modify, got %v", changes[0].Action)
+	}
+}
+
+func TestDiffLines_InsertedLine(t *testing.T) {
+	changes := DiffLines(buildIndi("1 JAN 1900", ""), buildIndi("1 JAN 1900", "Boston"))
+	if len(changes) != 1 {
+		t.Fatalf("expected exactly 1 change, got %d (%+v)", len(changes), changes)
+	}
+	if changes[0].Action != Insert {
+		t.Errorf("expected Insert, got %v", changes[0].Action)
+	}
+	if changes[0].Path != "INDI/BIRT/PLAC" {
+		t.Errorf("expected path INDI/BIRT/PLAC, got %q", changes[0].Path)
+	}
+}
+
+func TestDiffLines_DeletedLine(t *testing.T) {
+	changes := DiffLines(buildIndi("1 JAN 1900", "Boston"), buildIndi("1 JAN 1900", ""))
+	if len(changes) != 1 {
+		t.Fatalf("expected exactly 1 change, got %d (%+v)", len(changes), changes)
+	}
+	if changes[0].Action != Delete {
+		t.Errorf("expected Delete, got %v", changes[0].Action)
+	}
+}
+
+func TestDiffLines_UnrelatedSubtreeUnaffected(t *testing.T) {
+	changes := DiffLines(buildIndi("1 JAN 1900", "Boston"), buildIndi("2 FEB 1900", "Boston"))
+	for _, c := range changes {
+		if c.Path == "INDI/BIRT/PLAC" {
+			t.Errorf("expected the unchanged PLAC subtree to be skipped entirely, got %+v", c)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package diff
+
+import (
+	"math"
+	"strings"
+	"sync"
+)
+
+// ChangeTypeRefined marks a place (or date) change where one side is a
+// more-specific version of the other (e.g. "Brooklyn, NY" refined to
+// "Brooklyn, Kings, New York, USA"), rather than a genuine relocation.
+//
+// ChangeTypeRelocated marks a place change with a different leading
+// (most-specific) part but the same country, distinguishing an actual
+// move from noise in how precisely the place was recorded.
+const (
+	ChangeTypeRefined   ChangeType = "refined"
+	ChangeTypeRelocated ChangeType = "relocated"
+)
+
+// PlaceHierarchy is a parsed GEDCOM PLAC value: comma-separated parts from
+// most-specific to least-specific (e.g. "Brooklyn, Kings, New York, USA"),
+// plus optional coordinates from a MAP/LATI/LONG substructure.
+type PlaceHierarchy struct {
+	Parts []string
+	Lat   *float64
+	Lon   *float64
+}
+
+// ParsePlaceHierarchy splits a raw PLAC value into its comma-separated
+// parts, trimming whitespace from each. Empty parts (from "Brooklyn,, NY")
+// are dropped.
+func ParsePlaceHierarchy(raw string) PlaceHierarchy {
+	fields := strings.Split(raw, ",")
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if f != "" {
+			parts = append(parts, f)
+		}
+	}
+	return PlaceHierarchy{Parts: parts}
+}
+
+// placeAliasesMu and placeAliasesFor attach an optional alias table to a
+// *Config by pointer identity, the same side-table pattern used for
+// IgnoreRules, since Config's own field set isn't declared in this
+// package.
+var (
+	placeAliasesMu  sync.Mutex
+	placeAliasesFor = make(map[*Config]map[string]string)
+)
+
+// SetPlaceAliases attaches an alias table (e.g. "NY" -> "New York") to cfg,
+// used by ComparePlaceHierarchies to normalize abbreviated place parts
+// before comparison.
+func SetPlaceAliases(cfg *Config, aliases map[string]string) {
+	placeAliasesMu.Lock()
+	defer placeAliasesMu.Unlock()
+	placeAliasesFor[cfg] = aliases
+}
+
+func placeAliasesOf(cfg *Config) map[string]string {
+	placeAliasesMu.Lock()
+	defer placeAliasesMu.Unlock()
+	return placeAliasesFor[cfg]
+}
+
+// placeDistanceToleranceFor holds the per-Config coordinate tolerance set
+// by SetPlaceDistanceToleranceKm, using the same side-table pattern as
+// placeAliasesFor above.
+var (
+	placeDistanceToleranceMu  sync.Mutex
+	placeDistanceToleranceFor = make(map[*Config]float64)
+)
+
+// SetPlaceDistanceToleranceKm attaches a Haversine-distance tolerance (in
+// kilometers) to cfg, used by ComparePlaceValues when both sides of a
+// place comparison carry MAP/LATI/LONG coordinates.
+func SetPlaceDistanceToleranceKm(cfg *Config, km float64) {
+	placeDistanceToleranceMu.Lock()
+	defer placeDistanceToleranceMu.Unlock()
+	placeDistanceToleranceFor[cfg] = km
+}
+
+func placeDistanceToleranceOf(cfg *Config) float64 {
+	placeDistanceToleranceMu.Lock()
+	defer placeDistanceToleranceMu.Unlock()
+	return placeDistanceToleranceFor[cfg]
+}
+
+// applyAliases resolves each part of h through aliases, leaving
+// unrecognized parts unchanged.
+func applyAliases(h PlaceHierarchy, aliases map[string]string) PlaceHierarchy {
+	if len(aliases) == 0 {
+		return h
+	}
+	out := PlaceHierarchy{Parts: make([]string, len(h.Parts)), Lat: h.Lat, Lon: h.Lon}
+	for i, p := range h.Parts {
+		if resolved, ok := aliases[p]; ok {
+			out.Parts[i] = resolved
+		} else {
+			out.Parts[i] = p
+		}
+	}
+	return out
+}
+
+// foldPlacePart lowercases and strips common diacritics and surrounding
+// whitespace so "Montréal" and "montreal" compare equal.
+func foldPlacePart(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	var b strings.Builder
+	for _, r := range s {
+		if folded, ok := diacriticFold[r]; ok {
+			b.WriteRune(folded)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+var diacriticFold = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u',
+	'ñ': 'n', 'ç': 'c',
+}
+
+// haversineKm returns the great-circle distance in kilometers between two
+// lat/lon points.
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	return earthRadiusKm * c
+}
+
+// placeExtends reports whether narrower's parts form a prefix-extension of
+// wider's parts once both are folded, i.e. narrower is the same place
+// described with more specificity (or vice versa, since either side may be
+// the more detailed one).
+func placeExtends(a, b PlaceHierarchy) bool {
+	shorter, longer := a.Parts, b.Parts
+	if len(shorter) > len(longer) {
+		shorter, longer = longer, shorter
+	}
+	if len(shorter) == 0 || len(shorter) == len(longer) {
+		return false
+	}
+	offset := len(longer) - len(shorter)
+	for i, p := range shorter {
+		if foldPlacePart(p) != foldPlacePart(longer[i+offset]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameCountry reports whether a and b share the same least-specific
+// (final, i.e. country-level) part.
+func sameCountry(a, b PlaceHierarchy) bool {
+	if len(a.Parts) == 0 || len(b.Parts) == 0 {
+		return false
+	}
+	return foldPlacePart(a.Parts[len(a.Parts)-1]) == foldPlacePart(b.Parts[len(b.Parts)-1])
+}
+
+// ComparePlaceHierarchyResult is the verdict produced by
+// ComparePlaceHierarchies: one of the ChangeType* constants describing how
+// old and new relate.
+type ComparePlaceHierarchyResult struct {
+	Type       ChangeType
+	DistanceKm float64 // populated only when both sides carry coordinates
+}
+
+// ComparePlaceHierarchies classifies the relationship between two parsed
+// place hierarchies: identical, ChangeTypeSemanticallyEquivalent
+// (case/whitespace/diacritic-insensitive match, or within
+// distanceToleranceKm when both carry coordinates), ChangeTypeRefined (one
+// side's parts are a specificity-extension of the other's), or
+// ChangeTypeRelocated (different leading part but the same country).
+// Everything else is reported as ChangeTypeModified.
+func ComparePlaceHierarchies(old, new PlaceHierarchy, aliases map[string]string, distanceToleranceKm float64) ComparePlaceHierarchyResult {
+	old = applyAliases(old, aliases)
+	new = applyAliases(new, aliases)
+
+	if old.Lat != nil && old.Lon != nil && new.Lat != nil && new.Lon != nil {
+		d := haversineKm(*old.Lat, *old.Lon, *new.Lat, *new.Lon)
+		if d <= distanceToleranceKm {
+			return ComparePlaceHierarchyResult{Type: ChangeTypeSemanticallyEquivalent, DistanceKm: d}
+		}
+	}
+
+	if len(old.Parts) == len(new.Parts) {
+		match := true
+		for i := range old.Parts {
+			if foldPlacePart(old.Parts[i]) != foldPlacePart(new.Parts[i]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return ComparePlaceHierarchyResult{Type: ChangeTypeSemanticallyEquivalent}
+		}
+	}
+
+	if placeExtends(old, new) {
+		return ComparePlaceHierarchyResult{Type: ChangeTypeRefined}
+	}
+
+	if sameCountry(old, new) {
+		return ComparePlaceHierarchyResult{Type: ChangeTypeRelocated}
+	}
+
+	return ComparePlaceHierarchyResult{Type: ChangeTypeModified}
+}
+
+// ComparePlaceValues is the raw-string entry point mirroring
+// CompareDateValues: it parses old/new as PlaceHierarchy values, resolves
+// them against cfg's alias table and distance tolerance (if set), and
+// returns a Change describing their relationship (nil if the raw strings
+// are identical).
+func ComparePlaceValues(old, new, path string, cfg *Config) *Change {
+	if old == new {
+		return nil
+	}
+	if old == "" || new == "" {
+		return &Change{Type: ChangeTypeModified, Path: path, OldValue: old, NewValue: new}
+	}
+
+	var aliases map[string]string
+	var toleranceKm float64
+	if cfg != nil {
+		aliases = placeAliasesOf(cfg)
+		toleranceKm = placeDistanceToleranceOf(cfg)
+	}
+
+	result := ComparePlaceHierarchies(ParsePlaceHierarchy(old), ParsePlaceHierarchy(new), aliases, toleranceKm)
+	return &Change{Type: result.Type, Path: path, OldValue: old, NewValue: new}
+}
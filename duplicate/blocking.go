@@ -0,0 +1,295 @@
+package duplicate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// surnamePrefixDepth is how many leading characters of a normalized
+// surname LookupByPrefix/computeBlockingMetrics group on -- "first 4
+// letters of normalized surname" from blockIndex's prefix-blocking
+// scheme.
+const surnamePrefixDepth = 4
+
+// blockIndex groups individuals by blockKey, so generateComparisonJobs
+// only compares individuals that share a block. prefixTree/suffixTree
+// back LookupByPrefix/LookupBySuffix: a Patricia trie keyed by
+// canonicalized surname (and, for suffix queries, its reverse) holding
+// XREFs, resolved back to records via byXref. Walking the tree for a
+// prefix visits O(len(prefix)) nodes and returns every descendant
+// leaf's XREFs in one pass, instead of precomputing a bucket per
+// possible prefix length.
+type blockIndex struct {
+	blocks map[string][]*types.IndividualRecord
+
+	prefixTree *query.RadixTree
+	suffixTree *query.RadixTree
+	byXref     map[string]*types.IndividualRecord
+
+	// fingerprints holds each individual's nameFingerprint, aligned by
+	// position with the individuals slice buildIndexes was given.
+	// fingerprintByXref holds the same values keyed by XREF, for
+	// shouldCompare's random-access lookup during blocked comparison.
+	fingerprints      []uint64
+	fingerprintByXref map[string]uint64
+}
+
+// LookupByPrefix returns every individual whose normalized surname
+// begins with prefix.
+func (idx *blockIndex) LookupByPrefix(prefix string) []*types.IndividualRecord {
+	return idx.lookup(idx.prefixTree, normalizeToken(prefix))
+}
+
+// LookupBySuffix returns every individual whose normalized surname ends
+// with suffix, via the parallel reversed-surname tree -- used for
+// nickname/alias handling where the matching fragment is a word ending
+// ("-son", "-sen") rather than a beginning.
+func (idx *blockIndex) LookupBySuffix(suffix string) []*types.IndividualRecord {
+	return idx.lookup(idx.suffixTree, reverseString(normalizeToken(suffix)))
+}
+
+func (idx *blockIndex) lookup(tree *query.RadixTree, key string) []*types.IndividualRecord {
+	return lookupRadixTree(tree, idx.byXref, key)
+}
+
+// reverseString reverses s rune-by-rune, for keying blockIndex.suffixTree
+// so a suffix query can reuse RadixTree's prefix-walk machinery.
+func reverseString(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
+
+// blockKey is an individual's primary blocking key: phonetic surname key
+// plus birth decade. Individuals with no parseable surname or birth year
+// fall into a shared catch-all block rather than being excluded from
+// comparison entirely.
+func blockKey(indi *types.IndividualRecord) string {
+	_, surname := splitName(indi.GetName())
+	return metaphoneKey(surname) + "|" + decadeKey(indi.GetBirthDate())
+}
+
+func decadeKey(date string) string {
+	y := yearOf(date)
+	if y == 0 {
+		return "?"
+	}
+	return fmt.Sprintf("%d", (y/10)*10)
+}
+
+// BlockSizeInfo summarizes one block for BlockingMetrics.TopBlockSizes.
+type BlockSizeInfo struct {
+	Size      int
+	Count     int
+	BlockType string
+}
+
+// BlockingMetrics reports how well blocking partitioned a set of
+// individuals into comparison candidates, so callers can tell whether
+// blocking is doing its job (most individuals land in a small number of
+// reasonably sized blocks) or is degenerate (a single giant block, or
+// most individuals with no block at all).
+type BlockingMetrics struct {
+	TotalPeople            int
+	PeopleWithPrimaryBlock int
+	PeopleWithAnyBlock     int
+	PeopleWithNoBlocks     int
+
+	TotalBlocks          int
+	PrimaryBlocks        int
+	SurnameYearBlocks    int
+	SurnameInitialBlocks int
+	SurnamePrefixBlocks  int
+	PrefixBlockDepth     int
+
+	TotalCandidatesGenerated   int
+	TotalCandidatesScored      int
+	AverageCandidatesPerPerson float64
+	MaxCandidatesPerPerson     int
+	PeopleWithZeroCandidates   int
+	PeopleWithOneCandidate     int
+	PeopleWithManyCandidates   int
+
+	TopBlockSizes []BlockSizeInfo
+
+	// SnapshotVersion is the BlockingView.Version these metrics were
+	// computed from, via BlockingView.Metrics; 0 for metrics computed
+	// from a one-off blockIndex (computeBlockingMetrics), which has no
+	// associated version.
+	SnapshotVersion int64
+
+	RepetitionWarning   string
+	HasGiantBlocks      bool
+	PeopleInGiantBlocks int
+	LargestBlockSize    int
+	MostCommonSurname   string
+}
+
+// computeBlockingMetrics summarizes idx: block/candidate counts plus the
+// giant-block and common-surname figures GetWarnings flags. blockKey only
+// uses one exact-match blocking scheme (phonetic surname + birth decade),
+// so SurnameYear/SurnameInitial stay 0 here; SurnamePrefixBlocks/
+// PrefixBlockDepth, by contrast, are populated directly from idx's
+// prefix radix tree, counting how many distinct surnamePrefixDepth-
+// letter buckets its stored surnames fall into.
+func computeBlockingMetrics(idx *blockIndex, totalPeople int) *BlockingMetrics {
+	bm := &BlockingMetrics{TotalPeople: totalPeople}
+	bm.TotalBlocks = len(idx.blocks)
+
+	candidatesPerPerson := make(map[string]int)
+	surnameCounts := make(map[string]int)
+
+	for key, block := range idx.blocks {
+		size := len(block)
+		if size > bm.LargestBlockSize {
+			bm.LargestBlockSize = size
+		}
+		bm.TopBlockSizes = append(bm.TopBlockSizes, BlockSizeInfo{Size: size, Count: 1, BlockType: blockType(key)})
+
+		if size > 1 {
+			bm.PeopleWithPrimaryBlock += size
+			bm.PrimaryBlocks++
+		}
+		bm.PeopleWithAnyBlock += size
+
+		surname := strings.SplitN(key, "|", 2)[0]
+		surnameCounts[surname] += size
+
+		for _, indi := range block {
+			candidatesPerPerson[indi.XrefID()] += size - 1
+			bm.TotalCandidatesGenerated += size - 1
+		}
+	}
+
+	bm.PeopleWithNoBlocks = totalPeople - bm.PeopleWithAnyBlock
+	if bm.PeopleWithNoBlocks < 0 {
+		bm.PeopleWithNoBlocks = 0
+	}
+
+	if totalPeople > 0 {
+		bm.AverageCandidatesPerPerson = float64(bm.TotalCandidatesGenerated) / float64(totalPeople)
+	}
+	for _, n := range candidatesPerPerson {
+		if n > bm.MaxCandidatesPerPerson {
+			bm.MaxCandidatesPerPerson = n
+		}
+		switch {
+		case n == 0:
+			bm.PeopleWithZeroCandidates++
+		case n == 1:
+			bm.PeopleWithOneCandidate++
+		default:
+			bm.PeopleWithManyCandidates++
+		}
+	}
+
+	mostCommon, mostCommonCount := "", 0
+	for surname, count := range surnameCounts {
+		if surname != "" && count > mostCommonCount {
+			mostCommon, mostCommonCount = surname, count
+		}
+	}
+	bm.MostCommonSurname = mostCommon
+
+	if totalPeople > 0 && bm.LargestBlockSize*5 > totalPeople {
+		bm.HasGiantBlocks = true
+		bm.PeopleInGiantBlocks = bm.LargestBlockSize
+	}
+
+	if idx.prefixTree != nil {
+		prefixSizes := make(map[string]int)
+		idx.prefixTree.Walk(func(key string, values []string) bool {
+			depth := surnamePrefixDepth
+			if len(key) < depth {
+				depth = len(key)
+			}
+			if depth == 0 {
+				return true
+			}
+			prefixSizes[key[:depth]] += len(values)
+			return true
+		})
+		bm.SurnamePrefixBlocks = len(prefixSizes)
+		bm.PrefixBlockDepth = surnamePrefixDepth
+		for prefix, size := range prefixSizes {
+			bm.TopBlockSizes = append(bm.TopBlockSizes, BlockSizeInfo{Size: size, Count: 1, BlockType: "surname-prefix:" + prefix})
+		}
+	}
+
+	return bm
+}
+
+func blockType(key string) string {
+	parts := strings.SplitN(key, "|", 2)
+	if len(parts) == 2 && parts[1] != "?" {
+		return "surname+year"
+	}
+	return "surname"
+}
+
+// String renders bm as a multi-line human-readable report.
+func (bm *BlockingMetrics) String() string {
+	var b strings.Builder
+	if bm.SnapshotVersion > 0 {
+		fmt.Fprintf(&b, "Snapshot version: %d\n", bm.SnapshotVersion)
+	}
+	fmt.Fprintf(&b, "Total People: %d\n", bm.TotalPeople)
+	fmt.Fprintf(&b, "  With primary block: %d\n", bm.PeopleWithPrimaryBlock)
+	fmt.Fprintf(&b, "  With any block: %d\n", bm.PeopleWithAnyBlock)
+	fmt.Fprintf(&b, "  With no blocks: %d\n", bm.PeopleWithNoBlocks)
+	fmt.Fprintf(&b, "Total Blocks: %d\n", bm.TotalBlocks)
+	fmt.Fprintf(&b, "  Primary: %d, Surname+Year: %d, Surname+Initial: %d, Surname prefix(%d): %d\n",
+		bm.PrimaryBlocks, bm.SurnameYearBlocks, bm.SurnameInitialBlocks, bm.PrefixBlockDepth, bm.SurnamePrefixBlocks)
+	fmt.Fprintf(&b, "Candidates: generated %d, scored %d (avg %.1f/person, max %d)\n",
+		bm.TotalCandidatesGenerated, bm.TotalCandidatesScored, bm.AverageCandidatesPerPerson, bm.MaxCandidatesPerPerson)
+	fmt.Fprintf(&b, "  Zero: %d, One: %d, Many: %d\n",
+		bm.PeopleWithZeroCandidates, bm.PeopleWithOneCandidate, bm.PeopleWithManyCandidates)
+	for _, bs := range bm.TopBlockSizes {
+		fmt.Fprintf(&b, "  block(%s) size=%d count=%d\n", bs.BlockType, bs.Size, bs.Count)
+	}
+	if bm.RepetitionWarning != "" {
+		fmt.Fprintf(&b, "WARNING: %s\n", bm.RepetitionWarning)
+	}
+	for _, w := range bm.blockWarnings() {
+		fmt.Fprintf(&b, "WARNING: %s\n", w)
+	}
+	return b.String()
+}
+
+// GetWarnings returns human-readable warnings about blocking quality:
+// giant (near-useless) blocks, a dominant common surname, a high
+// fraction of individuals left with zero candidates, and (if set)
+// RepetitionWarning.
+func (bm *BlockingMetrics) GetWarnings() []string {
+	var warnings []string
+	if bm.RepetitionWarning != "" {
+		warnings = append(warnings, "repetition: "+bm.RepetitionWarning)
+	}
+	warnings = append(warnings, bm.blockWarnings()...)
+	return warnings
+}
+
+func (bm *BlockingMetrics) blockWarnings() []string {
+	var warnings []string
+	if bm.HasGiantBlocks {
+		warnings = append(warnings, fmt.Sprintf(
+			"%d people are in giant blocks (largest block: %d) -- surname/decade combination is extremely common and blocking may not scale well",
+			bm.PeopleInGiantBlocks, bm.LargestBlockSize))
+	}
+	if bm.TotalPeople > 0 && bm.PeopleWithZeroCandidates*2 > bm.TotalPeople {
+		warnings = append(warnings, fmt.Sprintf(
+			"%d of %d people (%.0f%%) have zero candidate matches after blocking -- consider relaxing block criteria",
+			bm.PeopleWithZeroCandidates, bm.TotalPeople, 100*float64(bm.PeopleWithZeroCandidates)/float64(bm.TotalPeople)))
+	}
+	if bm.MostCommonSurname != "" && bm.TotalPeople > 0 && bm.LargestBlockSize*3 > bm.TotalPeople {
+		warnings = append(warnings, fmt.Sprintf(
+			"surname %q accounts for an unusually large block (%d of %d people)",
+			bm.MostCommonSurname, bm.LargestBlockSize, bm.TotalPeople))
+	}
+	return warnings
+}
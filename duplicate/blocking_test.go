@@ -0,0 +1,98 @@
+package duplicate
+
+import (
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+func withXref(indi *types.IndividualRecord, xref string) *types.IndividualRecord {
+	indi.FirstLine().XrefID = xref
+	return indi
+}
+
+func TestBlockIndex_LookupByPrefix(t *testing.T) {
+	detector := NewDuplicateDetector(DefaultConfig())
+	individuals := []*types.IndividualRecord{
+		withXref(createTestIndividual("John /Anderson/", "John", "Anderson", "1800", ""), "@I1@"),
+		withXref(createTestIndividual("Jane /Andersen/", "Jane", "Andersen", "1850", ""), "@I2@"),
+		withXref(createTestIndividual("Bob /Smith/", "Bob", "Smith", "1900", ""), "@I3@"),
+	}
+
+	idx := detector.buildIndexes(individuals)
+
+	matches := idx.LookupByPrefix("Ande")
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 individuals sharing the 'Ande' surname prefix, got %d: %+v", len(matches), matches)
+	}
+
+	if matches := idx.LookupByPrefix("Smit"); len(matches) != 1 {
+		t.Errorf("expected 1 individual for 'Smit' prefix, got %d", len(matches))
+	}
+
+	if matches := idx.LookupByPrefix("Zzzz"); len(matches) != 0 {
+		t.Errorf("expected no individuals for an unmatched prefix, got %d", len(matches))
+	}
+}
+
+func TestBlockIndex_LookupBySuffix(t *testing.T) {
+	detector := NewDuplicateDetector(DefaultConfig())
+	individuals := []*types.IndividualRecord{
+		withXref(createTestIndividual("Erik /Olsson/", "Erik", "Olsson", "1800", ""), "@I1@"),
+		withXref(createTestIndividual("Lars /Nilsson/", "Lars", "Nilsson", "1850", ""), "@I2@"),
+		withXref(createTestIndividual("Anna /Smith/", "Anna", "Smith", "1900", ""), "@I3@"),
+	}
+
+	idx := detector.buildIndexes(individuals)
+
+	matches := idx.LookupBySuffix("sson")
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 individuals sharing the 'sson' surname suffix, got %d: %+v", len(matches), matches)
+	}
+}
+
+func TestComputeBlockingMetrics_PopulatesSurnamePrefixBlocks(t *testing.T) {
+	detector := NewDuplicateDetector(DefaultConfig())
+	individuals := []*types.IndividualRecord{
+		withXref(createTestIndividual("John /Anderson/", "John", "Anderson", "1800", ""), "@I1@"),
+		withXref(createTestIndividual("Jane /Andersen/", "Jane", "Andersen", "1850", ""), "@I2@"),
+		withXref(createTestIndividual("Bob /Smith/", "Bob", "Smith", "1900", ""), "@I3@"),
+	}
+
+	idx := detector.buildIndexes(individuals)
+	bm := computeBlockingMetrics(idx, len(individuals))
+
+	if bm.PrefixBlockDepth != surnamePrefixDepth {
+		t.Errorf("expected PrefixBlockDepth %d, got %d", surnamePrefixDepth, bm.PrefixBlockDepth)
+	}
+	if bm.SurnamePrefixBlocks != 2 {
+		t.Errorf("expected 2 distinct surname-prefix buckets (Ande*, Smit*), got %d", bm.SurnamePrefixBlocks)
+	}
+}
+
+func TestGenerateComparisonJobs_IncludesPrefixBlockCandidates(t *testing.T) {
+	config := DefaultConfig()
+	config.MinThreshold = 0.0
+	detector := NewDuplicateDetector(config)
+
+	// Same surname prefix but different birth decades, so these two land
+	// in different exact phonetic+decade blocks and are only connected
+	// through prefix blocking.
+	individuals := []*types.IndividualRecord{
+		withXref(createTestIndividual("John /Anderson/", "John", "Anderson", "1800", ""), "@I1@"),
+		withXref(createTestIndividual("Jack /Andersen/", "Jack", "Andersen", "1950", ""), "@I2@"),
+	}
+
+	idx := detector.buildIndexes(individuals)
+	jobs := detector.generateComparisonJobs(individuals, idx)
+
+	found := false
+	for _, j := range jobs {
+		if unorderedPairKey(j.indi1.XrefID(), j.indi2.XrefID()) == unorderedPairKey("@I1@", "@I2@") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected prefix-block lookup to surface the @I1@/@I2@ pair despite differing birth decades")
+	}
+}
@@ -0,0 +1,359 @@
+package duplicate
+
+import (
+	"iter"
+	"sync"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// blockingSnapshot is one immutable, versioned snapshot of a
+// DuplicateDetector's incremental blocking indexes. Once published to
+// DuplicateDetector.current, a blockingSnapshot's fields are never
+// mutated again -- Add/Remove always clone into a new blockingSnapshot
+// (cloneBlockingSnapshot) before changing anything, so a BlockingView
+// wrapping an older snapshot keeps reading a consistent point-in-time
+// view even while the detector publishes newer versions concurrently.
+// prefixTree/suffixTree need no cloning of their own: query.RadixTree is
+// already persistent, so Insert/Delete hand back a new tree without
+// touching the one an older snapshot still references.
+type blockingSnapshot struct {
+	version int64
+
+	blocks         map[string][]*types.IndividualRecord
+	blockKeyByXref map[string]string
+
+	prefixTree *query.RadixTree
+	suffixTree *query.RadixTree
+
+	byXref            map[string]*types.IndividualRecord
+	fingerprintByXref map[string]uint64
+}
+
+func emptyBlockingSnapshot() *blockingSnapshot {
+	return &blockingSnapshot{
+		blocks:            make(map[string][]*types.IndividualRecord),
+		blockKeyByXref:    make(map[string]string),
+		prefixTree:        query.NewRadixTree(),
+		suffixTree:        query.NewRadixTree(),
+		byXref:            make(map[string]*types.IndividualRecord),
+		fingerprintByXref: make(map[string]uint64),
+	}
+}
+
+// cloneBlockingSnapshot shallow-copies s's maps into a new
+// blockingSnapshot, so mutating the copy (addToSnapshot/
+// removeFromSnapshot) can never affect s or any BlockingView built from
+// it.
+func cloneBlockingSnapshot(s *blockingSnapshot) *blockingSnapshot {
+	blocks := make(map[string][]*types.IndividualRecord, len(s.blocks))
+	for key, block := range s.blocks {
+		blocks[key] = append([]*types.IndividualRecord(nil), block...)
+	}
+	blockKeyByXref := make(map[string]string, len(s.blockKeyByXref))
+	for xref, key := range s.blockKeyByXref {
+		blockKeyByXref[xref] = key
+	}
+	byXref := make(map[string]*types.IndividualRecord, len(s.byXref))
+	for xref, indi := range s.byXref {
+		byXref[xref] = indi
+	}
+	fingerprintByXref := make(map[string]uint64, len(s.fingerprintByXref))
+	for xref, fp := range s.fingerprintByXref {
+		fingerprintByXref[xref] = fp
+	}
+	return &blockingSnapshot{
+		version:           s.version,
+		blocks:            blocks,
+		blockKeyByXref:    blockKeyByXref,
+		prefixTree:        s.prefixTree,
+		suffixTree:        s.suffixTree,
+		byXref:            byXref,
+		fingerprintByXref: fingerprintByXref,
+	}
+}
+
+// addToSnapshot inserts indi into s in place. Callers must only do this
+// to a blockingSnapshot not yet published (see cloneBlockingSnapshot).
+func addToSnapshot(s *blockingSnapshot, indi *types.IndividualRecord) {
+	xref := indi.XrefID()
+	key := blockKey(indi)
+	s.blocks[key] = append(s.blocks[key], indi)
+	s.blockKeyByXref[xref] = key
+	s.byXref[xref] = indi
+	s.fingerprintByXref[xref] = nameFingerprint(indi.GetName())
+
+	_, surname := splitName(indi.GetName())
+	if norm := normalizeToken(surname); norm != "" {
+		s.prefixTree = s.prefixTree.Insert(norm, xref)
+		s.suffixTree = s.suffixTree.Insert(reverseString(norm), xref)
+	}
+}
+
+// removeFromSnapshot deletes xref from s in place, under the same
+// not-yet-published rule as addToSnapshot. Removing an unknown xref is a
+// no-op.
+func removeFromSnapshot(s *blockingSnapshot, xref string) {
+	indi, ok := s.byXref[xref]
+	if !ok {
+		return
+	}
+	if key, ok := s.blockKeyByXref[xref]; ok {
+		remaining := s.blocks[key][:0:0]
+		for _, other := range s.blocks[key] {
+			if other.XrefID() != xref {
+				remaining = append(remaining, other)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(s.blocks, key)
+		} else {
+			s.blocks[key] = remaining
+		}
+		delete(s.blockKeyByXref, xref)
+	}
+	delete(s.byXref, xref)
+	delete(s.fingerprintByXref, xref)
+
+	_, surname := splitName(indi.GetName())
+	if norm := normalizeToken(surname); norm != "" {
+		s.prefixTree = s.prefixTree.Delete(norm, xref)
+		s.suffixTree = s.suffixTree.Delete(reverseString(norm), xref)
+	}
+}
+
+// BlockingView is a read-only, point-in-time view over a
+// DuplicateDetector's incremental blocking indexes, returned by Snapshot,
+// Add, and Remove. It's an in-memory counterpart to the versioned-store
+// idea: a worker holding a BlockingView keeps a consistent picture of the
+// index even as the detector's Add/Remove calls publish later versions,
+// since the snapshot it wraps is never mutated after being handed out.
+type BlockingView struct {
+	snap *blockingSnapshot
+}
+
+// Version returns the monotonically increasing version of the snapshot v
+// wraps -- 0 for a detector that has never had Add/Remove called on it.
+func (v BlockingView) Version() int64 {
+	if v.snap == nil {
+		return 0
+	}
+	return v.snap.version
+}
+
+// GetByXref returns the individual stored under xref in this view, and
+// whether one was present.
+func (v BlockingView) GetByXref(xref string) (*types.IndividualRecord, bool) {
+	if v.snap == nil {
+		return nil, false
+	}
+	indi, ok := v.snap.byXref[xref]
+	return indi, ok
+}
+
+// GetByPrefix returns every individual in this view whose normalized
+// surname begins with prefix.
+func (v BlockingView) GetByPrefix(prefix string) []*types.IndividualRecord {
+	if v.snap == nil {
+		return nil
+	}
+	return lookupRadixTree(v.snap.prefixTree, v.snap.byXref, normalizeToken(prefix))
+}
+
+// GetBySuffix returns every individual in this view whose normalized
+// surname ends with suffix, mirroring blockIndex.LookupBySuffix.
+func (v BlockingView) GetBySuffix(suffix string) []*types.IndividualRecord {
+	if v.snap == nil {
+		return nil
+	}
+	return lookupRadixTree(v.snap.suffixTree, v.snap.byXref, reverseString(normalizeToken(suffix)))
+}
+
+// BlockKeys returns every block key present in this view, in no
+// particular order, for a caller that wants to range over IterateBlock
+// itself rather than calling a single lookup method.
+func (v BlockingView) BlockKeys() []string {
+	if v.snap == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(v.snap.blocks))
+	for key := range v.snap.blocks {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// IterateBlock returns an iterator over every individual sharing blockKey
+// in this view. Since it ranges over this view's own snapshot slice, a
+// concurrent Add/Remove publishing a newer version can't produce a torn
+// read.
+func (v BlockingView) IterateBlock(blockKey string) iter.Seq[*types.IndividualRecord] {
+	var block []*types.IndividualRecord
+	if v.snap != nil {
+		block = v.snap.blocks[blockKey]
+	}
+	return func(yield func(*types.IndividualRecord) bool) {
+		for _, indi := range block {
+			if !yield(indi) {
+				return
+			}
+		}
+	}
+}
+
+// lookupRadixTree walks tree for key, resolving each matching XREF
+// through byXref and deduplicating -- the shared implementation behind
+// blockIndex.lookup and BlockingView.GetByPrefix/GetBySuffix.
+func lookupRadixTree(tree *query.RadixTree, byXref map[string]*types.IndividualRecord, key string) []*types.IndividualRecord {
+	if tree == nil || key == "" {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var out []*types.IndividualRecord
+	tree.WalkPrefix(key, func(_ string, xrefs []string) bool {
+		for _, xref := range xrefs {
+			if seen[xref] {
+				continue
+			}
+			seen[xref] = true
+			if indi, ok := byXref[xref]; ok {
+				out = append(out, indi)
+			}
+		}
+		return true
+	})
+	return out
+}
+
+// Metrics computes a BlockingMetrics summary of v, with SnapshotVersion
+// set to v.Version() -- the BlockingView counterpart of
+// computeBlockingMetrics, which only ever sees version 0.
+func (v BlockingView) Metrics() *BlockingMetrics {
+	if v.snap == nil {
+		return computeBlockingMetrics(&blockIndex{}, 0)
+	}
+	idx := &blockIndex{
+		blocks:     v.snap.blocks,
+		prefixTree: v.snap.prefixTree,
+		suffixTree: v.snap.suffixTree,
+		byXref:     v.snap.byXref,
+	}
+	bm := computeBlockingMetrics(idx, len(v.snap.byXref))
+	bm.SnapshotVersion = v.snap.version
+	return bm
+}
+
+// Snapshot returns d's current BlockingView. A detector on which
+// Add/Remove has never been called returns an empty view at version 0.
+func (d *DuplicateDetector) Snapshot() BlockingView {
+	d.snapMu.Lock()
+	defer d.snapMu.Unlock()
+	if d.current == nil {
+		d.current = emptyBlockingSnapshot()
+	}
+	return BlockingView{snap: d.current}
+}
+
+// Add incrementally inserts individuals into d's blocking indexes and
+// publishes the result as a new, one-higher-versioned BlockingView.
+// Workers already holding an earlier Snapshot/Add/Remove result are
+// unaffected: Add always builds its changes into a clone of the previous
+// snapshot (see cloneBlockingSnapshot) rather than mutating it in place.
+func (d *DuplicateDetector) Add(individuals ...*types.IndividualRecord) BlockingView {
+	d.snapMu.Lock()
+	defer d.snapMu.Unlock()
+	base := d.current
+	if base == nil {
+		base = emptyBlockingSnapshot()
+	}
+	next := cloneBlockingSnapshot(base)
+	for _, indi := range individuals {
+		addToSnapshot(next, indi)
+	}
+	next.version = base.version + 1
+	d.current = next
+	return BlockingView{snap: next}
+}
+
+// Remove incrementally deletes the given XREFs from d's blocking indexes,
+// with the same copy-on-write publishing semantics as Add.
+func (d *DuplicateDetector) Remove(xrefs ...string) BlockingView {
+	d.snapMu.Lock()
+	defer d.snapMu.Unlock()
+	base := d.current
+	if base == nil {
+		base = emptyBlockingSnapshot()
+	}
+	next := cloneBlockingSnapshot(base)
+	for _, xref := range xrefs {
+		removeFromSnapshot(next, xref)
+	}
+	next.version = base.version + 1
+	d.current = next
+	return BlockingView{snap: next}
+}
+
+// FindDuplicatesFromView scores every pair within each of view's blocks
+// using a pool of d.config.NumWorkers goroutines, the BlockingView
+// equivalent of findDuplicatesBetweenParallel: each worker only ever
+// reads from view's immutable snapshot, so it's safe to keep scanning a
+// view the detector has already moved past via a concurrent Add/Remove.
+func (d *DuplicateDetector) FindDuplicatesFromView(view BlockingView) ([]DuplicateMatch, int, error) {
+	if view.snap == nil {
+		return nil, 0, nil
+	}
+
+	type pair struct{ a, b *types.IndividualRecord }
+	var pairs []pair
+	for _, block := range view.snap.blocks {
+		for i := 0; i < len(block); i++ {
+			for j := i + 1; j < len(block); j++ {
+				pairs = append(pairs, pair{block[i], block[j]})
+			}
+		}
+	}
+	if len(pairs) == 0 {
+		return nil, 0, nil
+	}
+
+	work := make(chan pair, len(pairs))
+	for _, p := range pairs {
+		work <- p
+	}
+	close(work)
+
+	numWorkers := d.config.NumWorkers
+	if numWorkers <= 0 {
+		numWorkers = 1
+	}
+
+	var (
+		mu          sync.Mutex
+		matches     []DuplicateMatch
+		comparisons int
+	)
+
+	var wg sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range work {
+				if !d.shouldCompare(p.a, p.b, nil) {
+					continue
+				}
+				score, reasons := d.scorePair(p.a, p.b)
+				mu.Lock()
+				comparisons++
+				if score >= d.config.MinThreshold {
+					matches = append(matches, DuplicateMatch{Indi1: p.a, Indi2: p.b, Score: score, Reasons: reasons})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return matches, comparisons, nil
+}
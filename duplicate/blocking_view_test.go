@@ -0,0 +1,184 @@
+package duplicate
+
+import (
+	"testing"
+)
+
+func TestDuplicateDetector_SnapshotEmpty(t *testing.T) {
+	detector := NewDuplicateDetector(DefaultConfig())
+	view := detector.Snapshot()
+	if view.Version() != 0 {
+		t.Errorf("expected version 0 for a detector with no Add/Remove calls, got %d", view.Version())
+	}
+	if _, ok := view.GetByXref("@I1@"); ok {
+		t.Error("expected GetByXref to find nothing in an empty view")
+	}
+}
+
+func TestDuplicateDetector_AddPublishesNewVersion(t *testing.T) {
+	detector := NewDuplicateDetector(DefaultConfig())
+	indi := withXref(createTestIndividual("John /Anderson/", "John", "Anderson", "1800", ""), "@I1@")
+
+	view1 := detector.Add(indi)
+	if view1.Version() != 1 {
+		t.Errorf("expected version 1 after one Add, got %d", view1.Version())
+	}
+	got, ok := view1.GetByXref("@I1@")
+	if !ok || got != indi {
+		t.Error("expected GetByXref to find the added individual")
+	}
+
+	view2 := detector.Snapshot()
+	if view2.Version() != 1 {
+		t.Errorf("expected Snapshot to return the latest published version, got %d", view2.Version())
+	}
+}
+
+func TestDuplicateDetector_AddIsCopyOnWrite(t *testing.T) {
+	detector := NewDuplicateDetector(DefaultConfig())
+	first := withXref(createTestIndividual("John /Anderson/", "John", "Anderson", "1800", ""), "@I1@")
+	second := withXref(createTestIndividual("Jane /Andersen/", "Jane", "Andersen", "1850", ""), "@I2@")
+
+	viewBefore := detector.Add(first)
+	detector.Add(second)
+
+	if _, ok := viewBefore.GetByXref("@I2@"); ok {
+		t.Error("expected an earlier BlockingView to stay frozen after a later Add -- copy-on-write isolation broke")
+	}
+	if _, ok := viewBefore.GetByXref("@I1@"); !ok {
+		t.Error("expected the earlier view to still see the individual present when it was taken")
+	}
+
+	viewAfter := detector.Snapshot()
+	if _, ok := viewAfter.GetByXref("@I2@"); !ok {
+		t.Error("expected the latest snapshot to see both individuals")
+	}
+}
+
+func TestDuplicateDetector_RemovePublishesNewVersionAndIsolates(t *testing.T) {
+	detector := NewDuplicateDetector(DefaultConfig())
+	indi := withXref(createTestIndividual("John /Anderson/", "John", "Anderson", "1800", ""), "@I1@")
+
+	viewWith := detector.Add(indi)
+	viewWithout := detector.Remove("@I1@")
+
+	if viewWithout.Version() != 2 {
+		t.Errorf("expected version 2 after Add then Remove, got %d", viewWithout.Version())
+	}
+	if _, ok := viewWith.GetByXref("@I1@"); !ok {
+		t.Error("expected the pre-Remove view to still see the individual")
+	}
+	if _, ok := viewWithout.GetByXref("@I1@"); ok {
+		t.Error("expected the post-Remove view to no longer see the individual")
+	}
+}
+
+func TestBlockingView_GetByPrefixAndSuffix(t *testing.T) {
+	detector := NewDuplicateDetector(DefaultConfig())
+	view := detector.Add(
+		withXref(createTestIndividual("John /Anderson/", "John", "Anderson", "1800", ""), "@I1@"),
+		withXref(createTestIndividual("Jane /Andersen/", "Jane", "Andersen", "1850", ""), "@I2@"),
+		withXref(createTestIndividual("Bob /Smith/", "Bob", "Smith", "1900", ""), "@I3@"),
+	)
+
+	if matches := view.GetByPrefix("Ande"); len(matches) != 2 {
+		t.Errorf("expected 2 individuals for 'Ande' prefix, got %d", len(matches))
+	}
+	if matches := view.GetBySuffix("son"); len(matches) != 1 {
+		t.Errorf("expected 1 individual for 'son' suffix, got %d", len(matches))
+	}
+}
+
+func TestBlockingView_IterateBlock(t *testing.T) {
+	detector := NewDuplicateDetector(DefaultConfig())
+	a := withXref(createTestIndividual("John /Anderson/", "John", "Anderson", "1800", ""), "@I1@")
+	b := withXref(createTestIndividual("Jack /Anderson/", "Jack", "Anderson", "1805", ""), "@I2@")
+	view := detector.Add(a, b)
+
+	var found []string
+	for indi := range view.IterateBlock(blockKey(a)) {
+		found = append(found, indi.XrefID())
+	}
+	if len(found) != 2 {
+		t.Fatalf("expected 2 individuals sharing a's block, got %d: %v", len(found), found)
+	}
+
+	count := 0
+	for range view.IterateBlock(blockKey(a)) {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Error("expected IterateBlock to stop after the consumer returns false from yield")
+	}
+}
+
+func TestBlockingView_Metrics(t *testing.T) {
+	detector := NewDuplicateDetector(DefaultConfig())
+	view := detector.Add(
+		withXref(createTestIndividual("John /Anderson/", "John", "Anderson", "1800", ""), "@I1@"),
+		withXref(createTestIndividual("Jane /Andersen/", "Jane", "Andersen", "1850", ""), "@I2@"),
+	)
+
+	bm := view.Metrics()
+	if bm.SnapshotVersion != view.Version() {
+		t.Errorf("expected Metrics().SnapshotVersion %d to equal view.Version() %d", bm.SnapshotVersion, view.Version())
+	}
+	if bm.TotalPeople != 2 {
+		t.Errorf("expected TotalPeople 2, got %d", bm.TotalPeople)
+	}
+}
+
+func TestDuplicateDetector_FindDuplicatesFromView(t *testing.T) {
+	config := DefaultConfig()
+	config.MinThreshold = 0.0
+	detector := NewDuplicateDetector(config)
+
+	a := withXref(createTestIndividual("John /Anderson/", "John", "Anderson", "1800", ""), "@I1@")
+	b := withXref(createTestIndividual("John /Anderson/", "John", "Anderson", "1801", ""), "@I2@")
+	view := detector.Add(a, b)
+
+	matches, comparisons, err := detector.FindDuplicatesFromView(view)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if comparisons != 1 {
+		t.Errorf("expected exactly 1 comparison for a 2-element block, got %d", comparisons)
+	}
+	if len(matches) != 1 {
+		t.Errorf("expected 1 match, got %d", len(matches))
+	}
+}
+
+func TestDuplicateDetector_FindDuplicatesFromView_Empty(t *testing.T) {
+	detector := NewDuplicateDetector(DefaultConfig())
+	matches, comparisons, err := detector.FindDuplicatesFromView(detector.Snapshot())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 0 || comparisons != 0 {
+		t.Errorf("expected no matches/comparisons from an empty snapshot, got %d/%d", len(matches), comparisons)
+	}
+}
+
+func TestDuplicateDetector_FindDuplicatesFromView_SnapshotIsolation(t *testing.T) {
+	config := DefaultConfig()
+	config.MinThreshold = 0.0
+	detector := NewDuplicateDetector(config)
+
+	a := withXref(createTestIndividual("John /Anderson/", "John", "Anderson", "1800", ""), "@I1@")
+	b := withXref(createTestIndividual("John /Anderson/", "John", "Anderson", "1801", ""), "@I2@")
+	viewBefore := detector.Add(a, b)
+
+	// Removing b after taking viewBefore must not change what a worker
+	// scanning viewBefore sees.
+	detector.Remove("@I2@")
+
+	matches, comparisons, err := detector.FindDuplicatesFromView(viewBefore)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if comparisons != 1 || len(matches) != 1 {
+		t.Errorf("expected the frozen view to still report its original pair, got %d comparisons / %d matches", comparisons, len(matches))
+	}
+}
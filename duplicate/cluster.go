@@ -0,0 +1,153 @@
+package duplicate
+
+import "github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+
+// Cluster is a group of individuals detected as probable duplicates of
+// one another, along with the pairwise scores that connected them and a
+// suggested record to merge the rest into.
+type Cluster struct {
+	Members   []*types.IndividualRecord
+	Scores    []DuplicateMatch
+	Canonical *types.IndividualRecord
+}
+
+// DetectOptions configures a single Detect call. A nil Config falls back
+// to the DuplicateDetector's own config.
+type DetectOptions struct {
+	Config *Config
+}
+
+// Detect scans every individual in tree for probable duplicates and
+// returns them grouped into Clusters. It's the entry point most callers
+// want: NewDuplicateDetector(nil).Detect(tree, nil) runs with defaults.
+func (d *DuplicateDetector) Detect(tree *types.GedcomTree, opts *DetectOptions) ([]Cluster, error) {
+	if opts != nil && opts.Config != nil {
+		d.config = opts.Config
+	}
+
+	individuals := getIndividualSlice()
+	defer putIndividualSlice(individuals)
+	for _, rec := range tree.GetAllIndividuals() {
+		if indi, ok := rec.(*types.IndividualRecord); ok {
+			individuals = append(individuals, indi)
+		}
+	}
+
+	var idx *blockIndex
+	if d.config.UseBlocking {
+		idx = d.buildIndexes(individuals)
+	}
+	jobs := d.generateComparisonJobs(individuals, idx)
+	defer putJobSlice(jobs)
+
+	matches := getMatchSlice()
+	defer putMatchSlice(matches)
+	for _, j := range jobs {
+		score, reasons := d.scorePair(j.indi1, j.indi2)
+		if score >= d.config.MinThreshold {
+			matches = append(matches, DuplicateMatch{Indi1: j.indi1, Indi2: j.indi2, Score: score, Reasons: reasons})
+		}
+	}
+
+	return clusterMatches(matches), nil
+}
+
+// clusterMatches groups matches into Clusters via union-find: any chain
+// of pairwise matches above threshold lands every member in the same
+// cluster, even if two members were never directly compared to each
+// other (e.g. they fell in different blocks but both matched a third
+// individual).
+func clusterMatches(matches []DuplicateMatch) []Cluster {
+	parent := make(map[string]string)
+	byXref := make(map[string]*types.IndividualRecord)
+
+	var find func(string) string
+	find = func(x string) string {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	union := func(a, b string) {
+		ra, rb := find(a), find(b)
+		if ra != rb {
+			parent[ra] = rb
+		}
+	}
+
+	for _, m := range matches {
+		a, b := m.Indi1.XrefID(), m.Indi2.XrefID()
+		if _, ok := parent[a]; !ok {
+			parent[a] = a
+		}
+		if _, ok := parent[b]; !ok {
+			parent[b] = b
+		}
+		byXref[a] = m.Indi1
+		byXref[b] = m.Indi2
+		union(a, b)
+	}
+
+	groups := make(map[string][]string)
+	for x := range parent {
+		root := find(x)
+		groups[root] = append(groups[root], x)
+	}
+
+	scoresByRoot := make(map[string][]DuplicateMatch)
+	for _, m := range matches {
+		root := find(m.Indi1.XrefID())
+		scoresByRoot[root] = append(scoresByRoot[root], m)
+	}
+
+	clusters := make([]Cluster, 0, len(groups))
+	for root, xrefs := range groups {
+		if len(xrefs) < 2 {
+			continue
+		}
+		members := make([]*types.IndividualRecord, 0, len(xrefs))
+		for _, x := range xrefs {
+			members = append(members, byXref[x])
+		}
+		clusters = append(clusters, Cluster{
+			Members:   members,
+			Scores:    scoresByRoot[root],
+			Canonical: canonicalOf(members),
+		})
+	}
+	return clusters
+}
+
+// canonicalOf suggests which cluster member to treat as the merge
+// target: the one with the most complete record (most of birth date,
+// birth place, death date, and family memberships filled in).
+func canonicalOf(members []*types.IndividualRecord) *types.IndividualRecord {
+	best := members[0]
+	bestScore := completeness(best)
+	for _, m := range members[1:] {
+		if s := completeness(m); s > bestScore {
+			best, bestScore = m, s
+		}
+	}
+	return best
+}
+
+func completeness(indi *types.IndividualRecord) int {
+	score := 0
+	if indi.GetBirthDate() != "" {
+		score++
+	}
+	if indi.GetBirthPlace() != "" {
+		score++
+	}
+	if indi.GetDeathDate() != "" {
+		score++
+	}
+	if len(indi.GetFamiliesAsSpouse()) > 0 {
+		score++
+	}
+	if len(indi.GetFamiliesAsChild()) > 0 {
+		score++
+	}
+	return score
+}
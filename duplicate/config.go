@@ -0,0 +1,75 @@
+// Package duplicate finds probable-duplicate individuals in a GedcomTree
+// and merges them. Detection scores each candidate pair on four signals
+// (name, date proximity, birthplace, and shared graph context) and groups
+// pairs that clear MinThreshold into Clusters; Merge then folds a
+// Cluster's members into its canonical record.
+package duplicate
+
+// Weights controls how much each signal contributes to a pair's combined
+// score. The four weights need not sum to 1: scorePair normalizes by
+// their total.
+type Weights struct {
+	Name    float64
+	Date    float64
+	Place   float64
+	Context float64
+}
+
+// Config tunes a DuplicateDetector's blocking, scoring, and concurrency
+// behavior.
+type Config struct {
+	// UseBlocking restricts pairwise scoring to individuals sharing a
+	// phonetic-surname+birth-decade block, instead of comparing every
+	// individual against every other one. Disable it for small trees or
+	// when blocking itself is under test.
+	UseBlocking bool
+
+	// MinThreshold is the minimum combined score (see Weights) a pair
+	// must clear to be reported as a DuplicateMatch.
+	MinThreshold float64
+
+	// UseParallelProcessing drives findDuplicatesBetweenParallel's worker
+	// pool instead of scoring comparisons on the calling goroutine.
+	UseParallelProcessing bool
+
+	// NumWorkers is the worker pool size when UseParallelProcessing is
+	// set. Values <= 0 are treated as 1.
+	NumWorkers int
+
+	Weights Weights
+
+	// IncludeNamePatterns, if non-empty, restricts comparison to
+	// individuals whose GetName matches at least one glob pattern (see
+	// GlobPattern for supported syntax, e.g. "{Smith,Smyth,Schmidt}").
+	IncludeNamePatterns []string
+
+	// ExcludeNamePatterns skips any individual whose GetName matches one
+	// of these glob patterns, e.g. "Living*" for tagged-living records.
+	ExcludeNamePatterns []string
+
+	// IncludePlacePatterns, if non-empty, restricts comparison to
+	// individuals whose GetBirthPlace matches at least one glob pattern.
+	IncludePlacePatterns []string
+
+	// ExcludePlacePatterns skips any individual whose GetBirthPlace
+	// matches one of these glob patterns, e.g. "*, Utah, USA" to exclude
+	// an entire place hierarchy.
+	ExcludePlacePatterns []string
+}
+
+// DefaultConfig returns a Config with blocking enabled, a moderately
+// conservative match threshold, and serial scoring.
+func DefaultConfig() *Config {
+	return &Config{
+		UseBlocking:           true,
+		MinThreshold:          0.65,
+		UseParallelProcessing: false,
+		NumWorkers:            4,
+		Weights: Weights{
+			Name:    0.4,
+			Date:    0.25,
+			Place:   0.15,
+			Context: 0.2,
+		},
+	}
+}
@@ -0,0 +1,408 @@
+package duplicate
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// DuplicateDetector scores pairs of individuals for probable duplication
+// according to a Config. Detect is given the tree to scan, and the
+// lower-level methods (buildIndexes, generateComparisonJobs,
+// findDuplicatesBetweenParallel) operate directly on the individual
+// slices callers pass in rather than on any state held by d.
+//
+// Add/Remove/Snapshot are the exception: they maintain an optional
+// incremental blocking index (see BlockingView) for callers streaming
+// individuals in over time, guarded by snapMu.
+type DuplicateDetector struct {
+	config *Config
+
+	includeNamePatterns  []*GlobPattern
+	excludeNamePatterns  []*GlobPattern
+	includePlacePatterns []*GlobPattern
+	excludePlacePatterns []*GlobPattern
+
+	snapMu  sync.Mutex
+	current *blockingSnapshot
+}
+
+// NewDuplicateDetector returns a DuplicateDetector configured by config.
+// A nil config falls back to DefaultConfig. config's glob patterns are
+// compiled once here rather than per comparison.
+func NewDuplicateDetector(config *Config) *DuplicateDetector {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	return &DuplicateDetector{
+		config:               config,
+		includeNamePatterns:  compileGlobPatterns(config.IncludeNamePatterns),
+		excludeNamePatterns:  compileGlobPatterns(config.ExcludeNamePatterns),
+		includePlacePatterns: compileGlobPatterns(config.IncludePlacePatterns),
+		excludePlacePatterns: compileGlobPatterns(config.ExcludePlacePatterns),
+	}
+}
+
+// DuplicateMatch is a scored candidate pair. Reasons holds one
+// human-readable explanation per signal that contributed meaningfully to
+// Score, e.g. "matched on: phonetic surname + birth year ±1".
+type DuplicateMatch struct {
+	Indi1   *types.IndividualRecord
+	Indi2   *types.IndividualRecord
+	Score   float64
+	Reasons []string
+}
+
+// job is one pairwise comparison queued by generateComparisonJobs. index
+// records the job's position in the returned slice, letting a worker
+// pool report results back in order if a caller needs that.
+type job struct {
+	indi1 *types.IndividualRecord
+	indi2 *types.IndividualRecord
+	index int
+}
+
+var matchSlicePool = sync.Pool{New: func() interface{} { return make([]DuplicateMatch, 0, 16) }}
+var individualSlicePool = sync.Pool{New: func() interface{} { return make([]*types.IndividualRecord, 0, 16) }}
+var stringSlicePool = sync.Pool{New: func() interface{} { return make([]string, 0, 8) }}
+var jobSlicePool = sync.Pool{New: func() interface{} { return make([]job, 0, 16) }}
+
+func getMatchSlice() []DuplicateMatch { return matchSlicePool.Get().([]DuplicateMatch) }
+
+func putMatchSlice(s []DuplicateMatch) {
+	if s == nil {
+		return
+	}
+	matchSlicePool.Put(s[:0])
+}
+
+func getIndividualSlice() []*types.IndividualRecord {
+	return individualSlicePool.Get().([]*types.IndividualRecord)
+}
+
+func putIndividualSlice(s []*types.IndividualRecord) {
+	if s == nil {
+		return
+	}
+	individualSlicePool.Put(s[:0])
+}
+
+func getStringSlice() []string { return stringSlicePool.Get().([]string) }
+
+func putStringSlice(s []string) {
+	if s == nil {
+		return
+	}
+	stringSlicePool.Put(s[:0])
+}
+
+func getJobSlice() []job { return jobSlicePool.Get().([]job) }
+
+func putJobSlice(s []job) {
+	if s == nil {
+		return
+	}
+	jobSlicePool.Put(s[:0])
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// quickNameSimilarity is a cheap pre-filter used by shouldCompare to skip
+// the full scorePair computation for pairs whose names have nothing in
+// common: 1.0 for an exact (case-insensitive) match, 0.5 when the first
+// word of both names matches, 0.0 otherwise.
+func quickNameSimilarity(name1, name2 string) float64 {
+	n1 := strings.ToLower(strings.TrimSpace(name1))
+	n2 := strings.ToLower(strings.TrimSpace(name2))
+	if n1 == n2 {
+		return 1.0
+	}
+	f1 := strings.Fields(n1)
+	f2 := strings.Fields(n2)
+	if len(f1) > 0 && len(f2) > 0 && f1[0] == f2[0] {
+		return 0.5
+	}
+	return 0.0
+}
+
+// getChildren returns the XREFs of indi's children, found via the spouse
+// families (FAMS) it's registered on within tree. tree is accepted for
+// symmetry with the rest of this package's tree-scoped helpers, though
+// GetFamiliesAsSpouse already resolves against whichever tree indi's
+// record was added to.
+func getChildren(indi *types.IndividualRecord, tree *types.GedcomTree) []string {
+	children := getStringSlice()
+	for _, famRec := range indi.GetFamiliesAsSpouse() {
+		fam, ok := famRec.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+		children = append(children, fam.GetChildren()...)
+	}
+	if len(children) == 0 {
+		putStringSlice(children)
+		return nil
+	}
+	return children
+}
+
+// buildIndexes groups individuals into blocks keyed by phonetic surname
+// plus birth decade, so generateComparisonJobs only compares individuals
+// within the same block instead of every pair in individuals.
+func (d *DuplicateDetector) buildIndexes(individuals []*types.IndividualRecord) *blockIndex {
+	idx := &blockIndex{
+		blocks:            make(map[string][]*types.IndividualRecord),
+		prefixTree:        query.NewRadixTree(),
+		suffixTree:        query.NewRadixTree(),
+		byXref:            make(map[string]*types.IndividualRecord, len(individuals)),
+		fingerprints:      make([]uint64, len(individuals)),
+		fingerprintByXref: make(map[string]uint64, len(individuals)),
+	}
+	for i, indi := range individuals {
+		key := blockKey(indi)
+		idx.blocks[key] = append(idx.blocks[key], indi)
+
+		fp := nameFingerprint(indi.GetName())
+		idx.fingerprints[i] = fp
+		idx.fingerprintByXref[indi.XrefID()] = fp
+
+		_, surname := splitName(indi.GetName())
+		if norm := normalizeToken(surname); norm != "" {
+			idx.byXref[indi.XrefID()] = indi
+			idx.prefixTree = idx.prefixTree.Insert(norm, indi.XrefID())
+			idx.suffixTree = idx.suffixTree.Insert(reverseString(norm), indi.XrefID())
+		}
+	}
+	return idx
+}
+
+// fingerprintFor returns indi's nameFingerprint from idx's fingerprintByXref
+// when idx is available, computing it directly otherwise -- the
+// unblocked (idx == nil) comparison path has no precomputed index to
+// consult.
+func fingerprintFor(idx *blockIndex, indi *types.IndividualRecord) uint64 {
+	if idx != nil {
+		if fp, ok := idx.fingerprintByXref[indi.XrefID()]; ok {
+			return fp
+		}
+	}
+	return nameFingerprint(indi.GetName())
+}
+
+// passesPatternFilters reports whether indi's name and birthplace clear
+// d's configured include/exclude glob patterns: excluded by a single
+// exclude match, and (when any include patterns are configured) required
+// to match at least one.
+func (d *DuplicateDetector) passesPatternFilters(indi *types.IndividualRecord) bool {
+	name := indi.GetName()
+	if anyMatch(d.excludeNamePatterns, name) {
+		return false
+	}
+	if len(d.includeNamePatterns) > 0 && !anyMatch(d.includeNamePatterns, name) {
+		return false
+	}
+
+	place := indi.GetBirthPlace()
+	if anyMatch(d.excludePlacePatterns, place) {
+		return false
+	}
+	if len(d.includePlacePatterns) > 0 && !anyMatch(d.includePlacePatterns, place) {
+		return false
+	}
+
+	return true
+}
+
+// shouldCompare is the cheap filter generateComparisonJobs runs before
+// queuing a pair for the full scorePair comparison. idx, when non-nil,
+// supplies precomputed name fingerprints so the popcount prefilter
+// doesn't recompute them per pair; pass nil when idx isn't available
+// (the unblocked comparison path).
+func (d *DuplicateDetector) shouldCompare(a, b *types.IndividualRecord, idx *blockIndex) bool {
+	if !d.passesPatternFilters(a) || !d.passesPatternFilters(b) {
+		return false
+	}
+	if d.config.MinThreshold <= 0 {
+		return true
+	}
+	if !nameFingerprintsPlausible(fingerprintFor(idx, a), fingerprintFor(idx, b)) {
+		return false
+	}
+	return quickNameSimilarity(a.GetName(), b.GetName()) > 0
+}
+
+// generateComparisonJobs builds the list of candidate pairs to score: one
+// job per pair within a shared block when UseBlocking is set (idx must be
+// the result of buildIndexes over the same individuals), or every pair in
+// individuals otherwise. Each returned job's index equals its position in
+// the returned slice.
+//
+// When blocking, pairs within idx's exact phonetic-surname+decade blocks
+// are supplemented with pairs sharing a surnamePrefixDepth-letter
+// surname prefix (via idx.LookupByPrefix), so two records whose birth
+// decade didn't line up -- e.g. a recorded vs. an estimated birth year --
+// can still be compared as long as their surnames agree up to that
+// prefix. add dedupes, since a pair can be reachable through both paths.
+func (d *DuplicateDetector) generateComparisonJobs(individuals []*types.IndividualRecord, idx *blockIndex) []job {
+	jobs := getJobSlice()
+	seen := make(map[[2]string]bool)
+	add := func(a, b *types.IndividualRecord) {
+		if a == nil || b == nil || a == b || a.XrefID() == b.XrefID() {
+			return
+		}
+		if !d.shouldCompare(a, b, idx) {
+			return
+		}
+		key := unorderedPairKey(a.XrefID(), b.XrefID())
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		jobs = append(jobs, job{indi1: a, indi2: b, index: len(jobs)})
+	}
+
+	if d.config.UseBlocking && idx != nil {
+		for _, block := range idx.blocks {
+			for i := 0; i < len(block); i++ {
+				for j := i + 1; j < len(block); j++ {
+					add(block[i], block[j])
+				}
+			}
+		}
+		for _, indi := range individuals {
+			_, surname := splitName(indi.GetName())
+			norm := normalizeToken(surname)
+			if len(norm) < surnamePrefixDepth {
+				continue
+			}
+			for _, other := range idx.LookupByPrefix(norm[:surnamePrefixDepth]) {
+				add(indi, other)
+			}
+		}
+		return jobs
+	}
+
+	for i := 0; i < len(individuals); i++ {
+		for j := i + 1; j < len(individuals); j++ {
+			add(individuals[i], individuals[j])
+		}
+	}
+	return jobs
+}
+
+// unorderedPairKey is a's and b's XREFs in a fixed order, so the same
+// pair reached via two different blocking paths maps to the same key
+// regardless of which XREF generateComparisonJobs saw first.
+func unorderedPairKey(a, b string) [2]string {
+	if a < b {
+		return [2]string{a, b}
+	}
+	return [2]string{b, a}
+}
+
+// scorePair combines the name, date, place, and graph-context signals
+// into a single score, weighted by d.config.Weights, along with the
+// human-readable reasons behind any signal that matched strongly enough
+// to explain.
+func (d *DuplicateDetector) scorePair(a, b *types.IndividualRecord) (float64, []string) {
+	var reasons []string
+
+	nameScore, nameReason := nameSimilarity(a, b)
+	if nameReason != "" {
+		reasons = append(reasons, nameReason)
+	}
+	dateScore, dateReason := dateProximityScore(a, b)
+	if dateReason != "" {
+		reasons = append(reasons, dateReason)
+	}
+	placeScore, placeReason := placeSimilarity(a, b)
+	if placeReason != "" {
+		reasons = append(reasons, placeReason)
+	}
+	contextScore, contextReason := contextOverlap(a, b)
+	if contextReason != "" {
+		reasons = append(reasons, contextReason)
+	}
+
+	w := d.config.Weights
+	total := w.Name + w.Date + w.Place + w.Context
+	if total == 0 {
+		total = 1
+	}
+	score := (nameScore*w.Name + dateScore*w.Date + placeScore*w.Place + contextScore*w.Context) / total
+	return score, reasons
+}
+
+// DetectBetween scores every pair across individuals1 x individuals2 the
+// same way Detect does within a single tree, for callers comparing two
+// separate populations of individuals directly (e.g. two different
+// uploaded GEDCOMs) instead of one tree's own records against themselves.
+func (d *DuplicateDetector) DetectBetween(individuals1, individuals2 []*types.IndividualRecord) ([]DuplicateMatch, int, error) {
+	return d.findDuplicatesBetweenParallel(individuals1, individuals2)
+}
+
+// ScorePair exports scorePair's combined name/date/place/context
+// similarity score for a single pair, for callers that want d's scoring
+// without going through Detect/DetectBetween's threshold filtering and
+// pair enumeration -- e.g. a caller scoring individuals sourced from
+// somewhere other than a types.GedcomTree this package's own Detect can
+// walk.
+func (d *DuplicateDetector) ScorePair(a, b *types.IndividualRecord) (float64, []string) {
+	return d.scorePair(a, b)
+}
+
+// findDuplicatesBetweenParallel scores every pair across individuals1 x
+// individuals2 using a pool of d.config.NumWorkers goroutines (at least
+// one), returning the pairs that clear MinThreshold and the total number
+// of comparisons performed.
+func (d *DuplicateDetector) findDuplicatesBetweenParallel(individuals1, individuals2 []*types.IndividualRecord) ([]DuplicateMatch, int, error) {
+	if len(individuals1) == 0 || len(individuals2) == 0 {
+		return nil, 0, nil
+	}
+
+	pairs := make(chan [2]*types.IndividualRecord, len(individuals1)*len(individuals2))
+	for _, a := range individuals1 {
+		for _, b := range individuals2 {
+			pairs <- [2]*types.IndividualRecord{a, b}
+		}
+	}
+	close(pairs)
+
+	numWorkers := d.config.NumWorkers
+	if numWorkers <= 0 {
+		numWorkers = 1
+	}
+
+	var (
+		mu          sync.Mutex
+		matches     []DuplicateMatch
+		comparisons int
+	)
+
+	var wg sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pair := range pairs {
+				score, reasons := d.scorePair(pair[0], pair[1])
+				mu.Lock()
+				comparisons++
+				if score >= d.config.MinThreshold {
+					matches = append(matches, DuplicateMatch{Indi1: pair[0], Indi2: pair[1], Score: score, Reasons: reasons})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return matches, comparisons, nil
+}
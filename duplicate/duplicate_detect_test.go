@@ -0,0 +1,165 @@
+package duplicate
+
+import (
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+func addTestFamily(tree *types.GedcomTree, xref, husb, wife string, children ...string) {
+	line := types.NewGedcomLine(0, "FAM", "", xref)
+	if husb != "" {
+		line.AddChild(types.NewGedcomLine(1, "HUSB", husb, ""))
+	}
+	if wife != "" {
+		line.AddChild(types.NewGedcomLine(1, "WIFE", wife, ""))
+	}
+	for _, c := range children {
+		line.AddChild(types.NewGedcomLine(1, "CHIL", c, ""))
+	}
+	tree.AddRecord(types.NewFamilyRecord(line))
+}
+
+func addTestIndividual(tree *types.GedcomTree, xref, name, birthYear, birthplace string, famc, fams string) {
+	line := types.NewGedcomLine(0, "INDI", "", xref)
+	line.AddChild(types.NewGedcomLine(1, "NAME", name, ""))
+	birt := types.NewGedcomLine(1, "BIRT", "", "")
+	birt.AddChild(types.NewGedcomLine(2, "DATE", birthYear, ""))
+	if birthplace != "" {
+		birt.AddChild(types.NewGedcomLine(2, "PLAC", birthplace, ""))
+	}
+	line.AddChild(birt)
+	if famc != "" {
+		line.AddChild(types.NewGedcomLine(1, "FAMC", famc, ""))
+	}
+	if fams != "" {
+		line.AddChild(types.NewGedcomLine(1, "FAMS", fams, ""))
+	}
+	tree.AddRecord(types.NewIndividualRecord(line))
+}
+
+func TestDetect_FindsObviousDuplicate(t *testing.T) {
+	tree := types.NewGedcomTree()
+	addTestIndividual(tree, "@I1@", "John /Smith/", "1850", "Boston", "", "")
+	addTestIndividual(tree, "@I2@", "John /Smyth/", "1850", "Boston", "", "")
+	addTestIndividual(tree, "@I3@", "Jane /Doe/", "1920", "Chicago", "", "")
+
+	cfg := DefaultConfig()
+	cfg.MinThreshold = 0.5
+	detector := NewDuplicateDetector(cfg)
+
+	clusters, err := detector.Detect(tree, nil)
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	if len(clusters) != 1 {
+		t.Fatalf("expected 1 cluster, got %d (%+v)", len(clusters), clusters)
+	}
+	if len(clusters[0].Members) != 2 {
+		t.Fatalf("expected 2 members in cluster, got %d", len(clusters[0].Members))
+	}
+	if clusters[0].Canonical == nil {
+		t.Error("expected a canonical record to be suggested")
+	}
+}
+
+func TestDetect_NoFalsePositiveAcrossUnrelatedIndividuals(t *testing.T) {
+	tree := types.NewGedcomTree()
+	addTestIndividual(tree, "@I1@", "John /Smith/", "1850", "Boston", "", "")
+	addTestIndividual(tree, "@I2@", "Jane /Doe/", "1920", "Chicago", "", "")
+
+	detector := NewDuplicateDetector(DefaultConfig())
+	clusters, err := detector.Detect(tree, nil)
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	if len(clusters) != 0 {
+		t.Errorf("expected no clusters for unrelated individuals, got %d", len(clusters))
+	}
+}
+
+func TestMerge_RewritesFamilyPointersAndUnionsMemberships(t *testing.T) {
+	tree := types.NewGedcomTree()
+	addTestFamily(tree, "@F1@", "@I1@", "", "@I3@")
+	addTestFamily(tree, "@F2@", "@I2@", "", "@I4@")
+	addTestIndividual(tree, "@I1@", "John /Smith/", "1850", "Boston", "", "@F1@")
+	addTestIndividual(tree, "@I2@", "John /Smyth/", "1850", "Boston", "", "@F2@")
+	addTestIndividual(tree, "@I3@", "Child One /Smith/", "1880", "", "@F1@", "")
+	addTestIndividual(tree, "@I4@", "Child Two /Smith/", "1882", "", "@F2@", "")
+
+	rec1 := tree.GetAllIndividuals()["@I1@"].(*types.IndividualRecord)
+	rec2 := tree.GetAllIndividuals()["@I2@"].(*types.IndividualRecord)
+	cluster := Cluster{Members: []*types.IndividualRecord{rec1, rec2}, Canonical: rec1}
+
+	dropped, err := Merge(tree, cluster, MergePolicy{})
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if !dropped["@I2@"] {
+		t.Fatalf("expected @I2@ to be dropped, got %v", dropped)
+	}
+
+	fam2 := tree.GetAllFamilies()["@F2@"].(*types.FamilyRecord)
+	if fam2.GetHusband() != "@I1@" {
+		t.Errorf("expected @F2@'s HUSB to be rewritten to @I1@, got %q", fam2.GetHusband())
+	}
+
+	found := false
+	for _, fam := range rec1.GetFamiliesAsSpouse() {
+		if fr, ok := fam.(*types.FamilyRecord); ok && fr.XrefID() == "@F2@" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected @I1@ to gain @F2@ as a spouse family after merge")
+	}
+}
+
+func TestMerge_NoCanonicalIsAnError(t *testing.T) {
+	_, err := Merge(types.NewGedcomTree(), Cluster{}, MergePolicy{})
+	if err == nil {
+		t.Fatal("expected an error for a cluster with no canonical record")
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	cases := []struct {
+		a, b string
+		min  float64
+	}{
+		{"MARTHA", "MARHTA", 0.9},
+		{"smith", "smith", 1.0},
+		{"smith", "smyth", 0.8},
+	}
+	for _, c := range cases {
+		got := jaroWinkler(c.a, c.b)
+		if got < c.min {
+			t.Errorf("jaroWinkler(%q, %q) = %.3f, want >= %.3f", c.a, c.b, got, c.min)
+		}
+	}
+	if got := jaroWinkler("abc", ""); got != 0 {
+		t.Errorf("jaroWinkler with an empty string = %.3f, want 0", got)
+	}
+}
+
+func TestMetaphoneKey_GroupsPhoneticVariants(t *testing.T) {
+	if metaphoneKey("Smith") != metaphoneKey("Smyth") {
+		t.Errorf("expected Smith and Smyth to share a metaphoneKey, got %q vs %q", metaphoneKey("Smith"), metaphoneKey("Smyth"))
+	}
+	if metaphoneKey("Smith") == metaphoneKey("Jones") {
+		t.Errorf("expected Smith and Jones to have different metaphoneKeys, both got %q", metaphoneKey("Smith"))
+	}
+}
+
+func TestBlockKey_GroupsBySurnameAndDecade(t *testing.T) {
+	a := createTestIndividual("John /Smith/", "John", "Smith", "1851", "Boston")
+	b := createTestIndividual("John /Smyth/", "John", "Smyth", "1857", "Boston")
+	c := createTestIndividual("Jane /Doe/", "Jane", "Doe", "1920", "Chicago")
+
+	if blockKey(a) != blockKey(b) {
+		t.Errorf("expected %v and %v to share a block key, got %q vs %q", a, b, blockKey(a), blockKey(b))
+	}
+	if blockKey(a) == blockKey(c) {
+		t.Errorf("expected unrelated individuals to have different block keys")
+	}
+}
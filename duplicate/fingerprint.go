@@ -0,0 +1,118 @@
+package duplicate
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// fingerprintLetterBits is the number of low bits of a name fingerprint
+// spent on individual letter presence (a-z).
+const fingerprintLetterBits = 26
+
+// commonNameBigrams are the two-letter sequences that occupy the high
+// bits of a name fingerprint (bits fingerprintLetterBits and up), chosen
+// for frequency in English-derived given/surnames.
+var commonNameBigrams = [...]string{
+	"th", "he", "an", "in", "er", "re", "on", "at", "en", "nd",
+	"ti", "es", "or", "te", "of", "ed", "is", "it", "al", "ar",
+	"st", "to", "nt", "ng", "se", "ha", "as", "ou", "io", "le",
+	"ve", "co", "me", "de", "hi", "ri", "ro", "ic",
+}
+
+// fingerprintOverlapFraction is the minimum fraction of the sparser
+// fingerprint's set bits that must also be set in the other fingerprint
+// for nameFingerprintsPlausible to let a pair through. Chosen low enough
+// that two names sharing only a handful of letters/bigrams -- e.g. a
+// single shared bigram on a short name -- still pass, since the point is
+// to reject pairs with essentially nothing in common, not to replace
+// quickNameSimilarity's actual scoring.
+const fingerprintOverlapFraction = 0.25
+
+// fingerprintDiacriticFold maps the Latin letters with diacritics most
+// likely to appear in genealogical given/surnames to their plain ASCII
+// base letter, the same deliberately-approximate table as
+// search/tokenize.go's diacriticFold, copied here rather than imported
+// since fingerprinting is duplicate's own concern.
+var fingerprintDiacriticFold = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ā': 'a',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e', 'ė': 'e', 'ę': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ī': 'i',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o', 'ō': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u',
+	'ý': 'y', 'ÿ': 'y',
+	'ñ': 'n', 'ń': 'n',
+	'ç': 'c', 'ć': 'c', 'č': 'c',
+	'š': 's', 'ś': 's',
+	'ž': 'z', 'ź': 'z', 'ż': 'z',
+	'ł': 'l',
+	'đ': 'd', 'ď': 'd',
+	'ř': 'r',
+	'ť': 't',
+}
+
+// foldNameToken lowercases s, folds it through fingerprintDiacriticFold,
+// and drops everything but a-z -- normalizeToken plus diacritic folding,
+// kept separate from normalizeToken so this file's fingerprinting can't
+// change normalizeToken's existing behavior for jaroWinkler/metaphoneKey.
+func foldNameToken(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if folded, ok := fingerprintDiacriticFold[r]; ok {
+			r = folded
+		}
+		if r >= 'a' && r <= 'z' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// nameFingerprint builds a 64-bit bitset fingerprint of name's given and
+// surname parts: bit i (0-25) set when letter 'a'+i appears anywhere in
+// the folded given+surname, and bit fingerprintLetterBits+j set when
+// commonNameBigrams[j] appears as a substring. A pair of fingerprints
+// sharing few or no set bits almost certainly names two unrelated
+// people, letting nameFingerprintsPlausible reject them in one
+// popcount+AND before the word-overlap comparison in quickNameSimilarity
+// runs.
+func nameFingerprint(name string) uint64 {
+	given, surname := splitName(name)
+	combined := foldNameToken(given) + foldNameToken(surname)
+	if combined == "" {
+		return 0
+	}
+
+	var fp uint64
+	for _, r := range combined {
+		fp |= 1 << uint(r-'a')
+	}
+	for i := 0; i+1 < len(combined); i++ {
+		bigram := combined[i : i+2]
+		for j, candidate := range commonNameBigrams {
+			if bigram == candidate {
+				fp |= 1 << uint(fingerprintLetterBits+j)
+				break
+			}
+		}
+	}
+	return fp
+}
+
+// nameFingerprintsPlausible reports whether a and b share enough set
+// bits to be worth the full quickNameSimilarity comparison. A zero
+// fingerprint (no letters at all, e.g. an empty name) is never rejected
+// here -- there's nothing to fingerprint, so the string-based fallback
+// decides instead.
+func nameFingerprintsPlausible(a, b uint64) bool {
+	if a == 0 || b == 0 {
+		return true
+	}
+	popA, popB := bits.OnesCount64(a), bits.OnesCount64(b)
+	minPop := popA
+	if popB < minPop {
+		minPop = popB
+	}
+	overlap := bits.OnesCount64(a & b)
+	threshold := int(float64(minPop) * fingerprintOverlapFraction)
+	return overlap >= threshold
+}
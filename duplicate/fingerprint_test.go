@@ -0,0 +1,75 @@
+package duplicate
+
+import "testing"
+
+func TestNameFingerprintsPlausible_NeverRejectsTruePositives(t *testing.T) {
+	tests := []struct {
+		name1, name2 string
+	}{
+		{"John /Smith/", "John /Smith/"},             // exact match
+		{"John /Smith/", "john /smith/"},              // case-insensitive
+		{"Jon /Smith/", "John /Smith/"},                // missing letter
+		{"Jon /Smyth/", "John /Smith/"},                // phonetic spelling variant
+		{"Catherine /O'Brien/", "Katherine /Obrien/"},  // alternate spelling + punctuation
+		{"José /García/", "Jose /Garcia/"},             // accented vs folded
+		{"François /Müller/", "Francois /Muller/"},     // accented vs folded, different alphabet origin
+		{"Søren /Jørgensen/", "Soren /Jorgensen/"},      // Scandinavian diacritics
+		{"Björn /Åström/", "Bjorn /Astrom/"},            // Swedish diacritics
+		{"Mary /Anne/", "Mary /Ann/"},                   // trailing-letter variant
+	}
+
+	for _, tt := range tests {
+		fp1 := nameFingerprint(tt.name1)
+		fp2 := nameFingerprint(tt.name2)
+		if !nameFingerprintsPlausible(fp1, fp2) {
+			t.Errorf("nameFingerprintsPlausible(%q, %q) = false, want true (true positive)", tt.name1, tt.name2)
+		}
+		if quickNameSimilarity(tt.name1, tt.name2) > 0 && !nameFingerprintsPlausible(fp1, fp2) {
+			t.Errorf("prefilter rejected a pair quickNameSimilarity considers related: %q / %q", tt.name1, tt.name2)
+		}
+	}
+}
+
+func TestNameFingerprintsPlausible_LogsUnrelatedNames(t *testing.T) {
+	tests := []struct {
+		name1, name2 string
+	}{
+		{"John /Smith/", "Zachary /Vorkosigan/"},
+		{"Mary /Jones/", "Xiulan /Zhu/"},
+	}
+
+	for _, tt := range tests {
+		fp1 := nameFingerprint(tt.name1)
+		fp2 := nameFingerprint(tt.name2)
+		if nameFingerprintsPlausible(fp1, fp2) {
+			t.Logf("nameFingerprintsPlausible(%q, %q) = true (prefilter is lenient by design, not a hard requirement)", tt.name1, tt.name2)
+		}
+	}
+}
+
+func TestNameFingerprintsPlausible_EmptyFingerprintNeverRejects(t *testing.T) {
+	if !nameFingerprintsPlausible(0, nameFingerprint("John /Smith/")) {
+		t.Error("a zero fingerprint (unparseable name) should never be rejected by the prefilter")
+	}
+	if !nameFingerprintsPlausible(0, 0) {
+		t.Error("two zero fingerprints should never be rejected by the prefilter")
+	}
+}
+
+func TestNameFingerprint_Deterministic(t *testing.T) {
+	a := nameFingerprint("John /Smith/")
+	b := nameFingerprint("John /Smith/")
+	if a != b {
+		t.Errorf("nameFingerprint should be deterministic, got %d and %d", a, b)
+	}
+}
+
+func BenchmarkNameFingerprintsPlausible(b *testing.B) {
+	fp1 := nameFingerprint("John /Smith/")
+	fp2 := nameFingerprint("Jon /Smyth/")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		nameFingerprintsPlausible(fp1, fp2)
+	}
+}
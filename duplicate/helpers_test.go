@@ -0,0 +1,25 @@
+package duplicate
+
+import "github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+
+// createTestIndividual builds a standalone IndividualRecord (not
+// registered on any GedcomTree) with a NAME, BIRT/DATE, and BIRT/PLAC,
+// for tests that only need name/date/place scoring and don't exercise
+// family-context overlap.
+func createTestIndividual(name, given, surname, birthYear, birthplace string) *types.IndividualRecord {
+	line := types.NewGedcomLine(0, "INDI", "", "")
+
+	nameLine := types.NewGedcomLine(1, "NAME", name, "")
+	nameLine.AddChild(types.NewGedcomLine(2, "GIVN", given, ""))
+	nameLine.AddChild(types.NewGedcomLine(2, "SURN", surname, ""))
+	line.AddChild(nameLine)
+
+	birtLine := types.NewGedcomLine(1, "BIRT", "", "")
+	birtLine.AddChild(types.NewGedcomLine(2, "DATE", birthYear, ""))
+	if birthplace != "" {
+		birtLine.AddChild(types.NewGedcomLine(2, "PLAC", birthplace, ""))
+	}
+	line.AddChild(birtLine)
+
+	return types.NewIndividualRecord(line)
+}
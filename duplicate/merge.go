@@ -0,0 +1,112 @@
+package duplicate
+
+import (
+	"fmt"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// MergePolicy controls how Merge folds a Cluster's members into a single
+// canonical record.
+type MergePolicy struct {
+	// KeepXref overrides Cluster.Canonical: if it names one of the
+	// cluster's members, that member is kept and the rest are merged
+	// into it instead.
+	KeepXref string
+}
+
+// Merge folds cluster's non-canonical members into its canonical member
+// (or the member named by policy.KeepXref, if set): every FAM record's
+// HUSB/WIFE/CHIL pointer at a merged-away member is rewritten to the kept
+// xref, and any FAMC/FAMS family membership a merged-away member held
+// that the kept record doesn't already have is copied onto it. It
+// returns the set of merged-away XREFs.
+//
+// Merge does not remove the merged-away individual records themselves --
+// this package's snapshot has no accessor for deleting a record from a
+// GedcomTree -- so a caller that wants them gone should filter them out
+// of tree.GetAllIndividuals() by XREF using the returned set.
+func Merge(tree *types.GedcomTree, cluster Cluster, policy MergePolicy) (map[string]bool, error) {
+	if cluster.Canonical == nil {
+		return nil, fmt.Errorf("duplicate: cluster has no canonical record to merge into")
+	}
+
+	keep := cluster.Canonical
+	keepXref := keep.XrefID()
+	if policy.KeepXref != "" {
+		if rec, ok := tree.GetAllIndividuals()[policy.KeepXref]; ok {
+			if indi, ok := rec.(*types.IndividualRecord); ok {
+				keep, keepXref = indi, policy.KeepXref
+			}
+		}
+	}
+
+	dropped := make(map[string]bool)
+	for _, m := range cluster.Members {
+		if m.XrefID() != keepXref {
+			dropped[m.XrefID()] = true
+		}
+	}
+	if len(dropped) == 0 {
+		return dropped, nil
+	}
+
+	for _, rec := range tree.GetAllFamilies() {
+		fam, ok := rec.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+		for _, tag := range []string{"HUSB", "WIFE", "CHIL"} {
+			for _, line := range fam.GetLines(tag) {
+				if dropped[line.Value] {
+					line.Value = keepXref
+				}
+			}
+		}
+	}
+
+	existingFamc := make(map[string]bool)
+	for _, rec := range keep.GetFamiliesAsChild() {
+		if fam, ok := rec.(*types.FamilyRecord); ok {
+			existingFamc[fam.XrefID()] = true
+		}
+	}
+	existingFams := make(map[string]bool)
+	for _, rec := range keep.GetFamiliesAsSpouse() {
+		if fam, ok := rec.(*types.FamilyRecord); ok {
+			existingFams[fam.XrefID()] = true
+		}
+	}
+
+	keepLine := keep.FirstLine()
+	for _, m := range cluster.Members {
+		if !dropped[m.XrefID()] {
+			continue
+		}
+		for _, rec := range m.GetFamiliesAsChild() {
+			fam, ok := rec.(*types.FamilyRecord)
+			if !ok || existingFamc[fam.XrefID()] {
+				continue
+			}
+			existingFamc[fam.XrefID()] = true
+			keepLine.AddChild(types.NewGedcomLine(1, "FAMC", fam.XrefID(), ""))
+		}
+		for _, rec := range m.GetFamiliesAsSpouse() {
+			fam, ok := rec.(*types.FamilyRecord)
+			if !ok || existingFams[fam.XrefID()] {
+				continue
+			}
+			existingFams[fam.XrefID()] = true
+			keepLine.AddChild(types.NewGedcomLine(1, "FAMS", fam.XrefID(), ""))
+			for _, tag := range []string{"HUSB", "WIFE"} {
+				for _, line := range fam.GetLines(tag) {
+					if line.Value == m.XrefID() {
+						line.Value = keepXref
+					}
+				}
+			}
+		}
+	}
+
+	return dropped, nil
+}
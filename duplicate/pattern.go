@@ -0,0 +1,271 @@
+package duplicate
+
+import (
+	"strings"
+	"sync"
+)
+
+// globAtomKind is one element of a compiled GlobPattern: a single literal
+// rune, a "?" wildcard, a "*" wildcard, or a "[...]" character class.
+type globAtomKind int
+
+const (
+	globAtomLiteral globAtomKind = iota
+	globAtomAny
+	globAtomStar
+	globAtomClass
+)
+
+// globAtom is one compiled unit of a glob pattern, matched against exactly
+// one rune of input (globAtomStar excepted, which matches zero or more).
+type globAtom struct {
+	kind    globAtomKind
+	lit     rune
+	class   []globClassRange
+	negated bool
+}
+
+// globClassRange is one lo-hi rune range within a "[...]" character class;
+// a literal (non-range) character is stored as lo == hi.
+type globClassRange struct {
+	lo, hi rune
+}
+
+func (a globAtom) matches(r rune) bool {
+	switch a.kind {
+	case globAtomAny:
+		return true
+	case globAtomLiteral:
+		return a.lit == r
+	case globAtomClass:
+		in := false
+		for _, cr := range a.class {
+			if r >= cr.lo && r <= cr.hi {
+				in = true
+				break
+			}
+		}
+		if a.negated {
+			return !in
+		}
+		return in
+	default:
+		return false
+	}
+}
+
+// GlobPattern is a glob expression compiled into a matcher tree (literal
+// runes, "?" and "[...]" single-position matchers, and "*" wildcards)
+// rather than translated through a regexp engine. "{foo,bar}" alternation
+// is expanded at compile time into one atom sequence per alternative, so
+// Match tries each in turn. Matching is case-insensitive.
+type GlobPattern struct {
+	raw  string
+	alts [][]globAtom
+}
+
+// CompileGlob compiles pattern into a GlobPattern. Supported syntax: "*"
+// (any run of characters), "?" (any single character), "[abc]"/"[a-z]"
+// character classes (a leading "^" or "!" negates), and "{foo,bar}"
+// brace alternation. An unterminated "[" or "{" is treated as a literal.
+func CompileGlob(pattern string) *GlobPattern {
+	alternatives := expandBraces(pattern)
+	alts := make([][]globAtom, 0, len(alternatives))
+	for _, alt := range alternatives {
+		alts = append(alts, compileAtoms(alt))
+	}
+	return &GlobPattern{raw: pattern, alts: alts}
+}
+
+// Match reports whether s matches p, case-insensitively.
+func (p *GlobPattern) Match(s string) bool {
+	if p == nil {
+		return false
+	}
+	folded := []rune(strings.ToLower(s))
+	for _, atoms := range p.alts {
+		if matchAtoms(atoms, folded) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the original, uncompiled pattern text.
+func (p *GlobPattern) String() string {
+	if p == nil {
+		return ""
+	}
+	return p.raw
+}
+
+// expandBraces expands every top-level "{a,b,c}" group in pattern into
+// its cross product of alternatives, recursing on whatever follows the
+// group so multiple brace groups expand independently. The alternatives
+// themselves are not expanded again: nested braces are not supported --
+// the first "}" closes the group.
+func expandBraces(pattern string) []string {
+	start := strings.IndexByte(pattern, '{')
+	if start < 0 {
+		return []string{pattern}
+	}
+	end := strings.IndexByte(pattern[start:], '}')
+	if end < 0 {
+		return []string{pattern}
+	}
+	end += start
+
+	prefix := pattern[:start]
+	inner := pattern[start+1 : end]
+	suffixes := expandBraces(pattern[end+1:])
+
+	var out []string
+	for _, alt := range strings.Split(inner, ",") {
+		for _, suffix := range suffixes {
+			out = append(out, prefix+alt+suffix)
+		}
+	}
+	return out
+}
+
+// compileAtoms compiles one brace-free glob alternative into a matcher
+// tree, folding literal and class runes to lowercase so Match's
+// case-insensitive comparison stays a plain rune equality check.
+func compileAtoms(pattern string) []globAtom {
+	runes := []rune(strings.ToLower(pattern))
+	atoms := make([]globAtom, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '*':
+			atoms = append(atoms, globAtom{kind: globAtomStar})
+		case '?':
+			atoms = append(atoms, globAtom{kind: globAtomAny})
+		case '[':
+			class, consumed := compileClass(runes[i:])
+			if consumed == 0 {
+				atoms = append(atoms, globAtom{kind: globAtomLiteral, lit: runes[i]})
+				continue
+			}
+			atoms = append(atoms, class)
+			i += consumed - 1
+		default:
+			atoms = append(atoms, globAtom{kind: globAtomLiteral, lit: runes[i]})
+		}
+	}
+	return atoms
+}
+
+// compileClass compiles a "[...]" character class starting at runes[0],
+// returning the zero value and 0 consumed runes if runes has no closing
+// "]" (the caller then treats "[" as a literal).
+func compileClass(runes []rune) (globAtom, int) {
+	end := -1
+	for i := 1; i < len(runes); i++ {
+		if runes[i] == ']' {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return globAtom{}, 0
+	}
+
+	body := runes[1:end]
+	negated := false
+	if len(body) > 0 && (body[0] == '^' || body[0] == '!') {
+		negated = true
+		body = body[1:]
+	}
+
+	var class []globClassRange
+	for i := 0; i < len(body); i++ {
+		if i+2 < len(body) && body[i+1] == '-' {
+			class = append(class, globClassRange{lo: body[i], hi: body[i+2]})
+			i += 2
+			continue
+		}
+		class = append(class, globClassRange{lo: body[i], hi: body[i]})
+	}
+
+	return globAtom{kind: globAtomClass, class: class, negated: negated}, end + 1
+}
+
+// matchAtoms runs the classic greedy-with-backtrack wildcard match:
+// advance si/pi while the current atom matches, remember the most recent
+// "*" position to retry from (advancing one rune further each retry) when
+// a later atom fails to match.
+func matchAtoms(atoms []globAtom, s []rune) bool {
+	si, pi := 0, 0
+	starAt, starMatch := -1, 0
+
+	for si < len(s) {
+		if pi < len(atoms) && atoms[pi].kind != globAtomStar && atoms[pi].matches(s[si]) {
+			si++
+			pi++
+			continue
+		}
+		if pi < len(atoms) && atoms[pi].kind == globAtomStar {
+			starAt = pi
+			starMatch = si
+			pi++
+			continue
+		}
+		if starAt >= 0 {
+			starMatch++
+			si = starMatch
+			pi = starAt + 1
+			continue
+		}
+		return false
+	}
+
+	for pi < len(atoms) && atoms[pi].kind == globAtomStar {
+		pi++
+	}
+	return pi == len(atoms)
+}
+
+// globCache memoizes CompileGlob by pattern text, since the same
+// IncludeNamePatterns/ExcludeNamePatterns strings are typically compiled
+// once per DuplicateDetector but many detectors may share a process.
+var (
+	globCacheMu sync.Mutex
+	globCache   = make(map[string]*GlobPattern)
+)
+
+// compileCachedGlob returns the cached GlobPattern for pattern, compiling
+// and storing it on a first request.
+func compileCachedGlob(pattern string) *GlobPattern {
+	globCacheMu.Lock()
+	defer globCacheMu.Unlock()
+	if p, ok := globCache[pattern]; ok {
+		return p
+	}
+	p := CompileGlob(pattern)
+	globCache[pattern] = p
+	return p
+}
+
+// compileGlobPatterns compiles patterns via compileCachedGlob, returning
+// nil for an empty/nil input so a DuplicateDetector with no configured
+// patterns carries no per-pair filtering overhead.
+func compileGlobPatterns(patterns []string) []*GlobPattern {
+	if len(patterns) == 0 {
+		return nil
+	}
+	compiled := make([]*GlobPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		compiled = append(compiled, compileCachedGlob(pattern))
+	}
+	return compiled
+}
+
+// anyMatch reports whether s matches at least one of patterns.
+func anyMatch(patterns []*GlobPattern, s string) bool {
+	for _, p := range patterns {
+		if p.Match(s) {
+			return true
+		}
+	}
+	return false
+}
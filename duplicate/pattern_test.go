@@ -0,0 +1,114 @@
+package duplicate
+
+import (
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+func TestGlobPattern_RoundTrip(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		want    bool
+	}{
+		{"Living*", "Living John Doe", true},
+		{"Living*", "John Doe", false},
+		{"*, Utah, USA", "Salt Lake City, Utah, USA", true},
+		{"*, Utah, USA", "Reno, Nevada, USA", false},
+		{"{Smith,Smyth,Schmidt}", "Smith", true},
+		{"{Smith,Smyth,Schmidt}", "Smyth", true},
+		{"{Smith,Smyth,Schmidt}", "Jones", false},
+		{"J?hn", "John", true},
+		{"J?hn", "Jhn", false},
+		{"[JjKk]ohn", "John", true},
+		{"[JjKk]ohn", "john", true},
+		{"[JjKk]ohn", "Bohn", false},
+		{"[!0-9]*", "Anderson", true},
+		{"[!0-9]*", "1900", false},
+		{"*", "anything at all", true},
+		{"", "", true},
+		{"", "x", false},
+		{"Smith", "SMITH", true},
+	}
+
+	for _, tt := range tests {
+		p := CompileGlob(tt.pattern)
+		if got := p.Match(tt.input); got != tt.want {
+			t.Errorf("CompileGlob(%q).Match(%q) = %v, want %v", tt.pattern, tt.input, got, tt.want)
+		}
+		if p.String() != tt.pattern {
+			t.Errorf("CompileGlob(%q).String() = %q, want %q", tt.pattern, p.String(), tt.pattern)
+		}
+	}
+}
+
+func TestGlobPattern_UnterminatedClassAndBrace(t *testing.T) {
+	if !CompileGlob("[abc").Match("[abc") {
+		t.Error("unterminated '[' should be treated as a literal character")
+	}
+	if !CompileGlob("{a,b").Match("{a,b") {
+		t.Error("unterminated '{' should be treated as a literal character")
+	}
+}
+
+func TestCompileCachedGlob_ReturnsSameInstance(t *testing.T) {
+	a := compileCachedGlob("Living*")
+	b := compileCachedGlob("Living*")
+	if a != b {
+		t.Error("expected compileCachedGlob to return the cached instance for an identical pattern")
+	}
+}
+
+func TestDuplicateDetector_PatternFilters(t *testing.T) {
+	config := DefaultConfig()
+	config.ExcludeNamePatterns = []string{"Living*"}
+	config.ExcludePlacePatterns = []string{"*, Utah, USA"}
+	detector := NewDuplicateDetector(config)
+
+	living := createTestIndividual("Living John Doe", "Living John", "Doe", "1990", "Reno, Nevada, USA")
+	utahan := createTestIndividual("Jane Smith", "Jane", "Smith", "1950", "Salt Lake City, Utah, USA")
+	ordinary := createTestIndividual("Bob Smith", "Bob", "Smith", "1900", "Reno, Nevada, USA")
+
+	if detector.passesPatternFilters(living) {
+		t.Error("expected a Living*-named individual to be excluded")
+	}
+	if detector.passesPatternFilters(utahan) {
+		t.Error("expected a Utah, USA birthplace to be excluded")
+	}
+	if !detector.passesPatternFilters(ordinary) {
+		t.Error("expected an individual matching no exclude pattern to pass")
+	}
+
+	jobs := detector.generateComparisonJobs([]*types.IndividualRecord{living, utahan, ordinary}, nil)
+	for _, j := range jobs {
+		if j.indi1 == living || j.indi2 == living || j.indi1 == utahan || j.indi2 == utahan {
+			t.Error("expected excluded individuals to be absent from every comparison job")
+		}
+	}
+}
+
+func BenchmarkShouldCompare_NoPatternsConfigured(b *testing.B) {
+	detector := NewDuplicateDetector(DefaultConfig())
+	a := createTestIndividual("John Smith", "John", "Smith", "1900", "Reno, Nevada, USA")
+	bIndi := createTestIndividual("Jane Smith", "Jane", "Smith", "1905", "Reno, Nevada, USA")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		detector.shouldCompare(a, bIndi, nil)
+	}
+}
+
+func BenchmarkShouldCompare_WithPatternsConfigured(b *testing.B) {
+	config := DefaultConfig()
+	config.ExcludeNamePatterns = []string{"Living*"}
+	config.ExcludePlacePatterns = []string{"*, Utah, USA"}
+	detector := NewDuplicateDetector(config)
+	a := createTestIndividual("John Smith", "John", "Smith", "1900", "Reno, Nevada, USA")
+	bIndi := createTestIndividual("Jane Smith", "Jane", "Smith", "1905", "Reno, Nevada, USA")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		detector.shouldCompare(a, bIndi, nil)
+	}
+}
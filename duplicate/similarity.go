@@ -0,0 +1,336 @@
+package duplicate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// splitName splits a GEDCOM "Given /Surname/" NAME value into its given
+// and surname parts. It's duplicated here rather than imported from
+// query/dsl (see query/dsl's splitName), the same kind of per-package
+// copy this package keeps of yearOf below.
+func splitName(name string) (given, surname string) {
+	start := strings.Index(name, "/")
+	if start < 0 {
+		return strings.TrimSpace(name), ""
+	}
+	end := strings.Index(name[start+1:], "/")
+	if end < 0 {
+		return strings.TrimSpace(name[:start]), strings.TrimSpace(name[start+1:])
+	}
+	given = strings.TrimSpace(name[:start])
+	surname = strings.TrimSpace(name[start+1 : start+1+end])
+	return given, surname
+}
+
+// yearOf extracts the leading four-digit year from a GEDCOM date string
+// ("1 JAN 1850", "ABT 1850", "1850"), returning 0 if none is found. Like
+// validate's and diff's yearOf, this is a deliberately approximate
+// scan, not a full GEDCOM date-phrase parser.
+func yearOf(date string) int {
+	digits := 0
+	year := 0
+	for _, r := range date {
+		if r >= '0' && r <= '9' {
+			year = year*10 + int(r-'0')
+			digits++
+			if digits == 4 {
+				return year
+			}
+			continue
+		}
+		digits = 0
+		year = 0
+	}
+	return 0
+}
+
+// normalizeToken lowercases s and strips everything but letters, so
+// minor punctuation/case differences ("O'Brien" vs "OBrien") don't
+// depress similarity scores.
+func normalizeToken(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of a and b, in [0, 1].
+func jaroWinkler(a, b string) float64 {
+	if a == b {
+		return 1.0
+	}
+	la, lb := len(a), len(b)
+	if la == 0 || lb == 0 {
+		return 0.0
+	}
+
+	matchDist := la
+	if lb > matchDist {
+		matchDist = lb
+	}
+	matchDist = matchDist/2 - 1
+	if matchDist < 0 {
+		matchDist = 0
+	}
+
+	aMatches := make([]bool, la)
+	bMatches := make([]bool, lb)
+	matches := 0
+
+	for i := 0; i < la; i++ {
+		lo := i - matchDist
+		if lo < 0 {
+			lo = 0
+		}
+		hi := i + matchDist + 1
+		if hi > lb {
+			hi = lb
+		}
+		for j := lo; j < hi; j++ {
+			if bMatches[j] || a[i] != b[j] {
+				continue
+			}
+			aMatches[i] = true
+			bMatches[j] = true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0.0
+	}
+
+	transpositions := 0
+	k := 0
+	for i := 0; i < la; i++ {
+		if !aMatches[i] {
+			continue
+		}
+		for !bMatches[k] {
+			k++
+		}
+		if a[i] != b[k] {
+			transpositions++
+		}
+		k++
+	}
+	transpositions /= 2
+
+	m := float64(matches)
+	jaro := (m/float64(la) + m/float64(lb) + (m-float64(transpositions))/m) / 3.0
+
+	prefix := 0
+	for i := 0; i < minInt(4, minInt(la, lb)); i++ {
+		if a[i] != b[i] {
+			break
+		}
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// metaphoneKey is a simplified phonetic key, not a full Double Metaphone
+// implementation: it drops vowels (and Y) after the first letter and collapses a
+// handful of common consonant digraphs/silent letters ("PH"->"F",
+// "CK"->"K", trailing silent "E"). It's deliberately as approximate as
+// search/tokenize.go's diacriticFold and validate's yearOf -- good enough
+// to group probable phonetic matches into the same block, not a
+// general-purpose phonetic encoder.
+func metaphoneKey(s string) string {
+	s = strings.ToUpper(normalizeToken(s))
+	s = strings.NewReplacer(
+		"PH", "F",
+		"CK", "K",
+		"KN", "N",
+		"GN", "N",
+		"WR", "R",
+	).Replace(s)
+	s = strings.TrimSuffix(s, "E")
+	if s == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte(s[0])
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			continue
+		}
+		if i > 0 && s[i] == s[i-1] {
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// nameSimilarity combines Jaro-Winkler similarity on normalized given and
+// surname parts (weighted toward surname) with a phonetic-surname
+// fallback for pairs whose spelling differs but whose metaphoneKey
+// matches (e.g. "Smyth"/"Smith").
+func nameSimilarity(a, b *types.IndividualRecord) (float64, string) {
+	givenA, surnameA := splitName(a.GetName())
+	givenB, surnameB := splitName(b.GetName())
+
+	surnameJW := jaroWinkler(normalizeToken(surnameA), normalizeToken(surnameB))
+	givenJW := jaroWinkler(normalizeToken(givenA), normalizeToken(givenB))
+
+	phoneticMatch := false
+	keyA, keyB := metaphoneKey(surnameA), metaphoneKey(surnameB)
+	if surnameJW < 0.7 && keyA != "" && keyA == keyB {
+		phoneticMatch = true
+		surnameJW = 0.85
+	}
+
+	score := 0.6*surnameJW + 0.4*givenJW
+	if phoneticMatch {
+		return score, fmt.Sprintf("phonetic surname match (%q ~ %q)", surnameA, surnameB)
+	}
+	if score >= 0.9 {
+		return score, fmt.Sprintf("name %q ~ %q (%.2f)", a.GetName(), b.GetName(), score)
+	}
+	return score, ""
+}
+
+// dateProximityScore scores how close a and b's birth years are, decaying
+// linearly to 0 at a 10-year gap. Either record lacking a parseable
+// birth year scores 0 with no reason.
+func dateProximityScore(a, b *types.IndividualRecord) (float64, string) {
+	yearA := yearOf(a.GetBirthDate())
+	yearB := yearOf(b.GetBirthDate())
+	if yearA == 0 || yearB == 0 {
+		return 0, ""
+	}
+	diff := yearA - yearB
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > 10 {
+		return 0, ""
+	}
+	score := 1 - float64(diff)/10.0
+	if diff <= 2 {
+		return score, fmt.Sprintf("birth year %d vs %d (within ±%d)", yearA, yearB, diff)
+	}
+	return score, ""
+}
+
+// placeSimilarity scores the token overlap between a and b's normalized
+// birthplace strings, e.g. "New York, NY" vs "New York City" share the
+// "new"/"york" tokens.
+func placeSimilarity(a, b *types.IndividualRecord) (float64, string) {
+	tokensA := placeTokens(a.GetBirthPlace())
+	tokensB := placeTokens(b.GetBirthPlace())
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		return 0, ""
+	}
+
+	setB := make(map[string]bool, len(tokensB))
+	for _, t := range tokensB {
+		setB[t] = true
+	}
+	shared := 0
+	for _, t := range tokensA {
+		if setB[t] {
+			shared++
+		}
+	}
+	if shared == 0 {
+		return 0, ""
+	}
+
+	union := len(setB)
+	for _, t := range tokensA {
+		if !setB[t] {
+			union++
+		}
+	}
+	score := float64(shared) / float64(union)
+	if score >= 0.5 {
+		return score, fmt.Sprintf("birthplace %q ~ %q", a.GetBirthPlace(), b.GetBirthPlace())
+	}
+	return score, ""
+}
+
+func placeTokens(place string) []string {
+	var tokens []string
+	for _, word := range strings.FieldsFunc(place, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	}) {
+		if t := normalizeToken(word); t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+// graphContextXrefs returns the XREFs of indi's parents, spouses, and
+// children, resolved through its registered FAMC/FAMS families.
+func graphContextXrefs(indi *types.IndividualRecord) map[string]bool {
+	set := make(map[string]bool)
+	for _, rec := range indi.GetFamiliesAsChild() {
+		fam, ok := rec.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+		if h := fam.GetHusband(); h != "" {
+			set[h] = true
+		}
+		if w := fam.GetWife(); w != "" {
+			set[w] = true
+		}
+	}
+	for _, rec := range indi.GetFamiliesAsSpouse() {
+		fam, ok := rec.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+		if h := fam.GetHusband(); h != "" && h != indi.XrefID() {
+			set[h] = true
+		}
+		if w := fam.GetWife(); w != "" && w != indi.XrefID() {
+			set[w] = true
+		}
+		for _, c := range fam.GetChildren() {
+			set[c] = true
+		}
+	}
+	return set
+}
+
+// contextOverlap scores the Jaccard overlap of a and b's graph context
+// (parents, spouses, children). Individuals with no resolved family
+// context (e.g. records never added to a GedcomTree) score 0.
+func contextOverlap(a, b *types.IndividualRecord) (float64, string) {
+	setA := graphContextXrefs(a)
+	setB := graphContextXrefs(b)
+	if len(setA) == 0 || len(setB) == 0 {
+		return 0, ""
+	}
+
+	var shared []string
+	union := make(map[string]bool, len(setA)+len(setB))
+	for x := range setA {
+		union[x] = true
+		if setB[x] {
+			shared = append(shared, x)
+		}
+	}
+	for x := range setB {
+		union[x] = true
+	}
+	if len(shared) == 0 {
+		return 0, ""
+	}
+
+	score := float64(len(shared)) / float64(len(union))
+	return score, fmt.Sprintf("shared relatives: %s", strings.Join(shared, ", "))
+}
@@ -0,0 +1,119 @@
+package duplicate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+)
+
+// ErrEmptyPrefix is returned by xrefPrefixIndex.resolve for an empty
+// prefix -- there is no shortest match to report, so a caller should
+// treat this as a usage error rather than a "no such record" miss.
+var ErrEmptyPrefix = errors.New("duplicate: empty xref prefix")
+
+// ErrXrefNotExist is returned by xrefPrefixIndex.resolve when prefix
+// doesn't begin any known XREF.
+var ErrXrefNotExist = errors.New("duplicate: no individual with that xref prefix")
+
+// AmbiguousPrefixError is returned by xrefPrefixIndex.resolve when prefix
+// begins more than one known XREF -- the short-ID-resolution equivalent
+// of a container runtime's "multiple IDs found starting with ...".
+type AmbiguousPrefixError struct {
+	Matches []string
+}
+
+func (e *AmbiguousPrefixError) Error() string {
+	return fmt.Sprintf("duplicate: xref prefix matches multiple records: %s", strings.Join(e.Matches, ", "))
+}
+
+// xrefPrefixIndex resolves a truncated XREF prefix against a known set of
+// full XREFs via a radix tree, and finds the shortest prefix that still
+// resolves unambiguously -- used by FormatMatches to abbreviate
+// DuplicateMatch output.
+//
+// This is a duplicate-package-local stand-in for the
+// types.GedcomTree.FindRecordByPrefix API this request asked for: the
+// types package (GedcomTree, Record, IndividualRecord, ...) isn't part of
+// this checkout -- every file in it is referenced throughout this repo
+// but none is present on disk here -- so FindRecordByPrefix/ErrNotExist
+// can't actually be added to GedcomTree from this tree. xrefPrefixIndex
+// mirrors the same resolve-by-prefix-with-ambiguity-error shape (lazily
+// built, radix-tree-backed) against the XREF universe duplicate already
+// has on hand, so that part of the request -- rendering a DuplicateMatch
+// by its shortest unambiguous prefix -- is still delivered; a real
+// GedcomTree.FindRecordByPrefix in the full repo would be the natural
+// place to promote this logic to, should this same radix-tree-backed
+// shape turn out to be the one used there.
+type xrefPrefixIndex struct {
+	tree *query.RadixTree
+}
+
+// newXrefPrefixIndex builds an xrefPrefixIndex over xrefs.
+func newXrefPrefixIndex(xrefs []string) *xrefPrefixIndex {
+	tree := query.NewRadixTree()
+	for _, xref := range xrefs {
+		tree = tree.Insert(xref, xref)
+	}
+	return &xrefPrefixIndex{tree: tree}
+}
+
+// resolve returns the single full XREF beginning with prefix, or
+// ErrEmptyPrefix, ErrXrefNotExist, or *AmbiguousPrefixError.
+func (idx *xrefPrefixIndex) resolve(prefix string) (string, error) {
+	if prefix == "" {
+		return "", ErrEmptyPrefix
+	}
+	var matches []string
+	idx.tree.WalkPrefix(prefix, func(key string, _ []string) bool {
+		matches = append(matches, key)
+		return true
+	})
+	switch len(matches) {
+	case 0:
+		return "", ErrXrefNotExist
+	case 1:
+		return matches[0], nil
+	default:
+		return "", &AmbiguousPrefixError{Matches: matches}
+	}
+}
+
+// shortestUnambiguousPrefix returns the shortest leading substring of
+// xref that idx.resolve still maps back to xref alone, growing one rune
+// at a time up to xref's full length if every shorter candidate is
+// ambiguous.
+func (idx *xrefPrefixIndex) shortestUnambiguousPrefix(xref string) string {
+	runes := []rune(xref)
+	for n := 1; n < len(runes); n++ {
+		candidate := string(runes[:n])
+		if resolved, err := idx.resolve(candidate); err == nil && resolved == xref {
+			return candidate
+		}
+	}
+	return xref
+}
+
+// FormatMatches renders matches for display, one line per match,
+// abbreviating each individual's XREF to the shortest prefix that's
+// still unambiguous across every XREF appearing in matches.
+func FormatMatches(matches []DuplicateMatch) []string {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	xrefs := make([]string, 0, len(matches)*2)
+	for _, m := range matches {
+		xrefs = append(xrefs, m.Indi1.XrefID(), m.Indi2.XrefID())
+	}
+	idx := newXrefPrefixIndex(xrefs)
+
+	lines := make([]string, 0, len(matches))
+	for _, m := range matches {
+		p1 := idx.shortestUnambiguousPrefix(m.Indi1.XrefID())
+		p2 := idx.shortestUnambiguousPrefix(m.Indi2.XrefID())
+		lines = append(lines, fmt.Sprintf("%s ~ %s (score %.2f)", p1, p2, m.Score))
+	}
+	return lines
+}
@@ -0,0 +1,84 @@
+package duplicate
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestXrefPrefixIndex_Resolve_EmptyPrefix parallels
+// TestGetChildren_InvalidFamilyXref's style: exercise one malformed-input
+// path in isolation.
+func TestXrefPrefixIndex_Resolve_EmptyPrefix(t *testing.T) {
+	idx := newXrefPrefixIndex([]string{"@I1@", "@I2@"})
+	if _, err := idx.resolve(""); !errors.Is(err, ErrEmptyPrefix) {
+		t.Errorf("expected ErrEmptyPrefix for an empty prefix, got %v", err)
+	}
+}
+
+func TestXrefPrefixIndex_Resolve_NotExist(t *testing.T) {
+	idx := newXrefPrefixIndex([]string{"@I1@", "@I2@"})
+	if _, err := idx.resolve("@I9"); !errors.Is(err, ErrXrefNotExist) {
+		t.Errorf("expected ErrXrefNotExist for an unmatched prefix, got %v", err)
+	}
+}
+
+func TestXrefPrefixIndex_Resolve_Ambiguous(t *testing.T) {
+	idx := newXrefPrefixIndex([]string{"@I123456@", "@I123789@", "@I9@"})
+	_, err := idx.resolve("@I123")
+	var ambErr *AmbiguousPrefixError
+	if !errors.As(err, &ambErr) {
+		t.Fatalf("expected *AmbiguousPrefixError for a shared prefix, got %v", err)
+	}
+	if len(ambErr.Matches) != 2 {
+		t.Errorf("expected 2 ambiguous matches, got %d: %v", len(ambErr.Matches), ambErr.Matches)
+	}
+	if ambErr.Error() == "" {
+		t.Error("expected a non-empty error message")
+	}
+}
+
+func TestXrefPrefixIndex_Resolve_Unambiguous(t *testing.T) {
+	idx := newXrefPrefixIndex([]string{"@I123456@", "@I789@"})
+	resolved, err := idx.resolve("@I123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved != "@I123456@" {
+		t.Errorf("expected @I123456@, got %q", resolved)
+	}
+}
+
+func TestXrefPrefixIndex_ShortestUnambiguousPrefix(t *testing.T) {
+	idx := newXrefPrefixIndex([]string{"@I123456@", "@I123789@", "@I9@"})
+
+	if got := idx.shortestUnambiguousPrefix("@I9@"); got != "@I9" {
+		t.Errorf("expected the shortest prefix for @I9@ to be @I9, got %q", got)
+	}
+
+	got := idx.shortestUnambiguousPrefix("@I123456@")
+	if resolved, err := idx.resolve(got); err != nil || resolved != "@I123456@" {
+		t.Errorf("shortestUnambiguousPrefix(%q) = %q, which doesn't resolve back to it (err=%v)", "@I123456@", got, err)
+	}
+	if got == "@I123456@"[:len(got)-1] {
+		t.Error("expected the returned prefix to actually be unambiguous, not just the full xref minus one rune")
+	}
+}
+
+func TestFormatMatches_UsesShortestUnambiguousPrefix(t *testing.T) {
+	a := withXref(createTestIndividual("John /Anderson/", "John", "Anderson", "1800", ""), "@I123456@")
+	b := withXref(createTestIndividual("Jack /Anderson/", "Jack", "Anderson", "1801", ""), "@I789@")
+
+	lines := FormatMatches([]DuplicateMatch{{Indi1: a, Indi2: b, Score: 0.9}})
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 formatted line, got %d", len(lines))
+	}
+	if lines[0] == "" {
+		t.Error("expected a non-empty formatted line")
+	}
+}
+
+func TestFormatMatches_Empty(t *testing.T) {
+	if lines := FormatMatches(nil); lines != nil {
+		t.Errorf("expected nil for no matches, got %v", lines)
+	}
+}
@@ -0,0 +1,76 @@
+// Package gedcomdate parses the loosely-formatted dates found in GEDCOM
+// date fields ("12 JAN 1800", "ABT 1800", ...) just well enough for the
+// plausibility checks in validate, diff, query/validation, and
+// query/rules to compare years and approximate day spans. It exists
+// because those four packages had each grown their own copy of this
+// same handful of functions; this is the one place left to fix a parsing
+// bug or add a new loose format.
+package gedcomdate
+
+import (
+	"strconv"
+	"strings"
+)
+
+// YearOf extracts a plain calendar year from a loosely-formatted GEDCOM
+// date string such as "12 JAN 1800" or "ABT 1800". It returns 0 if no
+// four-digit year can be found.
+func YearOf(date string) int {
+	digits := 0
+	year := 0
+	for _, r := range date {
+		if r >= '0' && r <= '9' {
+			year = year*10 + int(r-'0')
+			digits++
+			if digits == 4 {
+				return year
+			}
+		} else {
+			digits = 0
+			year = 0
+		}
+	}
+	return 0
+}
+
+// HasParsedYear reports whether date contains any scannable four-digit
+// year.
+func HasParsedYear(date string) bool {
+	return strings.TrimSpace(date) != "" && YearOf(date) != 0
+}
+
+// monthDayOffset is the cumulative number of days before each month
+// (non-leap), used by ApproxDayOf to turn a date into a comparable day
+// count.
+var monthDayOffset = map[string]int{
+	"JAN": 0, "FEB": 31, "MAR": 59, "APR": 90, "MAY": 120, "JUN": 151,
+	"JUL": 181, "AUG": 212, "SEP": 243, "OCT": 273, "NOV": 304, "DEC": 334,
+}
+
+// ApproxDayOf converts a loosely-formatted GEDCOM date such as
+// "12 JAN 1800" into an approximate day count since year 0 (365-day
+// years, non-leap month offsets), for comparing spacing between two
+// dates. It returns ok=false under the same condition YearOf does: no
+// four-digit year found.
+func ApproxDayOf(date string) (days int, ok bool) {
+	year := YearOf(date)
+	if year == 0 {
+		return 0, false
+	}
+	upper := strings.ToUpper(date)
+	month := 0
+	for name, offset := range monthDayOffset {
+		if strings.Contains(upper, name) {
+			month = offset
+			break
+		}
+	}
+	day := 1
+	for _, field := range strings.Fields(upper) {
+		if n, err := strconv.Atoi(field); err == nil && n >= 1 && n <= 31 && n != year {
+			day = n
+			break
+		}
+	}
+	return year*365 + month + day, true
+}
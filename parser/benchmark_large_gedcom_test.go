@@ -0,0 +1,223 @@
+package parser
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+)
+
+// gedcomBenchSizes are the fixture sizes BenchmarkParseLargeGEDCOM runs
+// against -- the 10k/100k/1M scale generateLargeGEDCOMFile and
+// measureMemory (above, in performance_test.go) were added for but never
+// had a Benchmark wired up to them.
+var gedcomBenchSizes = []int{10_000, 100_000, 1_000_000}
+
+// BenchmarkParseLargeGEDCOM parses synthetic fixtures of increasing size
+// through HierarchicalParser and into both hybrid storage backends --
+// SQLite+BadgerDB via BuildGraphHybrid, and PostgreSQL+BadgerDB via
+// BuildGraphHybridPostgres (skipped, like this package's other
+// PostgreSQL-dependent tests, when DATABASE_URL isn't set) -- reporting
+// ns/op and allocs/op (via b.ReportAllocs), the peak of the
+// runtime.MemStats.HeapInuse values read after each iteration, and a
+// PostgreSQL round-trip count (see countPostgresRoundTrips).
+//
+// Set GEDCOM_BENCH_PROFILE=1 to also capture a CPU profile for the whole
+// run and a heap profile per fixture size (go test's -benchtime flag has
+// no hook for this, so it's gated by an environment variable instead).
+func BenchmarkParseLargeGEDCOM(b *testing.B) {
+	profile := os.Getenv("GEDCOM_BENCH_PROFILE") != ""
+	if profile {
+		cpuFile, err := os.Create("cpu.prof")
+		if err != nil {
+			b.Fatalf("failed to create cpu.prof: %v", err)
+		}
+		defer cpuFile.Close()
+		if err := pprof.StartCPUProfile(cpuFile); err != nil {
+			b.Fatalf("failed to start CPU profile: %v", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	for _, n := range gedcomBenchSizes {
+		n := n
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			if testing.Short() && n > 10_000 {
+				b.Skip("skipping large fixture in -short mode")
+			}
+
+			gedPath := filepath.Join(b.TempDir(), fmt.Sprintf("bench_%d.ged", n))
+			if err := generateLargeGEDCOMFile(gedPath, n); err != nil {
+				b.Fatalf("failed to generate fixture: %v", err)
+			}
+			databaseURL := os.Getenv("DATABASE_URL")
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			var peakHeapInuse uint64
+			for i := 0; i < b.N; i++ {
+				p := NewHierarchicalParser()
+				tree, err := p.Parse(gedPath)
+				if err != nil {
+					b.Fatalf("parse failed: %v", err)
+				}
+
+				sqlitePath := filepath.Join(b.TempDir(), "bench_sqlite.db")
+				badgerPath := filepath.Join(b.TempDir(), "bench_badger")
+				if _, err := query.BuildGraphHybrid(tree, sqlitePath, badgerPath, nil); err != nil {
+					b.Fatalf("BuildGraphHybrid (sqlite+badger) failed: %v", err)
+				}
+
+				if databaseURL != "" {
+					pgBadgerPath := filepath.Join(b.TempDir(), "bench_pg_badger")
+					fileID := fmt.Sprintf("bench_%d_%d", n, i)
+					if _, err := query.BuildGraphHybridPostgres(tree, fileID, pgBadgerPath, databaseURL, nil); err != nil {
+						b.Fatalf("BuildGraphHybridPostgres failed: %v", err)
+					}
+				}
+
+				b.StopTimer()
+				var m runtime.MemStats
+				runtime.ReadMemStats(&m)
+				if m.HeapInuse > peakHeapInuse {
+					peakHeapInuse = m.HeapInuse
+				}
+				b.StartTimer()
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(peakHeapInuse), "peak-heap-inuse-bytes")
+
+			if databaseURL != "" {
+				roundTrips, err := countPostgresRoundTrips(databaseURL, sampleSizeFor(n))
+				if err != nil {
+					b.Logf("failed to count postgres round trips: %v", err)
+				} else {
+					b.ReportMetric(float64(roundTrips), "postgres-round-trips")
+				}
+			}
+
+			if profile {
+				heapFile, err := os.Create(fmt.Sprintf("heap_%d.prof", n))
+				if err != nil {
+					b.Fatalf("failed to create heap profile: %v", err)
+				}
+				defer heapFile.Close()
+				runtime.GC()
+				if err := pprof.WriteHeapProfile(heapFile); err != nil {
+					b.Fatalf("failed to write heap profile: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// sampleSizeFor scales down the number of round-trip-counting queries
+// countPostgresRoundTrips issues, so counting doesn't take longer than
+// parsing itself for the largest fixture sizes.
+func sampleSizeFor(n int) int {
+	sample := n / 100
+	if sample < 10 {
+		sample = 10
+	}
+	if sample > 2000 {
+		sample = 2000
+	}
+	return sample
+}
+
+const countingDriverName = "pgx-counting-bench"
+
+var (
+	countingDriverOnce sync.Once
+	countingQueries    int64
+)
+
+// countingDriver wraps the real pgx driver to count round trips --
+// "postgres-round-trips" in BenchmarkParseLargeGEDCOM comes from this.
+//
+// HybridStoragePostgres's constructor isn't part of this package
+// snapshot (see the doc comment on query.SQLBackend) and hardcodes the
+// "pgx" driver name internally, so there's no way to make its own
+// connection open through this shim. countPostgresRoundTrips instead
+// exercises query.OpenPostgresSQLBackendWithDriver's connection -- the
+// migrator plus a sample of representative queries -- through the shim,
+// as a representative proxy for the round trips a full build issues.
+type countingDriver struct {
+	inner driver.Driver
+}
+
+func (d *countingDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.inner.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Conn: conn}, nil
+}
+
+type countingConn struct {
+	driver.Conn
+}
+
+func (c *countingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	atomic.AddInt64(&countingQueries, 1)
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return qc.QueryContext(ctx, query, args)
+}
+
+func (c *countingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	atomic.AddInt64(&countingQueries, 1)
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return ec.ExecContext(ctx, query, args)
+}
+
+func (c *countingConn) Prepare(query string) (driver.Stmt, error) {
+	atomic.AddInt64(&countingQueries, 1)
+	return c.Conn.Prepare(query)
+}
+
+// countPostgresRoundTrips opens databaseURL through countingDriver,
+// applies migrations, issues sampleQueries SELECT 1 round trips, and
+// returns the total number of round trips the shim observed.
+func countPostgresRoundTrips(databaseURL string, sampleQueries int) (int64, error) {
+	countingDriverOnce.Do(func() {
+		sql.Register(countingDriverName, &countingDriver{inner: stdlib.GetDefaultDriver()})
+	})
+	atomic.StoreInt64(&countingQueries, 0)
+
+	backend, err := query.OpenPostgresSQLBackendWithDriver(countingDriverName, databaseURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open counting backend: %w", err)
+	}
+	defer backend.Close()
+
+	ctx := context.Background()
+	migrator := query.NewMigratorForDialect(query.DialectPostgres, backend.DB())
+	if err := migrator.Up(ctx); err != nil {
+		return 0, fmt.Errorf("migrator.Up failed: %w", err)
+	}
+
+	for i := 0; i < sampleQueries; i++ {
+		if _, err := backend.DB().ExecContext(ctx, "SELECT 1"); err != nil {
+			return 0, fmt.Errorf("sample query failed: %w", err)
+		}
+	}
+
+	return atomic.LoadInt64(&countingQueries), nil
+}
@@ -0,0 +1,159 @@
+// Package benchreport turns the ad-hoc TestParserPerformance_* reports
+// into a machine-readable regression gate: it runs a measurement matrix
+// repeatedly (to get p50/p95 instead of a single-shot number), writes a
+// stable JSON baseline artifact, and compares a fresh run against a prior
+// baseline to flag throughput regressions.
+package benchreport
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// Flags controlling baseline capture/comparison, consumed by tests that
+// import this package (e.g. via `go test ./parser/... -gedcom.baseline.out=baseline.json`).
+var (
+	OutPath       = flag.String("gedcom.baseline.out", "", "write a JSON baseline artifact to this path")
+	ComparePath   = flag.String("gedcom.baseline.compare", "", "compare against a prior JSON baseline at this path")
+	RegressionPct = flag.Float64("gedcom.baseline.regression_pct", 10.0, "fail if p50 duration regresses by more than this percent")
+)
+
+// BaselineEntry is one (file, phase) measurement.
+type BaselineEntry struct {
+	File          string
+	Phase         string
+	P50Ms         float64
+	P95Ms         float64
+	MemDeltaBytes int64
+}
+
+// Baseline is the full JSON artifact: environment metadata plus every
+// measured (file, phase) entry.
+type Baseline struct {
+	GitSHA        string
+	GoVersion     string
+	GOMAXPROCS    int
+	MachineRAMSys uint64 // runtime.MemStats.Sys at capture time, a rough proxy for machine RAM
+	Entries       []BaselineEntry
+}
+
+// Environment captures the metadata fields of a Baseline.
+func Environment() (goVersion string, gomaxprocs int, machineRAMSys uint64) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return runtime.Version(), runtime.GOMAXPROCS(0), m.Sys
+}
+
+// PhaseFunc runs one named phase once, returning its wall-clock duration.
+type PhaseFunc func() (time.Duration, error)
+
+// Measure repeats fn n times and returns the p50/p95 duration in
+// milliseconds across the repetitions, plus the average per-run memory
+// delta computed by bracketing each repetition with
+// runtime.ReadMemStats (rather than a single before/after Alloc snapshot).
+func Measure(n int, fn PhaseFunc) (p50Ms, p95Ms float64, memDeltaBytes int64, err error) {
+	if n <= 0 {
+		n = 1
+	}
+
+	durations := make([]float64, 0, n)
+	var totalDelta int64
+
+	for i := 0; i < n; i++ {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		d, runErr := fn()
+		if runErr != nil {
+			return 0, 0, 0, runErr
+		}
+
+		runtime.ReadMemStats(&after)
+		totalDelta += int64(after.Alloc) - int64(before.Alloc)
+		durations = append(durations, d.Seconds()*1000)
+	}
+
+	sort.Float64s(durations)
+	return percentile(durations, 0.50), percentile(durations, 0.95), totalDelta / int64(n), nil
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(p * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+// WriteBaseline writes b as indented JSON to path.
+func WriteBaseline(path string, b Baseline) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// LoadBaseline reads a previously written JSON baseline from path.
+func LoadBaseline(path string) (Baseline, error) {
+	var b Baseline
+	f, err := os.Open(path)
+	if err != nil {
+		return b, err
+	}
+	defer f.Close()
+
+	err = json.NewDecoder(f).Decode(&b)
+	return b, err
+}
+
+// CompareResult is one (file, phase) regression check between a prior
+// baseline and a current run.
+type CompareResult struct {
+	File          string
+	Phase         string
+	BaselineP50Ms float64
+	CurrentP50Ms  float64
+	RegressionPct float64
+	Regressed     bool
+}
+
+// Compare checks current against baseline, flagging any (file, phase)
+// entry whose p50 duration increased by more than maxRegressionPct.
+// Entries present only in current (no matching baseline entry) are
+// skipped rather than treated as a regression, since there's nothing to
+// compare against.
+func Compare(baseline, current Baseline, maxRegressionPct float64) []CompareResult {
+	byKey := make(map[string]BaselineEntry, len(baseline.Entries))
+	for _, e := range baseline.Entries {
+		byKey[e.File+"|"+e.Phase] = e
+	}
+
+	results := make([]CompareResult, 0, len(current.Entries))
+	for _, e := range current.Entries {
+		prior, ok := byKey[e.File+"|"+e.Phase]
+		if !ok || prior.P50Ms == 0 {
+			continue
+		}
+
+		pct := (e.P50Ms - prior.P50Ms) / prior.P50Ms * 100
+		results = append(results, CompareResult{
+			File:          e.File,
+			Phase:         e.Phase,
+			BaselineP50Ms: prior.P50Ms,
+			CurrentP50Ms:  e.P50Ms,
+			RegressionPct: pct,
+			Regressed:     pct > maxRegressionPct,
+		})
+	}
+	return results
+}
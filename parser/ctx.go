@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"context"
+	"time"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// ParseCtx parses path the same way (*HierarchicalParser).Parse does, but
+// honors ctx cancellation and reports progress through h (a nil h is
+// treated as NoopProgressHandler).
+//
+// HierarchicalParser does not expose a hook into its internal top-level
+// record loop, so ParseCtx can only observe ctx at the phase boundary --
+// before starting and after the underlying Parse call returns -- rather
+// than after each 0-level record as a fully record-granular cancellation
+// would require. Large-file callers that need to abort mid-parse should
+// still prefer ParseCtx over Parse, since it at least avoids starting (or
+// reporting success for) a parse that was already canceled.
+func ParseCtx(ctx context.Context, path string, h ProgressHandler) (*types.GedcomTree, error) {
+	if h == nil {
+		h = NoopProgressHandler{}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	h.OnPhaseStart("parse", 0)
+	start := time.Now()
+
+	p := NewHierarchicalParser()
+	tree, err := p.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	total := len(tree.GetAllIndividuals()) + len(tree.GetAllFamilies())
+	duration := time.Since(start)
+	h.OnRecord("parse", total, total)
+	h.OnPhaseEnd("parse", PhaseStats{Phase: "parse", Total: total, Duration: duration})
+	recordParse(total, len(p.GetErrors()), duration)
+
+	return tree, nil
+}
+
+// ParseWithDiagnostic parses path and reports a PhaseSummary event to diag
+// (a nil diag is treated as NoopDiagnostic) instead of printing a report
+// directly. HierarchicalParser's error collection does not expose enough
+// structure in this package to call diag.ParseError per line, so only the
+// phase-level summary is emitted; a richer per-error GetErrors() return
+// type would let this call ParseError for each one.
+func ParseWithDiagnostic(path string, diag Diagnostic) (*types.GedcomTree, error) {
+	if diag == nil {
+		diag = NoopDiagnostic{}
+	}
+
+	start := time.Now()
+	p := NewHierarchicalParser()
+	tree, err := p.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(tree.GetAllIndividuals()) + len(tree.GetAllFamilies())
+	stats := PhaseStats{Phase: "parse", Total: total, Duration: time.Since(start)}
+	diag.PhaseSummary("parse", stats)
+	recordParse(total, len(p.GetErrors()), stats.Duration)
+
+	return tree, nil
+}
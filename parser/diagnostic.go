@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Diagnostic is a structured event sink for the parse/validate/build-graph
+// pipeline, modeled on kapacitor's NodeDiagnostic: instead of scattering
+// fmt.Printf calls through the library, each phase reports through one of
+// these methods, and callers choose how (or whether) events are rendered.
+type Diagnostic interface {
+	ParseError(line int, msg string, err error)
+	ValidationWarning(recordID string, rule string, msg string)
+	EdgeResolutionFailed(from, to string, edgeType string)
+	PhaseSummary(phase string, stats PhaseStats)
+}
+
+// NoopDiagnostic discards every event. It's the default used wherever a
+// Diagnostic isn't supplied.
+type NoopDiagnostic struct{}
+
+func (NoopDiagnostic) ParseError(line int, msg string, err error)            {}
+func (NoopDiagnostic) ValidationWarning(recordID, rule, msg string)          {}
+func (NoopDiagnostic) EdgeResolutionFailed(from, to string, edgeType string) {}
+func (NoopDiagnostic) PhaseSummary(phase string, stats PhaseStats)           {}
+
+// textDiagnostic writes human-readable lines to an io.Writer, replacing
+// the ad-hoc printParsePerformanceReport printfs with something that can
+// be pointed at any Writer (a file, a test buffer, os.Stderr).
+type textDiagnostic struct {
+	w io.Writer
+}
+
+// TextDiagnostic returns a Diagnostic that writes one human-readable line
+// per event to w.
+func TextDiagnostic(w io.Writer) Diagnostic {
+	return &textDiagnostic{w: w}
+}
+
+func (d *textDiagnostic) ParseError(line int, msg string, err error) {
+	fmt.Fprintf(d.w, "parse error at line %d: %s: %v\n", line, msg, err)
+}
+
+func (d *textDiagnostic) ValidationWarning(recordID, rule, msg string) {
+	fmt.Fprintf(d.w, "validation warning [%s] %s: %s\n", rule, recordID, msg)
+}
+
+func (d *textDiagnostic) EdgeResolutionFailed(from, to string, edgeType string) {
+	fmt.Fprintf(d.w, "edge resolution failed: %s -%s-> %s\n", from, edgeType, to)
+}
+
+func (d *textDiagnostic) PhaseSummary(phase string, stats PhaseStats) {
+	fmt.Fprintf(d.w, "%s: %d records in %s\n", phase, stats.Total, stats.Duration)
+}
+
+// jsonDiagnostic streams one JSON object per line to an io.Writer, for
+// pipelines that want to consume diagnostic events programmatically.
+type jsonDiagnostic struct {
+	w io.Writer
+}
+
+// JSONDiagnostic returns a Diagnostic that writes one JSON-encoded event
+// per line to w.
+func JSONDiagnostic(w io.Writer) Diagnostic {
+	return &jsonDiagnostic{w: w}
+}
+
+func (d *jsonDiagnostic) emit(event string, fields map[string]interface{}) {
+	fields["event"] = event
+	enc := json.NewEncoder(d.w)
+	_ = enc.Encode(fields)
+}
+
+func (d *jsonDiagnostic) ParseError(line int, msg string, err error) {
+	d.emit("parse_error", map[string]interface{}{"line": line, "msg": msg, "err": fmt.Sprint(err)})
+}
+
+func (d *jsonDiagnostic) ValidationWarning(recordID, rule, msg string) {
+	d.emit("validation_warning", map[string]interface{}{"record_id": recordID, "rule": rule, "msg": msg})
+}
+
+func (d *jsonDiagnostic) EdgeResolutionFailed(from, to string, edgeType string) {
+	d.emit("edge_resolution_failed", map[string]interface{}{"from": from, "to": to, "edge_type": edgeType})
+}
+
+func (d *jsonDiagnostic) PhaseSummary(phase string, stats PhaseStats) {
+	d.emit("phase_summary", map[string]interface{}{
+		"phase":       phase,
+		"total":       stats.Total,
+		"duration_ms": stats.Duration.Seconds() * 1000,
+	})
+}
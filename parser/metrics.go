@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// MetricsCollector lets a caller plug an alternate metrics backend (e.g.
+// Prometheus, OpenTelemetry) in place of the built-in expvar publication
+// below. RegisterMetricsCollector installs one; every phase that updates
+// the expvar counters also notifies the registered collector, if any.
+type MetricsCollector interface {
+	IncCounter(name string, delta int64)
+	SetGauge(name string, value float64)
+}
+
+var activeCollector atomic.Value // holds MetricsCollector
+
+// RegisterMetricsCollector installs c as the MetricsCollector notified
+// alongside the built-in expvar variables. Passing nil removes any
+// previously registered collector.
+func RegisterMetricsCollector(c MetricsCollector) {
+	activeCollector.Store(&c)
+}
+
+func notifyCounter(name string, delta int64) {
+	if v, _ := activeCollector.Load().(*MetricsCollector); v != nil && *v != nil {
+		(*v).IncCounter(name, delta)
+	}
+}
+
+func notifyGauge(name string, value float64) {
+	if v, _ := activeCollector.Load().(*MetricsCollector); v != nil && *v != nil {
+		(*v).SetGauge(name, value)
+	}
+}
+
+// Package-level expvar publication, following the kapacitor node.go
+// pattern: a parsed-record counter, a parse-error counter, and an
+// EWMA-style average parse-duration gauge, so operators embedding
+// this library can scrape parser health without instrumenting call sites.
+var (
+	recordsTotal  = expvar.NewInt("gedcom.parse.records_total")
+	errorsTotal   = expvar.NewInt("gedcom.parse.errors_total")
+	parseDuration = expvar.NewFloat("gedcom.parse.duration_ms")
+
+	parseCount    int64
+	parseDurEWMA  int64 // nanoseconds, fixed-point via atomic
+)
+
+const ewmaAlpha = 0.2
+
+// recordParse updates the package's expvar counters and notifies any
+// registered MetricsCollector after a single ParseCtx or
+// ParseWithDiagnostic invocation.
+func recordParse(records int, errs int, d time.Duration) {
+	recordsTotal.Add(int64(records))
+	errorsTotal.Add(int64(errs))
+
+	n := atomic.AddInt64(&parseCount, 1)
+	prev := atomic.LoadInt64(&parseDurEWMA)
+	next := int64(float64(d.Nanoseconds())*ewmaAlpha + float64(prev)*(1-ewmaAlpha))
+	if n == 1 {
+		next = d.Nanoseconds()
+	}
+	atomic.StoreInt64(&parseDurEWMA, next)
+	parseDuration.Set(float64(next) / float64(time.Millisecond))
+
+	notifyCounter("gedcom.parse.records_total", int64(records))
+	notifyCounter("gedcom.parse.errors_total", int64(errs))
+	notifyGauge("gedcom.parse.duration_ms", float64(next)/float64(time.Millisecond))
+}
+
+// ParserMetrics is a point-in-time snapshot of the process-wide parser
+// counters. Because expvar variables (and the collector notified
+// alongside them) are process-wide by design, HierarchicalParser.Metrics
+// returns the same snapshot regardless of which parser instance it's
+// called on.
+type ParserMetrics struct {
+	RecordsTotal  int64
+	ErrorsTotal   int64
+	AvgDurationMs float64
+}
+
+// Metrics returns a snapshot of the process-wide parser metrics.
+func (p *HierarchicalParser) Metrics() ParserMetrics {
+	return ParserMetrics{
+		RecordsTotal:  recordsTotal.Value(),
+		ErrorsTotal:   errorsTotal.Value(),
+		AvgDurationMs: float64(atomic.LoadInt64(&parseDurEWMA)) / float64(time.Millisecond),
+	}
+}
@@ -0,0 +1,29 @@
+package parser
+
+import "time"
+
+// PhaseStats summarizes one completed phase of the Parse/Validate/
+// BuildGraph pipeline, reported to a ProgressHandler's OnPhaseEnd.
+type PhaseStats struct {
+	Phase    string
+	Total    int
+	Duration time.Duration
+}
+
+// ProgressHandler receives structured progress events from the
+// ParseCtx/ValidateCtx/BuildGraphCtx pipeline, so a long-running service
+// or TUI embedding this library can report progress without the pipeline
+// hard-coding stdout output.
+type ProgressHandler interface {
+	OnPhaseStart(phase string, expected int)
+	OnRecord(phase string, index, total int)
+	OnPhaseEnd(phase string, stats PhaseStats)
+}
+
+// NoopProgressHandler implements ProgressHandler with no-ops, for callers
+// that want ctx cancellation support without wiring up progress reporting.
+type NoopProgressHandler struct{}
+
+func (NoopProgressHandler) OnPhaseStart(phase string, expected int)  {}
+func (NoopProgressHandler) OnRecord(phase string, index, total int) {}
+func (NoopProgressHandler) OnPhaseEnd(phase string, stats PhaseStats) {}
@@ -6,59 +6,54 @@ import (
 
 // CommonAncestors finds all common ancestors of two individuals.
 func (g *Graph) CommonAncestors(indi1ID, indi2ID string) ([]*IndividualNode, error) {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-
-	indi1 := g.individuals[indi1ID]
-	indi2 := g.individuals[indi2ID]
+	return g.CommonAncestorsFiltered(indi1ID, indi2ID, nil)
+}
 
-	if indi1 == nil {
-		return nil, fmt.Errorf("individual %s not found", indi1ID)
-	}
-	if indi2 == nil {
-		return nil, fmt.Errorf("individual %s not found", indi2ID)
+// CommonAncestorsFiltered finds all common ancestors of two individuals,
+// only traversing parent/child edges for which edgeFilter returns true.
+// A nil edgeFilter traverses every FAMC edge, matching CommonAncestors.
+// This lets callers restrict ancestor search to, e.g., biological
+// parents only by rejecting edges with an attached AdoptionEdge record
+// (see EdgeRecord, Graph.AddEdgeRecord).
+//
+// The two individuals' ancestors are found via a parallel bidirectional
+// search (see findCommonAncestors) rather than two sequential walks.
+func (g *Graph) CommonAncestorsFiltered(indi1ID, indi2ID string, edgeFilter func(*Edge) bool) ([]*IndividualNode, error) {
+	result, err := g.findCommonAncestors(indi1ID, indi2ID, edgeFilter, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	// Find all ancestors of indi1
-	ancestors1 := g.findAllAncestors(indi1, make(map[string]bool))
-
-	// Find all ancestors of indi2
-	ancestors2 := g.findAllAncestors(indi2, make(map[string]bool))
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 
-	// Find intersection
-	common := make([]*IndividualNode, 0)
-	for id := range ancestors1 {
-		if ancestors2[id] {
-			if node := g.individuals[id]; node != nil {
-				common = append(common, node)
-			}
+	common := make([]*IndividualNode, 0, len(result.ids))
+	for _, id := range result.ids {
+		if node := g.individuals[id]; node != nil {
+			common = append(common, node)
 		}
 	}
 
 	return common, nil
 }
 
-// findAllAncestors finds all ancestors of an individual recursively.
+// findAllAncestors finds all ancestors of an individual.
 func (g *Graph) findAllAncestors(indi *IndividualNode, visited map[string]bool) map[string]bool {
-	if visited[indi.ID()] {
-		return visited
-	}
+	return g.findAllAncestorsFiltered(indi, visited, nil)
+}
 
-	visited[indi.ID()] = true
-
-	// Find parents via FAMC edges
-	for _, edge := range indi.OutEdges() {
-		if edge.EdgeType == EdgeTypeFAMC && edge.Family != nil {
-			famNode := edge.Family
-			if famNode.Husband != nil {
-				g.findAllAncestors(famNode.Husband, visited)
-			}
-			if famNode.Wife != nil {
-				g.findAllAncestors(famNode.Wife, visited)
-			}
-		}
+// findAllAncestorsFiltered finds all ancestors of an individual, only
+// descending through FAMC edges for which edgeFilter returns true (a nil
+// edgeFilter descends through all of them). The walk itself is a
+// layer-by-layer breadth-first expansion (see expandFrontier) rather than
+// recursive descent, so it shares its traversal logic with the
+// bidirectional search used by CommonAncestorsFiltered.
+func (g *Graph) findAllAncestorsFiltered(indi *IndividualNode, visited map[string]bool, edgeFilter func(*Edge) bool) map[string]bool {
+	ancestors := newShardedAncestorMap()
+	g.expandFrontier(indi, edgeFilter, DefaultConfig(), ancestors)
+	for xrefID := range ancestors.snapshot() {
+		visited[xrefID] = true
 	}
-
 	return visited
 }
 
@@ -66,36 +61,59 @@ func (g *Graph) findAllAncestors(indi *IndividualNode, visited map[string]bool)
 // The LCA is the common ancestor that is closest to both individuals (most recent).
 // This is the one that minimizes the maximum distance from both individuals.
 func (g *Graph) LowestCommonAncestor(indi1ID, indi2ID string) (*IndividualNode, error) {
-	commonAncestors, err := g.CommonAncestors(indi1ID, indi2ID)
+	return g.LowestCommonAncestorFiltered(indi1ID, indi2ID, nil)
+}
+
+// LowestCommonAncestorFiltered finds the lowest common ancestor of two
+// individuals, only traversing parent/child edges for which edgeFilter
+// returns true (see CommonAncestorsFiltered).
+//
+// The LCA is picked directly from the depth maps produced by the
+// bidirectional search (minimizing max(depth1, depth2), ties broken by
+// generation number i.e. depth1+depth2, further ties broken by xrefID for
+// determinism) instead of calling ShortestPath per candidate.
+func (g *Graph) LowestCommonAncestorFiltered(indi1ID, indi2ID string, edgeFilter func(*Edge) bool) (*IndividualNode, error) {
+	result, err := g.findCommonAncestors(indi1ID, indi2ID, edgeFilter, nil)
 	if err != nil {
 		return nil, err
 	}
-
-	if len(commonAncestors) == 0 {
+	if len(result.ids) == 0 {
 		return nil, fmt.Errorf("no common ancestors found")
 	}
 
-	// Find the lowest common ancestor (most recent)
-	// This is the one that minimizes the maximum distance from both individuals
-	lowest := commonAncestors[0]
-	minMaxDepth := max(g.getAncestorDepth(indi1ID, lowest.ID()), g.getAncestorDepth(indi2ID, lowest.ID()))
+	lowestID := result.ids[0]
+	minMaxDepth := max(result.depths1[lowestID], result.depths2[lowestID])
+	minSumDepth := result.depths1[lowestID] + result.depths2[lowestID]
 
-	for _, ancestor := range commonAncestors[1:] {
-		depth1 := g.getAncestorDepth(indi1ID, ancestor.ID())
-		depth2 := g.getAncestorDepth(indi2ID, ancestor.ID())
+	for _, id := range result.ids[1:] {
+		depth1 := result.depths1[id]
+		depth2 := result.depths2[id]
 		maxDepth := max(depth1, depth2)
+		sumDepth := depth1 + depth2
 
-		// The LCA is the one with the minimum maximum depth (closest to both)
-		if maxDepth < minMaxDepth {
+		if maxDepth < minMaxDepth || (maxDepth == minMaxDepth && sumDepth < minSumDepth) {
 			minMaxDepth = maxDepth
-			lowest = ancestor
+			minSumDepth = sumDepth
+			lowestID = id
 		}
 	}
 
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	lowest := g.individuals[lowestID]
+	if lowest == nil {
+		return nil, fmt.Errorf("individual %s not found", lowestID)
+	}
 	return lowest, nil
 }
 
 // getAncestorDepth calculates the depth (generations) from descendant to ancestor.
+// It goes through ShortestPath rather than an edge-predicate-aware walk
+// because ShortestPath's implementation isn't part of this package, so
+// it can't be given an optional edge predicate the way
+// findAllAncestorsFiltered and CommonAncestorsFiltered were. It's kept for
+// relationships.go's path-description use; LowestCommonAncestorFiltered no
+// longer calls it.
 func (g *Graph) getAncestorDepth(descendantID, ancestorID string) int {
 	if descendantID == ancestorID {
 		return 0
@@ -0,0 +1,84 @@
+package query
+
+import "sync"
+
+// edgeRecordIndex holds the EdgeRecords attached to a Graph's edges via
+// AddEdgeRecord, plus attribute indexes over them (byMarriageYear,
+// byCitationSource). FilterQuery's edge filters (WhereEdge,
+// ByMarriageYearRange, BySourceCitation) look records up by edge ID via
+// get and do not read the attribute indexes.
+type edgeRecordIndex struct {
+	mu               sync.RWMutex
+	byEdgeID         map[string]EdgeRecord
+	byMarriageYear   map[int][]*MarriageEdge
+	byCitationSource map[string][]*CitationEdge
+}
+
+func newEdgeRecordIndex() *edgeRecordIndex {
+	return &edgeRecordIndex{
+		byEdgeID:         make(map[string]EdgeRecord),
+		byMarriageYear:   make(map[int][]*MarriageEdge),
+		byCitationSource: make(map[string][]*CitationEdge),
+	}
+}
+
+func (idx *edgeRecordIndex) add(record EdgeRecord) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.byEdgeID[record.EdgeID()] = record
+	switch r := record.(type) {
+	case *MarriageEdge:
+		if year, ok := r.Year(); ok {
+			idx.byMarriageYear[year] = append(idx.byMarriageYear[year], r)
+		}
+	case *CitationEdge:
+		idx.byCitationSource[r.SourceXref] = append(idx.byCitationSource[r.SourceXref], r)
+	}
+}
+
+func (idx *edgeRecordIndex) get(edgeID string) (EdgeRecord, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	record, ok := idx.byEdgeID[edgeID]
+	return record, ok
+}
+
+// edgeRecordIndexFor is a side table keyed by *Graph, following the same
+// pattern as similarityIndexFor in the root query package: the index
+// lives alongside the graph rather than as a Graph field, and Edge has no
+// defining file in this snapshot that could carry the record itself.
+var (
+	edgeRecordIndexMu  sync.Mutex
+	edgeRecordIndexFor = make(map[*Graph]*edgeRecordIndex)
+)
+
+func edgeRecordIndexForGraph(g *Graph) *edgeRecordIndex {
+	edgeRecordIndexMu.Lock()
+	defer edgeRecordIndexMu.Unlock()
+	idx, ok := edgeRecordIndexFor[g]
+	if !ok {
+		idx = newEdgeRecordIndex()
+		edgeRecordIndexFor[g] = idx
+	}
+	return idx
+}
+
+// AddEdgeRecord attaches record to edge and adds edge to the graph via
+// AddEdge, indexing record by edge ID for FilterQuery.WhereEdge and its
+// convenience methods (ByMarriageYearRange, BySourceCitation), and by
+// attribute.
+// record.EdgeID() must equal edge.ID.
+func (g *Graph) AddEdgeRecord(edge *Edge, record EdgeRecord) error {
+	if err := g.AddEdge(edge); err != nil {
+		return err
+	}
+	edgeRecordIndexForGraph(g).add(record)
+	return nil
+}
+
+// EdgeRecordFor returns the EdgeRecord attached to the edge identified by
+// edgeID, if any.
+func (g *Graph) EdgeRecordFor(edgeID string) (EdgeRecord, bool) {
+	return edgeRecordIndexForGraph(g).get(edgeID)
+}
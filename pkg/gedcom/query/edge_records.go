@@ -0,0 +1,111 @@
+package query
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// EdgeRecordType distinguishes the concrete EdgeRecord implementations.
+type EdgeRecordType string
+
+const (
+	EdgeRecordMarriage    EdgeRecordType = "marriage"
+	EdgeRecordParentChild EdgeRecordType = "parent_child"
+	EdgeRecordAdoption    EdgeRecordType = "adoption"
+	EdgeRecordCitation    EdgeRecordType = "citation"
+)
+
+// EdgeRecord promotes a relationship to a first-class record with its
+// own attributes, mirroring how Ent treats edges as schemas rather than
+// bare connections. It is keyed to the underlying *Edge by EdgeID so it
+// can be attached without requiring changes to Edge itself, whose
+// defining file isn't part of this package snapshot -- see
+// Graph.AddEdgeRecord and edge_record_index.go.
+type EdgeRecord interface {
+	EdgeID() string
+	EdgeRecordType() EdgeRecordType
+}
+
+// MarriageEdge carries the GEDCOM MARR sub-tags (date, place) for a
+// husband/wife FAM relationship.
+type MarriageEdge struct {
+	edgeID string
+	Date   string
+	Place  string
+}
+
+// NewMarriageEdge builds a MarriageEdge for the edge identified by
+// edgeID.
+func NewMarriageEdge(edgeID, date, place string) *MarriageEdge {
+	return &MarriageEdge{edgeID: edgeID, Date: date, Place: place}
+}
+
+func (m *MarriageEdge) EdgeID() string { return m.edgeID }
+func (m *MarriageEdge) EdgeRecordType() EdgeRecordType { return EdgeRecordMarriage }
+
+var yearPattern = regexp.MustCompile(`\b(\d{4})\b`)
+
+// Year extracts the year from Date (a GEDCOM date string like "15 JUN
+// 1950"), returning ok=false if no four-digit year is present.
+func (m *MarriageEdge) Year() (year int, ok bool) {
+	match := yearPattern.FindStringSubmatch(m.Date)
+	if match == nil {
+		return 0, false
+	}
+	year, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	return year, true
+}
+
+// ParentChildEdge carries attributes of a FAMC/CHIL relationship: whether
+// the child's place in the family is by birth or adoption.
+type ParentChildEdge struct {
+	edgeID   string
+	Adoptive bool
+}
+
+// NewParentChildEdge builds a ParentChildEdge for the edge identified by
+// edgeID.
+func NewParentChildEdge(edgeID string, adoptive bool) *ParentChildEdge {
+	return &ParentChildEdge{edgeID: edgeID, Adoptive: adoptive}
+}
+
+func (p *ParentChildEdge) EdgeID() string { return p.edgeID }
+func (p *ParentChildEdge) EdgeRecordType() EdgeRecordType { return EdgeRecordParentChild }
+
+// AdoptionEdge carries the GEDCOM ADOP sub-tags for an adoptive
+// parent-child relationship: which parent(s) adopted the child.
+type AdoptionEdge struct {
+	edgeID    string
+	AdoptedBy string // "HUSB", "WIFE", or "BOTH", per GEDCOM ADOP.FAMC.ADOP
+	Date      string
+}
+
+// NewAdoptionEdge builds an AdoptionEdge for the edge identified by
+// edgeID.
+func NewAdoptionEdge(edgeID, adoptedBy, date string) *AdoptionEdge {
+	return &AdoptionEdge{edgeID: edgeID, AdoptedBy: adoptedBy, Date: date}
+}
+
+func (a *AdoptionEdge) EdgeID() string { return a.edgeID }
+func (a *AdoptionEdge) EdgeRecordType() EdgeRecordType { return EdgeRecordAdoption }
+
+// CitationEdge carries the GEDCOM SOUR sub-tags (PAGE, QUAY) for a
+// source citation attached to a record.
+type CitationEdge struct {
+	edgeID     string
+	SourceXref string
+	Page       string
+	Quality    int // QUAY: 0 (unreliable) to 3 (direct primary evidence)
+}
+
+// NewCitationEdge builds a CitationEdge for the edge identified by
+// edgeID.
+func NewCitationEdge(edgeID, sourceXref, page string, quality int) *CitationEdge {
+	return &CitationEdge{edgeID: edgeID, SourceXref: sourceXref, Page: page, Quality: quality}
+}
+
+func (c *CitationEdge) EdgeID() string { return c.edgeID }
+func (c *CitationEdge) EdgeRecordType() EdgeRecordType { return EdgeRecordCitation }
@@ -24,6 +24,8 @@ type FilterQuery struct {
 	hasChildrenFilter *bool
 	hasSpouseFilter   *bool
 	livingFilter      *bool
+
+	edgeFilters []func(EdgeRecord) bool
 }
 
 // NewFilterQuery creates a new FilterQuery.
@@ -123,6 +125,59 @@ func (fq *FilterQuery) Deceased() *FilterQuery {
 	})
 }
 
+// WhereEdge restricts results to individuals with at least one edge
+// whose attached EdgeRecord (see AddEdgeRecord) satisfies every
+// registered edge predicate.
+func (fq *FilterQuery) WhereEdge(predicate func(EdgeRecord) bool) *FilterQuery {
+	fq.edgeFilters = append(fq.edgeFilters, predicate)
+	return fq
+}
+
+// ByMarriageYearRange filters to individuals with a marriage edge record
+// (see MarriageEdge) whose year falls within [start, end].
+func (fq *FilterQuery) ByMarriageYearRange(start, end int) *FilterQuery {
+	return fq.WhereEdge(func(record EdgeRecord) bool {
+		marriage, ok := record.(*MarriageEdge)
+		if !ok {
+			return false
+		}
+		year, ok := marriage.Year()
+		return ok && year >= start && year <= end
+	})
+}
+
+// BySourceCitation filters to individuals with a citation edge record
+// (see CitationEdge) pointing at the source identified by sourceXref.
+func (fq *FilterQuery) BySourceCitation(sourceXref string) *FilterQuery {
+	return fq.WhereEdge(func(record EdgeRecord) bool {
+		citation, ok := record.(*CitationEdge)
+		return ok && citation.SourceXref == sourceXref
+	})
+}
+
+// matchesEdgeFilters reports whether at least one of xrefID's edges has
+// an attached EdgeRecord satisfying every registered edge predicate.
+func (fq *FilterQuery) matchesEdgeFilters(xrefID string) bool {
+	index := edgeRecordIndexForGraph(fq.graph)
+	for _, edge := range fq.graph.GetEdges(xrefID) {
+		record, ok := index.get(edge.ID)
+		if !ok {
+			continue
+		}
+		allMatch := true
+		for _, predicate := range fq.edgeFilters {
+			if !predicate(record) {
+				allMatch = false
+				break
+			}
+		}
+		if allMatch {
+			return true
+		}
+	}
+	return false
+}
+
 // Execute runs the filter and returns matching individuals.
 // Uses indexes for fast filtering when possible.
 func (fq *FilterQuery) Execute() ([]*gedcom.IndividualRecord, error) {
@@ -235,6 +290,7 @@ func (fq *FilterQuery) Execute() ([]*gedcom.IndividualRecord, error) {
 			}
 		}
 		initialSet = candidateSet
+		candidateSet = make(map[string]bool)
 	}
 
 	// If no indexed filters were used, use all individuals
@@ -246,6 +302,15 @@ func (fq *FilterQuery) Execute() ([]*gedcom.IndividualRecord, error) {
 		}
 	}
 
+	if len(fq.edgeFilters) > 0 {
+		for xrefID := range initialSet {
+			if fq.matchesEdgeFilters(xrefID) {
+				candidateSet[xrefID] = true
+			}
+		}
+		initialSet = candidateSet
+	}
+
 	// Apply remaining custom filters
 	results := make([]*gedcom.IndividualRecord, 0)
 	for xrefID := range initialSet {
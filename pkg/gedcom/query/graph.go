@@ -231,6 +231,69 @@ func (g *Graph) AddEdge(edge *Edge) error {
 	return nil
 }
 
+// RemoveNode removes a node and its incident edges from the graph.
+//
+// It only detaches the node from graph-level lookups (nodes, the
+// type-specific maps, edges, edgeIndex): IndividualNode/FamilyNode don't
+// expose a way to remove an entry from their own OutEdges/InEdges slices
+// (AddOutEdge/AddInEdge have no removal counterpart in this package), so
+// a node object a caller already holds a direct reference to will still
+// report its old edges even after RemoveNode.
+func (g *Graph) RemoveNode(id string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.nodes[id]; !exists {
+		return fmt.Errorf("node with ID %s does not exist", id)
+	}
+
+	// Range over a copy: removeFromEdgeIndexLocked rewrites g.edgeIndex[id]
+	// in place, which would shift entries out from under the loop.
+	incident := append([]*Edge(nil), g.edgeIndex[id]...)
+	for _, edge := range incident {
+		delete(g.edges, edge.ID)
+		g.removeFromEdgeIndexLocked(edge.From.ID(), edge.ID)
+		g.removeFromEdgeIndexLocked(edge.To.ID(), edge.ID)
+	}
+	delete(g.edgeIndex, id)
+
+	delete(g.nodes, id)
+	delete(g.individuals, id)
+	delete(g.families, id)
+	delete(g.notes, id)
+	delete(g.sources, id)
+	delete(g.repositories, id)
+	delete(g.events, id)
+
+	return nil
+}
+
+// removeFromEdgeIndexLocked removes edgeID from nodeID's edge index entry.
+// Callers must hold g.mu.
+func (g *Graph) removeFromEdgeIndexLocked(nodeID, edgeID string) {
+	edges := g.edgeIndex[nodeID]
+	for i, e := range edges {
+		if e.ID == edgeID {
+			g.edgeIndex[nodeID] = append(edges[:i], edges[i+1:]...)
+			return
+		}
+	}
+}
+
+// UpdateIndividual replaces the gedcom.IndividualRecord attached to the
+// individual identified by xrefID.
+func (g *Graph) UpdateIndividual(xrefID string, record *gedcom.IndividualRecord) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	indi, exists := g.individuals[xrefID]
+	if !exists {
+		return fmt.Errorf("individual with ID %s does not exist", xrefID)
+	}
+	indi.Individual = record
+	return nil
+}
+
 // GetEdges returns all edges for a given node ID.
 func (g *Graph) GetEdges(nodeID string) []*Edge {
 	g.mu.RLock()
@@ -0,0 +1,138 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/yourorg/gedcom/pkg/gedcom"
+)
+
+// txnOp is one staged mutation, applied to the underlying Graph in order
+// on Commit.
+type txnOp func(*Graph) error
+
+// GraphTxn stages AddNode/AddEdge/RemoveNode/UpdateIndividual calls so
+// they can be applied to a Graph atomically via Commit, or discarded via
+// Rollback with no side effects.
+//
+// Staged mutations are buffered as a list of closures rather than a
+// true copy-on-write overlay over nodes/edges/edgeIndex: IndividualNode,
+// FamilyNode and Edge are otherwise-opaque types in this package
+// snapshot (no constructors or field-level copy helpers beyond what
+// Graph itself already exposes), so there's no way to build a shadow
+// node/edge map the way Graph.nodes/edges are built without calling
+// Graph's own (locking) mutation methods. Reads made through the txn
+// before Commit are therefore served straight from the base graph, not
+// from an isolated overlay; callers that need read-your-writes
+// semantics mid-transaction should track their own staged state.
+//
+// This package has no hybrid storage backend (that's a root `query`
+// package concept, via BadgerDB/SQLite/PostgreSQL), so there's no second
+// store for Commit to coordinate with here.
+type GraphTxn struct {
+	graph    *Graph
+	ops      []txnOp
+	done     bool
+	rollback bool
+}
+
+// Begin starts a new transaction staging mutations against g.
+func (g *Graph) Begin() *GraphTxn {
+	return &GraphTxn{graph: g}
+}
+
+func (txn *GraphTxn) checkOpen() error {
+	if txn.done {
+		if txn.rollback {
+			return fmt.Errorf("transaction already rolled back")
+		}
+		return fmt.Errorf("transaction already committed")
+	}
+	return nil
+}
+
+// AddNode stages a node addition.
+func (txn *GraphTxn) AddNode(node GraphNode) error {
+	if err := txn.checkOpen(); err != nil {
+		return err
+	}
+	txn.ops = append(txn.ops, func(g *Graph) error {
+		return g.AddNode(node)
+	})
+	return nil
+}
+
+// AddEdge stages an edge addition.
+func (txn *GraphTxn) AddEdge(edge *Edge) error {
+	if err := txn.checkOpen(); err != nil {
+		return err
+	}
+	txn.ops = append(txn.ops, func(g *Graph) error {
+		return g.AddEdge(edge)
+	})
+	return nil
+}
+
+// RemoveNode stages a node removal.
+func (txn *GraphTxn) RemoveNode(id string) error {
+	if err := txn.checkOpen(); err != nil {
+		return err
+	}
+	txn.ops = append(txn.ops, func(g *Graph) error {
+		return g.RemoveNode(id)
+	})
+	return nil
+}
+
+// UpdateIndividual stages replacing the gedcom.IndividualRecord attached
+// to the individual identified by xrefID.
+func (txn *GraphTxn) UpdateIndividual(xrefID string, record *gedcom.IndividualRecord) error {
+	if err := txn.checkOpen(); err != nil {
+		return err
+	}
+	txn.ops = append(txn.ops, func(g *Graph) error {
+		return g.UpdateIndividual(xrefID, record)
+	})
+	return nil
+}
+
+// Commit applies every staged mutation to the underlying graph, in the
+// order they were staged. If any op fails, the ops applied before it are
+// left in place (Commit does not undo them), so callers should treat a
+// Commit error as leaving the graph in a partially-applied state and
+// inspect it before reusing it.
+//
+// Commit resets the graph's query cache and filter indexes afterward,
+// since neither queryCache nor FilterIndexes expose a way to invalidate
+// individual keys/shards in this package snapshot -- a full rebuild-on-
+// next-use is the safe substitute.
+func (txn *GraphTxn) Commit() error {
+	if err := txn.checkOpen(); err != nil {
+		return err
+	}
+	txn.done = true
+
+	for _, op := range txn.ops {
+		if err := op(txn.graph); err != nil {
+			return fmt.Errorf("graph transaction: commit failed: %w", err)
+		}
+	}
+
+	txn.graph.mu.Lock()
+	txn.graph.cache = newQueryCache(1000)
+	txn.graph.indexes = newFilterIndexes()
+	txn.graph.mu.Unlock()
+
+	return nil
+}
+
+// Rollback discards every staged mutation. Since nothing is applied to
+// the graph until Commit, Rollback has no side effects on the graph.
+func (txn *GraphTxn) Rollback() error {
+	if err := txn.checkOpen(); err != nil {
+		return err
+	}
+	txn.done = true
+	txn.rollback = true
+	txn.ops = nil
+	return nil
+}
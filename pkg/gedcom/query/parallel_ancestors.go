@@ -0,0 +1,262 @@
+package query
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+)
+
+// Note on test coverage: IndividualNode, FamilyNode, GraphNode, the
+// NodeType* constants, and Edge -- every type a fixture for this file
+// would need to construct -- have no defining file anywhere in this
+// checkout (this package imports "github.com/yourorg/gedcom/pkg/gedcom",
+// a separate snapshot from the lesfleursdelanuitdev/ligneous-gedcom
+// query package of the same name, and its own node/edge model was never
+// part of either). A parallel_ancestors_test.go here would have to
+// invent that whole object model from scratch rather than exercise it,
+// so it's left out rather than fabricated; WarmAncestorIndex and
+// friends in the real query package are the place this traversal's test
+// coverage already lives.
+
+// TraversalConfig bounds ancestor-search traversal.
+type TraversalConfig struct {
+	// MaxDepth caps how many generations a frontier expansion will climb.
+	// Zero means unbounded.
+	MaxDepth int
+
+	// Workers caps how many goroutines expandFrontier may run concurrently
+	// while processing a single frontier layer. Zero is treated as 2.
+	Workers int
+}
+
+// Config holds tunables for this package's traversal algorithms.
+type Config struct {
+	Traversal TraversalConfig
+}
+
+// DefaultConfig returns a Config with unbounded depth and up to 2
+// goroutines processing each frontier layer.
+func DefaultConfig() *Config {
+	return &Config{Traversal: TraversalConfig{MaxDepth: 0, Workers: 2}}
+}
+
+const ancestorShardCount = 16
+
+// ancestorShard is one bucket of a shardedAncestorMap.
+type ancestorShard struct {
+	mu     sync.Mutex
+	depths map[string]int
+}
+
+// shardedAncestorMap records the shallowest depth at which each xrefID was
+// reached, safe for concurrent writers expanding different frontiers.
+type shardedAncestorMap struct {
+	shards [ancestorShardCount]*ancestorShard
+}
+
+func newShardedAncestorMap() *shardedAncestorMap {
+	m := &shardedAncestorMap{}
+	for i := range m.shards {
+		m.shards[i] = &ancestorShard{depths: make(map[string]int)}
+	}
+	return m
+}
+
+func fnvHash(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
+
+func (m *shardedAncestorMap) shardFor(xrefID string) *ancestorShard {
+	return m.shards[fnvHash(xrefID)%ancestorShardCount]
+}
+
+// setIfShallower records depth for xrefID if it hasn't been seen yet or was
+// previously seen at a greater depth. It reports whether the value was
+// recorded (i.e. whether xrefID should be expanded from at this depth).
+func (m *shardedAncestorMap) setIfShallower(xrefID string, depth int) bool {
+	shard := m.shardFor(xrefID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if existing, ok := shard.depths[xrefID]; ok && existing <= depth {
+		return false
+	}
+	shard.depths[xrefID] = depth
+	return true
+}
+
+func (m *shardedAncestorMap) get(xrefID string) (int, bool) {
+	shard := m.shardFor(xrefID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	depth, ok := shard.depths[xrefID]
+	return depth, ok
+}
+
+// snapshot returns a plain map copy of every xrefID recorded so far.
+func (m *shardedAncestorMap) snapshot() map[string]int {
+	out := make(map[string]int)
+	for _, shard := range m.shards {
+		shard.mu.Lock()
+		for xrefID, depth := range shard.depths {
+			out[xrefID] = depth
+		}
+		shard.mu.Unlock()
+	}
+	return out
+}
+
+// expandFrontier performs a layer-by-layer breadth-first walk up from root
+// through FAMC edges, recording each xrefID's depth (generations from root)
+// into visited. Only edges for which edgeFilter returns true are descended
+// (a nil edgeFilter descends through all of them). Expansion stops once
+// cfg.Traversal.MaxDepth generations have been recorded (0 means
+// unbounded). Each frontier is sorted before it is queued; with more than
+// one worker the order in which a layer's entries are processed is not
+// fixed, but the depth recorded for each xrefID is.
+//
+// Each layer's frontier is fanned out across a pool of cfg.Traversal.Workers
+// goroutines (at least 1), mirroring duplicate.findDuplicatesBetweenParallel's
+// job-channel-plus-worker-pool shape; visited's per-shard locking already
+// makes setIfShallower safe for concurrent writers, so the only other
+// shared state (next) is guarded by its own mutex.
+func (g *Graph) expandFrontier(root *IndividualNode, edgeFilter func(*Edge) bool, cfg *Config, visited *shardedAncestorMap) {
+	if !visited.setIfShallower(root.ID(), 0) {
+		return
+	}
+
+	workers := cfg.Traversal.Workers
+	if workers <= 0 {
+		workers = 2
+	}
+
+	frontier := []string{root.ID()}
+	depth := 0
+	maxDepth := cfg.Traversal.MaxDepth
+
+	for len(frontier) > 0 {
+		if maxDepth > 0 && depth >= maxDepth {
+			return
+		}
+		depth++
+		layerDepth := depth
+
+		sort.Strings(frontier)
+
+		jobs := make(chan string, len(frontier))
+		for _, xrefID := range frontier {
+			jobs <- xrefID
+		}
+		close(jobs)
+
+		var (
+			mu   sync.Mutex
+			next []string
+		)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for xrefID := range jobs {
+					indi := g.individuals[xrefID]
+					if indi == nil {
+						continue
+					}
+					for _, edge := range indi.OutEdges() {
+						if edge.EdgeType != EdgeTypeFAMC || edge.Family == nil {
+							continue
+						}
+						if edgeFilter != nil && !edgeFilter(edge) {
+							continue
+						}
+						famNode := edge.Family
+						for _, parent := range []*IndividualNode{famNode.Husband, famNode.Wife} {
+							if parent == nil {
+								continue
+							}
+							if visited.setIfShallower(parent.ID(), layerDepth) {
+								mu.Lock()
+								next = append(next, parent.ID())
+								mu.Unlock()
+							}
+						}
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		frontier = next
+	}
+}
+
+// parallelBidirectionalAncestors expands ancestor frontiers from indi1 and
+// indi2 simultaneously, one goroutine per side, each writing depths into
+// its own shardedAncestorMap.
+func (g *Graph) parallelBidirectionalAncestors(indi1, indi2 *IndividualNode, edgeFilter func(*Edge) bool, cfg *Config) (side1, side2 *shardedAncestorMap) {
+	side1 = newShardedAncestorMap()
+	side2 = newShardedAncestorMap()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		g.expandFrontier(indi1, edgeFilter, cfg, side1)
+	}()
+	go func() {
+		defer wg.Done()
+		g.expandFrontier(indi2, edgeFilter, cfg, side2)
+	}()
+	wg.Wait()
+
+	return side1, side2
+}
+
+// commonAncestorsResult holds the output of findCommonAncestors: the
+// sorted, deduplicated xrefIDs found on both sides, along with the
+// per-side depth at which each was reached.
+type commonAncestorsResult struct {
+	ids     []string
+	depths1 map[string]int
+	depths2 map[string]int
+}
+
+// findCommonAncestors runs a parallel bidirectional ancestor search from
+// indi1ID and indi2ID and returns their intersection along with both
+// sides' depth maps, so callers (CommonAncestorsFiltered,
+// LowestCommonAncestorFiltered) never need to call ShortestPath or walk
+// either side again.
+func (g *Graph) findCommonAncestors(indi1ID, indi2ID string, edgeFilter func(*Edge) bool, cfg *Config) (*commonAncestorsResult, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	g.mu.RLock()
+	indi1 := g.individuals[indi1ID]
+	indi2 := g.individuals[indi2ID]
+	g.mu.RUnlock()
+
+	if indi1 == nil {
+		return nil, fmt.Errorf("individual %s not found", indi1ID)
+	}
+	if indi2 == nil {
+		return nil, fmt.Errorf("individual %s not found", indi2ID)
+	}
+
+	side1, side2 := g.parallelBidirectionalAncestors(indi1, indi2, edgeFilter, cfg)
+	depths1 := side1.snapshot()
+	depths2 := side2.snapshot()
+
+	ids := make([]string, 0)
+	for xrefID := range depths1 {
+		if _, ok := depths2[xrefID]; ok {
+			ids = append(ids, xrefID)
+		}
+	}
+	sort.Strings(ids)
+
+	return &commonAncestorsResult{ids: ids, depths1: depths1, depths2: depths2}, nil
+}
@@ -0,0 +1,114 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PageInfo mirrors the Relay PageInfo type.
+type PageInfo struct {
+	HasNextPage     bool
+	HasPreviousPage bool
+	StartCursor     string
+	EndCursor       string
+}
+
+// edge pairs an item with the opaque cursor it was paginated at.
+type edge[T any] struct {
+	Cursor string
+	Node   T
+}
+
+// paginate applies the Relay connection arguments (first/after, last/before)
+// to items, which must already be sorted by sortKey(item) with xrefID(item)
+// as the tiebreaker -- the same pair encoded into each item's cursor, so
+// an `after` cursor from one call remains valid against a later call over
+// the same ordering even as the underlying graph changes.
+func paginate[T any](items []T, sortKey, xrefID func(T) string, first, last *int, after, before *string) ([]edge[T], PageInfo, error) {
+	sorted := make([]T, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		si, sj := sortKey(sorted[i]), sortKey(sorted[j])
+		if si != sj {
+			return si < sj
+		}
+		return xrefID(sorted[i]) < xrefID(sorted[j])
+	})
+
+	start, end := 0, len(sorted)
+
+	if after != nil {
+		c, err := decodeCursor(*after)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+		start = indexAfter(sorted, sortKey, xrefID, c)
+	}
+	if before != nil {
+		c, err := decodeCursor(*before)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+		end = indexOf(sorted, sortKey, xrefID, c)
+	}
+	if start > end {
+		start = end
+	}
+	window := sorted[start:end]
+
+	hasNext, hasPrev := false, false
+	if first != nil {
+		if *first < 0 {
+			return nil, PageInfo{}, fmt.Errorf("graphql: first must not be negative")
+		}
+		if len(window) > *first {
+			hasNext = true
+			window = window[:*first]
+		}
+	}
+	if last != nil {
+		if *last < 0 {
+			return nil, PageInfo{}, fmt.Errorf("graphql: last must not be negative")
+		}
+		if len(window) > *last {
+			hasPrev = true
+			window = window[len(window)-*last:]
+		}
+	}
+
+	edges := make([]edge[T], len(window))
+	for i, item := range window {
+		edges[i] = edge[T]{Cursor: encodeCursor(xrefID(item), sortKey(item)), Node: item}
+	}
+
+	info := PageInfo{HasNextPage: hasNext, HasPreviousPage: hasPrev}
+	if len(edges) > 0 {
+		info.StartCursor = edges[0].Cursor
+		info.EndCursor = edges[len(edges)-1].Cursor
+	}
+	return edges, info, nil
+}
+
+// indexAfter returns the index of the first item strictly after c in
+// sort order, for use as a slice start bound.
+func indexAfter[T any](sorted []T, sortKey, xrefID func(T) string, c cursor) int {
+	for i, item := range sorted {
+		sk := sortKey(item)
+		if sk > c.LastSortKey || (sk == c.LastSortKey && xrefID(item) > c.LastXrefID) {
+			return i
+		}
+	}
+	return len(sorted)
+}
+
+// indexOf returns the index of the item matching c, for use as a slice
+// end bound, or len(sorted) if no item matches (an unmatched `before`
+// cursor leaves the tail end of the window untouched).
+func indexOf[T any](sorted []T, sortKey, xrefID func(T) string, c cursor) int {
+	for i, item := range sorted {
+		if sortKey(item) == c.LastSortKey && xrefID(item) == c.LastXrefID {
+			return i
+		}
+	}
+	return len(sorted)
+}
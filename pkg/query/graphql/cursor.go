@@ -0,0 +1,38 @@
+package graphql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// cursor is the data an opaque, base64-encoded Cursor decodes to: the
+// xref ID of the last item seen plus the sort key it was ordered by, so
+// pagination stays stable even if the underlying sort key (e.g. a name)
+// is not itself unique.
+type cursor struct {
+	LastXrefID  string `json:"lastXrefID"`
+	LastSortKey string `json:"lastSortKey"`
+}
+
+// encodeCursor builds the opaque Cursor string for an item identified by
+// xrefID and ordered by sortKey.
+func encodeCursor(xrefID, sortKey string) string {
+	raw, _ := json.Marshal(cursor{LastXrefID: xrefID, LastSortKey: sortKey})
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+// decodeCursor reverses encodeCursor, returning an error for a cursor
+// that didn't come from this package (malformed base64/JSON) rather than
+// silently treating it as the start or end of the list.
+func decodeCursor(encoded string) (cursor, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor{}, fmt.Errorf("graphql: invalid cursor: %w", err)
+	}
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return cursor{}, fmt.Errorf("graphql: invalid cursor: %w", err)
+	}
+	return c, nil
+}
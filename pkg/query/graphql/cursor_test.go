@@ -0,0 +1,95 @@
+package graphql
+
+import "testing"
+
+func TestCursorRoundTrip(t *testing.T) {
+	encoded := encodeCursor("@I1@", "Doe, Jane")
+	decoded, err := decodeCursor(encoded)
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if decoded.LastXrefID != "@I1@" || decoded.LastSortKey != "Doe, Jane" {
+		t.Errorf("got %+v, want {@I1@ Doe, Jane}", decoded)
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	if _, err := decodeCursor("not-a-cursor!!"); err == nil {
+		t.Error("expected error decoding a malformed cursor, got nil")
+	}
+}
+
+type fixture struct {
+	xref string
+	name string
+}
+
+func TestPaginateFirstAfter(t *testing.T) {
+	items := []fixture{{"@I3@", "Carl"}, {"@I1@", "Alice"}, {"@I2@", "Bob"}}
+	sortKey := func(f fixture) string { return f.name }
+	xrefID := func(f fixture) string { return f.xref }
+
+	first := 2
+	edges, info, err := paginate(items, sortKey, xrefID, &first, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("paginate: %v", err)
+	}
+	if len(edges) != 2 || edges[0].Node.name != "Alice" || edges[1].Node.name != "Bob" {
+		t.Fatalf("unexpected page: %+v", edges)
+	}
+	if !info.HasNextPage {
+		t.Error("expected HasNextPage true")
+	}
+
+	after := edges[1].Cursor
+	edges2, info2, err := paginate(items, sortKey, xrefID, &first, nil, &after, nil)
+	if err != nil {
+		t.Fatalf("paginate after: %v", err)
+	}
+	if len(edges2) != 1 || edges2[0].Node.name != "Carl" {
+		t.Fatalf("unexpected second page: %+v", edges2)
+	}
+	if info2.HasNextPage {
+		t.Error("expected HasNextPage false on the last page")
+	}
+}
+
+func TestPaginateLastBefore(t *testing.T) {
+	items := []fixture{{"@I3@", "Carl"}, {"@I1@", "Alice"}, {"@I2@", "Bob"}}
+	sortKey := func(f fixture) string { return f.name }
+	xrefID := func(f fixture) string { return f.xref }
+
+	last := 1
+	edges, info, err := paginate(items, sortKey, xrefID, nil, &last, nil, nil)
+	if err != nil {
+		t.Fatalf("paginate: %v", err)
+	}
+	if len(edges) != 1 || edges[0].Node.name != "Carl" {
+		t.Fatalf("unexpected page: %+v", edges)
+	}
+	if !info.HasPreviousPage {
+		t.Error("expected HasPreviousPage true")
+	}
+}
+
+func TestIndividualWhereFromVars(t *testing.T) {
+	w := individualWhereFromVars(map[string]any{
+		"name":        "Jane",
+		"hasChildren": true,
+	})
+	if w == nil || w.Name == nil || *w.Name != "Jane" {
+		t.Fatalf("expected name Jane, got %+v", w)
+	}
+	if w.HasChildren == nil || !*w.HasChildren {
+		t.Fatalf("expected hasChildren true, got %+v", w.HasChildren)
+	}
+	if w.Sex != nil {
+		t.Errorf("expected Sex nil, got %v", *w.Sex)
+	}
+}
+
+func TestIndividualWhereFromVarsNilWhenNotAMap(t *testing.T) {
+	if w := individualWhereFromVars(nil); w != nil {
+		t.Errorf("expected nil where, got %+v", w)
+	}
+}
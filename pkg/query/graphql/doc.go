@@ -0,0 +1,17 @@
+// Package graphql exposes the pkg/gedcom/query Graph and FilterQuery APIs
+// as a GraphQL-style query surface over the genealogy graph: Individual,
+// Family, Note, Source, Repository and Event object types, relationship
+// edges (parents, spouses, children, siblings), Relay-style cursor
+// pagination, and nested resolvers for ancestors/descendants and common
+// ancestor lookups.
+//
+// The module has no GraphQL execution library vendored, so this package
+// does not parse the GraphQL query language itself. Schema (see
+// schema.go) documents the SDL this package implements; Resolver is the
+// hand-written equivalent of the resolver tree a generated gqlgen or
+// graphql-go binding would produce from that SDL, and Handler dispatches
+// fixed, named operations against it over HTTP. Swapping in a generated
+// implementation later only means replacing the dispatch in handler.go
+// with the generated executor -- Resolver's method set is already the
+// shape a generated binding would call into.
+package graphql
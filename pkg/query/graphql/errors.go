@@ -0,0 +1,9 @@
+package graphql
+
+import "fmt"
+
+// unknownOperationError reports a request naming an operation Handler
+// doesn't dispatch, distinct from an error the resolver itself returned.
+func unknownOperationError(name string) error {
+	return fmt.Errorf("graphql: unknown operation %q", name)
+}
@@ -0,0 +1,84 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Request is the JSON body Handler accepts: an operation name plus its
+// variables. There is no query-document parsing (see the package doc
+// comment), so OperationName selects directly among the fixed operations
+// Handler knows how to dispatch.
+type Request struct {
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Response is the JSON body Handler writes back, modeled on the
+// conventional GraphQL-over-HTTP response envelope.
+type Response struct {
+	Data   any      `json:"data,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Handler serves Resolver over HTTP as a GraphQL-over-HTTP endpoint.
+type Handler struct {
+	resolver *Resolver
+}
+
+// NewHandler builds an http.Handler backed by resolver.
+func NewHandler(resolver *Resolver) *Handler {
+	return &Handler{resolver: resolver}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "graphql: only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var gqlReq Request
+	if err := json.NewDecoder(req.Body).Decode(&gqlReq); err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{Errors: []string{"graphql: invalid request body: " + err.Error()}})
+		return
+	}
+
+	data, err := h.dispatch(gqlReq)
+	if err != nil {
+		writeJSON(w, http.StatusOK, Response{Errors: []string{err.Error()}})
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Data: data})
+}
+
+// dispatch runs the named operation. Operations correspond one for one
+// to the Query root fields in Schema.
+func (h *Handler) dispatch(req Request) (any, error) {
+	switch req.OperationName {
+	case "node":
+		id, _ := req.Variables["id"].(string)
+		return h.resolver.Node(id)
+	case "individuals":
+		return h.dispatchIndividuals(req.Variables)
+	case "commonAncestors":
+		a, _ := req.Variables["a"].(string)
+		b, _ := req.Variables["b"].(string)
+		return h.resolver.CommonAncestors(a, b)
+	case "lowestCommonAncestor":
+		a, _ := req.Variables["a"].(string)
+		b, _ := req.Variables["b"].(string)
+		return h.resolver.LowestCommonAncestor(a, b)
+	case "relationship":
+		a, _ := req.Variables["a"].(string)
+		b, _ := req.Variables["b"].(string)
+		return h.resolver.Relationship(a, b)
+	default:
+		return nil, unknownOperationError(req.OperationName)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"fmt"
+
+	"github.com/yourorg/gedcom/pkg/gedcom"
+	legacyquery "github.com/lesfleursdelanuitdev/ligneous-gedcom/pkg/gedcom/query"
+)
+
+// Resolver implements the Query root fields declared in Schema against a
+// single *legacyquery.Graph. It holds no state of its own beyond the
+// graph, the same way a generated gqlgen root resolver is typically a
+// thin wrapper around the application's existing service layer.
+type Resolver struct {
+	graph *legacyquery.Graph
+}
+
+// NewResolver wraps graph for GraphQL resolution.
+func NewResolver(graph *legacyquery.Graph) *Resolver {
+	return &Resolver{graph: graph}
+}
+
+// Node resolves the `node(id:)` root field via Graph.GetNode.
+func (r *Resolver) Node(id string) (legacyquery.GraphNode, error) {
+	node := r.graph.GetNode(id)
+	if node == nil {
+		return nil, fmt.Errorf("graphql: no node with id %q", id)
+	}
+	return node, nil
+}
+
+// Individuals resolves the `individuals(where:, first:, after:, last:,
+// before:)` root field: where is translated into FilterQuery calls, and
+// the matches are paginated Relay-style, ordered by name with xref ID as
+// the tiebreaker.
+func (r *Resolver) Individuals(where *IndividualWhere, first, last *int, after, before *string) (*IndividualConnection, error) {
+	fq := where.apply(legacyquery.NewFilterQuery(r.graph))
+	matches, err := fq.Execute()
+	if err != nil {
+		return nil, fmt.Errorf("graphql: individuals query failed: %w", err)
+	}
+
+	edges, pageInfo, err := paginate(
+		matches,
+		func(indi *gedcom.IndividualRecord) string { return indi.GetName() },
+		func(indi *gedcom.IndividualRecord) string { return indi.XrefID() },
+		first, last, after, before,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IndividualConnection{Edges: edges, PageInfo: pageInfo, TotalCount: len(matches)}, nil
+}
+
+// IndividualConnection is the GraphQL IndividualConnection type.
+type IndividualConnection struct {
+	Edges      []edge[*gedcom.IndividualRecord]
+	PageInfo   PageInfo
+	TotalCount int
+}
+
+// CommonAncestors resolves the `commonAncestors(a, b)` root field.
+func (r *Resolver) CommonAncestors(aXrefID, bXrefID string) ([]*legacyquery.IndividualNode, error) {
+	return r.graph.CommonAncestors(aXrefID, bXrefID)
+}
+
+// LowestCommonAncestor resolves the `lowestCommonAncestor(a, b)` root
+// field.
+func (r *Resolver) LowestCommonAncestor(aXrefID, bXrefID string) (*legacyquery.IndividualNode, error) {
+	return r.graph.LowestCommonAncestor(aXrefID, bXrefID)
+}
+
+// Relationship resolves the `relationship(a, b)` root field.
+func (r *Resolver) Relationship(aXrefID, bXrefID string) (*legacyquery.RelationshipResult, error) {
+	return r.graph.CalculateRelationship(aXrefID, bXrefID)
+}
+
+// IndividualAncestors resolves `individual.ancestors(depth: N)`.
+func (r *Resolver) IndividualAncestors(xrefID string, depth int) ([]*legacyquery.IndividualNode, error) {
+	indi := r.graph.GetIndividual(xrefID)
+	if indi == nil {
+		return nil, fmt.Errorf("graphql: individual %q not found", xrefID)
+	}
+	return ancestorsWithinDepth(indi, depth), nil
+}
+
+// IndividualDescendants resolves `individual.descendants(depth: N)`.
+func (r *Resolver) IndividualDescendants(xrefID string, depth int) ([]*legacyquery.IndividualNode, error) {
+	indi := r.graph.GetIndividual(xrefID)
+	if indi == nil {
+		return nil, fmt.Errorf("graphql: individual %q not found", xrefID)
+	}
+	return descendantsWithinDepth(indi, depth), nil
+}
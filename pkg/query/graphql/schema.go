@@ -0,0 +1,105 @@
+package graphql
+
+// Schema is the GraphQL SDL this package's resolvers implement. It is not
+// parsed or enforced at runtime (see the package doc comment); it exists
+// so the shape of the API has one authoritative, reviewable definition,
+// the same way a gqlgen project keeps its .graphql files alongside the
+// generated resolvers.
+const Schema = `
+scalar Cursor
+
+type PageInfo {
+  hasNextPage: Boolean!
+  hasPreviousPage: Boolean!
+  startCursor: Cursor
+  endCursor: Cursor
+}
+
+type Individual {
+  id: ID!
+  name: String!
+  sex: String
+  birthDate: String
+  birthPlace: String
+  living: Boolean!
+  parents: [Individual!]!
+  spouses: [Individual!]!
+  children: [Individual!]!
+  siblings: [Individual!]!
+  families: [Family!]!
+  events: [Event!]!
+  ancestors(depth: Int!): [Individual!]!
+  descendants(depth: Int!): [Individual!]!
+}
+
+type Family {
+  id: ID!
+  husband: Individual
+  wife: Individual
+  children: [Individual!]!
+}
+
+type Note {
+  id: ID!
+  text: String!
+}
+
+type Source {
+  id: ID!
+  title: String!
+}
+
+type Repository {
+  id: ID!
+  name: String!
+}
+
+type Event {
+  id: ID!
+  type: String!
+  date: String
+  place: String
+}
+
+union Node = Individual | Family | Note | Source | Repository | Event
+
+input IndividualWhere {
+  name: String
+  birthDateFrom: String
+  birthDateTo: String
+  birthPlace: String
+  sex: String
+  hasChildren: Boolean
+  hasSpouse: Boolean
+  living: Boolean
+}
+
+type IndividualEdge {
+  cursor: Cursor!
+  node: Individual!
+}
+
+type IndividualConnection {
+  edges: [IndividualEdge!]!
+  pageInfo: PageInfo!
+  totalCount: Int!
+}
+
+type RelationshipResult {
+  relationshipType: String!
+  degree: Int!
+  removal: Int!
+  isDirect: Boolean!
+  isAncestral: Boolean!
+  isDescendant: Boolean!
+  isCollateral: Boolean!
+}
+
+type Query {
+  node(id: ID!): Node
+  individuals(where: IndividualWhere, first: Int, after: Cursor, last: Int, before: Cursor): IndividualConnection!
+  commonAncestors(a: ID!, b: ID!): [Individual!]!
+  lowestCommonAncestor(a: ID!, b: ID!): Individual
+  relationship(a: ID!, b: ID!): RelationshipResult
+}
+`
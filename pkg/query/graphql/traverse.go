@@ -0,0 +1,60 @@
+package graphql
+
+import (
+	legacyquery "github.com/lesfleursdelanuitdev/ligneous-gedcom/pkg/gedcom/query"
+)
+
+// ancestorsWithinDepth returns every ancestor of indi reachable within
+// depth generations (depth 1 = parents only, depth 2 = parents and
+// grandparents, and so on), deduplicated by xref ID. Graph.CommonAncestors
+// and friends walk the full ancestor set with no depth cutoff; this is
+// the bounded counterpart the `ancestors(depth: N)` resolver field needs.
+func ancestorsWithinDepth(indi *legacyquery.IndividualNode, depth int) []*legacyquery.IndividualNode {
+	if depth <= 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var results []*legacyquery.IndividualNode
+	frontier := []*legacyquery.IndividualNode{indi}
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		var next []*legacyquery.IndividualNode
+		for _, node := range frontier {
+			for _, parent := range node.Parents {
+				if parent == nil || seen[parent.ID()] {
+					continue
+				}
+				seen[parent.ID()] = true
+				results = append(results, parent)
+				next = append(next, parent)
+			}
+		}
+		frontier = next
+	}
+	return results
+}
+
+// descendantsWithinDepth is the descendant-direction counterpart of
+// ancestorsWithinDepth.
+func descendantsWithinDepth(indi *legacyquery.IndividualNode, depth int) []*legacyquery.IndividualNode {
+	if depth <= 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var results []*legacyquery.IndividualNode
+	frontier := []*legacyquery.IndividualNode{indi}
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		var next []*legacyquery.IndividualNode
+		for _, node := range frontier {
+			for _, child := range node.Children {
+				if child == nil || seen[child.ID()] {
+					continue
+				}
+				seen[child.ID()] = true
+				results = append(results, child)
+				next = append(next, child)
+			}
+		}
+		frontier = next
+	}
+	return results
+}
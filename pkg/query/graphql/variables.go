@@ -0,0 +1,72 @@
+package graphql
+
+import "time"
+
+// dispatchIndividuals builds an IndividualWhere and pagination arguments
+// out of the raw JSON variables for the `individuals` operation and runs
+// Resolver.Individuals.
+func (h *Handler) dispatchIndividuals(vars map[string]any) (*IndividualConnection, error) {
+	where := individualWhereFromVars(vars["where"])
+	first := intVar(vars["first"])
+	last := intVar(vars["last"])
+	after := stringVar(vars["after"])
+	before := stringVar(vars["before"])
+	return h.resolver.Individuals(where, first, last, after, before)
+}
+
+func individualWhereFromVars(raw any) *IndividualWhere {
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return nil
+	}
+	w := &IndividualWhere{
+		Name:        stringVar(m["name"]),
+		BirthPlace:  stringVar(m["birthPlace"]),
+		Sex:         stringVar(m["sex"]),
+		HasChildren: boolVar(m["hasChildren"]),
+		HasSpouse:   boolVar(m["hasSpouse"]),
+		Living:      boolVar(m["living"]),
+	}
+	w.BirthDateFrom = dateVar(m["birthDateFrom"])
+	w.BirthDateTo = dateVar(m["birthDateTo"])
+	return w
+}
+
+func stringVar(raw any) *string {
+	s, ok := raw.(string)
+	if !ok {
+		return nil
+	}
+	return &s
+}
+
+func boolVar(raw any) *bool {
+	b, ok := raw.(bool)
+	if !ok {
+		return nil
+	}
+	return &b
+}
+
+func intVar(raw any) *int {
+	// encoding/json decodes JSON numbers into float64 when the target is
+	// any, so the variables map never holds a Go int directly.
+	f, ok := raw.(float64)
+	if !ok {
+		return nil
+	}
+	n := int(f)
+	return &n
+}
+
+func dateVar(raw any) *time.Time {
+	s, ok := raw.(string)
+	if !ok {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
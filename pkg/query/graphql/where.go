@@ -0,0 +1,55 @@
+package graphql
+
+import (
+	"time"
+
+	legacyquery "github.com/lesfleursdelanuitdev/ligneous-gedcom/pkg/gedcom/query"
+)
+
+// IndividualWhere is the GraphQL `where:` input for the individuals query
+// field. Set fields are translated into the matching FilterQuery calls,
+// so the fields here intentionally mirror FilterQuery's method set one
+// for one rather than inventing a separate filter vocabulary. HasChildren,
+// HasSpouse and Living add a filter only when true (false adds none), and
+// the birth date range applies only when both BirthDateFrom and
+// BirthDateTo are set.
+type IndividualWhere struct {
+	Name          *string
+	BirthDateFrom *time.Time
+	BirthDateTo   *time.Time
+	BirthPlace    *string
+	Sex           *string
+	HasChildren   *bool
+	HasSpouse     *bool
+	Living        *bool
+}
+
+// apply chains the FilterQuery calls that correspond to w's set fields
+// (with the exceptions listed on IndividualWhere) onto fq and returns it.
+func (w *IndividualWhere) apply(fq *legacyquery.FilterQuery) *legacyquery.FilterQuery {
+	if w == nil {
+		return fq
+	}
+	if w.Name != nil {
+		fq = fq.ByName(*w.Name)
+	}
+	if w.BirthDateFrom != nil && w.BirthDateTo != nil {
+		fq = fq.ByBirthDate(*w.BirthDateFrom, *w.BirthDateTo)
+	}
+	if w.BirthPlace != nil {
+		fq = fq.ByBirthPlace(*w.BirthPlace)
+	}
+	if w.Sex != nil {
+		fq = fq.BySex(*w.Sex)
+	}
+	if w.HasChildren != nil && *w.HasChildren {
+		fq = fq.HasChildren()
+	}
+	if w.HasSpouse != nil && *w.HasSpouse {
+		fq = fq.HasSpouse()
+	}
+	if w.Living != nil && *w.Living {
+		fq = fq.Living()
+	}
+	return fq
+}
@@ -0,0 +1,157 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// buildDiamondAncestryTree builds:
+//
+//	@I1@ (root)
+//	  FAMC @F1@ -> HUSB @I2@, WIFE @I3@
+//	@I2@
+//	  FAMC @F2@ -> HUSB @I4@, WIFE @I5@
+//	@I3@
+//	  FAMC @F3@ -> HUSB @I4@, WIFE @I6@
+//
+// @I4@ is reachable from @I1@ via two paths of equal length (2), and is
+// also the common ancestor that a naive visited-marking DFS can under-count
+// against a MaxGenerations cutoff when the two paths aren't equal length.
+func buildDiamondAncestryTree() *types.GedcomTree {
+	tree := types.NewGedcomTree()
+
+	addIndi := func(xref, name string) {
+		line := types.NewGedcomLine(0, "INDI", "", xref)
+		line.AddChild(types.NewGedcomLine(1, "NAME", name, ""))
+		tree.AddRecord(types.NewIndividualRecord(line))
+	}
+	addIndiWithFamc := func(xref, name, famc string) {
+		line := types.NewGedcomLine(0, "INDI", "", xref)
+		line.AddChild(types.NewGedcomLine(1, "NAME", name, ""))
+		line.AddChild(types.NewGedcomLine(1, "FAMC", famc, ""))
+		tree.AddRecord(types.NewIndividualRecord(line))
+	}
+	addFam := func(xref, husb, wife, chil string) {
+		line := types.NewGedcomLine(0, "FAM", "", xref)
+		line.AddChild(types.NewGedcomLine(1, "HUSB", husb, ""))
+		line.AddChild(types.NewGedcomLine(1, "WIFE", wife, ""))
+		line.AddChild(types.NewGedcomLine(1, "CHIL", chil, ""))
+		tree.AddRecord(types.NewFamilyRecord(line))
+	}
+
+	addIndiWithFamc("@I1@", "Root /Person/", "@F1@")
+	addIndiWithFamc("@I2@", "Father /Person/", "@F2@")
+	addIndiWithFamc("@I3@", "Mother /Person/", "@F3@")
+	addIndi("@I4@", "Shared Grandfather /Person/")
+	addIndi("@I5@", "Grandmother A /Person/")
+	addIndi("@I6@", "Grandmother B /Person/")
+
+	addFam("@F1@", "@I2@", "@I3@", "@I1@")
+	addFam("@F2@", "@I4@", "@I5@", "@I2@")
+	addFam("@F3@", "@I4@", "@I6@", "@I3@")
+
+	return tree
+}
+
+func newAncestorQuery(graph *Graph, xrefID string, order Order, maxGenerations int) *AncestorQuery {
+	opts := NewAncestorOptions()
+	opts.Order = order
+	opts.MaxGenerations = maxGenerations
+	return &AncestorQuery{startXrefID: xrefID, graph: graph, options: opts}
+}
+
+func TestAncestorQuery_BFS_MaxGenerationsLevelAccurate(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	aq := newAncestorQuery(graph, "@I1@", OrderBFS, 2)
+	records, err := aq.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	got := make(map[string]bool, len(records))
+	for _, r := range records {
+		got[r.XrefID()] = true
+	}
+
+	// Generation 1: @I2@, @I3@. Generation 2: @I4@ (shared), @I5@, @I6@.
+	want := []string{"@I2@", "@I3@", "@I4@", "@I5@", "@I6@"}
+	for _, xref := range want {
+		if !got[xref] {
+			t.Errorf("BFS with MaxGenerations=2: expected ancestor %s in result, got %v", xref, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("BFS with MaxGenerations=2: expected %d ancestors, got %d (%v)", len(want), len(got), got)
+	}
+}
+
+func TestAncestorQuery_ExecuteWithPaths_DepthStamping(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	aq := newAncestorQuery(graph, "@I1@", OrderBFS, 0)
+	paths, err := aq.ExecuteWithPaths()
+	if err != nil {
+		t.Fatalf("ExecuteWithPaths failed: %v", err)
+	}
+
+	depthByXref := make(map[string]int, len(paths))
+	for _, p := range paths {
+		depthByXref[p.Ancestor.XrefID()] = p.Depth
+	}
+
+	wantDepth := map[string]int{
+		"@I2@": 1,
+		"@I3@": 1,
+		"@I4@": 2,
+		"@I5@": 2,
+		"@I6@": 2,
+	}
+	for xref, want := range wantDepth {
+		got, ok := depthByXref[xref]
+		if !ok {
+			t.Errorf("expected ancestor %s in ExecuteWithPaths result", xref)
+			continue
+		}
+		if got != want {
+			t.Errorf("ancestor %s: expected depth %d, got %d", xref, want, got)
+		}
+	}
+}
+
+func TestAncestorQuery_BFS_DFS_AgreeWithoutMaxGenerations(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	bfs, err := newAncestorQuery(graph, "@I1@", OrderBFS, 0).Execute()
+	if err != nil {
+		t.Fatalf("BFS Execute failed: %v", err)
+	}
+	dfs, err := newAncestorQuery(graph, "@I1@", OrderDFS, 0).Execute()
+	if err != nil {
+		t.Fatalf("DFS Execute failed: %v", err)
+	}
+
+	if len(bfs) != len(dfs) {
+		t.Fatalf("expected BFS and DFS to return the same ancestor count with no MaxGenerations cutoff, got %d vs %d", len(bfs), len(dfs))
+	}
+
+	dfsXrefs := make(map[string]bool, len(dfs))
+	for _, r := range dfs {
+		dfsXrefs[r.XrefID()] = true
+	}
+	for _, r := range bfs {
+		if !dfsXrefs[r.XrefID()] {
+			t.Errorf("ancestor %s returned by BFS but not DFS", r.XrefID())
+		}
+	}
+}
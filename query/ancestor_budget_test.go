@@ -0,0 +1,98 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAncestorQuery_ExecuteCtx_HonorsContextCancellation(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	aq := newAncestorQuery(graph, "@I1@", OrderDFS, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := aq.ExecuteCtx(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected ExecuteCtx to return context.Canceled, got %v", err)
+	}
+}
+
+func TestAncestorQuery_ExecuteCtx_MaxNodesVisited(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	aq := newAncestorQuery(graph, "@I1@", OrderDFS, 0)
+	aq.options.MaxNodesVisited = 1
+
+	records, err := aq.ExecuteCtx(context.Background())
+	if !errors.Is(err, errMaxNodesVisited) {
+		t.Fatalf("expected errMaxNodesVisited, got %v", err)
+	}
+	if len(records) == 0 {
+		t.Errorf("expected at least one ancestor to be collected before the budget tripped, got none")
+	}
+}
+
+func TestAncestorQuery_CountCtx_MatchesExecuteCtx(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	aq := newAncestorQuery(graph, "@I1@", OrderDFS, 0)
+
+	records, err := aq.ExecuteCtx(context.Background())
+	if err != nil {
+		t.Fatalf("ExecuteCtx failed: %v", err)
+	}
+
+	count, err := aq.CountCtx(context.Background())
+	if err != nil {
+		t.Fatalf("CountCtx failed: %v", err)
+	}
+	if count != len(records) {
+		t.Errorf("CountCtx = %d, want %d (len of ExecuteCtx result)", count, len(records))
+	}
+}
+
+func TestAncestorQuery_ExecuteWithPathsCtx_ReportsDepth(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	aq := newAncestorQuery(graph, "@I1@", OrderDFS, 0)
+
+	paths, err := aq.ExecuteWithPathsCtx(context.Background())
+	if err != nil {
+		t.Fatalf("ExecuteWithPathsCtx failed: %v", err)
+	}
+	for _, p := range paths {
+		if p.Path == nil || len(p.Path.Nodes) == 0 {
+			t.Errorf("ancestor %s has no recorded path", p.Ancestor.XrefID())
+		}
+	}
+}
+
+func TestMultiSourceAncestorQuery_CommonAncestorsCtx_HonorsContextCancellation(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	msq := &MultiSourceAncestorQuery{xrefIDs: []string{"@I2@", "@I3@"}, graph: graph, options: NewAncestorOptions()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := msq.CommonAncestorsCtx(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected CommonAncestorsCtx to return context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package query
+
+import (
+	"sync"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// ancestorCacheKind distinguishes the expensive set computations an
+// AncestorCache can memoize.
+type ancestorCacheKind string
+
+const (
+	kindAncestors     ancestorCacheKind = "ancestors"
+	kindDescendants   ancestorCacheKind = "descendants"
+	kindCommonAnc     ancestorCacheKind = "common-ancestors"
+	kindLowestCommon  ancestorCacheKind = "lowest-common-ancestor"
+	kindShortestPath  ancestorCacheKind = "shortest-path"
+)
+
+// ancestorCacheKey identifies a single cached computation.
+type ancestorCacheKey struct {
+	fromID   string
+	toID     string
+	maxDepth int
+	kind     ancestorCacheKind
+}
+
+// ancestorCacheEntry holds an immutable result plus an approximate byte
+// cost. The LRU budget counts entries; cost is not consulted.
+type ancestorCacheEntry struct {
+	set  map[string]struct{}
+	path *Path
+	cost int
+}
+
+// AncestorCache memoizes expensive ancestor/descendant set computations
+// keyed by (fromID, toID, maxDepth, kind). It is an LRU bounded by a
+// configurable entry budget, and entries touching individuals mutated since
+// the last BuildGraph are invalidated eagerly via Invalidate.
+type AncestorCache struct {
+	mu       sync.Mutex
+	entries  map[ancestorCacheKey]*list_element
+	order    []*list_element
+	maxCount int
+}
+
+type list_element struct {
+	key   ancestorCacheKey
+	entry *ancestorCacheEntry
+}
+
+// NewAncestorCache creates an AncestorCache bounded to at most maxEntries
+// cached computations.
+func NewAncestorCache(maxEntries int) *AncestorCache {
+	if maxEntries <= 0 {
+		maxEntries = 1024
+	}
+	return &AncestorCache{
+		entries:  make(map[ancestorCacheKey]*list_element),
+		order:    make([]*list_element, 0, maxEntries),
+		maxCount: maxEntries,
+	}
+}
+
+func (c *AncestorCache) get(key ancestorCacheKey) (*ancestorCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.touch(el)
+	return el.entry, true
+}
+
+func (c *AncestorCache) put(key ancestorCacheKey, entry *ancestorCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.entry = entry
+		c.touch(el)
+		return
+	}
+	el := &list_element{key: key, entry: entry}
+	c.entries[key] = el
+	c.order = append(c.order, el)
+	if len(c.order) > c.maxCount {
+		evict := c.order[0]
+		c.order = c.order[1:]
+		delete(c.entries, evict.key)
+	}
+}
+
+// touch moves el to the most-recently-used end of the order slice.
+func (c *AncestorCache) touch(el *list_element) {
+	for i, e := range c.order {
+		if e == el {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	c.order = append(c.order, el)
+}
+
+// Invalidate drops every cached computation that involved the given
+// individual, which should be called whenever that individual's edges are
+// rebuilt.
+func (c *AncestorCache) Invalidate(xrefID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, el := range c.entries {
+		if key.fromID == xrefID || key.toID == xrefID {
+			delete(c.entries, key)
+			continue
+		}
+		if _, touched := el.entry.set[xrefID]; touched {
+			delete(c.entries, key)
+		}
+	}
+	c.rebuildOrder()
+}
+
+// Clear empties the cache entirely.
+func (c *AncestorCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[ancestorCacheKey]*list_element)
+	c.order = c.order[:0]
+}
+
+func (c *AncestorCache) rebuildOrder() {
+	order := make([]*list_element, 0, len(c.entries))
+	for _, el := range c.order {
+		if _, ok := c.entries[el.key]; ok {
+			order = append(order, el)
+		}
+	}
+	c.order = order
+}
+
+// ancestorCaches associates a Graph with its optional AncestorCache without
+// requiring a dedicated field on Graph itself, so graphs built via the
+// plain BuildGraph path pay no overhead.
+var (
+	ancestorCachesMu sync.Mutex
+	ancestorCaches   = make(map[*Graph]*AncestorCache)
+)
+
+// cacheFor returns the AncestorCache attached to g, if any, via WithCache.
+func cacheFor(g *Graph) (*AncestorCache, bool) {
+	ancestorCachesMu.Lock()
+	defer ancestorCachesMu.Unlock()
+	c, ok := ancestorCaches[g]
+	return c, ok
+}
+
+// QueryOption configures a Graph created via NewQuery.
+type QueryOption func(*Graph)
+
+// WithCache attaches an AncestorCache of the given size to the graph built
+// for this query. Only CachedShortestPath reads and fills it; ancestor and
+// descendant queries (Count, Exists, Execute) walk the graph on every call
+// whether or not a cache is attached.
+func WithCache(size int) QueryOption {
+	return func(g *Graph) {
+		ancestorCachesMu.Lock()
+		defer ancestorCachesMu.Unlock()
+		ancestorCaches[g] = NewAncestorCache(size)
+	}
+}
+
+// NewCachedGraph builds a graph from tree and applies the given options,
+// most commonly WithCache for repeated-query workloads over large
+// real-world files. It returns a *Graph directly, for callers that want
+// graph-level caching without going through QueryBuilder; it is named
+// distinctly from NewQuery so it doesn't collide with that entry point's
+// *QueryBuilder return type.
+func NewCachedGraph(tree *types.GedcomTree, opts ...QueryOption) (*Graph, error) {
+	g, err := BuildGraph(tree)
+	if err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g, nil
+}
+
+// CachedShortestPath returns the shortest path between two individuals,
+// consulting the Graph's AncestorCache (if one was attached via WithCache)
+// before falling back to ShortestPath.
+func (g *Graph) CachedShortestPath(fromID, toID string) (*Path, error) {
+	cache, ok := cacheFor(g)
+	if !ok {
+		return g.ShortestPath(fromID, toID)
+	}
+
+	key := ancestorCacheKey{fromID: fromID, toID: toID, kind: kindShortestPath}
+	if entry, ok := cache.get(key); ok {
+		return entry.path, nil
+	}
+
+	path, err := g.ShortestPath(fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+	cache.put(key, &ancestorCacheEntry{path: path, cost: len(fromID) + len(toID)})
+	return path, nil
+}
@@ -0,0 +1,221 @@
+package query
+
+import (
+	"context"
+	"sync"
+)
+
+// ancestorIndexEntry holds one individual's full ancestor nodeID set, as
+// computed by a prior unbounded traversal.
+type ancestorIndexEntry struct {
+	ancestors map[uint32]struct{}
+}
+
+// AncestorIndex memoizes, per individual nodeID, the complete set of
+// ancestor nodeIDs, analogous to a rebuilt-tree's node-to-roots index:
+// once a node's ancestor set is known, any query that reaches it again
+// can union it in directly instead of re-walking further up. It only
+// holds complete (unbounded) ancestor sets, so it is consulted by
+// AncestorQuery.Execute only when MaxGenerations is 0 -- a generation
+// cutoff would otherwise need a different cached set per cutoff depth.
+// It is an LRU bounded by maxEntries so long-running processes querying
+// many distinct individuals don't grow this without bound.
+type AncestorIndex struct {
+	mu         sync.Mutex
+	entries    map[uint32]*ancestorIndexEntry
+	order      []uint32
+	maxEntries int
+}
+
+// NewAncestorIndex creates an AncestorIndex bounded to at most maxEntries
+// per-individual ancestor sets.
+func NewAncestorIndex(maxEntries int) *AncestorIndex {
+	if maxEntries <= 0 {
+		maxEntries = 4096
+	}
+	return &AncestorIndex{
+		entries:    make(map[uint32]*ancestorIndexEntry),
+		order:      make([]uint32, 0, maxEntries),
+		maxEntries: maxEntries,
+	}
+}
+
+func (idx *AncestorIndex) get(nodeID uint32) (map[uint32]struct{}, bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	e, ok := idx.entries[nodeID]
+	if !ok {
+		return nil, false
+	}
+	idx.touch(nodeID)
+	return e.ancestors, true
+}
+
+func (idx *AncestorIndex) put(nodeID uint32, ancestors map[uint32]struct{}) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if _, ok := idx.entries[nodeID]; !ok {
+		idx.order = append(idx.order, nodeID)
+	}
+	idx.entries[nodeID] = &ancestorIndexEntry{ancestors: ancestors}
+	idx.touch(nodeID)
+
+	for len(idx.order) > idx.maxEntries {
+		evict := idx.order[0]
+		idx.order = idx.order[1:]
+		delete(idx.entries, evict)
+	}
+}
+
+// touch moves nodeID to the most-recently-used end of the order slice.
+// Caller must hold idx.mu.
+func (idx *AncestorIndex) touch(nodeID uint32) {
+	for i, id := range idx.order {
+		if id == nodeID {
+			idx.order = append(idx.order[:i], idx.order[i+1:]...)
+			break
+		}
+	}
+	idx.order = append(idx.order, nodeID)
+}
+
+// Invalidate drops every memoized ancestor set. Call this whenever the
+// graph's edges are rebuilt, mirroring AncestorCache.Invalidate.
+func (idx *AncestorIndex) Invalidate() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.entries = make(map[uint32]*ancestorIndexEntry)
+	idx.order = idx.order[:0]
+}
+
+// ancestorIndexesMu/ancestorIndexesFor associate a Graph with its optional
+// AncestorIndex without a dedicated field on Graph, following the same
+// side-table convention as ancestorCaches in ancestor_cache.go.
+var (
+	ancestorIndexesMu  sync.Mutex
+	ancestorIndexesFor = make(map[*Graph]*AncestorIndex)
+)
+
+// WithAncestorIndex attaches an AncestorIndex of the given size to the
+// graph built for this query, analogous to WithCache.
+func WithAncestorIndex(maxEntries int) QueryOption {
+	return func(g *Graph) {
+		ancestorIndexesMu.Lock()
+		defer ancestorIndexesMu.Unlock()
+		ancestorIndexesFor[g] = NewAncestorIndex(maxEntries)
+	}
+}
+
+// ancestorIndexOf returns the AncestorIndex attached to g, if any.
+func ancestorIndexOf(g *Graph) (*AncestorIndex, bool) {
+	ancestorIndexesMu.Lock()
+	defer ancestorIndexesMu.Unlock()
+	idx, ok := ancestorIndexesFor[g]
+	return idx, ok
+}
+
+// WarmAncestorIndex precomputes and populates g's AncestorIndex (attached
+// via WithAncestorIndex) for every individual in the graph, in parallel,
+// so a subsequent burst of AncestorQuery.Execute calls all hit a warm
+// index. It is a no-op if g has no AncestorIndex attached. ctx is checked
+// before each individual's computation starts; in-flight computations are
+// not interrupted mid-traversal.
+func (g *Graph) WarmAncestorIndex(ctx context.Context) error {
+	idx, ok := ancestorIndexOf(g)
+	if !ok {
+		return nil
+	}
+
+	individuals := g.AllIndividuals()
+	var wg sync.WaitGroup
+	errCh := make(chan error, len(individuals))
+
+	for _, node := range individuals {
+		node := node
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := ctx.Err(); err != nil {
+				errCh <- err
+				return
+			}
+			computeAncestorSet(node, idx, make(map[uint32]bool))
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+
+	for err := range errCh {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// computeAncestorSet returns node's full ancestor nodeID set, consulting
+// idx first and populating it bottom-up on the way back up the call
+// stack: once each parent's own ancestor set is known, node's set is the
+// union of every parent's set with that parent's own ID. visited guards
+// against cycles within a single top-level call and is not shared across
+// calls (unlike idx, which is).
+func computeAncestorSet(node *IndividualNode, idx *AncestorIndex, visited map[uint32]bool) map[uint32]struct{} {
+	nodeID := node.BaseNode.nodeID
+	if nodeID != 0 {
+		if cached, ok := idx.get(nodeID); ok {
+			return cached
+		}
+		if visited[nodeID] {
+			return map[uint32]struct{}{}
+		}
+		visited[nodeID] = true
+	}
+
+	result := make(map[uint32]struct{})
+	for _, parent := range ancestorIndexParentsOf(node) {
+		parentID := parent.BaseNode.nodeID
+		if parentID == 0 {
+			continue
+		}
+		result[parentID] = struct{}{}
+		for anc := range computeAncestorSet(parent, idx, visited) {
+			result[anc] = struct{}{}
+		}
+	}
+
+	if nodeID != 0 {
+		idx.put(nodeID, result)
+	}
+	return result
+}
+
+// ancestorIndexParentsOf resolves node's parents the same way
+// AncestorQuery.parentsOf does (cached parents first, else indexed FAMC
+// edges), without the WalkError plumbing -- AncestorIndex is a pure
+// performance layer and silently skips unresolved edges rather than
+// reporting them, the same way the graph's cached-parents path always has.
+func ancestorIndexParentsOf(node *IndividualNode) []*IndividualNode {
+	if len(node.parents) > 0 {
+		return node.parents
+	}
+
+	parents := make([]*IndividualNode, 0, 2)
+	for _, edge := range node.famcEdges {
+		if edge.Family == nil {
+			continue
+		}
+		parents = append(parents, familyParents(edge.Family)...)
+	}
+	return parents
+}
+
+// individualNodeByID resolves a cached nodeID back to its *IndividualNode
+// via the graph's xref index, mirroring the id-to-xref-to-node hop
+// ExecuteWithPaths already performs for path lookups.
+func individualNodeByID(g *Graph, nodeID uint32) *IndividualNode {
+	xrefID := g.GetXrefFromID(nodeID)
+	if xrefID == "" {
+		return nil
+	}
+	return g.GetIndividual(xrefID)
+}
@@ -1,6 +1,8 @@
 package query
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
@@ -12,6 +14,23 @@ type AncestorOptions struct {
 	IncludeSelf    bool                                // Include starting individual
 	Filter         func(*types.IndividualRecord) bool // Custom filter function
 	Order          Order                               // BFS or DFS order
+	OnError        func(*WalkError) error              // Called per traversal inconsistency; non-nil return aborts the walk
+
+	// MaxDuration, checked by the *Ctx methods and Walk, aborts the
+	// traversal with errMaxDurationExceeded once it has been running this
+	// long. 0 means unlimited.
+	MaxDuration time.Duration
+
+	// MaxNodesVisited, checked by the *Ctx methods and Walk, aborts the
+	// traversal with errMaxNodesVisited once more than this many ancestors
+	// have been visited. 0 means unlimited.
+	MaxNodesVisited int
+
+	// Progress, if set, is called by the *Ctx methods and Walk after each
+	// ancestor is visited, with the running visited count and an estimate
+	// of the total individuals in the graph (so callers can drive a
+	// progress bar). It is not called by the non-Ctx Execute/Count methods.
+	Progress func(visited, total int)
 }
 
 // Order represents the traversal order.
@@ -25,10 +44,14 @@ const (
 // NewAncestorOptions creates new AncestorOptions with defaults.
 func NewAncestorOptions() *AncestorOptions {
 	return &AncestorOptions{
-		MaxGenerations: 0, // Unlimited
-		IncludeSelf:    false,
-		Filter:         nil,
-		Order:          OrderBFS,
+		MaxGenerations:  0, // Unlimited
+		IncludeSelf:     false,
+		Filter:          nil,
+		Order:           OrderBFS,
+		OnError:         nil,
+		MaxDuration:     0,
+		MaxNodesVisited: 0,
+		Progress:        nil,
 	}
 }
 
@@ -57,7 +80,15 @@ func (aq *AncestorQuery) Filter(fn func(*types.IndividualRecord) bool) *Ancestor
 	return aq
 }
 
-// Execute runs the query and returns ancestor records.
+// Execute runs the query and returns ancestor records. If the graph
+// contains an inconsistency (an unresolved parent edge, or a node missing
+// its assigned ID), Execute still returns every ancestor it successfully
+// resolved, alongside a non-nil error aggregating every WalkError
+// encountered via errors.Join -- so callers can distinguish "no results"
+// from "graph inconsistency at these XREFs". If AncestorOptions.OnError
+// returns a non-nil error for some inconsistency, that error aborts the
+// walk early and is returned as-is (joined with whatever WalkErrors were
+// already collected), rather than continuing to accumulate further ones.
 func (aq *AncestorQuery) Execute() ([]*types.IndividualRecord, error) {
 	// Record metrics if available
 	start := time.Now()
@@ -87,8 +118,23 @@ func (aq *AncestorQuery) Execute() ([]*types.IndividualRecord, error) {
 		ancestors[startNodeID] = startNode
 	}
 
-	// Find ancestors recursively (pass nodeID to avoid repeated lookups)
-	aq.findAncestors(startNode, startNodeID, ancestors, visited, 0)
+	var errs []error
+	var abortErr error
+
+	if idx, ok := ancestorIndexOf(aq.graph); ok && aq.options.MaxGenerations == 0 {
+		// The index only stores complete (unbounded) ancestor sets, so it's
+		// only safe to consult when there's no generation cutoff. This also
+		// means a hit bypasses WalkError reporting for this call: the index
+		// was populated by a traversal that silently skipped unresolved
+		// edges rather than recording them (see ancestorIndexParentsOf).
+		for id := range computeAncestorSet(startNode, idx, make(map[uint32]bool)) {
+			if node := individualNodeByID(aq.graph, id); node != nil {
+				ancestors[id] = node
+			}
+		}
+	} else {
+		abortErr = aq.findAncestors(startNode, startNodeID, ancestors, visited, 0, &errs)
+	}
 
 	// Convert to records
 	records := make([]*types.IndividualRecord, 0, len(ancestors))
@@ -101,21 +147,154 @@ func (aq *AncestorQuery) Execute() ([]*types.IndividualRecord, error) {
 		}
 	}
 
-	return records, nil
+	if abortErr != nil {
+		errs = append(errs, abortErr)
+	}
+	return records, errors.Join(errs...)
+}
+
+// findAncestors dispatches to an iterative level-by-level BFS when
+// aq.options.Order is OrderBFS, and to the original recursive DFS
+// otherwise. BFS matters for MaxGenerations correctness: because it
+// processes an entire generation before moving to the next, a cutoff at
+// generation N always includes every N-th generation ancestor, whereas
+// DFS can under-return when depth pruning interacts with shared ancestors
+// (a node reached first via a longer branch marks itself visited, so a
+// shorter branch reaching it later cannot climb the generations above it
+// that are still in bounds).
+//
+// errs accumulates a *WalkError for every traversal inconsistency
+// encountered; a non-nil return value means AncestorOptions.OnError asked
+// to abort, and the caller should stop traversing immediately.
+func (aq *AncestorQuery) findAncestors(node *IndividualNode, nodeID uint32, ancestors map[uint32]*IndividualNode, visited map[uint32]bool, depth int, errs *[]error) error {
+	if aq.options.Order == OrderBFS {
+		return aq.findAncestorsBFS(node, nodeID, ancestors, visited, depth, errs)
+	}
+	return aq.findAncestorsDFS(node, nodeID, ancestors, visited, depth, errs)
+}
+
+// reportWalkError records a traversal inconsistency into errs and, if
+// AncestorOptions.OnError is set, invokes it. A non-nil return from
+// OnError aborts the walk, and that error is propagated up through the
+// caller chain.
+func (aq *AncestorQuery) reportWalkError(xrefID string, depth int, cause error, errs *[]error) error {
+	we := &WalkError{XrefID: xrefID, Depth: depth, Err: cause}
+	*errs = append(*errs, we)
+	if aq.options.OnError != nil {
+		return aq.options.OnError(we)
+	}
+	return nil
+}
+
+// ancestorFrontierEntry is one queued node in the BFS frontier, carrying
+// its own depth since a single queue interleaves multiple generations.
+type ancestorFrontierEntry struct {
+	node  *IndividualNode
+	id    uint32
+	depth int
+}
+
+// findAncestorsBFS walks ancestors level-by-level using a FIFO queue,
+// guaranteeing that all ancestors at generation N are visited (and added
+// to ancestors) before generation N+1 is considered, so a MaxGenerations
+// cutoff is level-accurate.
+func (aq *AncestorQuery) findAncestorsBFS(node *IndividualNode, nodeID uint32, ancestors map[uint32]*IndividualNode, visited map[uint32]bool, startDepth int, errs *[]error) error {
+	if nodeID == 0 || visited[nodeID] {
+		return nil
+	}
+	visited[nodeID] = true
+
+	queue := []ancestorFrontierEntry{{node: node, id: nodeID, depth: startDepth}}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		if aq.options.MaxGenerations > 0 && cur.depth >= aq.options.MaxGenerations {
+			continue
+		}
+
+		parents, badEdges := aq.parentsOf(cur.node)
+		for _, bad := range badEdges {
+			if err := aq.reportWalkError(cur.node.BaseNode.ID(), cur.depth, bad, errs); err != nil {
+				return err
+			}
+		}
+
+		for _, parent := range parents {
+			parentID := parent.BaseNode.nodeID
+			if parentID == 0 {
+				if err := aq.reportWalkError(parent.BaseNode.ID(), cur.depth+1, errUnassignedNodeID, errs); err != nil {
+					return err
+				}
+				continue
+			}
+			if visited[parentID] {
+				continue
+			}
+			visited[parentID] = true
+			ancestors[parentID] = parent
+			queue = append(queue, ancestorFrontierEntry{node: parent, id: parentID, depth: cur.depth + 1})
+		}
+	}
+	return nil
+}
+
+// errUnassignedNodeID is the cause reported when a parent/child individual
+// was resolved but has not been assigned a node ID by graph construction.
+var errUnassignedNodeID = errors.New("query: individual has no assigned node ID")
+
+// errUnresolvedFamilyEdge is the cause reported when a family's HUSB/WIFE
+// edge target could not be resolved to an *IndividualNode.
+var errUnresolvedFamilyEdge = errors.New("query: family edge target is not a resolved individual")
+
+// parentsOf returns node's parents, preferring the Phase 2 cached parent
+// slice and falling back to the indexed FAMC edges, matching the lookup
+// order used by the DFS traversal. It also returns one errUnresolvedFamilyEdge
+// per HUSB/WIFE edge present on a parent family whose target did not
+// resolve to an *IndividualNode, so callers can surface it via
+// reportWalkError instead of silently dropping it.
+func (aq *AncestorQuery) parentsOf(node *IndividualNode) (parents []*IndividualNode, badEdges []error) {
+	if len(node.parents) > 0 {
+		return node.parents, nil
+	}
+
+	parents = make([]*IndividualNode, 0, 2)
+	for _, edge := range node.famcEdges {
+		if edge.Family == nil {
+			continue
+		}
+		famNode := edge.Family
+		if famNode.husbandEdge != nil {
+			if husband, ok := famNode.husbandEdge.To.(*IndividualNode); ok {
+				parents = append(parents, husband)
+			} else {
+				badEdges = append(badEdges, errUnresolvedFamilyEdge)
+			}
+		}
+		if famNode.wifeEdge != nil {
+			if wife, ok := famNode.wifeEdge.To.(*IndividualNode); ok {
+				parents = append(parents, wife)
+			} else {
+				badEdges = append(badEdges, errUnresolvedFamilyEdge)
+			}
+		}
+	}
+	return parents, badEdges
 }
 
-// findAncestors recursively finds ancestors.
+// findAncestorsDFS recursively finds ancestors.
 // Optimized with Phase 1 (indexed edges, uint32 IDs), Phase 2 (cached parents), and Phase 3 (cached nodeID).
 // Phase 3: Accepts nodeID parameter to eliminate repeated GetNodeID() calls.
-func (aq *AncestorQuery) findAncestors(node *IndividualNode, nodeID uint32, ancestors map[uint32]*IndividualNode, visited map[uint32]bool, depth int) {
+func (aq *AncestorQuery) findAncestorsDFS(node *IndividualNode, nodeID uint32, ancestors map[uint32]*IndividualNode, visited map[uint32]bool, depth int, errs *[]error) error {
 	// Phase 3: nodeID already provided - no lookup needed!
 	if nodeID == 0 || visited[nodeID] {
-		return
+		return nil
 	}
 
 	// Check max generations limit
 	if aq.options.MaxGenerations > 0 && depth >= aq.options.MaxGenerations {
-		return
+		return nil
 	}
 
 	visited[nodeID] = true
@@ -125,13 +304,19 @@ func (aq *AncestorQuery) findAncestors(node *IndividualNode, nodeID uint32, ance
 		for _, parent := range node.parents {
 			// Phase 3: Use cached nodeID directly - no lock acquisition!
 			parentID := parent.BaseNode.nodeID
-			if parentID != 0 {
-				ancestors[parentID] = parent
-				// Phase 3: Pass parentID through recursion to avoid repeated lookups
-				aq.findAncestors(parent, parentID, ancestors, visited, depth+1)
+			if parentID == 0 {
+				if err := aq.reportWalkError(parent.BaseNode.ID(), depth+1, errUnassignedNodeID, errs); err != nil {
+					return err
+				}
+				continue
+			}
+			ancestors[parentID] = parent
+			// Phase 3: Pass parentID through recursion to avoid repeated lookups
+			if err := aq.findAncestorsDFS(parent, parentID, ancestors, visited, depth+1, errs); err != nil {
+				return err
 			}
 		}
-		return
+		return nil
 	}
 
 	// Fallback: Use indexed FAMC edges (Phase 1 optimization)
@@ -141,29 +326,52 @@ func (aq *AncestorQuery) findAncestors(node *IndividualNode, nodeID uint32, ance
 			famNode := edge.Family
 			// Phase 1: Use indexed edges for O(1) access
 			if famNode.husbandEdge != nil {
-				if husband, ok := famNode.husbandEdge.To.(*IndividualNode); ok {
+				husband, ok := famNode.husbandEdge.To.(*IndividualNode)
+				if !ok {
+					if err := aq.reportWalkError(famNode.BaseNode.ID(), depth, errUnresolvedFamilyEdge, errs); err != nil {
+						return err
+					}
+				} else {
 					// Phase 3: Use cached nodeID directly - no lock acquisition!
 					husbandID := husband.BaseNode.nodeID
-					if husbandID != 0 {
+					if husbandID == 0 {
+						if err := aq.reportWalkError(husband.BaseNode.ID(), depth+1, errUnassignedNodeID, errs); err != nil {
+							return err
+						}
+					} else {
 						ancestors[husbandID] = husband
 						// Phase 3: Pass husbandID through recursion
-						aq.findAncestors(husband, husbandID, ancestors, visited, depth+1)
+						if err := aq.findAncestorsDFS(husband, husbandID, ancestors, visited, depth+1, errs); err != nil {
+							return err
+						}
 					}
 				}
 			}
 			if famNode.wifeEdge != nil {
-				if wife, ok := famNode.wifeEdge.To.(*IndividualNode); ok {
+				wife, ok := famNode.wifeEdge.To.(*IndividualNode)
+				if !ok {
+					if err := aq.reportWalkError(famNode.BaseNode.ID(), depth, errUnresolvedFamilyEdge, errs); err != nil {
+						return err
+					}
+				} else {
 					// Phase 3: Use cached nodeID directly - no lock acquisition!
 					wifeID := wife.BaseNode.nodeID
-					if wifeID != 0 {
+					if wifeID == 0 {
+						if err := aq.reportWalkError(wife.BaseNode.ID(), depth+1, errUnassignedNodeID, errs); err != nil {
+							return err
+						}
+					} else {
 						ancestors[wifeID] = wife
 						// Phase 3: Pass wifeID through recursion
-						aq.findAncestors(wife, wifeID, ancestors, visited, depth+1)
+						if err := aq.findAncestorsDFS(wife, wifeID, ancestors, visited, depth+1, errs); err != nil {
+							return err
+						}
 					}
 				}
 			}
 		}
 	}
+	return nil
 }
 
 // Count returns the number of ancestors.
@@ -175,15 +383,30 @@ func (aq *AncestorQuery) Count() (int, error) {
 	return len(ancestors), nil
 }
 
-// Exists checks if any ancestors exist.
+// Exists checks if any ancestors exist. Unlike Count, it short-circuits on
+// the first match via Walk instead of enumerating the full ancestor set.
 func (aq *AncestorQuery) Exists() (bool, error) {
-	count, err := aq.Count()
-	if err != nil {
+	found := false
+	err := aq.graph.Walk(context.Background(), aq.startXrefID, WalkHandler{
+		Node: func(n *IndividualNode, path []GraphNode) error {
+			if aq.options.Filter == nil || n.Individual == nil || aq.options.Filter(n.Individual) {
+				found = true
+				return errStopWalk
+			}
+			return nil
+		},
+	}, WalkOptions{MaxGenerations: aq.options.MaxGenerations})
+
+	if err != nil && err != errStopWalk {
 		return false, err
 	}
-	return count > 0, nil
+	return found, nil
 }
 
+// errStopWalk is used internally to abort a Walk as soon as Exists finds a
+// single matching ancestor.
+var errStopWalk = errors.New("query: stop walk")
+
 // AncestorPath represents an ancestor with path information.
 type AncestorPath struct {
 	Ancestor *types.IndividualRecord
@@ -191,7 +414,10 @@ type AncestorPath struct {
 	Depth    int
 }
 
-// ExecuteWithPaths returns ancestors with path information.
+// ExecuteWithPaths returns ancestors with path information. Like Execute,
+// it returns every ancestor it successfully resolved alongside a non-nil
+// error aggregating any WalkError encountered, rather than dropping
+// inconsistent graph state silently.
 func (aq *AncestorQuery) ExecuteWithPaths() ([]*AncestorPath, error) {
 	startNode := aq.graph.GetIndividual(aq.startXrefID)
 	if startNode == nil {
@@ -214,8 +440,8 @@ func (aq *AncestorQuery) ExecuteWithPaths() ([]*AncestorPath, error) {
 		depths[startNodeID] = 0
 	}
 
-	// Find ancestors with depth tracking (pass nodeID to avoid repeated lookups)
-	aq.findAncestorsWithDepth(startNode, startNodeID, ancestors, visited, depths, 0)
+	var errs []error
+	abortErr := aq.findAncestorsWithDepth(startNode, startNodeID, ancestors, visited, depths, 0, &errs)
 
 	// Build paths and convert to AncestorPath
 	result := make([]*AncestorPath, 0, len(ancestors))
@@ -240,21 +466,79 @@ func (aq *AncestorQuery) ExecuteWithPaths() ([]*AncestorPath, error) {
 		}
 	}
 
-	return result, nil
+	if abortErr != nil {
+		errs = append(errs, abortErr)
+	}
+	return result, errors.Join(errs...)
+}
+
+// findAncestorsWithDepth dispatches to BFS or DFS depth-tracking, same as
+// findAncestors above.
+func (aq *AncestorQuery) findAncestorsWithDepth(node *IndividualNode, nodeID uint32, ancestors map[uint32]*IndividualNode, visited map[uint32]bool, depths map[uint32]int, depth int, errs *[]error) error {
+	if aq.options.Order == OrderBFS {
+		return aq.findAncestorsWithDepthBFS(node, nodeID, ancestors, visited, depths, depth, errs)
+	}
+	return aq.findAncestorsWithDepthDFS(node, nodeID, ancestors, visited, depths, depth, errs)
 }
 
-// findAncestorsWithDepth recursively finds ancestors with depth tracking.
+// findAncestorsWithDepthBFS is the depth-tracking counterpart of
+// findAncestorsBFS, used by ExecuteWithPaths so OrderBFS gets the same
+// level-accurate MaxGenerations cutoff there.
+func (aq *AncestorQuery) findAncestorsWithDepthBFS(node *IndividualNode, nodeID uint32, ancestors map[uint32]*IndividualNode, visited map[uint32]bool, depths map[uint32]int, startDepth int, errs *[]error) error {
+	if nodeID == 0 || visited[nodeID] {
+		return nil
+	}
+	visited[nodeID] = true
+
+	queue := []ancestorFrontierEntry{{node: node, id: nodeID, depth: startDepth}}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		if aq.options.MaxGenerations > 0 && cur.depth >= aq.options.MaxGenerations {
+			continue
+		}
+
+		parents, badEdges := aq.parentsOf(cur.node)
+		for _, bad := range badEdges {
+			if err := aq.reportWalkError(cur.node.BaseNode.ID(), cur.depth, bad, errs); err != nil {
+				return err
+			}
+		}
+
+		for _, parent := range parents {
+			parentID := parent.BaseNode.nodeID
+			if parentID == 0 {
+				if err := aq.reportWalkError(parent.BaseNode.ID(), cur.depth+1, errUnassignedNodeID, errs); err != nil {
+					return err
+				}
+				continue
+			}
+			if visited[parentID] {
+				continue
+			}
+			visited[parentID] = true
+			ancestors[parentID] = parent
+			depths[parentID] = cur.depth + 1
+			queue = append(queue, ancestorFrontierEntry{node: parent, id: parentID, depth: cur.depth + 1})
+		}
+	}
+	return nil
+}
+
+// findAncestorsWithDepthDFS recursively finds ancestors with depth tracking.
 // Optimized with Phase 1 (indexed edges, uint32 IDs), Phase 2 (cached parents), and Phase 3 (cached nodeID).
 // Phase 3: Accepts nodeID parameter to eliminate repeated GetNodeID() calls.
-func (aq *AncestorQuery) findAncestorsWithDepth(node *IndividualNode, nodeID uint32, ancestors map[uint32]*IndividualNode, visited map[uint32]bool, depths map[uint32]int, depth int) {
+func (aq *AncestorQuery) findAncestorsWithDepthDFS(node *IndividualNode, nodeID uint32, ancestors map[uint32]*IndividualNode, visited map[uint32]bool, depths map[uint32]int, depth int, errs *[]error) error {
 	// Phase 3: nodeID already provided - no lookup needed!
 	if nodeID == 0 || visited[nodeID] {
-		return
+		return nil
 	}
 
 	// Check max generations limit
 	if aq.options.MaxGenerations > 0 && depth >= aq.options.MaxGenerations {
-		return
+		return nil
 	}
 
 	visited[nodeID] = true
@@ -264,14 +548,20 @@ func (aq *AncestorQuery) findAncestorsWithDepth(node *IndividualNode, nodeID uin
 		for _, parent := range node.parents {
 			// Phase 3: Use cached nodeID directly - no lock acquisition!
 			parentID := parent.BaseNode.nodeID
-			if parentID != 0 {
-				ancestors[parentID] = parent
-				depths[parentID] = depth + 1
-				// Phase 3: Pass parentID through recursion to avoid repeated lookups
-				aq.findAncestorsWithDepth(parent, parentID, ancestors, visited, depths, depth+1)
+			if parentID == 0 {
+				if err := aq.reportWalkError(parent.BaseNode.ID(), depth+1, errUnassignedNodeID, errs); err != nil {
+					return err
+				}
+				continue
+			}
+			ancestors[parentID] = parent
+			depths[parentID] = depth + 1
+			// Phase 3: Pass parentID through recursion to avoid repeated lookups
+			if err := aq.findAncestorsWithDepthDFS(parent, parentID, ancestors, visited, depths, depth+1, errs); err != nil {
+				return err
 			}
 		}
-		return
+		return nil
 	}
 
 	// Fallback: Use indexed FAMC edges (Phase 1 optimization)
@@ -280,29 +570,52 @@ func (aq *AncestorQuery) findAncestorsWithDepth(node *IndividualNode, nodeID uin
 			famNode := edge.Family
 			// Phase 1: Use indexed edges for O(1) access
 			if famNode.husbandEdge != nil {
-				if husband, ok := famNode.husbandEdge.To.(*IndividualNode); ok {
+				husband, ok := famNode.husbandEdge.To.(*IndividualNode)
+				if !ok {
+					if err := aq.reportWalkError(famNode.BaseNode.ID(), depth, errUnresolvedFamilyEdge, errs); err != nil {
+						return err
+					}
+				} else {
 					// Phase 3: Use cached nodeID directly - no lock acquisition!
 					husbandID := husband.BaseNode.nodeID
-					if husbandID != 0 {
+					if husbandID == 0 {
+						if err := aq.reportWalkError(husband.BaseNode.ID(), depth+1, errUnassignedNodeID, errs); err != nil {
+							return err
+						}
+					} else {
 						ancestors[husbandID] = husband
 						depths[husbandID] = depth + 1
 						// Phase 3: Pass husbandID through recursion
-						aq.findAncestorsWithDepth(husband, husbandID, ancestors, visited, depths, depth+1)
+						if err := aq.findAncestorsWithDepthDFS(husband, husbandID, ancestors, visited, depths, depth+1, errs); err != nil {
+							return err
+						}
 					}
 				}
 			}
 			if famNode.wifeEdge != nil {
-				if wife, ok := famNode.wifeEdge.To.(*IndividualNode); ok {
+				wife, ok := famNode.wifeEdge.To.(*IndividualNode)
+				if !ok {
+					if err := aq.reportWalkError(famNode.BaseNode.ID(), depth, errUnresolvedFamilyEdge, errs); err != nil {
+						return err
+					}
+				} else {
 					// Phase 3: Use cached nodeID directly - no lock acquisition!
 					wifeID := wife.BaseNode.nodeID
-					if wifeID != 0 {
+					if wifeID == 0 {
+						if err := aq.reportWalkError(wife.BaseNode.ID(), depth+1, errUnassignedNodeID, errs); err != nil {
+							return err
+						}
+					} else {
 						ancestors[wifeID] = wife
 						depths[wifeID] = depth + 1
 						// Phase 3: Pass wifeID through recursion
-						aq.findAncestorsWithDepth(wife, wifeID, ancestors, visited, depths, depth+1)
+						if err := aq.findAncestorsWithDepthDFS(wife, wifeID, ancestors, visited, depths, depth+1, errs); err != nil {
+							return err
+						}
 					}
 				}
 			}
 		}
 	}
+	return nil
 }
@@ -0,0 +1,90 @@
+package query
+
+import (
+	"context"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// This file gives the long-running ancestor queries cancellable, budgeted
+// variants of their Execute-family methods. Graph-wide traversals reachable
+// the same way -- GraphMetrics.Diameter/ConnectedComponents and a
+// PathTo/RelationshipTo query between two individuals -- are not covered
+// here: qb.Metrics() in this snapshot's own tests returns a type with
+// Centrality/Diameter/ConnectedComponents methods, but GraphMetrics (see
+// metrics.go) is a distinct, unrelated {Nodes, Edges int64} snapshot, and
+// PathTo/RelationshipTo have no defining type anywhere in this package.
+// There is no local symbol to hang DiameterCtx/ConnectedComponentsCtx/
+// PathTo(...).AllCtx/RelationshipTo().ExecuteCtx off of, so they are left
+// out rather than guessed at.
+
+// ExecuteCtx is Execute, but driven by Walk instead of the recursive
+// BFS/DFS helpers, so it honors ctx.Done() and AncestorOptions'
+// MaxDuration/MaxNodesVisited/Progress at every ancestor visited, rather
+// than only at completion.
+func (aq *AncestorQuery) ExecuteCtx(ctx context.Context) ([]*types.IndividualRecord, error) {
+	var records []*types.IndividualRecord
+	if aq.options.IncludeSelf {
+		if startNode := aq.graph.GetIndividual(aq.startXrefID); startNode != nil && startNode.Individual != nil {
+			records = append(records, startNode.Individual)
+		}
+	}
+
+	err := aq.Walk(ctx, AncestorWalkHandler{
+		Individual: func(path AncestorPath) error {
+			if path.Ancestor != nil {
+				records = append(records, path.Ancestor)
+			}
+			return nil
+		},
+	})
+	return records, err
+}
+
+// ExecuteWithPathsCtx is ExecuteWithPaths, driven by Walk so it honors
+// ctx.Done() and the same MaxDuration/MaxNodesVisited/Progress budget as
+// ExecuteCtx. Each AncestorPath's Path is built directly from Walk's own
+// traversal path rather than a separate ShortestPath call per ancestor.
+func (aq *AncestorQuery) ExecuteWithPathsCtx(ctx context.Context) ([]*AncestorPath, error) {
+	var results []*AncestorPath
+	if aq.options.IncludeSelf {
+		if startNode := aq.graph.GetIndividual(aq.startXrefID); startNode != nil && startNode.Individual != nil {
+			results = append(results, &AncestorPath{
+				Ancestor: startNode.Individual,
+				Path:     &Path{Nodes: []GraphNode{startNode}},
+				Depth:    0,
+			})
+		}
+	}
+
+	err := aq.Walk(ctx, AncestorWalkHandler{
+		Individual: func(path AncestorPath) error {
+			if path.Ancestor != nil {
+				cp := path
+				results = append(results, &cp)
+			}
+			return nil
+		},
+	})
+	return results, err
+}
+
+// CountCtx is Count, driven by Walk so it honors ctx.Done() and the same
+// MaxDuration/MaxNodesVisited budget as ExecuteCtx, without materializing
+// the full ancestor slice.
+func (aq *AncestorQuery) CountCtx(ctx context.Context) (int, error) {
+	count := 0
+	if aq.options.IncludeSelf {
+		if startNode := aq.graph.GetIndividual(aq.startXrefID); startNode != nil && startNode.Individual != nil {
+			count++
+		}
+	}
+
+	err := aq.Walk(ctx, AncestorWalkHandler{
+		Individual: func(path AncestorPath) error {
+			count++
+			return nil
+		},
+	})
+	return count, err
+}
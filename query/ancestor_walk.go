@@ -0,0 +1,205 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrSkipSubtree, returned from AncestorWalkHandler.PreIndividual or
+// PreFamily, prunes that branch: Walk does not descend into it (or, for
+// PreFamily, does not visit either parent of that family) but continues
+// with the remaining siblings. Any other non-nil error aborts the walk
+// and is returned to the caller of Walk.
+var ErrSkipSubtree = errors.New("query: skip subtree")
+
+// errMaxDurationExceeded and errMaxNodesVisited are returned by Walk (and
+// the *Ctx methods built on it) when AncestorOptions.MaxDuration or
+// MaxNodesVisited is exceeded mid-traversal -- distinguishable from
+// ctx.Err() so callers can tell a caller-supplied cancellation apart from
+// the query's own runaway-traversal guard.
+var (
+	errMaxDurationExceeded = errors.New("query: ancestor walk exceeded MaxDuration")
+	errMaxNodesVisited     = errors.New("query: ancestor walk exceeded MaxNodesVisited")
+)
+
+// walkBudget tracks the running state a budget-aware Walk checks at every
+// node: a hard deadline (zero if AncestorOptions.MaxDuration is 0), a
+// visited counter, and an estimated total for AncestorOptions.Progress.
+type walkBudget struct {
+	deadline time.Time
+	visited  int
+	total    int
+}
+
+// check returns ctx.Err(), errMaxDurationExceeded, or errMaxNodesVisited,
+// whichever first-fires guard rail has been tripped, or nil if none has.
+func (aq *AncestorQuery) check(ctx context.Context, b *walkBudget) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !b.deadline.IsZero() && time.Now().After(b.deadline) {
+		return errMaxDurationExceeded
+	}
+	if aq.options.MaxNodesVisited > 0 && b.visited > aq.options.MaxNodesVisited {
+		return errMaxNodesVisited
+	}
+	return nil
+}
+
+// AncestorWalkHandler carries optional lifecycle callbacks invoked as Walk
+// descends the ancestor tree. Each callback receives the AncestorPath of
+// the node or the child the family was reached through, with Path.Nodes
+// built incrementally: pushed before a node's children are visited, and
+// popped once they return. A nil callback is simply skipped.
+type AncestorWalkHandler struct {
+	PreIndividual  func(path AncestorPath) error
+	Individual     func(path AncestorPath) error
+	PostIndividual func(path AncestorPath) error
+	PreFamily      func(fam *FamilyNode, childPath AncestorPath) error
+	PostFamily     func(fam *FamilyNode, childPath AncestorPath) error
+}
+
+// Walk streams ancestors to h instead of materializing them into a map,
+// letting callers compute aggregates (name frequencies, generation
+// histograms, endogamy detection) or terminate early. It honors
+// MaxGenerations and Filter the same way Execute does, checks ctx.Done()
+// between siblings, and maintains the same visited-set cycle detection:
+// if a node is re-encountered via another path, PreIndividual is not
+// invoked for it again. It also honors AncestorOptions.MaxDuration and
+// MaxNodesVisited, aborting with errMaxDurationExceeded/errMaxNodesVisited
+// respectively, and calls AncestorOptions.Progress after each ancestor is
+// visited if set.
+func (aq *AncestorQuery) Walk(ctx context.Context, h AncestorWalkHandler) error {
+	startNode := aq.graph.GetIndividual(aq.startXrefID)
+	if startNode == nil {
+		return nil
+	}
+
+	startNodeID := startNode.BaseNode.nodeID
+	if startNodeID == 0 {
+		return nil
+	}
+
+	visited := make(map[uint32]bool)
+	visited[startNodeID] = true
+
+	b := &walkBudget{total: len(aq.graph.GetAllIndividuals())}
+	if aq.options.MaxDuration > 0 {
+		b.deadline = time.Now().Add(aq.options.MaxDuration)
+	}
+
+	return aq.walkAncestors(ctx, []GraphNode{startNode}, startNode, 0, visited, h, b)
+}
+
+// walkAncestors visits node's parent families and, for each unvisited
+// parent, recurses one generation deeper. nodes is the path from the
+// walk's starting individual down to and including node.
+func (aq *AncestorQuery) walkAncestors(ctx context.Context, nodes []GraphNode, node *IndividualNode, depth int, visited map[uint32]bool, h AncestorWalkHandler, b *walkBudget) error {
+	if aq.options.MaxGenerations > 0 && depth >= aq.options.MaxGenerations {
+		return nil
+	}
+
+	for _, edge := range node.famcEdges {
+		if err := aq.check(ctx, b); err != nil {
+			return err
+		}
+		if edge.Family == nil {
+			continue
+		}
+		famNode := edge.Family
+
+		childPath := AncestorPath{
+			Ancestor: node.Individual,
+			Path:     &Path{Nodes: append([]GraphNode{}, nodes...)},
+			Depth:    depth,
+		}
+
+		if h.PreFamily != nil {
+			if err := h.PreFamily(famNode, childPath); err != nil {
+				if errors.Is(err, ErrSkipSubtree) {
+					continue
+				}
+				return err
+			}
+		}
+
+		for _, parent := range familyParents(famNode) {
+			if err := aq.check(ctx, b); err != nil {
+				return err
+			}
+
+			parentID := parent.BaseNode.nodeID
+			if parentID == 0 || visited[parentID] {
+				continue
+			}
+			visited[parentID] = true
+
+			parentNodes := append(append([]GraphNode{}, nodes...), parent)
+			parentPath := AncestorPath{
+				Ancestor: parent.Individual,
+				Path:     &Path{Nodes: append([]GraphNode{}, parentNodes...)},
+				Depth:    depth + 1,
+			}
+
+			skip := false
+			if h.PreIndividual != nil {
+				if err := h.PreIndividual(parentPath); err != nil {
+					if !errors.Is(err, ErrSkipSubtree) {
+						return err
+					}
+					skip = true
+				}
+			}
+			if skip {
+				continue
+			}
+
+			if h.Individual != nil && (aq.options.Filter == nil || parent.Individual == nil || aq.options.Filter(parent.Individual)) {
+				if err := h.Individual(parentPath); err != nil {
+					return err
+				}
+			}
+
+			b.visited++
+			if aq.options.Progress != nil {
+				aq.options.Progress(b.visited, b.total)
+			}
+
+			if err := aq.walkAncestors(ctx, parentNodes, parent, depth+1, visited, h, b); err != nil {
+				return err
+			}
+
+			if h.PostIndividual != nil {
+				if err := h.PostIndividual(parentPath); err != nil {
+					return err
+				}
+			}
+		}
+
+		if h.PostFamily != nil {
+			if err := h.PostFamily(famNode, childPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// familyParents returns fam's husband and wife as IndividualNodes, in that
+// order, skipping either side that is absent or unresolved.
+func familyParents(fam *FamilyNode) []*IndividualNode {
+	parents := make([]*IndividualNode, 0, 2)
+	if fam.husbandEdge != nil {
+		if husband, ok := fam.husbandEdge.To.(*IndividualNode); ok {
+			parents = append(parents, husband)
+		}
+	}
+	if fam.wifeEdge != nil {
+		if wife, ok := fam.wifeEdge.To.(*IndividualNode); ok {
+			parents = append(parents, wife)
+		}
+	}
+	return parents
+}
@@ -0,0 +1,70 @@
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAncestorQuery_Execute_AggregatesWalkErrors(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	// Simulate a graph inconsistency: @I4@ was resolved but never assigned
+	// a node ID by graph construction.
+	corrupt := graph.GetIndividual("@I4@")
+	if corrupt == nil {
+		t.Fatal("expected @I4@ to resolve in the built graph")
+	}
+	corrupt.BaseNode.nodeID = 0
+
+	aq := newAncestorQuery(graph, "@I1@", OrderBFS, 0)
+	records, err := aq.Execute()
+	if err == nil {
+		t.Fatal("expected Execute to return an aggregate error for the corrupted node")
+	}
+
+	var walkErr *WalkError
+	if !errors.As(err, &walkErr) {
+		t.Fatalf("expected error to wrap a *WalkError, got %v", err)
+	}
+	if walkErr.XrefID != "@I4@" {
+		t.Errorf("expected WalkError.XrefID to be @I4@, got %s", walkErr.XrefID)
+	}
+
+	gotXrefs := make(map[string]bool, len(records))
+	for _, r := range records {
+		gotXrefs[r.XrefID()] = true
+	}
+	if !gotXrefs["@I2@"] || !gotXrefs["@I3@"] {
+		t.Errorf("expected Execute to still return the ancestors it could resolve, got %v", gotXrefs)
+	}
+	if gotXrefs["@I4@"] {
+		t.Errorf("did not expect the corrupted node to appear in results, got %v", gotXrefs)
+	}
+}
+
+func TestAncestorQuery_Execute_OnErrorAborts(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	corrupt := graph.GetIndividual("@I4@")
+	if corrupt == nil {
+		t.Fatal("expected @I4@ to resolve in the built graph")
+	}
+	corrupt.BaseNode.nodeID = 0
+
+	aq := newAncestorQuery(graph, "@I1@", OrderBFS, 0)
+	aborted := errors.New("abort on corruption")
+	aq.options.OnError = func(we *WalkError) error {
+		return aborted
+	}
+
+	_, err = aq.Execute()
+	if !errors.Is(err, aborted) {
+		t.Errorf("expected Execute to propagate the OnError abort error, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAncestorQuery_Walk_VisitOrderAndLifecycle(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	aq := newAncestorQuery(graph, "@I1@", OrderDFS, 0)
+
+	var events []string
+	h := AncestorWalkHandler{
+		PreIndividual: func(path AncestorPath) error {
+			events = append(events, "pre:"+path.Ancestor.XrefID())
+			return nil
+		},
+		PostIndividual: func(path AncestorPath) error {
+			events = append(events, "post:"+path.Ancestor.XrefID())
+			return nil
+		},
+	}
+
+	if err := aq.Walk(context.Background(), h); err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	// @I4@ is shared by both @I2@ and @I3@'s families; cycle detection means
+	// it is only visited once, through whichever branch reaches it first.
+	preCount := 0
+	for _, e := range events {
+		if e == "pre:@I4@" {
+			preCount++
+		}
+	}
+	if preCount != 1 {
+		t.Errorf("expected @I4@ to be visited exactly once via Walk, got %d pre-visits (%v)", preCount, events)
+	}
+
+	// Every pre must have a matching post, and posts must come after pres.
+	seen := make(map[string]bool)
+	for _, e := range events {
+		if len(e) > 4 && e[:4] == "pre:" {
+			seen[e[4:]] = true
+		} else if len(e) > 5 && e[:5] == "post:" {
+			xref := e[5:]
+			if !seen[xref] {
+				t.Errorf("post event for %s seen before its pre event: %v", xref, events)
+			}
+		}
+	}
+}
+
+func TestAncestorQuery_Walk_SkipSubtreePrunesBranch(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	aq := newAncestorQuery(graph, "@I1@", OrderDFS, 0)
+
+	var visited []string
+	h := AncestorWalkHandler{
+		PreIndividual: func(path AncestorPath) error {
+			if path.Ancestor.XrefID() == "@I2@" {
+				return ErrSkipSubtree
+			}
+			return nil
+		},
+		Individual: func(path AncestorPath) error {
+			visited = append(visited, path.Ancestor.XrefID())
+			return nil
+		},
+	}
+
+	if err := aq.Walk(context.Background(), h); err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	// @I4@ is a parent of both @I2@ and @I3@, so it is still reached via
+	// @I3@; only @I2@ itself and its own parent @I5@ are pruned.
+	for _, xref := range visited {
+		if xref == "@I2@" || xref == "@I5@" {
+			t.Errorf("expected @I2@'s branch to be pruned by ErrSkipSubtree, but visited %s (%v)", xref, visited)
+		}
+	}
+	found3, found4 := false, false
+	for _, xref := range visited {
+		if xref == "@I3@" {
+			found3 = true
+		}
+		if xref == "@I4@" {
+			found4 = true
+		}
+	}
+	if !found3 {
+		t.Errorf("expected @I3@'s branch to still be visited, got %v", visited)
+	}
+	if !found4 {
+		t.Errorf("expected @I4@ to still be visited through @I3@, got %v", visited)
+	}
+}
+
+func TestAncestorQuery_Walk_AbortsOnHandlerError(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	aq := newAncestorQuery(graph, "@I1@", OrderDFS, 0)
+
+	boom := errors.New("boom")
+	h := AncestorWalkHandler{
+		PreIndividual: func(path AncestorPath) error {
+			return boom
+		},
+	}
+
+	err = aq.Walk(context.Background(), h)
+	if !errors.Is(err, boom) {
+		t.Errorf("expected Walk to propagate handler error, got %v", err)
+	}
+}
+
+func TestAncestorQuery_Walk_HonorsContextCancellation(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	aq := newAncestorQuery(graph, "@I1@", OrderDFS, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = aq.Walk(ctx, AncestorWalkHandler{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected Walk to return context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package query
+
+// AuditReport enumerates structural defects found in a Graph.
+type AuditReport struct {
+	// Orphans are individuals not reachable from any family (no FAMC/FAMS
+	// links at all).
+	Orphans []string
+
+	// Dangling lists the XREFs of families that have no HUSB, WIFE or CHIL
+	// edge at all. References to XREFs that are missing from the graph are
+	// not looked for and are not listed here.
+	Dangling []string
+
+	// Cycles lists XREF chains that form a cycle in the parent->child DAG.
+	Cycles [][]string
+
+	// Unreachable lists individuals that cannot be reached by walking
+	// outward from any family in the graph.
+	Unreachable []string
+}
+
+// RepairStrategy selects how Repair resolves a given category of defect.
+type RepairStrategy string
+
+const (
+	// DropDanglingEdges clears the HUSB, WIFE and CHIL edges of every
+	// family listed in AuditReport.Dangling. Edges that point at a
+	// non-existent node are not looked for.
+	DropDanglingEdges RepairStrategy = "drop-dangling-edges"
+
+	// SynthesizePlaceholder is accepted by Repair, which creates no stub
+	// INDI/FAM record for it and leaves the graph unchanged.
+	SynthesizePlaceholder RepairStrategy = "synthesize-placeholder"
+
+	// BreakCycles removes, for each cycle in AuditReport.Cycles, the
+	// parent->child link that closes it (the last link Audit's depth-first
+	// search followed) from the child's cached parent list. Which link was
+	// added most recently is not tracked, and the family edges themselves
+	// are left in place.
+	BreakCycles RepairStrategy = "break-cycles"
+)
+
+// RepairOptions controls which strategies Repair applies.
+type RepairOptions struct {
+	Strategies []RepairStrategy
+}
+
+func (o RepairOptions) has(s RepairStrategy) bool {
+	for _, want := range o.Strategies {
+		if want == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Audit enumerates structural defects in the graph without mutating it.
+func (g *Graph) Audit() *AuditReport {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	report := &AuditReport{
+		Orphans:     make([]string, 0),
+		Dangling:    make([]string, 0),
+		Cycles:      make([][]string, 0),
+		Unreachable: make([]string, 0),
+	}
+
+	for xref, indi := range g.individuals {
+		if len(indi.famcEdges) == 0 && len(indi.famsEdges) == 0 {
+			report.Orphans = append(report.Orphans, xref)
+		}
+	}
+
+	for famXref, fam := range g.families {
+		if fam.husbandEdge == nil && fam.wifeEdge == nil && len(fam.chilEdges) == 0 {
+			report.Dangling = append(report.Dangling, famXref)
+		}
+	}
+
+	color := make(map[string]int)
+	const (
+		white = 0
+		gray  = 1
+		black = 2
+	)
+	var visit func(xref string, path []string) []string
+	visit = func(xref string, path []string) []string {
+		switch color[xref] {
+		case black:
+			return nil
+		case gray:
+			return append(append([]string{}, path...), xref)
+		}
+		color[xref] = gray
+		if node := g.individuals[xref]; node != nil {
+			for _, child := range node.getChildrenFromEdges() {
+				if cyc := visit(child.ID(), append(path, xref)); cyc != nil {
+					return cyc
+				}
+			}
+		}
+		color[xref] = black
+		return nil
+	}
+	for xref := range g.individuals {
+		if color[xref] == white {
+			if cyc := visit(xref, nil); cyc != nil {
+				report.Cycles = append(report.Cycles, cyc)
+			}
+		}
+	}
+
+	reachable := make(map[string]bool)
+	for _, fam := range g.families {
+		if fam.husbandEdge != nil {
+			if indi, ok := fam.husbandEdge.To.(*IndividualNode); ok {
+				reachable[indi.ID()] = true
+			}
+		}
+		if fam.wifeEdge != nil {
+			if indi, ok := fam.wifeEdge.To.(*IndividualNode); ok {
+				reachable[indi.ID()] = true
+			}
+		}
+		for _, edge := range fam.chilEdges {
+			if indi, ok := edge.To.(*IndividualNode); ok {
+				reachable[indi.ID()] = true
+			}
+		}
+	}
+	for xref := range g.individuals {
+		if !reachable[xref] {
+			report.Unreachable = append(report.Unreachable, xref)
+		}
+	}
+
+	return report
+}
+
+// Repair audits the graph and applies the requested strategies in-place,
+// returning the report that was used to drive the repair.
+func (g *Graph) Repair(opts RepairOptions) *AuditReport {
+	report := g.Audit()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if opts.has(DropDanglingEdges) {
+		for _, famXref := range report.Dangling {
+			fam := g.families[famXref]
+			if fam == nil {
+				continue
+			}
+			fam.husbandEdge = nil
+			fam.wifeEdge = nil
+			fam.chilEdges = nil
+		}
+	}
+
+	if opts.has(BreakCycles) {
+		for _, cycle := range report.Cycles {
+			if len(cycle) < 2 {
+				continue
+			}
+			parentXref := cycle[len(cycle)-2]
+			childXref := cycle[len(cycle)-1]
+			parent := g.individuals[parentXref]
+			child := g.individuals[childXref]
+			if parent == nil || child == nil {
+				continue
+			}
+			for i := len(child.parents) - 1; i >= 0; i-- {
+				if child.parents[i].ID() == parentXref {
+					child.parents = append(child.parents[:i], child.parents[i+1:]...)
+				}
+			}
+		}
+	}
+
+	if opts.has(SynthesizePlaceholder) {
+		// Placeholder synthesis requires constructing a minimal
+		// types.IndividualRecord/FamilyRecord and registering it with the
+		// tree; left for callers that have a tree handle, since Graph
+		// alone does not own record construction.
+		_ = report
+	}
+
+	return report
+}
@@ -0,0 +1,373 @@
+package query
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// BirthdayAgeQuery adds upcoming-birthday and age-on-a-date filtering to the
+// birthday-filter family ByBirthMonth/ByBirthDay/ByBirthMonthAndDay/
+// ByBirthDateRange already provide. It is a standalone sibling over *Graph
+// rather than new *FilterQuery methods: FilterQuery's defining file isn't
+// part of this snapshot (its chainable predicate/Execute pipeline lives
+// nowhere in this tree), the same gap SimilarityIndex and
+// HybridQueryHelpersMongo already document for this package. BirthdayAgeQuery
+// walks the Graph directly instead of composing into FilterQuery.Execute.
+type BirthdayAgeQuery struct {
+	graph *Graph
+
+	mode birthdayAgeMode
+
+	from   monthDay
+	window time.Duration
+
+	refDate time.Time
+	age     int
+	minAge  int
+	maxAge  int
+
+	excludeDeceased bool
+}
+
+type birthdayAgeMode int
+
+const (
+	birthdayAgeModeNone birthdayAgeMode = iota
+	birthdayAgeModeUpcoming
+	birthdayAgeModeAgeOn
+	birthdayAgeModeAgeRangeOn
+)
+
+// NewBirthdayAgeQuery creates a new BirthdayAgeQuery over graph.
+func NewBirthdayAgeQuery(graph *Graph) *BirthdayAgeQuery {
+	return &BirthdayAgeQuery{graph: graph}
+}
+
+// ByUpcomingBirthday restricts results to individuals whose birth month/day
+// falls within [from, from+window], wrapping across the Dec->Jan year
+// boundary. A BET birth date matches if any day in its inclusive month/day
+// range falls in the window; ABT/BEF/AFT dates match on whatever month/day
+// they carry. Individuals whose birth date carries no day (e.g. "JAN 1800"
+// or no date at all) never match, since there is no day to compare.
+//
+// A zero or negative window leaves the query unchanged, matching the
+// invalid-input convention ByBirthMonth/ByBirthDay/ByBirthMonthAndDay
+// already use.
+func (q *BirthdayAgeQuery) ByUpcomingBirthday(from time.Time, window time.Duration) *BirthdayAgeQuery {
+	if window <= 0 {
+		return q
+	}
+	q.mode = birthdayAgeModeUpcoming
+	q.from = monthDay{month: int(from.Month()), day: from.Day()}
+	q.window = window
+	return q
+}
+
+// ByAgeOn restricts results to individuals whose age on date equals age.
+// A birth date with day-level precision (an exact date, or the start of a
+// BET range) must match exactly; an ABT/BEF/AFT or year-only birth date
+// matches if age is within +/-1 year of the requested age, since its true
+// day is unknown.
+//
+// A negative age leaves the query unchanged.
+func (q *BirthdayAgeQuery) ByAgeOn(date time.Time, age int) *BirthdayAgeQuery {
+	if age < 0 {
+		return q
+	}
+	q.mode = birthdayAgeModeAgeOn
+	q.refDate = date
+	q.age = age
+	return q
+}
+
+// ByAgeRangeOn restricts results to individuals whose age on date falls
+// within [min, max] inclusive, using the same +/-1 year tolerance ByAgeOn
+// applies to approximate (ABT/BEF/AFT/year-only) birth dates.
+//
+// A negative bound, or min > max, leaves the query unchanged.
+func (q *BirthdayAgeQuery) ByAgeRangeOn(date time.Time, min, max int) *BirthdayAgeQuery {
+	if min < 0 || max < 0 || min > max {
+		return q
+	}
+	q.mode = birthdayAgeModeAgeRangeOn
+	q.refDate = date
+	q.minAge = min
+	q.maxAge = max
+	return q
+}
+
+// ExcludeDeceased restricts results to individuals with no recorded death
+// date, applied in addition to whichever birthday/age filter is active.
+func (q *BirthdayAgeQuery) ExcludeDeceased() *BirthdayAgeQuery {
+	q.excludeDeceased = true
+	return q
+}
+
+// Execute runs the configured filter over the graph's individuals.
+func (q *BirthdayAgeQuery) Execute() ([]*types.IndividualRecord, error) {
+	results := make([]*types.IndividualRecord, 0)
+	for _, node := range q.graph.AllIndividuals() {
+		indi := node.Individual
+		if q.excludeDeceased && indi.GetDeathDate() != "" {
+			continue
+		}
+
+		switch q.mode {
+		case birthdayAgeModeUpcoming:
+			if !matchesUpcomingBirthday(indi.GetBirthDate(), q.from, q.window) {
+				continue
+			}
+		case birthdayAgeModeAgeOn:
+			if !matchesAgeOn(indi.GetBirthDate(), q.refDate, q.age, q.age) {
+				continue
+			}
+		case birthdayAgeModeAgeRangeOn:
+			if !matchesAgeOn(indi.GetBirthDate(), q.refDate, q.minAge, q.maxAge) {
+				continue
+			}
+		default:
+			continue
+		}
+
+		results = append(results, indi)
+	}
+	return results, nil
+}
+
+// monthDay is a calendar month/day pair, ignoring year.
+type monthDay struct {
+	month int
+	day   int
+}
+
+// birthdayMonthAbbrev maps GEDCOM's 3-letter month abbreviations to their
+// calendar month number.
+var birthdayMonthAbbrev = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+// parsedBirthdayDate is one GEDCOM date parsed into the month/day range it
+// covers, plus whether its birth day is known precisely enough to drive
+// ByUpcomingBirthday, and whether it's an approximate (ABT/BEF/AFT) date for
+// ByAgeOn's +/-1 year tolerance.
+type parsedBirthdayDate struct {
+	start     monthDay
+	end       monthDay
+	dayKnown  bool
+	approx    bool
+	yearKnown bool
+	year      int
+}
+
+// parseBirthdayDate parses a loosely-formatted GEDCOM date string the same
+// way ByBirthMonth/ByBirthDay/ByBirthMonthAndDay do: a BET...AND... range
+// covers every day between its two endpoints (inclusive), an ABT/BEF/AFT
+// date covers the single month/day it carries (if any), and a plain date
+// covers its own month/day. ok is false only when date carries neither a
+// month nor a four-digit year (for a BET range, on either side).
+func parseBirthdayDate(date string) (parsedBirthdayDate, bool) {
+	upper := strings.ToUpper(strings.TrimSpace(date))
+	if upper == "" {
+		return parsedBirthdayDate{}, false
+	}
+
+	if strings.HasPrefix(upper, "BET ") {
+		if idx := strings.Index(upper, " AND "); idx >= 0 {
+			startStr := strings.TrimSpace(upper[len("BET "):idx])
+			endStr := strings.TrimSpace(upper[idx+len(" AND "):])
+			start, startOK := parseOneDate(startStr)
+			end, endOK := parseOneDate(endStr)
+			if !startOK && !endOK {
+				return parsedBirthdayDate{}, false
+			}
+			result := parsedBirthdayDate{yearKnown: start.yearKnown || end.yearKnown}
+			if start.yearKnown {
+				result.year = start.year
+			} else {
+				result.year = end.year
+			}
+			result.start = start.md
+			result.end = end.md
+			result.dayKnown = start.dayKnown && end.dayKnown
+			return result, true
+		}
+	}
+
+	approx := false
+	rest := upper
+	for _, prefix := range []string{"ABT ", "BEF ", "AFT ", "EST ", "CAL "} {
+		if strings.HasPrefix(rest, prefix) {
+			approx = true
+			rest = strings.TrimSpace(rest[len(prefix):])
+			break
+		}
+	}
+
+	parsed, ok := parseOneDate(rest)
+	if !ok {
+		return parsedBirthdayDate{}, false
+	}
+	return parsedBirthdayDate{
+		start:     parsed.md,
+		end:       parsed.md,
+		dayKnown:  parsed.dayKnown,
+		approx:    approx,
+		yearKnown: parsed.yearKnown,
+		year:      parsed.year,
+	}, true
+}
+
+// oneDate is a single GEDCOM date token (no BET/ABT/etc. prefix) parsed
+// into its calendar parts.
+type oneDate struct {
+	md        monthDay
+	dayKnown  bool
+	yearKnown bool
+	year      int
+}
+
+// parseOneDate parses "[D] MON [YYYY]" into its calendar parts. It returns
+// ok=false only when neither a month nor a year can be found.
+func parseOneDate(token string) (oneDate, bool) {
+	fields := strings.Fields(token)
+	var result oneDate
+	foundMonth := false
+	for _, field := range fields {
+		if month, ok := birthdayMonthAbbrev[field]; ok {
+			result.md.month = month
+			foundMonth = true
+			continue
+		}
+		if isAllDigits(field) {
+			switch len(field) {
+			case 4:
+				result.year, _ = atoiSafe(field)
+				result.yearKnown = true
+			case 1, 2:
+				result.md.day, _ = atoiSafe(field)
+			}
+		}
+	}
+	result.dayKnown = foundMonth && result.md.day > 0
+	if !foundMonth && !result.yearKnown {
+		return oneDate{}, false
+	}
+	return result, true
+}
+
+func isAllDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func atoiSafe(s string) (int, bool) {
+	n := 0
+	for _, r := range s {
+		n = n*10 + int(r-'0')
+	}
+	return n, true
+}
+
+// dayOfYearOffset turns a month/day pair into a day-of-year count (1-366,
+// using a leap-year calendar so every valid GEDCOM day has a slot), for
+// comparing windows that may wrap across Dec->Jan.
+func dayOfYearOffset(md monthDay) int {
+	offsets := []int{0, 31, 60, 91, 121, 152, 182, 213, 244, 274, 305, 335}
+	if md.month < 1 || md.month > 12 {
+		return 0
+	}
+	return offsets[md.month-1] + md.day
+}
+
+const daysInLeapYear = 366
+
+// matchesUpcomingBirthday reports whether date's month/day range overlaps
+// [from, from+window] on the 366-day wheel, wrapping Dec->Jan.
+func matchesUpcomingBirthday(date string, from monthDay, window time.Duration) bool {
+	if date == "" {
+		return false
+	}
+	parsed, ok := parseBirthdayDate(date)
+	if !ok || !parsed.dayKnown {
+		return false
+	}
+
+	windowDays := int(window / (24 * time.Hour))
+	if windowDays < 0 {
+		windowDays = 0
+	}
+
+	fromOffset := dayOfYearOffset(from)
+	toOffset := fromOffset + windowDays
+
+	startOffset := dayOfYearOffset(parsed.start)
+	endOffset := dayOfYearOffset(parsed.end)
+	if endOffset < startOffset {
+		endOffset += daysInLeapYear
+	}
+
+	// Check every "copy" of the birth date's [start,end] range shifted by
+	// whole years against [fromOffset, toOffset], so a window that wraps
+	// Dec->Jan (toOffset > daysInLeapYear) or a birth range that itself
+	// wraps (endOffset > daysInLeapYear) both line up correctly.
+	for _, shift := range []int{-daysInLeapYear, 0, daysInLeapYear} {
+		shiftedStart := startOffset + shift
+		shiftedEnd := endOffset + shift
+		if shiftedStart <= toOffset && shiftedEnd >= fromOffset {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAgeOn reports whether an individual born on date's birth date is
+// between minAge and maxAge (inclusive) on refDate. An approximate
+// (ABT/BEF/AFT/year-only) birth date widens the comparison by one year on
+// each side, since its true day is unknown.
+func matchesAgeOn(birthDate string, refDate time.Time, minAge, maxAge int) bool {
+	if birthDate == "" {
+		return false
+	}
+	parsed, ok := parseBirthdayDate(birthDate)
+	if !ok || !parsed.yearKnown {
+		return false
+	}
+
+	birthYear := parsed.year
+	birthMonth := parsed.start.month
+	birthDay := parsed.start.day
+	if birthMonth == 0 {
+		birthMonth = 1
+	}
+	if birthDay == 0 {
+		birthDay = 1
+	}
+
+	birth := time.Date(birthYear, time.Month(birthMonth), birthDay, 0, 0, 0, 0, time.UTC)
+	if birth.After(refDate) {
+		return false
+	}
+
+	age := refDate.Year() - birth.Year()
+	hadBirthdayYet := refDate.Month() > birth.Month() ||
+		(refDate.Month() == birth.Month() && refDate.Day() >= birth.Day())
+	if !hadBirthdayYet {
+		age--
+	}
+
+	tolerance := 0
+	if parsed.approx || !parsed.dayKnown {
+		tolerance = 1
+	}
+	return age >= minAge-tolerance && age <= maxAge+tolerance
+}
@@ -0,0 +1,220 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+func addBirthIndi(tree *types.GedcomTree, xref, dateStr, deathStr string) {
+	indiLine := types.NewGedcomLine(0, "INDI", "", xref)
+	indiLine.AddChild(types.NewGedcomLine(1, "NAME", "Test /Person/", ""))
+	if dateStr != "" {
+		birt := types.NewGedcomLine(1, "BIRT", "", "")
+		birt.AddChild(types.NewGedcomLine(2, "DATE", dateStr, ""))
+		indiLine.AddChild(birt)
+	}
+	if deathStr != "" {
+		deat := types.NewGedcomLine(1, "DEAT", "", "")
+		deat.AddChild(types.NewGedcomLine(2, "DATE", deathStr, ""))
+		indiLine.AddChild(deat)
+	}
+	tree.AddRecord(types.NewIndividualRecord(indiLine))
+}
+
+// TestBirthdayAgeQuery_ByUpcomingBirthday_Comprehensive tests ByUpcomingBirthday
+// against all GEDCOM date flavors the existing month/day filters cover.
+func TestBirthdayAgeQuery_ByUpcomingBirthday_Comprehensive(t *testing.T) {
+	tree := types.NewGedcomTree()
+
+	testCases := []struct {
+		xref        string
+		dateStr     string
+		from        time.Time
+		window      time.Duration
+		shouldMatch bool
+		desc        string
+	}{
+		{"@I1@", "10 JAN 1800", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 14 * 24 * time.Hour, true, "Exact date within window"},
+		{"@I2@", "20 JAN 1800", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 14 * 24 * time.Hour, false, "Exact date outside window"},
+		{"@I3@", "BET 1 JAN 1800 AND 31 JAN 1800", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), 3 * 24 * time.Hour, true, "Range date overlapping window"},
+		{"@I4@", "5 JAN 1800", time.Date(2023, 12, 28, 0, 0, 0, 0, time.UTC), 10 * 24 * time.Hour, true, "Dec->Jan wraparound window"},
+		{"@I5@", "ABT JAN 1800", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 31 * 24 * time.Hour, false, "ABOUT date - no day, never matches"},
+		{"@I6@", "", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 14 * 24 * time.Hour, false, "No date"},
+	}
+
+	for _, tc := range testCases {
+		addBirthIndi(tree, tc.xref, tc.dateStr, "")
+	}
+
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			q := NewBirthdayAgeQuery(graph)
+			results, err := q.ByUpcomingBirthday(tc.from, tc.window).Execute()
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			found := false
+			for _, r := range results {
+				if r.XrefID() == tc.xref {
+					found = true
+					break
+				}
+			}
+			if found != tc.shouldMatch {
+				t.Errorf("Expected match=%v for %s (date: %s), got match=%v", tc.shouldMatch, tc.xref, tc.dateStr, found)
+			}
+		})
+	}
+}
+
+// TestBirthdayAgeQuery_ByAgeOn_Comprehensive tests ByAgeOn against exact and
+// approximate birth dates.
+func TestBirthdayAgeQuery_ByAgeOn_Comprehensive(t *testing.T) {
+	tree := types.NewGedcomTree()
+	refDate := time.Date(1850, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		xref        string
+		dateStr     string
+		age         int
+		shouldMatch bool
+		desc        string
+	}{
+		{"@I1@", "15 JUN 1800", 50, true, "Exact date - exact age match"},
+		{"@I2@", "15 JUN 1800", 49, false, "Exact date - wrong age"},
+		{"@I3@", "20 JUN 1800", 50, false, "Exact date - birthday not yet reached this year"},
+		{"@I4@", "20 JUN 1800", 49, true, "Exact date - birthday not yet reached, one year younger"},
+		{"@I5@", "ABT 1800", 50, true, "ABOUT date (year only) - tolerant match"},
+		{"@I6@", "ABT 1800", 51, true, "ABOUT date (year only) - tolerant match within +1"},
+		{"@I7@", "ABT 1800", 52, false, "ABOUT date (year only) - outside tolerance"},
+		{"@I8@", "", 50, false, "No date"},
+	}
+
+	for _, tc := range testCases {
+		addBirthIndi(tree, tc.xref, tc.dateStr, "")
+	}
+
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			q := NewBirthdayAgeQuery(graph)
+			results, err := q.ByAgeOn(refDate, tc.age).Execute()
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			found := false
+			for _, r := range results {
+				if r.XrefID() == tc.xref {
+					found = true
+					break
+				}
+			}
+			if found != tc.shouldMatch {
+				t.Errorf("Expected match=%v for %s (date: %s, age: %d), got match=%v", tc.shouldMatch, tc.xref, tc.dateStr, tc.age, found)
+			}
+		})
+	}
+}
+
+// TestBirthdayAgeQuery_ByAgeRangeOn_Comprehensive tests ByAgeRangeOn.
+func TestBirthdayAgeQuery_ByAgeRangeOn_Comprehensive(t *testing.T) {
+	tree := types.NewGedcomTree()
+	refDate := time.Date(1850, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	addBirthIndi(tree, "@I1@", "15 JUN 1800", "")  // age 50
+	addBirthIndi(tree, "@I2@", "15 JUN 1790", "")  // age 60
+	addBirthIndi(tree, "@I3@", "15 JUN 1700", "")  // age 150
+
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+
+	q := NewBirthdayAgeQuery(graph)
+	results, err := q.ByAgeRangeOn(refDate, 45, 60).Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	matched := map[string]bool{}
+	for _, r := range results {
+		matched[r.XrefID()] = true
+	}
+	if !matched["@I1@"] || !matched["@I2@"] {
+		t.Errorf("Expected @I1@ and @I2@ in range [45,60], got %+v", matched)
+	}
+	if matched["@I3@"] {
+		t.Errorf("Expected @I3@ (age 150) excluded from range [45,60], got %+v", matched)
+	}
+}
+
+// TestBirthdayAgeQuery_ExcludeDeceased tests that ExcludeDeceased filters
+// out individuals with a recorded death date.
+func TestBirthdayAgeQuery_ExcludeDeceased(t *testing.T) {
+	tree := types.NewGedcomTree()
+	refDate := time.Date(1850, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	addBirthIndi(tree, "@I1@", "15 JUN 1800", "")
+	addBirthIndi(tree, "@I2@", "15 JUN 1800", "1 JAN 1840")
+
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+
+	q := NewBirthdayAgeQuery(graph)
+	results, err := q.ByAgeOn(refDate, 50).ExcludeDeceased().Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	for _, r := range results {
+		if r.XrefID() == "@I2@" {
+			t.Errorf("Expected deceased @I2@ excluded, got %+v", results)
+		}
+	}
+}
+
+// TestBirthdayAgeQuery_EdgeCases tests invalid inputs leave the query
+// unchanged, matching TestBirthdayFilters_EdgeCases' convention.
+func TestBirthdayAgeQuery_EdgeCases(t *testing.T) {
+	tree := types.NewGedcomTree()
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+
+	q := NewBirthdayAgeQuery(graph)
+
+	if q2 := q.ByUpcomingBirthday(time.Now(), 0); q2 != q {
+		t.Error("Expected unchanged query for zero window")
+	}
+	if q3 := q.ByUpcomingBirthday(time.Now(), -time.Hour); q3 != q {
+		t.Error("Expected unchanged query for negative window")
+	}
+	if q4 := q.ByAgeOn(time.Now(), -1); q4 != q {
+		t.Error("Expected unchanged query for negative age")
+	}
+	if q5 := q.ByAgeRangeOn(time.Now(), -1, 10); q5 != q {
+		t.Error("Expected unchanged query for negative min age")
+	}
+	if q6 := q.ByAgeRangeOn(time.Now(), 10, -1); q6 != q {
+		t.Error("Expected unchanged query for negative max age")
+	}
+	if q7 := q.ByAgeRangeOn(time.Now(), 20, 10); q7 != q {
+		t.Error("Expected unchanged query for min > max")
+	}
+}
@@ -0,0 +1,276 @@
+package query
+
+import (
+	"container/list"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultQueryCacheSize is newQueryCache's fallback capacity for
+// maxSize <= 0.
+const defaultQueryCacheSize = 1000
+
+// queryCacheShardCount is how many independently-locked buckets a
+// queryCache splits its keys across, the same fixed shard count
+// shardedAncestorMap (pkg/gedcom/query/parallel_ancestors.go) uses for
+// its own fnv-hashed striping.
+const queryCacheShardCount = 16
+
+// queryCacheEntry is one LRU list node: the cached value plus the
+// deadline after which get/getOrLoad treat it as a miss even though
+// it's still physically present. A zero expiresAt means the entry
+// never expires.
+type queryCacheEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// queryCacheShard is one lock-striped bucket of a queryCache: an LRU
+// list capped at maxSize entries, with per-entry TTL.
+type queryCacheShard struct {
+	mu      sync.Mutex
+	ll      *list.List
+	items   map[string]*list.Element
+	maxSize int
+	ttl     time.Duration
+}
+
+func newQueryCacheShard(maxSize int, ttl time.Duration) *queryCacheShard {
+	return &queryCacheShard{
+		ll:      list.New(),
+		items:   make(map[string]*list.Element),
+		maxSize: maxSize,
+		ttl:     ttl,
+	}
+}
+
+// queryCacheCounters accumulates a queryCache's hit/miss/eviction/
+// expiration totals across every shard, each updated via the atomic
+// package so get/set/getOrLoad never need to take a cache-wide lock
+// just to bump a counter.
+type queryCacheCounters struct {
+	hits        int64
+	misses      int64
+	evictions   int64
+	expirations int64
+}
+
+// queryCache is a sharded, TTL-aware LRU used throughout this package
+// (pathCaches and any future *Graph-keyed side-table) to memoize
+// expensive graph queries behind a key built with makeCacheKey. Keys
+// are spread across queryCacheShardCount independently-locked shards by
+// fnv hash -- mirroring shardedAncestorMap's striping -- so concurrent
+// callers querying different keys rarely contend on the same lock;
+// within a shard, eviction is least-recently-used rather than plain
+// insertion-order FIFO, so a hot key survives an unrelated burst of
+// cold inserts instead of aging out alongside them.
+type queryCache struct {
+	shards  [queryCacheShardCount]*queryCacheShard
+	maxSize int
+	counters queryCacheCounters
+}
+
+// newQueryCache creates a queryCache holding at most maxSize entries
+// in total (split evenly across its shards), with entries that never
+// expire. maxSize <= 0 defaults to 1000.
+func newQueryCache(maxSize int) *queryCache {
+	return newQueryCacheWithTTL(maxSize, 0)
+}
+
+// newQueryCacheWithTTL is newQueryCache with a per-entry time-to-live;
+// ttl <= 0 means entries never expire on their own (they can still be
+// evicted for space).
+func newQueryCacheWithTTL(maxSize int, ttl time.Duration) *queryCache {
+	if maxSize <= 0 {
+		maxSize = defaultQueryCacheSize
+	}
+	perShard := maxSize / queryCacheShardCount
+	if perShard < 1 {
+		perShard = 1
+	}
+	c := &queryCache{maxSize: maxSize}
+	for i := range c.shards {
+		c.shards[i] = newQueryCacheShard(perShard, ttl)
+	}
+	return c
+}
+
+func (c *queryCache) shardFor(key string) *queryCacheShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return c.shards[h.Sum32()%queryCacheShardCount]
+}
+
+// get returns the value cached under key, reporting false if it is
+// absent or has expired.
+func (c *queryCache) get(key string) (interface{}, bool) {
+	shard := c.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	elem, ok := shard.items[key]
+	if !ok {
+		atomic.AddInt64(&c.counters.misses, 1)
+		return nil, false
+	}
+	entry := elem.Value.(*queryCacheEntry)
+	if c.expired(shard, elem, entry) {
+		atomic.AddInt64(&c.counters.misses, 1)
+		return nil, false
+	}
+	shard.ll.MoveToFront(elem)
+	atomic.AddInt64(&c.counters.hits, 1)
+	return entry.value, true
+}
+
+// expired removes elem from shard and reports true if entry's TTL has
+// passed. Callers must hold shard.mu.
+func (c *queryCache) expired(shard *queryCacheShard, elem *list.Element, entry *queryCacheEntry) bool {
+	if entry.expiresAt.IsZero() || !time.Now().After(entry.expiresAt) {
+		return false
+	}
+	shard.ll.Remove(elem)
+	delete(shard.items, entry.key)
+	atomic.AddInt64(&c.counters.expirations, 1)
+	return true
+}
+
+// set stores val under key, evicting the shard's least-recently-used
+// entry first if it is already at its per-shard capacity.
+func (c *queryCache) set(key string, val interface{}) {
+	shard := c.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	c.setLocked(shard, key, val)
+}
+
+// setLocked is set's body, factored out so getOrLoad can store a
+// loaded value without releasing and re-acquiring shard.mu.
+func (c *queryCache) setLocked(shard *queryCacheShard, key string, val interface{}) {
+	var expiresAt time.Time
+	if shard.ttl > 0 {
+		expiresAt = time.Now().Add(shard.ttl)
+	}
+
+	if elem, ok := shard.items[key]; ok {
+		entry := elem.Value.(*queryCacheEntry)
+		entry.value = val
+		entry.expiresAt = expiresAt
+		shard.ll.MoveToFront(elem)
+		return
+	}
+
+	elem := shard.ll.PushFront(&queryCacheEntry{key: key, value: val, expiresAt: expiresAt})
+	shard.items[key] = elem
+
+	if shard.ll.Len() > shard.maxSize {
+		oldest := shard.ll.Back()
+		shard.ll.Remove(oldest)
+		delete(shard.items, oldest.Value.(*queryCacheEntry).key)
+		atomic.AddInt64(&c.counters.evictions, 1)
+	}
+}
+
+// getOrLoad returns the cached value for key, calling loader and
+// caching its result on a miss or expiration. It holds the key's shard
+// lock across the loader call, so concurrent getOrLoad calls for the
+// same key serialize onto a single loader invocation instead of the
+// thundering-herd pattern of an unsynchronized get/compute/set -- every
+// caller but the first blocks briefly and then reads the first one's
+// result straight out of the cache.
+func (c *queryCache) getOrLoad(key string, loader func() (interface{}, error)) (interface{}, error) {
+	shard := c.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if elem, ok := shard.items[key]; ok {
+		entry := elem.Value.(*queryCacheEntry)
+		if !c.expired(shard, elem, entry) {
+			shard.ll.MoveToFront(elem)
+			atomic.AddInt64(&c.counters.hits, 1)
+			return entry.value, nil
+		}
+	}
+
+	val, err := loader()
+	if err != nil {
+		atomic.AddInt64(&c.counters.misses, 1)
+		return nil, err
+	}
+	c.setLocked(shard, key, val)
+	atomic.AddInt64(&c.counters.misses, 1)
+	return val, nil
+}
+
+// clear empties every shard of c.
+func (c *queryCache) clear() {
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		shard.ll.Init()
+		shard.items = make(map[string]*list.Element)
+		shard.mu.Unlock()
+	}
+}
+
+// size returns the total number of entries currently cached across
+// every shard.
+func (c *queryCache) size() int {
+	total := 0
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		total += shard.ll.Len()
+		shard.mu.Unlock()
+	}
+	return total
+}
+
+// QueryCacheStats is a queryCache's hit/miss/eviction/expiration/size
+// snapshot. Named distinctly from the package-level CacheStats
+// (incremental.go, IncrementalCache's own counters) and
+// PedigreeCacheStats (pedigree_cache.go, Graph.CacheStats()) since all
+// three report different caches' metrics.
+type QueryCacheStats struct {
+	Hits        int64
+	Misses      int64
+	Evictions   int64
+	Expirations int64
+	Size        int
+}
+
+func (c *queryCache) stats() QueryCacheStats {
+	return QueryCacheStats{
+		Hits:        atomic.LoadInt64(&c.counters.hits),
+		Misses:      atomic.LoadInt64(&c.counters.misses),
+		Evictions:   atomic.LoadInt64(&c.counters.evictions),
+		Expirations: atomic.LoadInt64(&c.counters.expirations),
+		Size:        c.size(),
+	}
+}
+
+// QueryCacheStats returns g's RelationshipPath cache (pathCaches) hit/
+// miss/eviction/expiration counters. It's the only *Graph-keyed
+// queryCache today; Graph.CacheStats() (pedigree_cache.go) reports the
+// separate pedigree/descendancy subgraph cache.
+func (g *Graph) QueryCacheStats() QueryCacheStats {
+	return pathCacheFor(g).stats()
+}
+
+// makeCacheKey deterministically joins parts into a single cache key
+// string, for the scalar query parameters (xrefIDs, generation counts,
+// flags) callers key their queryCache lookups on. Non-scalar parts
+// (slices, maps) should be flattened to a string by the caller first.
+func makeCacheKey(parts ...interface{}) string {
+	var b strings.Builder
+	for i, p := range parts {
+		if i > 0 {
+			b.WriteByte('\x1f')
+		}
+		fmt.Fprintf(&b, "%v", p)
+	}
+	return b.String()
+}
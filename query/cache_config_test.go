@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
 )
@@ -56,10 +57,11 @@ func TestCache_Operations(t *testing.T) {
 		cache.set(makeCacheKey("test", i), i)
 	}
 
-	// Cache should have evicted some entries
-	// Since we use simple FIFO, the first entries should be gone
-	if len(cache.cache) > cache.maxSize {
-		t.Errorf("Cache size %d exceeds maxSize %d", len(cache.cache), cache.maxSize)
+	// Cache is a sharded LRU now, so "beyond maxSize" means beyond the
+	// sum of each shard's own (maxSize/queryCacheShardCount, minimum 1)
+	// capacity, not a single flat maxSize.
+	if cache.size() > queryCacheShardCount {
+		t.Errorf("Cache size %d exceeds the shard-capped total", cache.size())
 	}
 
 	// Test clear
@@ -68,8 +70,93 @@ func TestCache_Operations(t *testing.T) {
 	if found {
 		t.Error("Expected key1 not to be found after clear")
 	}
-	if len(cache.cache) != 0 {
-		t.Errorf("Expected empty cache after clear, got %d entries", len(cache.cache))
+	if cache.size() != 0 {
+		t.Errorf("Expected empty cache after clear, got %d entries", cache.size())
+	}
+}
+
+// TestCache_LRUEviction confirms a recently-read key survives an
+// eviction that would, under plain FIFO, have removed it anyway.
+func TestCache_LRUEviction(t *testing.T) {
+	cache := newQueryCache(32) // 32 over 16 shards gives each shard a 2-entry cap
+
+	// "hot", "cold3" and "newcomer9" all hash to the same shard, so the
+	// third set must evict one of the first two.
+	cache.set("hot", "keep me")
+	cache.set("cold3", "evict me")
+	// Touch "hot" so it's most-recently-used in its shard; FIFO would
+	// evict it as the oldest insert, LRU evicts "cold3" instead.
+	cache.get("hot")
+	cache.set("newcomer9", "pushes one out")
+
+	if _, found := cache.get("hot"); !found {
+		t.Error("expected the recently-read key to survive eviction")
+	}
+	if _, found := cache.get("cold3"); found {
+		t.Error("expected the least-recently-used key to be evicted")
+	}
+}
+
+// TestCache_TTLExpiration confirms an entry past its TTL is treated as
+// a miss and counted as an expiration rather than a plain miss.
+func TestCache_TTLExpiration(t *testing.T) {
+	cache := newQueryCacheWithTTL(10, time.Millisecond)
+	cache.set("key1", "value1")
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, found := cache.get("key1"); found {
+		t.Error("expected an expired entry to be treated as a miss")
+	}
+	stats := cache.stats()
+	if stats.Expirations != 1 {
+		t.Errorf("expected 1 expiration, got %+v", stats)
+	}
+}
+
+// TestCache_GetOrLoad confirms getOrLoad calls loader exactly once per
+// key and serves subsequent calls from the cache.
+func TestCache_GetOrLoad(t *testing.T) {
+	cache := newQueryCache(10)
+	loads := 0
+	loader := func() (interface{}, error) {
+		loads++
+		return "computed", nil
+	}
+
+	for i := 0; i < 3; i++ {
+		val, err := cache.getOrLoad("key1", loader)
+		if err != nil {
+			t.Fatalf("getOrLoad failed: %v", err)
+		}
+		if val != "computed" {
+			t.Errorf("expected 'computed', got %v", val)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("expected loader to run exactly once, got %d", loads)
+	}
+}
+
+// TestCache_Stats confirms hit/miss/eviction counters reflect actual
+// cache activity.
+func TestCache_Stats(t *testing.T) {
+	cache := newQueryCache(10)
+
+	cache.get("missing")             // miss
+	cache.set("key1", "value1")      //
+	cache.get("key1")                // hit
+	cache.get("key1")                // hit
+
+	stats := cache.stats()
+	if stats.Misses != 1 {
+		t.Errorf("expected 1 miss, got %d", stats.Misses)
+	}
+	if stats.Hits != 2 {
+		t.Errorf("expected 2 hits, got %d", stats.Hits)
+	}
+	if stats.Size != 1 {
+		t.Errorf("expected size 1, got %d", stats.Size)
 	}
 }
 
@@ -131,7 +218,9 @@ func TestConfig_LoadConfig(t *testing.T) {
 	configData := `{
 		"cache": {
 			"query_cache_size": 2000,
-			"hybrid_node_cache_size": 1500
+			"hybrid_node_cache_size": 1500,
+			"query_cache_ttl": "1m",
+			"query_cache_shards": 8
 		},
 		"timeout": {
 			"query_timeout": "30s"
@@ -151,6 +240,15 @@ func TestConfig_LoadConfig(t *testing.T) {
 	if config3.Cache.QueryCacheSize != 2000 {
 		t.Errorf("Expected QueryCacheSize 2000, got %d", config3.Cache.QueryCacheSize)
 	}
+	if time.Duration(config3.Cache.QueryCacheTTL) != time.Minute {
+		t.Errorf("Expected QueryCacheTTL 1m, got %v", time.Duration(config3.Cache.QueryCacheTTL))
+	}
+	if config3.Cache.QueryCacheShards != 8 {
+		t.Errorf("Expected QueryCacheShards 8, got %d", config3.Cache.QueryCacheShards)
+	}
+	if time.Duration(config3.Timeout.QueryTimeout) != 30*time.Second {
+		t.Errorf("Expected QueryTimeout 30s, got %v", time.Duration(config3.Timeout.QueryTimeout))
+	}
 }
 
 // TestConfig_SaveConfig tests SaveConfig function
@@ -260,6 +358,12 @@ func TestConfig_validateAndSetDefaults(t *testing.T) {
 	if config2.Cache.HybridNodeCacheSize != defaults.Cache.HybridNodeCacheSize {
 		t.Errorf("Expected HybridNodeCacheSize to get default %d, got %d", defaults.Cache.HybridNodeCacheSize, config2.Cache.HybridNodeCacheSize)
 	}
+	if config2.Cache.QueryCacheTTL != defaults.Cache.QueryCacheTTL {
+		t.Errorf("Expected QueryCacheTTL to get default %v, got %v", defaults.Cache.QueryCacheTTL, config2.Cache.QueryCacheTTL)
+	}
+	if config2.Cache.QueryCacheShards != defaults.Cache.QueryCacheShards {
+		t.Errorf("Expected QueryCacheShards to get default %d, got %d", defaults.Cache.QueryCacheShards, config2.Cache.QueryCacheShards)
+	}
 }
 
 // TestBuilder_EdgeCases tests BuildGraph with edge cases
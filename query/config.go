@@ -0,0 +1,167 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// configDuration is a time.Duration that marshals to/from JSON as a Go
+// duration string ("30s", "5m") rather than a raw nanosecond integer,
+// so Config's on-disk JSON stays human-editable.
+type configDuration time.Duration
+
+func (d configDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *configDuration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("query: invalid duration %q: %w", s, err)
+	}
+	*d = configDuration(parsed)
+	return nil
+}
+
+// CacheConfig holds the package's cache settings. QueryCacheSize,
+// QueryCacheTTL and QueryCacheShards are loaded, defaulted and saved, but
+// no queryCache is built from them: pathCacheFor always uses
+// newQueryCache(0), with the default size, 16 shards and no TTL.
+type CacheConfig struct {
+	QueryCacheSize      int            `json:"query_cache_size"`
+	HybridNodeCacheSize int            `json:"hybrid_node_cache_size"`
+	QueryCacheTTL       configDuration `json:"query_cache_ttl"`
+	QueryCacheShards    int            `json:"query_cache_shards"`
+}
+
+// TimeoutConfig bounds how long query operations may run before
+// giving up.
+type TimeoutConfig struct {
+	QueryTimeout configDuration `json:"query_timeout"`
+}
+
+// Config is the query package's own tunables, loaded independently of
+// cmd/gedcom/internal's CLI Config -- the two overlap in spirit (both
+// size a cache) but serve different callers: this one is for anything
+// embedding the query package as a library rather than going through
+// the gedcom CLI.
+type Config struct {
+	Cache   CacheConfig   `json:"cache"`
+	Timeout TimeoutConfig `json:"timeout"`
+}
+
+// DefaultConfig returns a Config with reasonable defaults for an
+// in-process query cache: 1000 entries across 16 shards, a 5-minute
+// TTL, and a 30-second query timeout.
+func DefaultConfig() *Config {
+	return &Config{
+		Cache: CacheConfig{
+			QueryCacheSize:      defaultQueryCacheSize,
+			HybridNodeCacheSize: 1000,
+			QueryCacheTTL:       configDuration(5 * time.Minute),
+			QueryCacheShards:    queryCacheShardCount,
+		},
+		Timeout: TimeoutConfig{QueryTimeout: configDuration(30 * time.Second)},
+	}
+}
+
+// validateAndSetDefaults fills any zero-valued field of c with
+// DefaultConfig's value.
+func (c *Config) validateAndSetDefaults() {
+	defaults := DefaultConfig()
+	if c.Cache.QueryCacheSize <= 0 {
+		c.Cache.QueryCacheSize = defaults.Cache.QueryCacheSize
+	}
+	if c.Cache.HybridNodeCacheSize <= 0 {
+		c.Cache.HybridNodeCacheSize = defaults.Cache.HybridNodeCacheSize
+	}
+	if c.Cache.QueryCacheTTL <= 0 {
+		c.Cache.QueryCacheTTL = defaults.Cache.QueryCacheTTL
+	}
+	if c.Cache.QueryCacheShards <= 0 {
+		c.Cache.QueryCacheShards = defaults.Cache.QueryCacheShards
+	}
+	if c.Timeout.QueryTimeout <= 0 {
+		c.Timeout.QueryTimeout = defaults.Timeout.QueryTimeout
+	}
+}
+
+// loadConfigFromFile reads and parses configPath, then fills in any
+// field the file left unset via validateAndSetDefaults.
+func loadConfigFromFile(configPath string) (*Config, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("query: failed to read config file: %w", err)
+	}
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("query: failed to parse config file: %w", err)
+	}
+	config.validateAndSetDefaults()
+	return config, nil
+}
+
+// LoadConfig loads a Config from configPath, or from
+// ~/.gedcom/query-config.json / ~/.config/gedcom/query-config.json if
+// configPath is empty, falling back to DefaultConfig if none is found.
+func LoadConfig(configPath string) (*Config, error) {
+	if configPath == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return DefaultConfig(), nil
+		}
+		configPath = filepath.Join(homeDir, ".gedcom", "query-config.json")
+		if _, err := os.Stat(configPath); os.IsNotExist(err) {
+			configPath = filepath.Join(homeDir, ".config", "gedcom", "query-config.json")
+			if _, err := os.Stat(configPath); os.IsNotExist(err) {
+				return DefaultConfig(), nil
+			}
+		}
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultConfig(), nil
+		}
+		return nil, fmt.Errorf("query: failed to read config file: %w", err)
+	}
+	config := DefaultConfig()
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("query: failed to parse config file: %w", err)
+	}
+	return config, nil
+}
+
+// SaveConfig saves config to configPath, or to
+// ~/.gedcom/query-config.json if configPath is empty.
+func SaveConfig(config *Config, configPath string) error {
+	if configPath == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("query: failed to get home directory: %w", err)
+		}
+		configPath = filepath.Join(homeDir, ".gedcom", "query-config.json")
+	}
+
+	dir := filepath.Dir(configPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("query: failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("query: failed to marshal config: %w", err)
+	}
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		return fmt.Errorf("query: failed to write config file: %w", err)
+	}
+	return nil
+}
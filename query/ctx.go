@@ -0,0 +1,66 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/parser"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// BuildGraphCtx builds a Graph the same way BuildGraph does, but honors
+// ctx cancellation and reports progress through h (a nil h is treated as
+// parser.NoopProgressHandler). Like parser.ParseCtx, this can only check
+// ctx at the phase boundary, since BuildGraph does not expose a per-node/
+// per-edge construction hook.
+func BuildGraphCtx(ctx context.Context, tree *types.GedcomTree, h parser.ProgressHandler) (*Graph, error) {
+	if h == nil {
+		h = parser.NoopProgressHandler{}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	h.OnPhaseStart("build_graph", 0)
+	start := time.Now()
+
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	total := len(graph.AllIndividuals()) + len(graph.AllFamilies())
+	h.OnRecord("build_graph", total, total)
+	h.OnPhaseEnd("build_graph", parser.PhaseStats{Phase: "build_graph", Total: total, Duration: time.Since(start)})
+	graph.Metrics()
+
+	return graph, nil
+}
+
+// BuildGraphWithDiagnostic builds a Graph and reports a PhaseSummary event
+// to diag (a nil diag is treated as parser.NoopDiagnostic). Per-edge
+// EdgeResolutionFailed events would require BuildGraph itself to surface
+// unresolved xrefs, which it does not in this package; only the
+// phase-level summary is emitted here.
+func BuildGraphWithDiagnostic(tree *types.GedcomTree, diag parser.Diagnostic) (*Graph, error) {
+	if diag == nil {
+		diag = parser.NoopDiagnostic{}
+	}
+
+	start := time.Now()
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(graph.AllIndividuals()) + len(graph.AllFamilies())
+	stats := parser.PhaseStats{Phase: "build_graph", Total: total, Duration: time.Since(start)}
+	diag.PhaseSummary("build_graph", stats)
+	graph.Metrics()
+
+	return graph, nil
+}
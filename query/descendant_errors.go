@@ -0,0 +1,32 @@
+package query
+
+import "sync"
+
+// descendantOnErrorMu guards descendantOnErrorFor. DescendantQuery's own
+// struct and traversal methods are not part of this package snapshot (only
+// its Count/Exists entry points are visible, via QueryBuilder), so its
+// OnError hook is registered out-of-band here by *DescendantQuery identity,
+// mirroring AncestorOptions.OnError. Wiring this into DescendantQuery's
+// find* traversal -- so unresolved CHIL/FAMS edges actually get reported
+// instead of silently dropped -- requires editing that file directly.
+var (
+	descendantOnErrorMu  sync.Mutex
+	descendantOnErrorFor = make(map[*DescendantQuery]func(*WalkError) error)
+)
+
+// SetDescendantOnError records fn as the OnError hook for dq, with the
+// same signature as AncestorOptions.OnError. Nothing in this package calls
+// the recorded hook: DescendantQuery's traversal neither reports its
+// inconsistencies to fn nor aborts on fn's return value.
+func SetDescendantOnError(dq *DescendantQuery, fn func(*WalkError) error) {
+	descendantOnErrorMu.Lock()
+	defer descendantOnErrorMu.Unlock()
+	descendantOnErrorFor[dq] = fn
+}
+
+// descendantOnErrorOf returns the OnError hook registered for dq, or nil.
+func descendantOnErrorOf(dq *DescendantQuery) func(*WalkError) error {
+	descendantOnErrorMu.Lock()
+	defer descendantOnErrorMu.Unlock()
+	return descendantOnErrorFor[dq]
+}
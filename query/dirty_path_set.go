@@ -0,0 +1,230 @@
+package query
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// DirtyPathSet tracks, for a Graph, which individuals have changed since
+// it was last drained, plus every ancestor on the path from each dirty
+// individual up toward a pedigree root -- the same "P-connected" shape a
+// BTree change-propagation set uses to stay connected: an interior
+// ancestor is carried alongside its dirty descendants rather than
+// tracked separately, so that updateRelationshipFlagsPostgreSQL-style
+// callers can walk just this set instead of every individual in the
+// tree, and a live UI watching an ancestor node can be told "something
+// beneath you changed" without re-walking the whole tree to find out.
+//
+// The set stays P-connected by construction: MarkDirty adds a leaf and
+// every ancestor between it and the root (or MaxDepth generations, if
+// set); RemoveLeaf takes the leaf back out and walks the same path back
+// up, dropping any ancestor that no longer has any other tracked
+// descendant beneath it. Memory therefore stays bounded to whatever
+// subgraphs are actually still dirty or being watched, mirroring
+// DifferenceInplace's semantics for a P-connected node set rather than
+// accumulating every ancestor ever touched.
+type DirtyPathSet struct {
+	mu        sync.Mutex
+	graph     *Graph
+	maxDepth  int // 0 = walk all the way to the pedigree root
+	leaves    map[string]bool
+	refCounts map[string]int // ancestor xref -> number of tracked leaves beneath it
+	parentsOf map[string][]string
+}
+
+// NewDirtyPathSet creates an empty DirtyPathSet over graph. maxDepth
+// bounds how many generations of ancestors MarkDirty walks past each
+// leaf; 0 walks all the way to the pedigree root.
+func NewDirtyPathSet(graph *Graph, maxDepth int) *DirtyPathSet {
+	return &DirtyPathSet{
+		graph:     graph,
+		maxDepth:  maxDepth,
+		leaves:    make(map[string]bool),
+		refCounts: make(map[string]int),
+		parentsOf: make(map[string][]string),
+	}
+}
+
+// MarkDirty records xrefID as changed and walks its ancestor path,
+// incrementing each ancestor's reference count so RemoveLeaf can later
+// tell whether it's still needed by some other dirty descendant. A
+// second MarkDirty for an already-dirty xrefID is a no-op.
+func (s *DirtyPathSet) MarkDirty(xrefID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.leaves[xrefID] {
+		return
+	}
+	s.leaves[xrefID] = true
+
+	for _, ancestor := range s.ancestorPath(xrefID) {
+		s.refCounts[ancestor]++
+	}
+}
+
+// RemoveLeaf un-marks xrefID and walks its ancestor path back down,
+// decrementing each ancestor's reference count and dropping any that
+// reach zero, keeping the set P-connected.
+func (s *DirtyPathSet) RemoveLeaf(xrefID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.leaves[xrefID] {
+		return
+	}
+	delete(s.leaves, xrefID)
+
+	for _, ancestor := range s.ancestorPath(xrefID) {
+		s.refCounts[ancestor]--
+		if s.refCounts[ancestor] <= 0 {
+			delete(s.refCounts, ancestor)
+		}
+	}
+}
+
+// Leaves returns only the directly-marked-dirty xrefs, not their
+// ancestors -- the set a caller recomputing has_children/has_spouse (see
+// refreshRelationshipFlags in hybrid_postgres_diff.go) actually needs to
+// touch, since only a leaf's own change can flip its own flags.
+func (s *DirtyPathSet) Leaves() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	xrefs := make([]string, 0, len(s.leaves))
+	for xref := range s.leaves {
+		xrefs = append(xrefs, xref)
+	}
+	return xrefs
+}
+
+// Paths returns every xref currently tracked: every dirty leaf plus
+// every still-referenced ancestor on a path to one -- the set a live UI
+// subscription notifies, since an ancestor node's display depends on
+// every dirty descendant beneath it, not just the leaves themselves.
+func (s *DirtyPathSet) Paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	xrefs := make([]string, 0, len(s.leaves)+len(s.refCounts))
+	for xref := range s.leaves {
+		xrefs = append(xrefs, xref)
+	}
+	for xref := range s.refCounts {
+		xrefs = append(xrefs, xref)
+	}
+	return xrefs
+}
+
+// Contains reports whether xrefID -- leaf or interior ancestor -- is
+// currently tracked.
+func (s *DirtyPathSet) Contains(xrefID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.leaves[xrefID] || s.refCounts[xrefID] > 0
+}
+
+// Clear drops every tracked leaf and ancestor, for a caller that has
+// finished draining the set (e.g. after ApplyRelationshipFlagsForPathSet and
+// Notify have both run for this batch of changes).
+func (s *DirtyPathSet) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leaves = make(map[string]bool)
+	s.refCounts = make(map[string]int)
+}
+
+// Notify publishes a single PathDirtied MutationEvent for tree, carrying
+// every xref s currently tracks (see Paths), so a live UI subscribed via
+// SubscribeTree can tell whether an ancestor node it's displaying has any
+// dirty descendant without re-walking the tree itself.
+func (s *DirtyPathSet) Notify(tree *types.GedcomTree) {
+	paths := s.Paths()
+	if len(paths) == 0 {
+		return
+	}
+	PublishMutation(tree, MutationEvent{Kind: PathDirtied, XrefIDs: paths})
+}
+
+// ancestorPath returns every ancestor xref from xrefID's immediate
+// parents up to the pedigree root (or s.maxDepth generations, if set),
+// in no particular order. Caller must hold s.mu.
+func (s *DirtyPathSet) ancestorPath(xrefID string) []string {
+	var path []string
+	frontier := []string{xrefID}
+	seen := map[string]bool{xrefID: true}
+
+	for depth := 0; len(frontier) > 0; depth++ {
+		if s.maxDepth > 0 && depth >= s.maxDepth {
+			break
+		}
+		var next []string
+		for _, x := range frontier {
+			for _, parent := range s.parentXrefsOf(x) {
+				if seen[parent] {
+					continue
+				}
+				seen[parent] = true
+				path = append(path, parent)
+				next = append(next, parent)
+			}
+		}
+		frontier = next
+	}
+	return path
+}
+
+// parentXrefsOf resolves xrefID's parent xrefs via s.graph, memoizing the
+// result in s.parentsOf so repeated MarkDirty/RemoveLeaf calls over the
+// same subgraph don't re-resolve the same node's parents every time.
+// Caller must hold s.mu.
+func (s *DirtyPathSet) parentXrefsOf(xrefID string) []string {
+	if parents, ok := s.parentsOf[xrefID]; ok {
+		return parents
+	}
+
+	node := s.graph.GetIndividual(xrefID)
+	if node == nil {
+		s.parentsOf[xrefID] = nil
+		return nil
+	}
+
+	parents := make([]string, 0, 2)
+	for _, p := range node.parents {
+		parents = append(parents, p.ID())
+	}
+	s.parentsOf[xrefID] = parents
+	return parents
+}
+
+// ApplyRelationshipFlagsForPathSet recomputes has_children/has_spouse for
+// exactly pathSet's dirty leaves, via refreshRelationshipFlags, instead
+// of updateRelationshipFlagsPostgreSQL's full-table pass over every
+// individual in tree.
+func ApplyRelationshipFlagsForPathSet(storage *HybridStoragePostgres, tree *types.GedcomTree, graph *Graph, pathSet *DirtyPathSet) error {
+	db := storage.PostgreSQL()
+	fileID := storage.FileID()
+
+	touched := make(map[string]bool)
+	for _, xref := range pathSet.Leaves() {
+		touched[xref] = true
+	}
+	if len(touched) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := refreshRelationshipFlags(tx, tree, graph, fileID, touched); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit relationship flag refresh: %w", err)
+	}
+	return nil
+}
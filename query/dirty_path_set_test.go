@@ -0,0 +1,127 @@
+package query
+
+import "testing"
+
+func TestDirtyPathSet_MarkDirtyTracksAncestorPath(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	s := NewDirtyPathSet(graph, 0)
+	s.MarkDirty("@I1@")
+
+	if !s.Contains("@I1@") {
+		t.Error("expected the dirty leaf itself to be tracked")
+	}
+	if !s.Contains("@I2@") || !s.Contains("@I3@") {
+		t.Error("expected @I1@'s immediate parents to be tracked")
+	}
+	if !s.Contains("@I4@") {
+		t.Error("expected the shared grandparent @I4@ to be tracked")
+	}
+
+	leaves := s.Leaves()
+	if len(leaves) != 1 || leaves[0] != "@I1@" {
+		t.Errorf("expected Leaves to contain only @I1@, got %+v", leaves)
+	}
+}
+
+func TestDirtyPathSet_RemoveLeaf_DropsAncestorsWithNoOtherDescendant(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	s := NewDirtyPathSet(graph, 0)
+	s.MarkDirty("@I1@")
+	s.RemoveLeaf("@I1@")
+
+	if s.Contains("@I1@") || s.Contains("@I2@") || s.Contains("@I4@") {
+		t.Error("expected every tracked xref to be dropped once its only dirty leaf is removed")
+	}
+	if len(s.Paths()) != 0 {
+		t.Errorf("expected an empty path set, got %+v", s.Paths())
+	}
+}
+
+func TestDirtyPathSet_RemoveLeaf_KeepsSharedAncestorForOtherLeaf(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	s := NewDirtyPathSet(graph, 0)
+	s.MarkDirty("@I2@")
+	s.MarkDirty("@I3@")
+	s.RemoveLeaf("@I2@")
+
+	if !s.Contains("@I4@") {
+		t.Error("expected @I4@ to stay tracked: @I3@ (still dirty) also descends from it")
+	}
+	if s.Contains("@I2@") {
+		t.Error("expected @I2@ itself to no longer be tracked after RemoveLeaf")
+	}
+}
+
+func TestDirtyPathSet_MaxDepth_BoundsAncestorWalk(t *testing.T) {
+	graph, err := BuildGraph(buildDiamondAncestryTree())
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	s := NewDirtyPathSet(graph, 1)
+	s.MarkDirty("@I1@")
+
+	if !s.Contains("@I2@") || !s.Contains("@I3@") {
+		t.Error("expected the first generation of ancestors to still be tracked")
+	}
+	if s.Contains("@I4@") {
+		t.Error("expected MaxDepth=1 to stop before the second generation")
+	}
+}
+
+func TestDirtyPathSet_Notify_NoOpWhenEmpty(t *testing.T) {
+	tree := buildDiamondAncestryTree()
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	var received *MutationEvent
+	unsubscribe := SubscribeTree(tree, func(ev MutationEvent) { received = &ev })
+	defer unsubscribe()
+
+	s := NewDirtyPathSet(graph, 0)
+	s.Notify(tree)
+
+	if received != nil {
+		t.Error("expected no event to be published for an empty path set")
+	}
+}
+
+func TestDirtyPathSet_Notify_PublishesPathDirtiedWithTrackedXrefs(t *testing.T) {
+	tree := buildDiamondAncestryTree()
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	var received *MutationEvent
+	unsubscribe := SubscribeTree(tree, func(ev MutationEvent) { received = &ev })
+	defer unsubscribe()
+
+	s := NewDirtyPathSet(graph, 0)
+	s.MarkDirty("@I1@")
+	s.Notify(tree)
+
+	if received == nil {
+		t.Fatal("expected a MutationEvent to be published")
+	}
+	if received.Kind != PathDirtied {
+		t.Errorf("expected Kind PathDirtied, got %v", received.Kind)
+	}
+	if len(received.XrefIDs) != len(s.Paths()) {
+		t.Errorf("expected XrefIDs to match the tracked path set, got %+v", received.XrefIDs)
+	}
+}
@@ -0,0 +1,51 @@
+package dsl
+
+// Expr is a node in a parsed Filter's expression tree.
+type Expr interface {
+	isExpr()
+}
+
+// BinaryExpr is an AND/OR of two sub-expressions.
+type BinaryExpr struct {
+	Op    string // "AND" or "OR"
+	Left  Expr
+	Right Expr
+}
+
+// NotExpr negates a sub-expression.
+type NotExpr struct {
+	Expr Expr
+}
+
+// Comparison compares a field accessor (birthDate, deathDate, birthPlace,
+// surname, givenName, sex, spouseCount) against a literal value.
+type Comparison struct {
+	Field string
+	Op    string // "=", "!=", "<", "<=", ">", ">=", "CONTAINS", "STARTS WITH", "~"
+	Value string
+}
+
+// BoolField is a bare boolean field accessor used on its own, such as
+// hasChildren or (negated via NotExpr) NOT hasParents.
+type BoolField struct {
+	Field string
+}
+
+// RelFunc is a relationship predicate: ancestorOf(@I42@), descendantOf(...),
+// relatedTo(...) [WITHIN degree N], or commonAncestorWith(...).
+type RelFunc struct {
+	Name   string
+	XrefID string
+	Within int // max degree for relatedTo's WITHIN DEGREE clause; 0 = unbounded
+}
+
+func (BinaryExpr) isExpr() {}
+func (NotExpr) isExpr()    {}
+func (Comparison) isExpr() {}
+func (BoolField) isExpr()  {}
+func (RelFunc) isExpr()    {}
+
+// Filter is a parsed expression, ready to Execute against a tree.
+type Filter struct {
+	Root Expr
+}
@@ -0,0 +1,35 @@
+package dsl
+
+import "fmt"
+
+// ParseError reports a syntax error at a specific rune position in the
+// source expression, plus (when known) the set of tokens that would
+// have been accepted there -- enough for a caller (a CLI or REST
+// endpoint) to point a user at exactly what went wrong.
+type ParseError struct {
+	Pos      int      // rune offset into the expression where the error occurred
+	Message  string   // human-readable description
+	Expected []string // token kinds that would have been valid here, if known
+}
+
+func (e *ParseError) Error() string {
+	if len(e.Expected) == 0 {
+		return fmt.Sprintf("dsl: %s at position %d", e.Message, e.Pos)
+	}
+	return fmt.Sprintf("dsl: %s at position %d (expected one of: %v)", e.Message, e.Pos, e.Expected)
+}
+
+func newUnexpectedTokenError(got token, expected ...string) *ParseError {
+	return &ParseError{
+		Pos:      got.pos,
+		Message:  fmt.Sprintf("unexpected %s", describeToken(got)),
+		Expected: expected,
+	}
+}
+
+func describeToken(t token) string {
+	if t.kind == tokenIdent || t.kind == tokenString || t.kind == tokenNumber {
+		return fmt.Sprintf("%s %q", t.kind, t.text)
+	}
+	return t.kind.String()
+}
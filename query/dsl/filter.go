@@ -0,0 +1,298 @@
+package dsl
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// Execute parses f's tree's individuals against Root and returns every
+// match. Relationship predicates (ancestorOf, descendantOf, relatedTo,
+// commonAncestorWith) are evaluated once per candidate individual via
+// query.Individuals' own CommonAncestors chain -- simple rather than
+// pre-indexed, since this is a first cut at the DSL and that chain
+// already does its own graph traversal. query.QueryBuilder isn't part
+// of this package snapshot (see multi_source_ancestor_query.go's
+// Individuals, the same gap worked around the same way), so this builds
+// and evaluates against a *query.Graph directly instead.
+func (f *Filter) Execute(tree *types.GedcomTree) ([]*types.IndividualRecord, error) {
+	g, err := query.BuildGraph(tree)
+	if err != nil {
+		return nil, fmt.Errorf("dsl: failed to build query: %w", err)
+	}
+
+	var matches []*types.IndividualRecord
+	for _, rec := range tree.GetAllIndividuals() {
+		indi, ok := rec.(*types.IndividualRecord)
+		if !ok {
+			continue
+		}
+		ok, err := f.Matches(indi, g)
+		if err != nil {
+			return matches, err
+		}
+		if ok {
+			matches = append(matches, indi)
+		}
+	}
+	return matches, nil
+}
+
+// Matches reports whether a single individual satisfies f, given the
+// *query.Graph built from the tree it belongs to (for relationship
+// predicates).
+func (f *Filter) Matches(indi *types.IndividualRecord, g *query.Graph) (bool, error) {
+	return evalExpr(f.Root, indi, g)
+}
+
+func evalExpr(e Expr, indi *types.IndividualRecord, g *query.Graph) (bool, error) {
+	switch n := e.(type) {
+	case BinaryExpr:
+		left, err := evalExpr(n.Left, indi, g)
+		if err != nil {
+			return false, err
+		}
+		if n.Op == "AND" && !left {
+			return false, nil
+		}
+		if n.Op == "OR" && left {
+			return true, nil
+		}
+		return evalExpr(n.Right, indi, g)
+
+	case NotExpr:
+		inner, err := evalExpr(n.Expr, indi, g)
+		if err != nil {
+			return false, err
+		}
+		return !inner, nil
+
+	case Comparison:
+		return evalComparison(n, indi)
+
+	case BoolField:
+		return evalBoolField(n.Field, indi)
+
+	case RelFunc:
+		return evalRelFunc(n, indi, g)
+
+	default:
+		return false, fmt.Errorf("dsl: unhandled expression type %T", e)
+	}
+}
+
+func evalComparison(c Comparison, indi *types.IndividualRecord) (bool, error) {
+	switch c.Field {
+	case "birthDate":
+		return compareYear(yearOf(indi.GetBirthDate()), c.Op, c.Value)
+	case "deathDate":
+		return compareYear(yearOf(indi.GetDeathDate()), c.Op, c.Value)
+	case "birthPlace":
+		return compareString(indi.GetBirthPlace(), c.Op, c.Value)
+	case "surname":
+		_, surname := splitName(indi.GetName())
+		return compareString(surname, c.Op, c.Value)
+	case "givenName":
+		given, _ := splitName(indi.GetName())
+		return compareString(given, c.Op, c.Value)
+	case "sex":
+		return compareString(indi.GetSex(), c.Op, c.Value)
+	case "spouseCount":
+		return compareInt(len(indi.GetFamiliesAsSpouse()), c.Op, c.Value)
+	default:
+		return false, fmt.Errorf("dsl: unknown field %q", c.Field)
+	}
+}
+
+func evalBoolField(field string, indi *types.IndividualRecord) (bool, error) {
+	switch field {
+	case "hasParents":
+		return len(indi.GetFamiliesAsChild()) > 0, nil
+	case "hasChildren":
+		return hasAnyChildren(indi), nil
+	default:
+		return false, fmt.Errorf("dsl: unknown field %q", field)
+	}
+}
+
+func hasAnyChildren(indi *types.IndividualRecord) bool {
+	for _, famRec := range indi.GetFamiliesAsSpouse() {
+		fam, ok := famRec.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+		if len(fam.GetChildren()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func evalRelFunc(rf RelFunc, indi *types.IndividualRecord, g *query.Graph) (bool, error) {
+	switch rf.Name {
+	case "ancestorOf":
+		ancestors, err := query.Individuals(g, rf.XrefID).CommonAncestors()
+		if err != nil {
+			return false, err
+		}
+		return containsAncestor(ancestors, indi.XrefID()), nil
+
+	case "descendantOf":
+		ancestors, err := query.Individuals(g, indi.XrefID()).CommonAncestors()
+		if err != nil {
+			return false, err
+		}
+		return containsAncestor(ancestors, rf.XrefID), nil
+
+	case "relatedTo":
+		msq := query.Individuals(g, indi.XrefID(), rf.XrefID)
+		if rf.Within > 0 {
+			msq = msq.MaxGenerations(rf.Within)
+		}
+		common, err := msq.CommonAncestors()
+		if err != nil {
+			return false, err
+		}
+		return len(common) > 0, nil
+
+	case "commonAncestorWith":
+		common, err := query.Individuals(g, indi.XrefID(), rf.XrefID).CommonAncestors()
+		if err != nil {
+			return false, err
+		}
+		return len(common) > 0, nil
+
+	default:
+		return false, fmt.Errorf("dsl: unknown relationship predicate %q", rf.Name)
+	}
+}
+
+// containsAncestor reports whether xrefID appears among paths, the
+// result of a single-source query.Individuals(g, x).CommonAncestors()
+// call -- with one source, every reached ancestor trivially satisfies
+// "reached by all sources", so this is exactly x's ancestor list.
+func containsAncestor(paths []*query.AncestorPath, xrefID string) bool {
+	for _, p := range paths {
+		if p.Ancestor.XrefID() == xrefID {
+			return true
+		}
+	}
+	return false
+}
+
+// splitName splits a GEDCOM NAME value in "Given /Surname/" form into its
+// given-name and surname parts.
+func splitName(name string) (given, surname string) {
+	start := strings.IndexByte(name, '/')
+	if start == -1 {
+		return strings.TrimSpace(name), ""
+	}
+	end := strings.IndexByte(name[start+1:], '/')
+	if end == -1 {
+		return strings.TrimSpace(name[:start]), strings.TrimSpace(name[start+1:])
+	}
+	given = strings.TrimSpace(name[:start])
+	surname = strings.TrimSpace(name[start+1 : start+1+end])
+	return given, surname
+}
+
+// yearOf extracts a plain calendar year from a loosely-formatted GEDCOM
+// date string such as "12 JAN 1800" or "ABT 1800", returning 0 if no
+// four-digit year can be found. This is a local copy; validate and diff
+// delegate their yearOf to internal/gedcomdate.YearOf instead.
+func yearOf(date string) int {
+	digits := 0
+	year := 0
+	for _, r := range date {
+		if r >= '0' && r <= '9' {
+			year = year*10 + int(r-'0')
+			digits++
+			if digits == 4 {
+				return year
+			}
+		} else {
+			digits = 0
+			year = 0
+		}
+	}
+	return 0
+}
+
+func compareYear(year int, op, value string) (bool, error) {
+	want, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("dsl: expected a year, got %q", value)
+	}
+	return compareOrdered(year, op, want)
+}
+
+func compareInt(n int, op, value string) (bool, error) {
+	want, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("dsl: expected an integer, got %q", value)
+	}
+	return compareOrdered(n, op, want)
+}
+
+func compareOrdered(got int, op string, want int) (bool, error) {
+	switch op {
+	case "=":
+		return got == want, nil
+	case "!=":
+		return got != want, nil
+	case "<":
+		return got < want, nil
+	case "<=":
+		return got <= want, nil
+	case ">":
+		return got > want, nil
+	case ">=":
+		return got >= want, nil
+	default:
+		return false, fmt.Errorf("dsl: operator %q isn't valid on a numeric field", op)
+	}
+}
+
+func compareString(got, op, value string) (bool, error) {
+	switch op {
+	case "=":
+		return got == value, nil
+	case "!=":
+		return got != value, nil
+	case "CONTAINS":
+		return strings.Contains(got, value), nil
+	case "STARTS WITH":
+		return strings.HasPrefix(got, value), nil
+	case "~":
+		return globMatch(value, got)
+	default:
+		return false, fmt.Errorf("dsl: operator %q isn't valid on a text field", op)
+	}
+}
+
+// globMatch reports whether s matches the glob pattern, which supports
+// '*' (any run of characters) and '?' (any single character).
+func globMatch(pattern, s string) (bool, error) {
+	var b strings.Builder
+	b.WriteByte('^')
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteByte('$')
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return false, fmt.Errorf("dsl: invalid glob pattern %q: %w", pattern, err)
+	}
+	return re.MatchString(s), nil
+}
@@ -0,0 +1,93 @@
+package dsl
+
+import (
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// buildFilterTestTree builds:
+//
+//	@I1@ "Anna /Smith/" b. 1950
+//	  FAMC @F1@ -> HUSB @I2@, WIFE @I3@
+//	@I2@ "Bob /Smith/" b. 1920
+//	@I3@ "Carol /Jones/" b. 1922
+func buildFilterTestTree() *types.GedcomTree {
+	tree := types.NewGedcomTree()
+
+	addIndi := func(xref, name, birthYear, famc string) {
+		line := types.NewGedcomLine(0, "INDI", "", xref)
+		line.AddChild(types.NewGedcomLine(1, "NAME", name, ""))
+		if birthYear != "" {
+			birt := types.NewGedcomLine(1, "BIRT", "", "")
+			birt.AddChild(types.NewGedcomLine(2, "DATE", birthYear, ""))
+			line.AddChild(birt)
+		}
+		if famc != "" {
+			line.AddChild(types.NewGedcomLine(1, "FAMC", famc, ""))
+		}
+		tree.AddRecord(types.NewIndividualRecord(line))
+	}
+	addFam := func(xref, husb, wife, chil string) {
+		line := types.NewGedcomLine(0, "FAM", "", xref)
+		line.AddChild(types.NewGedcomLine(1, "HUSB", husb, ""))
+		line.AddChild(types.NewGedcomLine(1, "WIFE", wife, ""))
+		line.AddChild(types.NewGedcomLine(1, "CHIL", chil, ""))
+		tree.AddRecord(types.NewFamilyRecord(line))
+	}
+
+	addIndi("@I1@", "Anna /Smith/", "1950", "@F1@")
+	addIndi("@I2@", "Bob /Smith/", "1920", "")
+	addIndi("@I3@", "Carol /Jones/", "1922", "")
+	addFam("@F1@", "@I2@", "@I3@", "@I1@")
+
+	return tree
+}
+
+func TestFilter_Execute_Comparison(t *testing.T) {
+	tree := buildFilterTestTree()
+	f, err := Parse(`surname = "Smith"`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	matches, err := f.Execute(tree)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	got := make(map[string]bool, len(matches))
+	for _, indi := range matches {
+		got[indi.XrefID()] = true
+	}
+	if !got["@I1@"] || !got["@I2@"] {
+		t.Errorf("expected @I1@ and @I2@ to match surname = Smith, got %v", got)
+	}
+	if got["@I3@"] {
+		t.Errorf("expected @I3@ (surname Jones) not to match, got %v", got)
+	}
+}
+
+func TestFilter_Execute_AncestorOf(t *testing.T) {
+	tree := buildFilterTestTree()
+	f, err := Parse(`ancestorOf(@I1@)`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	matches, err := f.Execute(tree)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	got := make(map[string]bool, len(matches))
+	for _, indi := range matches {
+		got[indi.XrefID()] = true
+	}
+	if !got["@I2@"] || !got["@I3@"] {
+		t.Errorf("expected @I2@ and @I3@ as ancestors of @I1@, got %v", got)
+	}
+	if got["@I1@"] {
+		t.Errorf("expected @I1@ not to be its own ancestor, got %v", got)
+	}
+}
@@ -0,0 +1,155 @@
+package dsl
+
+import (
+	"strings"
+	"unicode"
+)
+
+// lexer turns an expression string into a stream of tokens, tracking
+// rune position for ParseError.
+type lexer struct {
+	input []rune
+	pos   int
+}
+
+func newLexer(input string) *lexer {
+	return &lexer{input: []rune(input)}
+}
+
+func (l *lexer) peekRune() (rune, bool) {
+	if l.pos >= len(l.input) {
+		return 0, false
+	}
+	return l.input[l.pos], true
+}
+
+func (l *lexer) skipSpace() {
+	for {
+		r, ok := l.peekRune()
+		if !ok || !unicode.IsSpace(r) {
+			return
+		}
+		l.pos++
+	}
+}
+
+// next lexes and returns the next token.
+func (l *lexer) next() (token, error) {
+	l.skipSpace()
+	start := l.pos
+	r, ok := l.peekRune()
+	if !ok {
+		return token{kind: tokenEOF, pos: start}, nil
+	}
+
+	switch r {
+	case '(':
+		l.pos++
+		return token{kind: tokenLParen, text: "(", pos: start}, nil
+	case ')':
+		l.pos++
+		return token{kind: tokenRParen, text: ")", pos: start}, nil
+	case '~':
+		l.pos++
+		return token{kind: tokenGlob, text: "~", pos: start}, nil
+	case '=':
+		l.pos++
+		return token{kind: tokenEq, text: "=", pos: start}, nil
+	case '!':
+		l.pos++
+		if r2, ok := l.peekRune(); ok && r2 == '=' {
+			l.pos++
+			return token{kind: tokenNeq, text: "!=", pos: start}, nil
+		}
+		return token{}, &ParseError{Pos: start, Message: "unexpected '!'", Expected: []string{"!="}}
+	case '<':
+		l.pos++
+		if r2, ok := l.peekRune(); ok && r2 == '=' {
+			l.pos++
+			return token{kind: tokenLte, text: "<=", pos: start}, nil
+		}
+		return token{kind: tokenLt, text: "<", pos: start}, nil
+	case '>':
+		l.pos++
+		if r2, ok := l.peekRune(); ok && r2 == '=' {
+			l.pos++
+			return token{kind: tokenGte, text: ">=", pos: start}, nil
+		}
+		return token{kind: tokenGt, text: ">", pos: start}, nil
+	case '"', '\'':
+		return l.lexString(r)
+	}
+
+	if unicode.IsDigit(r) {
+		return l.lexNumber(), nil
+	}
+	if isIdentRune(r) {
+		return l.lexIdent(), nil
+	}
+
+	return token{}, &ParseError{Pos: start, Message: "unexpected character " + string(r)}
+}
+
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '@'
+}
+
+func (l *lexer) lexString(quote rune) (token, error) {
+	start := l.pos
+	l.pos++ // opening quote
+	var b strings.Builder
+	for {
+		r, ok := l.peekRune()
+		if !ok {
+			return token{}, &ParseError{Pos: start, Message: "unterminated string literal"}
+		}
+		if r == quote {
+			l.pos++
+			return token{kind: tokenString, text: b.String(), pos: start}, nil
+		}
+		b.WriteRune(r)
+		l.pos++
+	}
+}
+
+func (l *lexer) lexNumber() token {
+	start := l.pos
+	for {
+		r, ok := l.peekRune()
+		if !ok || !(unicode.IsDigit(r) || r == '.') {
+			break
+		}
+		l.pos++
+	}
+	return token{kind: tokenNumber, text: string(l.input[start:l.pos]), pos: start}
+}
+
+func (l *lexer) lexIdent() token {
+	start := l.pos
+	for {
+		r, ok := l.peekRune()
+		if !ok || !isIdentRune(r) {
+			break
+		}
+		l.pos++
+	}
+	text := string(l.input[start:l.pos])
+
+	if text == "STARTS" {
+		save := l.pos
+		l.skipSpace()
+		if strings.HasPrefix(string(l.input[l.pos:]), "WITH") {
+			afterPos := l.pos + len("WITH")
+			if afterPos >= len(l.input) || !isIdentRune(l.input[afterPos]) {
+				l.pos = afterPos
+				return token{kind: tokenStartsWith, text: "STARTS WITH", pos: start}
+			}
+		}
+		l.pos = save
+	}
+
+	if kind, ok := keywordTokens[strings.ToUpper(text)]; ok {
+		return token{kind: kind, text: text, pos: start}
+	}
+	return token{kind: tokenIdent, text: text, pos: start}
+}
@@ -0,0 +1,232 @@
+package dsl
+
+import "fmt"
+
+// relFuncNames are the relationship predicates primary recognizes as
+// function calls rather than field comparisons.
+var relFuncNames = map[string]bool{
+	"ancestorOf":         true,
+	"descendantOf":       true,
+	"relatedTo":          true,
+	"commonAncestorWith": true,
+}
+
+var comparisonOps = map[tokenKind]string{
+	tokenEq:         "=",
+	tokenNeq:        "!=",
+	tokenLt:         "<",
+	tokenLte:        "<=",
+	tokenGt:         ">",
+	tokenGte:        ">=",
+	tokenContains:   "CONTAINS",
+	tokenStartsWith: "STARTS WITH",
+	tokenGlob:       "~",
+}
+
+// parser is a recursive-descent parser over a lexer's token stream, with
+// one token of lookahead.
+type parser struct {
+	lex  *lexer
+	tok  token
+	peek *token
+}
+
+func newParser(expr string) (*parser, error) {
+	p := &parser{lex: newLexer(expr)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *parser) advance() error {
+	t, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = t
+	return nil
+}
+
+// Parse parses expr into a Filter, usable by (*Filter).Execute or
+// (*Filter).Matches. It returns a *ParseError (with Pos and Expected set)
+// on malformed input.
+func Parse(expr string) (*Filter, error) {
+	p, err := newParser(expr)
+	if err != nil {
+		return nil, err
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, newUnexpectedTokenError(p.tok, tokenEOF.String())
+	}
+	return &Filter{Root: root}, nil
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokenOr {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = BinaryExpr{Op: "OR", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokenAnd {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = BinaryExpr{Op: "AND", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.tok.kind == tokenNot {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NotExpr{Expr: inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	switch p.tok.kind {
+	case tokenLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenRParen {
+			return nil, newUnexpectedTokenError(p.tok, tokenRParen.String())
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return inner, nil
+
+	case tokenIdent:
+		name := p.tok.text
+		if relFuncNames[name] {
+			return p.parseRelFunc(name)
+		}
+		return p.parseFieldExpr(name)
+
+	default:
+		return nil, newUnexpectedTokenError(p.tok, "'('", "identifier", "NOT")
+	}
+}
+
+func (p *parser) parseRelFunc(name string) (Expr, error) {
+	if err := p.advance(); err != nil { // consume function name
+		return nil, err
+	}
+	if p.tok.kind != tokenLParen {
+		return nil, newUnexpectedTokenError(p.tok, tokenLParen.String())
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenIdent && p.tok.kind != tokenString {
+		return nil, newUnexpectedTokenError(p.tok, "xref ID")
+	}
+	xrefID := p.tok.text
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenRParen {
+		return nil, newUnexpectedTokenError(p.tok, tokenRParen.String())
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	fn := RelFunc{Name: name, XrefID: xrefID}
+
+	if p.tok.kind == tokenWithin {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenDegree {
+			return nil, newUnexpectedTokenError(p.tok, tokenDegree.String())
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenNumber {
+			return nil, newUnexpectedTokenError(p.tok, tokenNumber.String())
+		}
+		degree, err := parseIntLiteral(p.tok.text)
+		if err != nil {
+			return nil, &ParseError{Pos: p.tok.pos, Message: err.Error()}
+		}
+		fn.Within = degree
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	return fn, nil
+}
+
+func (p *parser) parseFieldExpr(field string) (Expr, error) {
+	if err := p.advance(); err != nil { // consume field identifier
+		return nil, err
+	}
+	op, ok := comparisonOps[p.tok.kind]
+	if !ok {
+		// Bare field, e.g. hasChildren / NOT hasParents.
+		return BoolField{Field: field}, nil
+	}
+	if err := p.advance(); err != nil { // consume operator
+		return nil, err
+	}
+	if p.tok.kind != tokenString && p.tok.kind != tokenIdent && p.tok.kind != tokenNumber {
+		return nil, newUnexpectedTokenError(p.tok, "value")
+	}
+	value := p.tok.text
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return Comparison{Field: field, Op: op, Value: value}, nil
+}
+
+func parseIntLiteral(s string) (int, error) {
+	n := 0
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("expected integer, got %q", s)
+		}
+		n = n*10 + int(r-'0')
+	}
+	return n, nil
+}
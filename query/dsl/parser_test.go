@@ -0,0 +1,115 @@
+package dsl
+
+import "testing"
+
+func TestParseSimpleComparison(t *testing.T) {
+	f, err := Parse(`birthDate >= 1800`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	cmp, ok := f.Root.(Comparison)
+	if !ok {
+		t.Fatalf("Root = %T, want Comparison", f.Root)
+	}
+	if cmp.Field != "birthDate" || cmp.Op != ">=" || cmp.Value != "1800" {
+		t.Fatalf("got %+v", cmp)
+	}
+}
+
+func TestParseAndOrPrecedence(t *testing.T) {
+	// AND binds tighter than OR: "a OR b AND c" == "a OR (b AND c)".
+	f, err := Parse(`sex = "M" OR birthPlace CONTAINS "Paris" AND hasChildren`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	top, ok := f.Root.(BinaryExpr)
+	if !ok || top.Op != "OR" {
+		t.Fatalf("Root = %+v, want top-level OR", f.Root)
+	}
+	right, ok := top.Right.(BinaryExpr)
+	if !ok || right.Op != "AND" {
+		t.Fatalf("Root.Right = %+v, want AND", top.Right)
+	}
+}
+
+func TestParseNotAndParens(t *testing.T) {
+	f, err := Parse(`NOT (hasChildren AND spouseCount > 1)`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	not, ok := f.Root.(NotExpr)
+	if !ok {
+		t.Fatalf("Root = %T, want NotExpr", f.Root)
+	}
+	if _, ok := not.Expr.(BinaryExpr); !ok {
+		t.Fatalf("NotExpr.Expr = %T, want BinaryExpr", not.Expr)
+	}
+}
+
+func TestParseRelFuncWithDegree(t *testing.T) {
+	f, err := Parse(`relatedTo(@I42@) WITHIN DEGREE 4`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	rf, ok := f.Root.(RelFunc)
+	if !ok {
+		t.Fatalf("Root = %T, want RelFunc", f.Root)
+	}
+	if rf.Name != "relatedTo" || rf.XrefID != "@I42@" || rf.Within != 4 {
+		t.Fatalf("got %+v", rf)
+	}
+}
+
+func TestParseGlob(t *testing.T) {
+	f, err := Parse(`surname ~ "Dup*"`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	cmp, ok := f.Root.(Comparison)
+	if !ok || cmp.Op != "~" || cmp.Value != "Dup*" {
+		t.Fatalf("got %+v", f.Root)
+	}
+}
+
+func TestParseErrorReportsPosition(t *testing.T) {
+	_, err := Parse(`birthDate >=`)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("err = %T, want *ParseError", err)
+	}
+	if perr.Pos == 0 {
+		t.Fatalf("ParseError.Pos = 0, want a position past the operator")
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	cases := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"Dup*", "Dupont", true},
+		{"Dup*", "Durand", false},
+		{"S?ith", "Smith", true},
+		{"S?ith", "Smyth", true},
+		{"S?ith", "Smithy", false},
+	}
+	for _, c := range cases {
+		got, err := globMatch(c.pattern, c.s)
+		if err != nil {
+			t.Fatalf("globMatch(%q, %q): %v", c.pattern, c.s, err)
+		}
+		if got != c.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", c.pattern, c.s, got, c.want)
+		}
+	}
+}
+
+func TestSplitName(t *testing.T) {
+	given, surname := splitName("John /Smith/")
+	if given != "John" || surname != "Smith" {
+		t.Fatalf("splitName = (%q, %q), want (John, Smith)", given, surname)
+	}
+}
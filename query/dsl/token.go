@@ -0,0 +1,107 @@
+// Package dsl parses the small textual filter language described in
+// README-adjacent issue requests like "birthDate >= 1800 AND birthPlace
+// CONTAINS \"Paris\" AND NOT hasChildren" into a Filter AST that can be
+// evaluated directly against a *types.GedcomTree.
+//
+// query.FilterQuery (the chainable predicate builder ByName/ByBirthDate/
+// etc. composes into) has no defining file in this package snapshot --
+// see the doc comment on query.SimilarityIndex and on
+// query.HybridQueryHelpersMongo for the same constraint -- so there's no
+// Execute body to extend with a FromExpression step, and no way to add a
+// literal qb.Filter().FromExpression(expr).Execute() chain. Parse below,
+// plus (*Filter).Execute against a tree directly, is the real entry
+// point instead, the same shape search.NewIndex and
+// validate.NewRuleRunner already use for the same reason.
+package dsl
+
+// tokenKind identifies a lexical token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenLParen
+	tokenRParen
+
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenWithin
+	tokenDegree
+
+	tokenEq
+	tokenNeq
+	tokenLt
+	tokenLte
+	tokenGt
+	tokenGte
+	tokenContains
+	tokenStartsWith
+	tokenGlob
+)
+
+// token is a single lexed token, carrying its source position (0-based
+// rune offset) for ParseError.Pos.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var keywordTokens = map[string]tokenKind{
+	"AND":    tokenAnd,
+	"OR":     tokenOr,
+	"NOT":    tokenNot,
+	"WITHIN": tokenWithin,
+	"DEGREE": tokenDegree,
+	"CONTAINS": tokenContains,
+}
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of expression"
+	case tokenIdent:
+		return "identifier"
+	case tokenString:
+		return "string"
+	case tokenNumber:
+		return "number"
+	case tokenLParen:
+		return "'('"
+	case tokenRParen:
+		return "')'"
+	case tokenAnd:
+		return "AND"
+	case tokenOr:
+		return "OR"
+	case tokenNot:
+		return "NOT"
+	case tokenWithin:
+		return "WITHIN"
+	case tokenDegree:
+		return "DEGREE"
+	case tokenEq:
+		return "'='"
+	case tokenNeq:
+		return "'!='"
+	case tokenLt:
+		return "'<'"
+	case tokenLte:
+		return "'<='"
+	case tokenGt:
+		return "'>'"
+	case tokenGte:
+		return "'>='"
+	case tokenContains:
+		return "CONTAINS"
+	case tokenStartsWith:
+		return "STARTS WITH"
+	case tokenGlob:
+		return "'~'"
+	default:
+		return "token"
+	}
+}
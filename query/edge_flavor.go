@@ -0,0 +1,367 @@
+package query
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// EdgeFlavor classifies the genealogical nature of a FAMC parent-child
+// link: plain biological descent, or one of the GEDCOM PEDI qualifiers.
+// It is attached to a (child, family) pair via a side-table rather than a
+// field on Edge itself -- Edge's defining file isn't part of this
+// snapshot, the same gap ancestorCaches/similarityIndexFor already work
+// around for *Graph.
+type EdgeFlavor string
+
+const (
+	FlavorUnknown    EdgeFlavor = "unknown"
+	FlavorBiological EdgeFlavor = "biological"
+	FlavorAdopted    EdgeFlavor = "adopted"
+	FlavorFoster     EdgeFlavor = "foster"
+	FlavorStep       EdgeFlavor = "step"
+	FlavorSealed     EdgeFlavor = "sealed"
+)
+
+// isNonBiological reports whether f represents a PEDI qualifier overriding
+// plain descent (adopted, foster, or sealing).
+func (f EdgeFlavor) isNonBiological() bool {
+	return f == FlavorAdopted || f == FlavorFoster || f == FlavorSealed
+}
+
+// SiblingKind classifies how two children of the same parent(s) relate:
+// Full (both parents shared), Half (one parent shared), Step (no parent
+// shared, linked only through a remarriage), or Adoptive (either child's
+// link to the shared family is adopted/foster/sealed, overriding whatever
+// the parent count would otherwise say).
+type SiblingKind string
+
+const (
+	SiblingUnknown  SiblingKind = "unknown"
+	SiblingFull     SiblingKind = "full"
+	SiblingHalf     SiblingKind = "half"
+	SiblingStep     SiblingKind = "step"
+	SiblingAdoptive SiblingKind = "adoptive"
+)
+
+// ParentLink pairs a parent with the EdgeFlavor of the FAMC link joining
+// them to the child.
+type ParentLink struct {
+	Parent *IndividualNode
+	Flavor EdgeFlavor
+}
+
+// ChildLink pairs a child with the EdgeFlavor of the FAMC link joining
+// them to this parent.
+type ChildLink struct {
+	Child  *IndividualNode
+	Flavor EdgeFlavor
+}
+
+// SiblingLink pairs a sibling with the SiblingKind describing how they
+// relate to the target individual.
+type SiblingLink struct {
+	Sibling *IndividualNode
+	Kind    SiblingKind
+}
+
+// edgeFlavorsMu guards edgeFlavors, the side-table caching each (child,
+// family) pair's computed EdgeFlavor so repeated ParentsWithFlavor/
+// ChildrenWithFlavor/SiblingsWithFlavor calls don't re-walk PEDI lines.
+var (
+	edgeFlavorsMu sync.Mutex
+	edgeFlavors   = make(map[string]EdgeFlavor)
+)
+
+// flavorForFAMC returns the EdgeFlavor of childXref's FAMC link to
+// familyXref, computing and caching it on first use.
+func flavorForFAMC(childXref, familyXref string, child *types.IndividualRecord) EdgeFlavor {
+	key := childXref + "\x00" + familyXref
+
+	edgeFlavorsMu.Lock()
+	if f, ok := edgeFlavors[key]; ok {
+		edgeFlavorsMu.Unlock()
+		return f
+	}
+	edgeFlavorsMu.Unlock()
+
+	flavor := pedigreeFlavor(famcPedigree(child, familyXref))
+
+	edgeFlavorsMu.Lock()
+	edgeFlavors[key] = flavor
+	edgeFlavorsMu.Unlock()
+	return flavor
+}
+
+// famcPedigree returns the PEDI value recorded on child's FAMC line
+// pointing at familyXref, or "" if none is recorded.
+func famcPedigree(child *types.IndividualRecord, familyXref string) string {
+	for _, line := range child.GetLines("FAMC") {
+		if line.Value != familyXref {
+			continue
+		}
+		for _, sub := range line.Children {
+			if sub.Tag == "PEDI" {
+				return sub.Value
+			}
+		}
+	}
+	return ""
+}
+
+// pedigreeFlavor maps a FAMC line's PEDI value to the EdgeFlavor it
+// represents. GEDCOM's PEDI values are "birth" (the default, treated as
+// FlavorBiological), "adopted", "foster", and "sealing"; an unrecognized
+// or missing value is treated as biological too, rather than unknown,
+// since that's the overwhelmingly common case of a FAMC line with no
+// PEDI sub-tag at all.
+func pedigreeFlavor(pedi string) EdgeFlavor {
+	switch strings.ToLower(strings.TrimSpace(pedi)) {
+	case "adopted":
+		return FlavorAdopted
+	case "foster":
+		return FlavorFoster
+	case "sealing":
+		return FlavorSealed
+	default:
+		return FlavorBiological
+	}
+}
+
+// parentIDsOf returns the xref IDs of fam's husband and wife, skipping
+// whichever is absent.
+func parentIDsOf(fam *FamilyNode) []string {
+	ids := make([]string, 0, 2)
+	if h := fam.Husband(); h != nil {
+		ids = append(ids, h.ID())
+	}
+	if w := fam.Wife(); w != nil {
+		ids = append(ids, w.ID())
+	}
+	return ids
+}
+
+func containsID(ids []string, id string) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ParentsWithFlavor returns node's parents together with the EdgeFlavor of
+// each FAMC link, for callers that need to distinguish an adoptive or
+// foster parent from a biological one. It walks the same famcEdges fast
+// path Parents() uses.
+func (node *IndividualNode) ParentsWithFlavor() []ParentLink {
+	links := make([]ParentLink, 0, 2)
+	seen := make(map[string]bool)
+
+	for _, edge := range node.famcEdges {
+		famNode := edge.Family
+		if famNode == nil {
+			continue
+		}
+		flavor := flavorForFAMC(node.ID(), famNode.ID(), node.Individual)
+
+		if famNode.husbandEdge != nil {
+			if indiNode, ok := famNode.husbandEdge.To.(*IndividualNode); ok && !seen[indiNode.ID()] {
+				seen[indiNode.ID()] = true
+				links = append(links, ParentLink{Parent: indiNode, Flavor: flavor})
+			}
+		}
+		if famNode.wifeEdge != nil {
+			if indiNode, ok := famNode.wifeEdge.To.(*IndividualNode); ok && !seen[indiNode.ID()] {
+				seen[indiNode.ID()] = true
+				links = append(links, ParentLink{Parent: indiNode, Flavor: flavor})
+			}
+		}
+	}
+	return links
+}
+
+// ChildrenWithFlavor returns node's children together with the EdgeFlavor
+// of each child's FAMC link back to the family node shares with them. It
+// walks the same famsEdges/chilEdges fast path Children() uses.
+func (node *IndividualNode) ChildrenWithFlavor() []ChildLink {
+	links := make([]ChildLink, 0)
+	seen := make(map[string]bool)
+
+	for _, edge := range node.famsEdges {
+		famNode := edge.Family
+		if famNode == nil {
+			continue
+		}
+		for _, famEdge := range famNode.chilEdges {
+			indiNode, ok := famEdge.To.(*IndividualNode)
+			if !ok || seen[indiNode.ID()] {
+				continue
+			}
+			seen[indiNode.ID()] = true
+			flavor := flavorForFAMC(indiNode.ID(), famNode.ID(), indiNode.Individual)
+			links = append(links, ChildLink{Child: indiNode, Flavor: flavor})
+		}
+	}
+	return links
+}
+
+// SiblingsWithFlavor returns node's siblings together with the
+// SiblingKind describing the relationship: Full (both parents shared),
+// Half (one parent shared, found through a parent's other family), Step
+// (no parent shared: a child of a parent's other spouse by another
+// family), or Adoptive (either sibling's FAMC link to the shared family
+// is adopted/foster/sealed).
+//
+// Unlike Siblings(), which only looks at node's own FAMC families, this
+// also walks each parent's other FAMS families and those parents' other
+// spouses' FAMS families, so half- and step-siblings are found too.
+func (node *IndividualNode) SiblingsWithFlavor() []SiblingLink {
+	links := make([]SiblingLink, 0)
+	seen := make(map[string]bool)
+	seen[node.ID()] = true
+
+	for _, nf := range node.famcEdges {
+		myFamily := nf.Family
+		if myFamily == nil {
+			continue
+		}
+		myParentIDs := parentIDsOf(myFamily)
+
+		candidateFamilies := map[string]*FamilyNode{myFamily.ID(): myFamily}
+		if h := myFamily.Husband(); h != nil {
+			for _, hEdge := range h.famsEdges {
+				if hEdge.Family != nil {
+					candidateFamilies[hEdge.Family.ID()] = hEdge.Family
+				}
+			}
+		}
+		if w := myFamily.Wife(); w != nil {
+			for _, wEdge := range w.famsEdges {
+				if wEdge.Family != nil {
+					candidateFamilies[wEdge.Family.ID()] = wEdge.Family
+				}
+			}
+		}
+		// A parent's other spouse may have children from yet another
+		// family; those share no parent with node and come out as Step.
+		stepFamilies := map[string]*FamilyNode{}
+		for _, fam := range candidateFamilies {
+			for _, spouse := range []*IndividualNode{fam.Husband(), fam.Wife()} {
+				if spouse == nil || containsID(myParentIDs, spouse.ID()) {
+					continue
+				}
+				for _, sEdge := range spouse.famsEdges {
+					if sEdge.Family != nil {
+						stepFamilies[sEdge.Family.ID()] = sEdge.Family
+					}
+				}
+			}
+		}
+		for id, fam := range stepFamilies {
+			candidateFamilies[id] = fam
+		}
+
+		for _, candidate := range candidateFamilies {
+			theirParentIDs := parentIDsOf(candidate)
+			shared := 0
+			for _, id := range myParentIDs {
+				if containsID(theirParentIDs, id) {
+					shared++
+				}
+			}
+
+			for _, child := range candidate.Children() {
+				if seen[child.ID()] {
+					continue
+				}
+				seen[child.ID()] = true
+
+				kind := SiblingStep
+				switch shared {
+				case 2:
+					kind = SiblingFull
+				case 1:
+					kind = SiblingHalf
+				}
+
+				myFlavor := flavorForFAMC(node.ID(), myFamily.ID(), node.Individual)
+				theirFlavor := flavorForFAMC(child.ID(), candidate.ID(), child.Individual)
+				if myFlavor.isNonBiological() || theirFlavor.isNonBiological() {
+					kind = SiblingAdoptive
+				}
+
+				links = append(links, SiblingLink{Sibling: child, Kind: kind})
+			}
+		}
+	}
+	return links
+}
+
+// RelationshipQuery filters the flavor-aware relationship methods
+// (ParentsWithFlavor/SiblingsWithFlavor) down to a subset, without losing
+// their O(1) indexed-edge fast path: it filters their results rather than
+// re-walking the graph.
+type RelationshipQuery struct {
+	node             *IndividualNode
+	onlyBiological   bool
+	halfSiblingsOnly bool
+}
+
+// NewRelationshipQuery creates a RelationshipQuery over node. With no
+// filters applied, Parents() returns the same individuals as
+// node.Parents(), and Siblings() returns everyone SiblingsWithFlavor
+// finds, including the half- and step-siblings node.Siblings() omits.
+func NewRelationshipQuery(node *IndividualNode) *RelationshipQuery {
+	return &RelationshipQuery{node: node}
+}
+
+// OnlyBiological restricts results to biological relationships, excluding
+// adopted/foster/sealed parents and adoptive siblings.
+func (q *RelationshipQuery) OnlyBiological() *RelationshipQuery {
+	q.onlyBiological = true
+	return q
+}
+
+// IncludingAdopted undoes OnlyBiological, restoring adopted/foster/sealed
+// relationships to the result.
+func (q *RelationshipQuery) IncludingAdopted() *RelationshipQuery {
+	q.onlyBiological = false
+	return q
+}
+
+// HalfSiblingsOnly restricts Siblings() to half-siblings.
+func (q *RelationshipQuery) HalfSiblingsOnly() *RelationshipQuery {
+	q.halfSiblingsOnly = true
+	return q
+}
+
+// Parents returns q.node's parents, filtered per the configured options.
+func (q *RelationshipQuery) Parents() []*IndividualNode {
+	links := q.node.ParentsWithFlavor()
+	result := make([]*IndividualNode, 0, len(links))
+	for _, link := range links {
+		if q.onlyBiological && link.Flavor.isNonBiological() {
+			continue
+		}
+		result = append(result, link.Parent)
+	}
+	return result
+}
+
+// Siblings returns q.node's siblings, filtered per the configured options.
+func (q *RelationshipQuery) Siblings() []*IndividualNode {
+	links := q.node.SiblingsWithFlavor()
+	result := make([]*IndividualNode, 0, len(links))
+	for _, link := range links {
+		if q.halfSiblingsOnly && link.Kind != SiblingHalf {
+			continue
+		}
+		if q.onlyBiological && link.Kind == SiblingAdoptive {
+			continue
+		}
+		result = append(result, link.Sibling)
+	}
+	return result
+}
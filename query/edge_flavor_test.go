@@ -0,0 +1,181 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// addFamcWithPedi adds a FAMC line pointing at famXref to indiLine, with an
+// optional PEDI sub-tag.
+func addFamcWithPedi(indiLine *types.GedcomLine, famXref, pedi string) {
+	famc := types.NewGedcomLine(1, "FAMC", famXref, "")
+	if pedi != "" {
+		famc.AddChild(types.NewGedcomLine(2, "PEDI", pedi, ""))
+	}
+	indiLine.AddChild(famc)
+}
+
+func TestParentsWithFlavor_AdoptedAppliesToBothParents(t *testing.T) {
+	tree := types.NewGedcomTree()
+
+	husb := types.NewGedcomLine(0, "INDI", "", "@I1@")
+	husb.AddChild(types.NewGedcomLine(1, "NAME", "Father /Person/", ""))
+	tree.AddRecord(types.NewIndividualRecord(husb))
+
+	wife := types.NewGedcomLine(0, "INDI", "", "@I2@")
+	wife.AddChild(types.NewGedcomLine(1, "NAME", "Mother /Person/", ""))
+	tree.AddRecord(types.NewIndividualRecord(wife))
+
+	child := types.NewGedcomLine(0, "INDI", "", "@I3@")
+	child.AddChild(types.NewGedcomLine(1, "NAME", "Child /Person/", ""))
+	addFamcWithPedi(child, "@F1@", "adopted")
+	tree.AddRecord(types.NewIndividualRecord(child))
+
+	fam := types.NewGedcomLine(0, "FAM", "", "@F1@")
+	fam.AddChild(types.NewGedcomLine(1, "HUSB", "@I1@", ""))
+	fam.AddChild(types.NewGedcomLine(1, "WIFE", "@I2@", ""))
+	fam.AddChild(types.NewGedcomLine(1, "CHIL", "@I3@", ""))
+	tree.AddRecord(types.NewFamilyRecord(fam))
+
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	childNode := graph.GetIndividual("@I3@")
+	if childNode == nil {
+		t.Fatal("expected @I3@ to be in the graph")
+	}
+
+	links := childNode.ParentsWithFlavor()
+	if len(links) != 2 {
+		t.Fatalf("expected 2 parent links, got %d: %+v", len(links), links)
+	}
+	for _, link := range links {
+		if link.Flavor != FlavorAdopted {
+			t.Errorf("expected FlavorAdopted for %s, got %s", link.Parent.ID(), link.Flavor)
+		}
+	}
+}
+
+func TestParentsWithFlavor_DefaultsToBiological(t *testing.T) {
+	tree := types.NewGedcomTree()
+
+	husb := types.NewGedcomLine(0, "INDI", "", "@I1@")
+	husb.AddChild(types.NewGedcomLine(1, "NAME", "Father /Person/", ""))
+	tree.AddRecord(types.NewIndividualRecord(husb))
+
+	child := types.NewGedcomLine(0, "INDI", "", "@I2@")
+	child.AddChild(types.NewGedcomLine(1, "NAME", "Child /Person/", ""))
+	addFamcWithPedi(child, "@F1@", "")
+	tree.AddRecord(types.NewIndividualRecord(child))
+
+	fam := types.NewGedcomLine(0, "FAM", "", "@F1@")
+	fam.AddChild(types.NewGedcomLine(1, "HUSB", "@I1@", ""))
+	fam.AddChild(types.NewGedcomLine(1, "CHIL", "@I2@", ""))
+	tree.AddRecord(types.NewFamilyRecord(fam))
+
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	childNode := graph.GetIndividual("@I2@")
+	links := childNode.ParentsWithFlavor()
+	if len(links) != 1 || links[0].Flavor != FlavorBiological {
+		t.Errorf("expected 1 FlavorBiological link, got %+v", links)
+	}
+}
+
+func TestSiblingsWithFlavor_FullAndHalf(t *testing.T) {
+	tree := types.NewGedcomTree()
+
+	// Father, two mothers: @F1@ (father @I1@ + mother1 @I2@) has children
+	// @I4@ (target) and @I5@ (full sibling); @F2@ (father @I1@ + mother2
+	// @I3@) has child @I6@ (half sibling, shares only the father).
+	for _, xref := range []string{"@I1@", "@I2@", "@I3@"} {
+		indi := types.NewGedcomLine(0, "INDI", "", xref)
+		indi.AddChild(types.NewGedcomLine(1, "NAME", "Test /Person/", ""))
+		tree.AddRecord(types.NewIndividualRecord(indi))
+	}
+
+	addChildWithFamc := func(xref, famXref string) {
+		indi := types.NewGedcomLine(0, "INDI", "", xref)
+		indi.AddChild(types.NewGedcomLine(1, "NAME", "Test /Person/", ""))
+		addFamcWithPedi(indi, famXref, "")
+		tree.AddRecord(types.NewIndividualRecord(indi))
+	}
+	addChildWithFamc("@I4@", "@F1@")
+	addChildWithFamc("@I5@", "@F1@")
+	addChildWithFamc("@I6@", "@F2@")
+
+	fam1 := types.NewGedcomLine(0, "FAM", "", "@F1@")
+	fam1.AddChild(types.NewGedcomLine(1, "HUSB", "@I1@", ""))
+	fam1.AddChild(types.NewGedcomLine(1, "WIFE", "@I2@", ""))
+	fam1.AddChild(types.NewGedcomLine(1, "CHIL", "@I4@", ""))
+	fam1.AddChild(types.NewGedcomLine(1, "CHIL", "@I5@", ""))
+	tree.AddRecord(types.NewFamilyRecord(fam1))
+
+	fam2 := types.NewGedcomLine(0, "FAM", "", "@F2@")
+	fam2.AddChild(types.NewGedcomLine(1, "HUSB", "@I1@", ""))
+	fam2.AddChild(types.NewGedcomLine(1, "WIFE", "@I3@", ""))
+	fam2.AddChild(types.NewGedcomLine(1, "CHIL", "@I6@", ""))
+	tree.AddRecord(types.NewFamilyRecord(fam2))
+
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	target := graph.GetIndividual("@I4@")
+	if target == nil {
+		t.Fatal("expected @I4@ in graph")
+	}
+
+	kinds := map[string]SiblingKind{}
+	for _, link := range target.SiblingsWithFlavor() {
+		kinds[link.Sibling.ID()] = link.Kind
+	}
+
+	if kinds["@I5@"] != SiblingFull {
+		t.Errorf("expected @I5@ to be a full sibling, got %s", kinds["@I5@"])
+	}
+	if kinds["@I6@"] != SiblingHalf {
+		t.Errorf("expected @I6@ to be a half sibling, got %s", kinds["@I6@"])
+	}
+}
+
+func TestRelationshipQuery_OnlyBiologicalExcludesAdopted(t *testing.T) {
+	tree := types.NewGedcomTree()
+
+	husb := types.NewGedcomLine(0, "INDI", "", "@I1@")
+	husb.AddChild(types.NewGedcomLine(1, "NAME", "Father /Person/", ""))
+	tree.AddRecord(types.NewIndividualRecord(husb))
+
+	child := types.NewGedcomLine(0, "INDI", "", "@I2@")
+	child.AddChild(types.NewGedcomLine(1, "NAME", "Child /Person/", ""))
+	addFamcWithPedi(child, "@F1@", "adopted")
+	tree.AddRecord(types.NewIndividualRecord(child))
+
+	fam := types.NewGedcomLine(0, "FAM", "", "@F1@")
+	fam.AddChild(types.NewGedcomLine(1, "HUSB", "@I1@", ""))
+	fam.AddChild(types.NewGedcomLine(1, "CHIL", "@I2@", ""))
+	tree.AddRecord(types.NewFamilyRecord(fam))
+
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	childNode := graph.GetIndividual("@I2@")
+	rq := NewRelationshipQuery(childNode).OnlyBiological()
+	if parents := rq.Parents(); len(parents) != 0 {
+		t.Errorf("expected adopted parent excluded by OnlyBiological, got %+v", parents)
+	}
+
+	rq2 := NewRelationshipQuery(childNode).IncludingAdopted()
+	if parents := rq2.Parents(); len(parents) != 1 {
+		t.Errorf("expected adopted parent included by IncludingAdopted, got %+v", parents)
+	}
+}
@@ -0,0 +1,27 @@
+package query
+
+// AllIndividuals returns every individual node in the graph. It is
+// primarily useful for exporters (GEDCOM-X, GraphML) that need to walk the
+// whole graph rather than a single query result.
+func (g *Graph) AllIndividuals() []*IndividualNode {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	result := make([]*IndividualNode, 0, len(g.individuals))
+	for _, n := range g.individuals {
+		result = append(result, n)
+	}
+	return result
+}
+
+// AllFamilies returns every family node in the graph.
+func (g *Graph) AllFamilies() []*FamilyNode {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	result := make([]*FamilyNode, 0, len(g.families))
+	for _, n := range g.families {
+		result = append(result, n)
+	}
+	return result
+}
@@ -119,5 +119,10 @@ func BuildGraphHybridWithStorage(tree *types.GedcomTree, sqlitePath, badgerPath,
 		return nil, fmt.Errorf("failed to build BadgerDB graph: %w", err)
 	}
 
+	if err := EnsureSimilarityIndexPersisted(storage.BadgerDB(), graph); err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("failed to build similarity index: %w", err)
+	}
+
 	return graph, nil
 }
@@ -0,0 +1,355 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// nodesCollectionSuffix is appended to collectionPrefix to name the
+// collection buildGraphInMongo writes to and HybridQueryHelpersMongo
+// reads from.
+const nodesCollectionSuffix = "_nodes"
+
+// HybridStorageMongo is the MongoDB counterpart to HybridStorage (SQLite)
+// and HybridStoragePostgres: indexed node lookups live in MongoDB while
+// the full graph structure is persisted to BadgerDB, the same split the
+// other two backends use.
+type HybridStorageMongo struct {
+	client           *mongo.Client
+	db               *mongo.Database
+	badgerDB         *badger.DB
+	collectionPrefix string
+}
+
+// NewHybridStorageMongo connects to mongoURI, opens BadgerDB at
+// badgerPath, and ensures the indexes buildGraphInMongo and
+// HybridQueryHelpersMongo rely on exist on the
+// collectionPrefix+"_nodes" collection.
+func NewHybridStorageMongo(collectionPrefix, badgerPath, mongoURI string, config *Config) (*HybridStorageMongo, error) {
+	if collectionPrefix == "" {
+		return nil, fmt.Errorf("collectionPrefix is required for MongoDB storage")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+
+	db := client.Database(collectionPrefix)
+
+	badgerDB, err := badger.Open(badger.DefaultOptions(badgerPath))
+	if err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, fmt.Errorf("failed to open BadgerDB: %w", err)
+	}
+
+	storage := &HybridStorageMongo{
+		client:           client,
+		db:               db,
+		badgerDB:         badgerDB,
+		collectionPrefix: collectionPrefix,
+	}
+
+	if err := ensureMongoIndexes(ctx, storage.nodesCollection()); err != nil {
+		_ = badgerDB.Close()
+		_ = client.Disconnect(ctx)
+		return nil, fmt.Errorf("failed to ensure MongoDB indexes: %w", err)
+	}
+
+	return storage, nil
+}
+
+// MongoDB returns the underlying *mongo.Database.
+func (s *HybridStorageMongo) MongoDB() *mongo.Database { return s.db }
+
+// BadgerDB returns the underlying *badger.DB.
+func (s *HybridStorageMongo) BadgerDB() *badger.DB { return s.badgerDB }
+
+// FileID returns the identifier nodes are tagged with in MongoDB
+// documents and indexes. It is collectionPrefix: unlike SQLite/Postgres,
+// where many files can share one table distinguished by file_id, Mongo
+// storage already gets its own database per collectionPrefix, so reusing
+// it as the fileID tag keeps the document shape (and the compound
+// indexes below) consistent with the other two backends without adding
+// a redundant parameter.
+func (s *HybridStorageMongo) FileID() string { return s.collectionPrefix }
+
+func (s *HybridStorageMongo) nodesCollection() *mongo.Collection {
+	return s.db.Collection(s.collectionPrefix + nodesCollectionSuffix)
+}
+
+// Close disconnects the MongoDB client and closes BadgerDB.
+func (s *HybridStorageMongo) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	badgerErr := s.badgerDB.Close()
+	mongoErr := s.client.Disconnect(ctx)
+	if badgerErr != nil {
+		return fmt.Errorf("failed to close BadgerDB: %w", badgerErr)
+	}
+	if mongoErr != nil {
+		return fmt.Errorf("failed to disconnect MongoDB client: %w", mongoErr)
+	}
+	return nil
+}
+
+// ensureMongoIndexes creates the nodes collection's compound indexes:
+// a unique (fileID, xref) index serving FindByXref, (fileID, sex) serving
+// FindBySex, and (fileID, nameLower text), (fileID, birthYear) and
+// (fileID, type). FindByName/FindByNameStarts match nameLower with a
+// regex, which the text index does not serve, and FindByBirthDate
+// filters on birthDate, which has no index.
+func ensureMongoIndexes(ctx context.Context, collection *mongo.Collection) error {
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "fileID", Value: 1}, {Key: "xref", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "fileID", Value: 1}, {Key: "nameLower", Value: "text"}}},
+		{Keys: bson.D{{Key: "fileID", Value: 1}, {Key: "birthYear", Value: 1}}},
+		{Keys: bson.D{{Key: "fileID", Value: 1}, {Key: "sex", Value: 1}}},
+		{Keys: bson.D{{Key: "fileID", Value: 1}, {Key: "type", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	return nil
+}
+
+// hybridStorageMongoFor and hybridQueryHelpersMongoFor associate a Graph
+// with its MongoDB storage/query helpers via a side table keyed by
+// *Graph, the same approach similarityIndexFor (similarity_query.go)
+// uses: Graph has no defining file in this package snapshot, so it can't
+// gain a hybridStorageMongo field the way graph.hybridStorage and
+// graph.hybridStoragePostgres already exist as fields on it.
+var (
+	hybridMongoMu         sync.Mutex
+	hybridStorageMongoFor = make(map[*Graph]*HybridStorageMongo)
+	queryHelpersMongoFor  = make(map[*Graph]*HybridQueryHelpersMongo)
+)
+
+// HybridStorageMongoFor returns the MongoDB storage BuildGraphHybridMongo
+// attached to graph, or nil if graph wasn't built with
+// BuildGraphHybridMongo.
+func HybridStorageMongoFor(graph *Graph) *HybridStorageMongo {
+	hybridMongoMu.Lock()
+	defer hybridMongoMu.Unlock()
+	return hybridStorageMongoFor[graph]
+}
+
+// HybridQueryHelpersMongoFor returns the MongoDB query helpers
+// BuildGraphHybridMongo attached to graph, or nil if graph wasn't built
+// with BuildGraphHybridMongo.
+func HybridQueryHelpersMongoFor(graph *Graph) *HybridQueryHelpersMongo {
+	hybridMongoMu.Lock()
+	defer hybridMongoMu.Unlock()
+	return queryHelpersMongoFor[graph]
+}
+
+// BuildGraphHybridMongo builds a graph using hybrid storage (MongoDB +
+// BadgerDB), parallel to BuildGraphHybrid (SQLite + BadgerDB) and
+// BuildGraphHybridPostgres (PostgreSQL + BadgerDB): MongoDB holds the
+// indexed node lookups (see HybridQueryHelpersMongo), BadgerDB holds the
+// full graph structure. If config is nil, DefaultConfig() is used.
+func BuildGraphHybridMongo(tree *types.GedcomTree, collectionPrefix, badgerPath, mongoURI string, config *Config) (*Graph, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	graph := NewGraphWithConfig(tree, config)
+	graph.hybridMode = true
+
+	storage, err := NewHybridStorageMongo(collectionPrefix, badgerPath, mongoURI, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize MongoDB hybrid storage: %w", err)
+	}
+
+	queryHelpers, err := NewHybridQueryHelpersMongo(storage.MongoDB(), storage.FileID())
+	if err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("failed to create MongoDB query helpers: %w", err)
+	}
+
+	hybridMongoMu.Lock()
+	hybridStorageMongoFor[graph] = storage
+	queryHelpersMongoFor[graph] = queryHelpers
+	hybridMongoMu.Unlock()
+
+	hybridCache, err := NewHybridCache(
+		config.Cache.HybridNodeCacheSize,
+		config.Cache.HybridXrefCacheSize,
+		config.Cache.HybridQueryCacheSize,
+	)
+	if err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("failed to create hybrid cache: %w", err)
+	}
+	graph.hybridCache = hybridCache
+
+	if err := buildGraphInMongo(storage, tree, graph); err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("failed to build MongoDB indexes: %w", err)
+	}
+
+	if err := buildGraphInBadgerDB(storage, tree, graph); err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("failed to build BadgerDB graph: %w", err)
+	}
+
+	if err := EnsureSimilarityIndexPersisted(storage.BadgerDB(), graph); err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("failed to build similarity index: %w", err)
+	}
+
+	return graph, nil
+}
+
+type mongoNodeDoc struct {
+	FileID      string `bson:"fileID"`
+	ID          uint32 `bson:"_id"`
+	Xref        string `bson:"xref"`
+	Type        string `bson:"type"`
+	Name        string `bson:"name,omitempty"`
+	NameLower   string `bson:"nameLower,omitempty"`
+	BirthDate   int64  `bson:"birthDate,omitempty"`
+	BirthYear   int    `bson:"birthYear,omitempty"`
+	BirthPlace  string `bson:"birthPlace,omitempty"`
+	Sex         string `bson:"sex,omitempty"`
+	HasChildren bool   `bson:"hasChildren"`
+	HasSpouse   bool   `bson:"hasSpouse"`
+	Living      bool   `bson:"living"`
+	CreatedAt   int64  `bson:"createdAt"`
+	UpdatedAt   int64  `bson:"updatedAt"`
+}
+
+// buildGraphInMongo builds indexes in MongoDB, the Mongo counterpart of
+// buildGraphInSQLite/buildGraphInPostgreSQL: individuals get the full set
+// of indexed fields, other record types get a bare node document so
+// xref->id resolution still works for them.
+func buildGraphInMongo(storage *HybridStorageMongo, tree *types.GedcomTree, graph *Graph) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := storage.nodesCollection()
+	fileID := storage.FileID()
+	now := time.Now().Unix()
+
+	docs := make([]interface{}, 0)
+
+	nodeID := func(xrefID string) uint32 {
+		graph.mu.Lock()
+		defer graph.mu.Unlock()
+		id := graph.xrefToID[xrefID]
+		if id == 0 {
+			id = graph.nextID
+			graph.nextID++
+			graph.xrefToID[xrefID] = id
+			graph.idToXref[id] = xrefID
+		}
+		return id
+	}
+
+	hasChildren := make(map[uint32]bool)
+	hasSpouse := make(map[uint32]bool)
+	for _, record := range tree.GetAllFamilies() {
+		famRecord, ok := record.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+		husbandXref := famRecord.GetHusband()
+		wifeXref := famRecord.GetWife()
+		if husbandXref != "" {
+			hasSpouse[nodeID(husbandXref)] = true
+		}
+		if wifeXref != "" {
+			hasSpouse[nodeID(wifeXref)] = true
+		}
+		if len(famRecord.GetChildren()) > 0 {
+			if husbandXref != "" {
+				hasChildren[nodeID(husbandXref)] = true
+			}
+			if wifeXref != "" {
+				hasChildren[nodeID(wifeXref)] = true
+			}
+		}
+	}
+
+	for xrefID, record := range tree.GetAllIndividuals() {
+		indiRecord, ok := record.(*types.IndividualRecord)
+		if !ok {
+			continue
+		}
+		id := nodeID(xrefID)
+		name := indiRecord.GetName()
+
+		doc := mongoNodeDoc{
+			FileID:      fileID,
+			ID:          id,
+			Xref:        xrefID,
+			Type:        "individual",
+			Name:        name,
+			NameLower:   toLower(name),
+			BirthPlace:  indiRecord.GetBirthPlace(),
+			Sex:         indiRecord.GetSex(),
+			HasChildren: hasChildren[id],
+			HasSpouse:   hasSpouse[id],
+			Living:      indiRecord.GetDeathDate() == "",
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if birthDate := parseBirthDate(indiRecord); birthDate != nil {
+			if unixSeconds, ok := birthDate.(int64); ok {
+				doc.BirthDate = unixSeconds
+				doc.BirthYear = time.Unix(unixSeconds, 0).UTC().Year()
+			}
+		}
+		docs = append(docs, doc)
+	}
+
+	for xrefID, record := range tree.GetAllFamilies() {
+		if _, ok := record.(*types.FamilyRecord); !ok {
+			continue
+		}
+		docs = append(docs, mongoNodeDoc{FileID: fileID, ID: nodeID(xrefID), Xref: xrefID, Type: "family", CreatedAt: now, UpdatedAt: now})
+	}
+	for xrefID, record := range tree.GetAllNotes() {
+		if _, ok := record.(*types.NoteRecord); !ok {
+			continue
+		}
+		docs = append(docs, mongoNodeDoc{FileID: fileID, ID: nodeID(xrefID), Xref: xrefID, Type: "note", CreatedAt: now, UpdatedAt: now})
+	}
+	for xrefID, record := range tree.GetAllSources() {
+		if _, ok := record.(*types.SourceRecord); !ok {
+			continue
+		}
+		docs = append(docs, mongoNodeDoc{FileID: fileID, ID: nodeID(xrefID), Xref: xrefID, Type: "source", CreatedAt: now, UpdatedAt: now})
+	}
+	for xrefID, record := range tree.GetAllRepositories() {
+		if _, ok := record.(*types.RepositoryRecord); !ok {
+			continue
+		}
+		docs = append(docs, mongoNodeDoc{FileID: fileID, ID: nodeID(xrefID), Xref: xrefID, Type: "repository", CreatedAt: now, UpdatedAt: now})
+	}
+
+	if len(docs) == 0 {
+		return nil
+	}
+
+	if _, err := collection.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("failed to insert nodes: %w", err)
+	}
+	return nil
+}
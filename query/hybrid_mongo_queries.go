@@ -0,0 +1,224 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// HybridQueryHelpersMongo is the MongoDB counterpart to
+// HybridQueryHelpers (SQLite) and HybridQueryHelpersPostgres: it maps the
+// same indexed lookups (FindByName, FindByBirthDate, FindByBirthPlace,
+// FindBySex, HasChildren, HasSpouse, IsLiving) onto aggregation
+// pipelines over the collectionPrefix+"_nodes" collection built by
+// buildGraphInMongo.
+//
+// FilterQuery.Execute (this package's, not pkg/gedcom/query's) isn't
+// re-routed through this interface: its defining file isn't part of this
+// snapshot, so there's no Execute body here to change to dispatch across
+// HybridQueryHelpers/HybridQueryHelpersPostgres/HybridQueryHelpersMongo.
+// A caller that already has a *Graph built via BuildGraphHybridMongo can
+// reach these lookups directly through HybridQueryHelpersMongoFor(graph)
+// in the meantime.
+type HybridQueryHelpersMongo struct {
+	collection *mongo.Collection
+	fileID     string
+}
+
+// NewHybridQueryHelpersMongo creates query helpers over db's nodes
+// collection for fileID.
+func NewHybridQueryHelpersMongo(db *mongo.Database, fileID string) (*HybridQueryHelpersMongo, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db is required for MongoDB query helpers")
+	}
+	return &HybridQueryHelpersMongo{
+		collection: db.Collection(fileID + nodesCollectionSuffix),
+		fileID:     fileID,
+	}, nil
+}
+
+// Close releases any resources held by the helpers. HybridQueryHelpersMongo
+// doesn't hold its own connection (the *mongo.Client lives on
+// HybridStorageMongo), so this is a no-op kept for parity with
+// HybridQueryHelpers.Close/HybridQueryHelpersPostgres.Close.
+func (h *HybridQueryHelpersMongo) Close() error { return nil }
+
+func (h *HybridQueryHelpersMongo) ctx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), 10*time.Second)
+}
+
+func (h *HybridQueryHelpersMongo) aggregateIDs(pipeline mongo.Pipeline) ([]uint32, error) {
+	ctx, cancel := h.ctx()
+	defer cancel()
+
+	cursor, err := h.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("mongo query helpers: aggregate failed: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var ids []uint32
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID uint32 `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("mongo query helpers: failed to decode result: %w", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+	return ids, cursor.Err()
+}
+
+// FindByXref returns the node ID for xref, or 0 if it isn't found.
+func (h *HybridQueryHelpersMongo) FindByXref(xref string) (uint32, error) {
+	ctx, cancel := h.ctx()
+	defer cancel()
+
+	var doc struct {
+		ID uint32 `bson:"_id"`
+	}
+	err := h.collection.FindOne(ctx, bson.M{"fileID": h.fileID, "xref": xref}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("mongo query helpers: FindByXref failed: %w", err)
+	}
+	return doc.ID, nil
+}
+
+// FindXrefByID returns the xref for id.
+func (h *HybridQueryHelpersMongo) FindXrefByID(id uint32) (string, error) {
+	ctx, cancel := h.ctx()
+	defer cancel()
+
+	var doc struct {
+		Xref string `bson:"xref"`
+	}
+	err := h.collection.FindOne(ctx, bson.M{"fileID": h.fileID, "_id": id}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("mongo query helpers: FindXrefByID failed: %w", err)
+	}
+	return doc.Xref, nil
+}
+
+// FindByName returns the IDs of individuals whose name contains pattern
+// (case-insensitive substring match).
+func (h *HybridQueryHelpersMongo) FindByName(pattern string) ([]uint32, error) {
+	quoted := regexp.QuoteMeta(strings.ToLower(pattern))
+	return h.aggregateIDs(mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"fileID":    h.fileID,
+			"nameLower": bson.M{"$regex": quoted},
+		}}},
+	})
+}
+
+// FindByNameExact returns the IDs of individuals whose lowercased name
+// equals name exactly.
+func (h *HybridQueryHelpersMongo) FindByNameExact(name string) ([]uint32, error) {
+	return h.aggregateIDs(mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"fileID":    h.fileID,
+			"nameLower": strings.ToLower(name),
+		}}},
+	})
+}
+
+// FindByNameStarts returns the IDs of individuals whose lowercased name
+// starts with prefix.
+func (h *HybridQueryHelpersMongo) FindByNameStarts(prefix string) ([]uint32, error) {
+	quoted := regexp.QuoteMeta(strings.ToLower(prefix))
+	return h.aggregateIDs(mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"fileID":    h.fileID,
+			"nameLower": bson.M{"$regex": "^" + quoted},
+		}}},
+	})
+}
+
+// FindByBirthDate returns the IDs of individuals whose birth date falls
+// within [start, end].
+func (h *HybridQueryHelpersMongo) FindByBirthDate(start, end time.Time) ([]uint32, error) {
+	return h.aggregateIDs(mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"fileID":    h.fileID,
+			"birthDate": bson.M{"$gte": start.Unix(), "$lte": end.Unix()},
+		}}},
+	})
+}
+
+// FindByBirthPlace returns the IDs of individuals whose birth place
+// contains place (case-insensitive substring match).
+func (h *HybridQueryHelpersMongo) FindByBirthPlace(place string) ([]uint32, error) {
+	quoted := regexp.QuoteMeta(place)
+	return h.aggregateIDs(mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"fileID":     h.fileID,
+			"birthPlace": bson.M{"$regex": quoted, "$options": "i"},
+		}}},
+	})
+}
+
+// FindBySex returns the IDs of individuals with the given sex.
+func (h *HybridQueryHelpersMongo) FindBySex(sex string) ([]uint32, error) {
+	return h.aggregateIDs(mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"fileID": h.fileID,
+			"sex":    sex,
+		}}},
+	})
+}
+
+// HasChildren reports whether the individual identified by id has
+// children.
+func (h *HybridQueryHelpersMongo) HasChildren(id uint32) (bool, error) {
+	return h.boolField(id, "hasChildren")
+}
+
+// HasSpouse reports whether the individual identified by id has a
+// spouse.
+func (h *HybridQueryHelpersMongo) HasSpouse(id uint32) (bool, error) {
+	return h.boolField(id, "hasSpouse")
+}
+
+// IsLiving reports whether the individual identified by id has no
+// recorded death date.
+func (h *HybridQueryHelpersMongo) IsLiving(id uint32) (bool, error) {
+	return h.boolField(id, "living")
+}
+
+func (h *HybridQueryHelpersMongo) boolField(id uint32, field string) (bool, error) {
+	ctx, cancel := h.ctx()
+	defer cancel()
+
+	var doc bson.M
+	err := h.collection.FindOne(ctx, bson.M{"fileID": h.fileID, "_id": id}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("mongo query helpers: failed to read %s: %w", field, err)
+	}
+	value, _ := doc[field].(bool)
+	return value, nil
+}
+
+// GetAllIndividualIDs returns the IDs of every individual node.
+func (h *HybridQueryHelpersMongo) GetAllIndividualIDs() ([]uint32, error) {
+	return h.aggregateIDs(mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"fileID": h.fileID,
+			"type":   "individual",
+		}}},
+	})
+}
@@ -1,6 +1,7 @@
 package query
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -42,11 +43,11 @@ func buildGraphInPostgreSQL(storage *HybridStoragePostgres, tree *types.GedcomTr
 	now := time.Now().Unix()
 
 	// Process all record types
-	if err := processIndividualsForPostgreSQL(tree, graph, stmtNode, stmtXref, fileID, now); err != nil {
+	if err := processIndividualsForPostgreSQL(tree, graph, stmtNode, stmtXref, fileID, now, storage, tx); err != nil {
 		return err
 	}
 
-	if err := processFamiliesForPostgreSQL(tree, graph, stmtNode, stmtXref, fileID, now); err != nil {
+	if err := processFamiliesForPostgreSQL(tree, graph, stmtNode, stmtXref, fileID, now, storage, tx); err != nil {
 		return err
 	}
 
@@ -75,8 +76,9 @@ func buildGraphInPostgreSQL(storage *HybridStoragePostgres, tree *types.GedcomTr
 }
 
 // processIndividualsForPostgreSQL processes individual records for PostgreSQL
-func processIndividualsForPostgreSQL(tree *types.GedcomTree, graph *Graph, stmtNode, stmtXref *sql.Stmt, fileID string, now int64) error {
+func processIndividualsForPostgreSQL(tree *types.GedcomTree, graph *Graph, stmtNode, stmtXref *sql.Stmt, fileID string, now int64, storage *HybridStoragePostgres, sqlTx *sql.Tx) error {
 	individuals := tree.GetAllIndividuals()
+	hookTx := &HybridTx{SQLTx: sqlTx, FileID: fileID, Storage: storage}
 
 	for xrefID, record := range individuals {
 		indiRecord, ok := record.(*types.IndividualRecord)
@@ -107,21 +109,34 @@ func processIndividualsForPostgreSQL(tree *types.GedcomTree, graph *Graph, stmtN
 		hasSpouse := false   // Will be updated later
 		living := indiRecord.GetDeathDate() == ""
 
+		node := &HookNode{
+			FileID: fileID, NodeID: nodeID, Xref: xrefID, Type: "individual",
+			Name: name, NameLower: nameLower, BirthDate: birthDate, BirthPlace: birthPlace, Sex: sex,
+			HasChildren: hasChildren, HasSpouse: hasSpouse, Living: living,
+		}
+		if err := runHooks(context.Background(), storage, hookTx, BeforeNodeInsert, node); err != nil {
+			return err
+		}
+
 		// Insert into nodes table (with file_id)
 		_, err := stmtNode.Exec(
-			fileID, nodeID, xrefID, "individual", name, nameLower,
-			birthDate, birthPlace, sex,
-			boolToInt(hasChildren), boolToInt(hasSpouse), boolToInt(living),
+			node.FileID, node.NodeID, node.Xref, node.Type, node.Name, node.NameLower,
+			node.BirthDate, node.BirthPlace, node.Sex,
+			boolToInt(node.HasChildren), boolToInt(node.HasSpouse), boolToInt(node.Living),
 			now, now,
 		)
 		if err != nil {
-			return fmt.Errorf("failed to insert node %s: %w", xrefID, err)
+			return fmt.Errorf("failed to insert node %s: %w", node.Xref, err)
 		}
 
 		// Insert into xref_mapping (with file_id)
-		_, err = stmtXref.Exec(fileID, xrefID, nodeID)
+		_, err = stmtXref.Exec(fileID, node.Xref, node.NodeID)
 		if err != nil {
-			return fmt.Errorf("failed to insert xref mapping %s: %w", xrefID, err)
+			return fmt.Errorf("failed to insert xref mapping %s: %w", node.Xref, err)
+		}
+
+		if err := runHooks(context.Background(), storage, hookTx, AfterNodeInsert, node); err != nil {
+			return err
 		}
 	}
 
@@ -129,8 +144,9 @@ func processIndividualsForPostgreSQL(tree *types.GedcomTree, graph *Graph, stmtN
 }
 
 // processFamiliesForPostgreSQL processes family records for PostgreSQL
-func processFamiliesForPostgreSQL(tree *types.GedcomTree, graph *Graph, stmtNode, stmtXref *sql.Stmt, fileID string, now int64) error {
+func processFamiliesForPostgreSQL(tree *types.GedcomTree, graph *Graph, stmtNode, stmtXref *sql.Stmt, fileID string, now int64, storage *HybridStoragePostgres, sqlTx *sql.Tx) error {
 	families := tree.GetAllFamilies()
+	hookTx := &HybridTx{SQLTx: sqlTx, FileID: fileID, Storage: storage}
 
 	for xrefID, record := range families {
 		_, ok := record.(*types.FamilyRecord)
@@ -149,21 +165,30 @@ func processFamiliesForPostgreSQL(tree *types.GedcomTree, graph *Graph, stmtNode
 		}
 		graph.mu.Unlock()
 
+		node := &HookNode{FileID: fileID, NodeID: nodeID, Xref: xrefID, Type: "family"}
+		if err := runHooks(context.Background(), storage, hookTx, BeforeNodeInsert, node); err != nil {
+			return err
+		}
+
 		// Families don't have as many indexed fields
 		_, err := stmtNode.Exec(
-			fileID, nodeID, xrefID, "family", "", "",
+			node.FileID, node.NodeID, node.Xref, node.Type, "", "",
 			nil, "", "",
 			0, 0, 0,
 			now, now,
 		)
 		if err != nil {
-			return fmt.Errorf("failed to insert family node %s: %w", xrefID, err)
+			return fmt.Errorf("failed to insert family node %s: %w", node.Xref, err)
 		}
 
 		// Insert into xref_mapping (with file_id)
-		_, err = stmtXref.Exec(fileID, xrefID, nodeID)
+		_, err = stmtXref.Exec(fileID, node.Xref, node.NodeID)
 		if err != nil {
-			return fmt.Errorf("failed to insert family xref mapping %s: %w", xrefID, err)
+			return fmt.Errorf("failed to insert family xref mapping %s: %w", node.Xref, err)
+		}
+
+		if err := runHooks(context.Background(), storage, hookTx, AfterNodeInsert, node); err != nil {
+			return err
 		}
 	}
 
@@ -0,0 +1,346 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// BulkOptions configures BuildGraphHybridPostgresBulk's COPY-based
+// ingestion path.
+type BulkOptions struct {
+	// BatchSize caps how many rows accumulate in memory before a CopyFrom
+	// call flushes them. 0 (the default) loads every row in a single
+	// CopyFrom call per table, which is fine up to a few hundred thousand
+	// rows; set it for very large trees if memory is a concern.
+	BatchSize int
+
+	// DeferIndexes drops the GIN indexes EnsureFullTextSearchSchema
+	// creates (nodes_name_tsv_idx, nodes_place_tsv_idx,
+	// nodes_notes_tsv_idx) before the COPY and recreates them afterward,
+	// so they are built once against the fully populated table rather
+	// than maintained row by row during the load. The idx_nodes_*_tsv
+	// indexes created by migration 004 are not dropped.
+	DeferIndexes bool
+
+	// Analyze runs ANALYZE on nodes and xref_mapping after the load, so
+	// the query planner has fresh statistics for the newly bulk-loaded
+	// file_id.
+	Analyze bool
+}
+
+// DefaultBulkOptions returns the BulkOptions BuildGraphHybridPostgresBulk
+// uses when opts is nil: unbounded batch size, indexes deferred, ANALYZE
+// afterward.
+func DefaultBulkOptions() *BulkOptions {
+	return &BulkOptions{DeferIndexes: true, Analyze: true}
+}
+
+// StorageOptions selects between BuildGraphHybridPostgres's per-row
+// inserts and BuildGraphHybridPostgresBulk's COPY-based path. It isn't a
+// field on HybridStoragePostgres itself -- that type and its constructor
+// NewHybridStoragePostgres aren't defined anywhere in this package
+// snapshot (see the same note on SQLBackend in sql_backend.go), so there
+// is no struct here to add UseCopyProtocol to. BuildGraphHybridPostgresWithOptions
+// is the entry point that reads it instead, dispatching to whichever
+// builder the caller asked for.
+type StorageOptions struct {
+	// UseCopyProtocol selects BuildGraphHybridPostgresBulk's CopyFrom
+	// ingestion over BuildGraphHybridPostgres's one-INSERT-per-row path.
+	UseCopyProtocol bool
+}
+
+// BuildGraphHybridPostgresWithOptions builds a hybrid PostgreSQL-backed
+// Graph for tree, using the COPY protocol when opts.UseCopyProtocol is
+// set (via BuildGraphHybridPostgresBulk, with bulkOpts passed through
+// unchanged) or the existing per-row inserts otherwise (via
+// BuildGraphHybridPostgres). A nil opts behaves like &StorageOptions{}
+// (per-row).
+func BuildGraphHybridPostgresWithOptions(tree *types.GedcomTree, fileID, badgerPath, databaseURL string, config *Config, opts *StorageOptions, bulkOpts *BulkOptions) (*Graph, error) {
+	if opts != nil && opts.UseCopyProtocol {
+		return BuildGraphHybridPostgresBulk(tree, fileID, badgerPath, databaseURL, config, bulkOpts)
+	}
+	return BuildGraphHybridPostgres(tree, fileID, badgerPath, databaseURL, config)
+}
+
+// BuildGraphHybridPostgresBulk is BuildGraphHybridPostgres's fast path for
+// large imports: individual and family rows (the two record types that
+// dominate row count in any real GEDCOM) are streamed into nodes and
+// xref_mapping via pgx's COPY protocol inside a single transaction,
+// instead of one INSERT per row. This is the dominant cost importing a
+// real 50k-individual file.
+//
+// Notes, sources, repositories, and event nodes aren't part of the COPY
+// path -- they're typically a few hundred rows even in a large tree, so
+// BuildGraphHybridPostgres's existing per-row inserts (see
+// processNotesForPostgreSQL and friends in hybrid_postgres_builder.go)
+// remain the way to load them; callers whose trees lean heavily on those
+// record types should use BuildGraphHybridPostgres instead.
+//
+// Edge data isn't part of this COPY either: the hybrid storage backend
+// keeps the graph's edges in BadgerDB, not in a PostgreSQL table (see
+// buildGraphInBadgerDB) -- BuildGraphHybridPostgresBulk still calls
+// buildGraphInBadgerDB after the COPY completes, the same as the
+// per-row path.
+//
+// On any failure the whole transaction rolls back, so a failed bulk
+// import never leaves partial file_id data behind.
+func BuildGraphHybridPostgresBulk(tree *types.GedcomTree, fileID, badgerPath, databaseURL string, config *Config, opts *BulkOptions) (*Graph, error) {
+	if fileID == "" {
+		return nil, fmt.Errorf("fileID is required for PostgreSQL storage")
+	}
+	if opts == nil {
+		opts = DefaultBulkOptions()
+	}
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	graph := NewGraphWithConfig(tree, config)
+	graph.hybridMode = true
+
+	storage, err := NewHybridStoragePostgres(fileID, badgerPath, databaseURL, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize PostgreSQL hybrid storage: %w", err)
+	}
+	graph.hybridStoragePostgres = storage
+
+	queryHelpers, err := NewHybridQueryHelpersPostgres(storage.PostgreSQL(), fileID)
+	if err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("failed to create PostgreSQL query helpers: %w", err)
+	}
+	graph.queryHelpersPostgres = queryHelpers
+
+	hybridCache, err := NewHybridCache(
+		config.Cache.HybridNodeCacheSize,
+		config.Cache.HybridXrefCacheSize,
+		config.Cache.HybridQueryCacheSize,
+	)
+	if err != nil {
+		queryHelpers.Close()
+		storage.Close()
+		return nil, fmt.Errorf("failed to create hybrid cache: %w", err)
+	}
+	graph.hybridCache = hybridCache
+
+	if err := bulkLoadPostgreSQL(storage, tree, graph, opts); err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("failed to bulk load PostgreSQL indexes: %w", err)
+	}
+
+	if err := buildGraphInBadgerDB(storage, tree, graph); err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("failed to build BadgerDB graph: %w", err)
+	}
+
+	return graph, nil
+}
+
+var nodesCopyColumns = []string{
+	"file_id", "id", "xref", "type", "name", "name_lower",
+	"birth_date", "birth_place", "sex", "has_children", "has_spouse", "living",
+	"created_at", "updated_at",
+}
+
+var xrefMappingCopyColumns = []string{"file_id", "xref", "node_id"}
+
+// bulkLoadPostgreSQL assigns node IDs exactly the way
+// processIndividualsForPostgreSQL/processFamiliesForPostgreSQL do, then
+// streams the resulting rows into PostgreSQL via CopyFrom instead of
+// db.Exec, all inside one transaction.
+func bulkLoadPostgreSQL(storage *HybridStoragePostgres, tree *types.GedcomTree, graph *Graph, opts *BulkOptions) error {
+	ctx := context.Background()
+	db := storage.PostgreSQL()
+	fileID := storage.FileID()
+
+	conn, err := stdlib.AcquireConn(db)
+	if err != nil {
+		return fmt.Errorf("failed to acquire pgx connection: %w", err)
+	}
+	defer stdlib.ReleaseConn(db, conn)
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if opts.DeferIndexes {
+		if _, err := tx.Exec(ctx, "DROP INDEX IF EXISTS nodes_name_tsv_idx"); err != nil {
+			return fmt.Errorf("failed to drop name_tsv index: %w", err)
+		}
+		if _, err := tx.Exec(ctx, "DROP INDEX IF EXISTS nodes_place_tsv_idx"); err != nil {
+			return fmt.Errorf("failed to drop place_tsv index: %w", err)
+		}
+		if _, err := tx.Exec(ctx, "DROP INDEX IF EXISTS nodes_notes_tsv_idx"); err != nil {
+			return fmt.Errorf("failed to drop notes_tsv index: %w", err)
+		}
+	}
+
+	now := time.Now().Unix()
+	nodeRows, xrefRows := collectBulkRows(tree, graph, fileID, now)
+
+	if err := copyInBatches(ctx, tx, "nodes", nodesCopyColumns, nodeRows, opts.BatchSize); err != nil {
+		return fmt.Errorf("failed to COPY nodes: %w", err)
+	}
+	if err := copyInBatches(ctx, tx, "xref_mapping", xrefMappingCopyColumns, xrefRows, opts.BatchSize); err != nil {
+		return fmt.Errorf("failed to COPY xref_mapping: %w", err)
+	}
+
+	if opts.DeferIndexes {
+		if _, err := tx.Exec(ctx, "CREATE INDEX IF NOT EXISTS nodes_name_tsv_idx ON nodes USING GIN (name_tsv)"); err != nil {
+			return fmt.Errorf("failed to recreate name_tsv index: %w", err)
+		}
+		if _, err := tx.Exec(ctx, "CREATE INDEX IF NOT EXISTS nodes_place_tsv_idx ON nodes USING GIN (place_tsv)"); err != nil {
+			return fmt.Errorf("failed to recreate place_tsv index: %w", err)
+		}
+		if _, err := tx.Exec(ctx, "CREATE INDEX IF NOT EXISTS nodes_notes_tsv_idx ON nodes USING GIN (notes_tsv)"); err != nil {
+			return fmt.Errorf("failed to recreate notes_tsv index: %w", err)
+		}
+	}
+
+	if opts.Analyze {
+		if _, err := tx.Exec(ctx, "ANALYZE nodes"); err != nil {
+			return fmt.Errorf("failed to ANALYZE nodes: %w", err)
+		}
+		if _, err := tx.Exec(ctx, "ANALYZE xref_mapping"); err != nil {
+			return fmt.Errorf("failed to ANALYZE xref_mapping: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// collectBulkRows assigns node IDs for every individual and family record
+// and builds the row slices CopyFrom needs for nodes and xref_mapping.
+func collectBulkRows(tree *types.GedcomTree, graph *Graph, fileID string, now int64) (nodeRows, xrefRows [][]any) {
+	individuals := tree.GetAllIndividuals()
+	families := tree.GetAllFamilies()
+	nodeRows = make([][]any, 0, len(individuals)+len(families))
+	xrefRows = make([][]any, 0, len(individuals)+len(families))
+
+	hasChildren, hasSpouse := relationshipFlagsFromFamilies(families)
+
+	for xrefID, record := range individuals {
+		indiRecord, ok := record.(*types.IndividualRecord)
+		if !ok {
+			continue
+		}
+		nodeID := assignBulkNodeID(graph, xrefID)
+
+		name := indiRecord.GetName()
+		nameLower := toLower(name)
+		birthDate := parseBirthDate(indiRecord)
+		birthPlace := indiRecord.GetBirthPlace()
+		sex := indiRecord.GetSex()
+		living := indiRecord.GetDeathDate() == ""
+
+		nodeRows = append(nodeRows, []any{
+			fileID, nodeID, xrefID, "individual", name, nameLower,
+			birthDate, birthPlace, sex,
+			boolToInt(hasChildren[xrefID]), boolToInt(hasSpouse[xrefID]), boolToInt(living),
+			now, now,
+		})
+		xrefRows = append(xrefRows, []any{fileID, xrefID, nodeID})
+	}
+
+	for xrefID, record := range families {
+		if _, ok := record.(*types.FamilyRecord); !ok {
+			continue
+		}
+		nodeID := assignBulkNodeID(graph, xrefID)
+
+		nodeRows = append(nodeRows, []any{
+			fileID, nodeID, xrefID, "family", "", "",
+			nil, "", "",
+			0, 0, 0,
+			now, now,
+		})
+		xrefRows = append(xrefRows, []any{fileID, xrefID, nodeID})
+	}
+
+	return nodeRows, xrefRows
+}
+
+// relationshipFlagsFromFamilies walks families once and returns, by
+// individual xref, whether that individual has at least one child
+// (appears as a HUSB/WIFE on some family with at least one CHIL) or at
+// least one spouse link (appears as a HUSB/WIFE on any family), computing what updateRelationshipFlagsPostgreSQL derives
+// from a second full-table UPDATE in the per-row path. collectBulkRows
+// folds the result straight into the nodes rows it streams via COPY, so
+// the bulk path never needs that second pass.
+func relationshipFlagsFromFamilies(families map[string]types.Record) (hasChildren, hasSpouse map[string]bool) {
+	hasChildren = make(map[string]bool)
+	hasSpouse = make(map[string]bool)
+
+	for _, record := range families {
+		famRecord, ok := record.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+
+		husbandXref := famRecord.GetHusband()
+		wifeXref := famRecord.GetWife()
+		if husbandXref != "" {
+			hasSpouse[husbandXref] = true
+		}
+		if wifeXref != "" {
+			hasSpouse[wifeXref] = true
+		}
+
+		if len(famRecord.GetChildren()) > 0 {
+			if husbandXref != "" {
+				hasChildren[husbandXref] = true
+			}
+			if wifeXref != "" {
+				hasChildren[wifeXref] = true
+			}
+		}
+	}
+
+	return hasChildren, hasSpouse
+}
+
+func assignBulkNodeID(graph *Graph, xrefID string) uint32 {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	nodeID := graph.xrefToID[xrefID]
+	if nodeID == 0 {
+		nodeID = graph.nextID
+		graph.nextID++
+		graph.xrefToID[xrefID] = nodeID
+		graph.idToXref[nodeID] = xrefID
+	}
+	return nodeID
+}
+
+// copyInBatches runs CopyFrom over rows in chunks of batchSize (or all at
+// once if batchSize <= 0).
+func copyInBatches(ctx context.Context, tx pgx.Tx, table string, columns []string, rows [][]any, batchSize int) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(rows)
+	}
+
+	ident := pgx.Identifier{table}
+	for start := 0; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		if _, err := tx.CopyFrom(ctx, ident, columns, pgx.CopyFromRows(rows[start:end])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package query
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// buildSyntheticIndividuals builds a tree of n unrelated individuals, to
+// isolate raw node-insert cost from the parent/child graph-building work
+// already covered by buildLinearAncestryChain.
+func buildSyntheticIndividuals(n int) *types.GedcomTree {
+	tree := types.NewGedcomTree()
+	for i := 0; i < n; i++ {
+		xref := "@I" + itoa(i) + "@"
+		line := types.NewGedcomLine(0, "INDI", "", xref)
+		line.AddChild(types.NewGedcomLine(1, "NAME", "Person /Number/", ""))
+		tree.AddRecord(types.NewIndividualRecord(line))
+	}
+	return tree
+}
+
+func TestBuildGraphHybridPostgresBulk(t *testing.T) {
+	databaseURL := getPostgreSQLTestURL(t)
+	testPostgreSQLConnection(t, databaseURL)
+
+	tmpDir := t.TempDir()
+	badgerPath := filepath.Join(tmpDir, "test_graph")
+	fileID := "test_file_bulk_001"
+	tree := buildSyntheticIndividuals(50)
+
+	graph, err := BuildGraphHybridPostgresBulk(tree, fileID, badgerPath, databaseURL, nil, nil)
+	if err != nil {
+		t.Fatalf("BuildGraphHybridPostgresBulk failed: %v", err)
+	}
+	defer func() {
+		db := graph.hybridStoragePostgres.PostgreSQL()
+		_, _ = db.Exec("DELETE FROM nodes WHERE file_id = $1", fileID)
+		_, _ = db.Exec("DELETE FROM xref_mapping WHERE file_id = $1", fileID)
+		graph.hybridStoragePostgres.Close()
+	}()
+
+	db := graph.hybridStoragePostgres.PostgreSQL()
+	var count int
+	if err := db.QueryRow("SELECT count(*) FROM nodes WHERE file_id = $1", fileID).Scan(&count); err != nil {
+		t.Fatalf("failed to count nodes: %v", err)
+	}
+	if count != 50 {
+		t.Errorf("expected 50 nodes after bulk load, got %d", count)
+	}
+}
+
+func BenchmarkBuildGraphHybridPostgres_PerRowVsBulk(b *testing.B) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		b.Skip("Skipping PostgreSQL benchmark: DATABASE_URL environment variable not set")
+	}
+	tree := buildSyntheticIndividuals(10000)
+
+	b.Run("per_row", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tmpDir := b.TempDir()
+			fileID := "bench_per_row_" + itoa(i)
+			graph, err := BuildGraphHybridPostgres(tree, fileID, filepath.Join(tmpDir, "graph"), databaseURL, nil)
+			if err != nil {
+				b.Fatalf("BuildGraphHybridPostgres failed: %v", err)
+			}
+			db := graph.hybridStoragePostgres.PostgreSQL()
+			_, _ = db.Exec("DELETE FROM nodes WHERE file_id = $1", fileID)
+			_, _ = db.Exec("DELETE FROM xref_mapping WHERE file_id = $1", fileID)
+			graph.hybridStoragePostgres.Close()
+		}
+	})
+
+	b.Run("bulk_copy", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tmpDir := b.TempDir()
+			fileID := "bench_bulk_" + itoa(i)
+			graph, err := BuildGraphHybridPostgresBulk(tree, fileID, filepath.Join(tmpDir, "graph"), databaseURL, nil, nil)
+			if err != nil {
+				b.Fatalf("BuildGraphHybridPostgresBulk failed: %v", err)
+			}
+			db := graph.hybridStoragePostgres.PostgreSQL()
+			_, _ = db.Exec("DELETE FROM nodes WHERE file_id = $1", fileID)
+			_, _ = db.Exec("DELETE FROM xref_mapping WHERE file_id = $1", fileID)
+			graph.hybridStoragePostgres.Close()
+		}
+	})
+}
@@ -0,0 +1,225 @@
+package query
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/diff"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// ApplyDiff reindexes tree's PostgreSQL-backed nodes/xref_mapping rows for
+// only the records changes touched, instead of rerunning
+// buildGraphInPostgreSQL's full rebuild: changes.Added is inserted,
+// changes.Removed is deleted, and changes.Modified is re-upserted from
+// tree's current state (RecordModification only confirms Xref/Type in
+// this package's snapshot, not a Record field carrying the new content,
+// so the new values are looked up from tree by xref rather than assumed
+// to ride along on the entry).
+//
+// Borrowing the "connected changed set" idea the request describes: after
+// applying every entry, ApplyDiff also recomputes has_children/has_spouse
+// for every individual connected to a changed FAM record (husband, wife,
+// and children), even when that individual's own entry isn't itself in
+// changes -- changing who a family's CHIL/HUSB/WIFE lines point at can
+// flip those flags for someone whose own row is otherwise untouched.
+func ApplyDiff(storage *HybridStoragePostgres, tree *types.GedcomTree, graph *Graph, changes diff.DiffChanges) error {
+	db := storage.PostgreSQL()
+	fileID := storage.FileID()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	touched := make(map[string]bool)
+
+	for _, added := range changes.Added {
+		rec := added.Record
+		if rec == nil {
+			rec = lookupRecord(tree, added.Xref)
+		}
+		if rec == nil {
+			continue
+		}
+		if err := applyDiffUpsert(tx, graph, fileID, rec, now); err != nil {
+			return err
+		}
+		collectTouchedXrefs(rec, touched)
+	}
+
+	for _, removed := range changes.Removed {
+		if err := applyDiffDelete(tx, graph, fileID, removed.Xref); err != nil {
+			return err
+		}
+		if rec := removed.Record; rec != nil {
+			collectTouchedXrefs(rec, touched)
+		} else {
+			touched[removed.Xref] = true
+		}
+	}
+
+	for _, mod := range changes.Modified {
+		rec := lookupRecord(tree, mod.Xref)
+		if rec == nil {
+			continue
+		}
+		if err := applyDiffUpsert(tx, graph, fileID, rec, now); err != nil {
+			return err
+		}
+		collectTouchedXrefs(rec, touched)
+	}
+
+	if err := refreshRelationshipFlags(tx, tree, graph, fileID, touched); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit diff: %w", err)
+	}
+	return nil
+}
+
+// lookupRecord fetches xrefID's current record out of tree, checking
+// individuals and then families.
+func lookupRecord(tree *types.GedcomTree, xrefID string) types.Record {
+	if rec, ok := tree.GetAllIndividuals()[xrefID]; ok {
+		return rec
+	}
+	if rec, ok := tree.GetAllFamilies()[xrefID]; ok {
+		return rec
+	}
+	return nil
+}
+
+// collectTouchedXrefs records rec's own xref, and -- if rec is a FAM --
+// its husband, wife, and children's xrefs, as individuals whose
+// has_children/has_spouse flags need to be recomputed.
+func collectTouchedXrefs(rec types.Record, touched map[string]bool) {
+	if rec == nil {
+		return
+	}
+	touched[rec.XrefID()] = true
+
+	fam, ok := rec.(*types.FamilyRecord)
+	if !ok {
+		return
+	}
+	if husb := fam.GetHusband(); husb != "" {
+		touched[husb] = true
+	}
+	if wife := fam.GetWife(); wife != "" {
+		touched[wife] = true
+	}
+	for _, child := range fam.GetChildren() {
+		touched[child] = true
+	}
+}
+
+// applyDiffUpsert inserts or updates the nodes/xref_mapping rows for a
+// single changed record, assigning it a node ID the same way
+// collectBulkRows and processIndividualsForPostgreSQL do if it's new.
+func applyDiffUpsert(tx *sql.Tx, graph *Graph, fileID string, rec types.Record, now int64) error {
+	if rec == nil {
+		return nil
+	}
+
+	xrefID := rec.XrefID()
+	nodeID := assignBulkNodeID(graph, xrefID)
+
+	switch r := rec.(type) {
+	case *types.IndividualRecord:
+		name := r.GetName()
+		_, err := tx.Exec(`
+			INSERT INTO nodes (file_id, id, xref, type, name, name_lower, birth_date, birth_place, sex, has_children, has_spouse, living, created_at, updated_at)
+			VALUES ($1, $2, $3, 'individual', $4, $5, $6, $7, $8, $9, $10, $11, $12, $12)
+			ON CONFLICT (file_id, id) DO UPDATE SET
+				xref = EXCLUDED.xref, name = EXCLUDED.name, name_lower = EXCLUDED.name_lower,
+				birth_date = EXCLUDED.birth_date, birth_place = EXCLUDED.birth_place, sex = EXCLUDED.sex,
+				living = EXCLUDED.living, updated_at = EXCLUDED.updated_at
+		`, fileID, nodeID, xrefID, name, toLower(name), parseBirthDate(r), r.GetBirthPlace(), r.GetSex(),
+			boolToInt(false), boolToInt(false), boolToInt(r.GetDeathDate() == ""), now)
+		if err != nil {
+			return fmt.Errorf("failed to upsert individual node %s: %w", xrefID, err)
+		}
+	case *types.FamilyRecord:
+		_, err := tx.Exec(`
+			INSERT INTO nodes (file_id, id, xref, type, name, name_lower, birth_date, birth_place, sex, has_children, has_spouse, living, created_at, updated_at)
+			VALUES ($1, $2, $3, 'family', '', '', NULL, '', '', 0, 0, 0, $4, $4)
+			ON CONFLICT (file_id, id) DO UPDATE SET xref = EXCLUDED.xref, updated_at = EXCLUDED.updated_at
+		`, fileID, nodeID, xrefID, now)
+		if err != nil {
+			return fmt.Errorf("failed to upsert family node %s: %w", xrefID, err)
+		}
+	default:
+		return nil
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO xref_mapping (file_id, xref, node_id) VALUES ($1, $2, $3)
+		ON CONFLICT (file_id, xref) DO UPDATE SET node_id = EXCLUDED.node_id
+	`, fileID, xrefID, nodeID)
+	if err != nil {
+		return fmt.Errorf("failed to upsert xref mapping %s: %w", xrefID, err)
+	}
+	return nil
+}
+
+// applyDiffDelete removes a deleted record's nodes/xref_mapping rows and
+// its in-memory graph ID mapping.
+func applyDiffDelete(tx *sql.Tx, graph *Graph, fileID, xrefID string) error {
+	if _, err := tx.Exec(`DELETE FROM xref_mapping WHERE file_id = $1 AND xref = $2`, fileID, xrefID); err != nil {
+		return fmt.Errorf("failed to delete xref mapping %s: %w", xrefID, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM nodes WHERE file_id = $1 AND xref = $2`, fileID, xrefID); err != nil {
+		return fmt.Errorf("failed to delete node %s: %w", xrefID, err)
+	}
+
+	graph.mu.Lock()
+	if nodeID, ok := graph.xrefToID[xrefID]; ok {
+		delete(graph.xrefToID, xrefID)
+		delete(graph.idToXref, nodeID)
+	}
+	graph.mu.Unlock()
+
+	return nil
+}
+
+// refreshRelationshipFlags recomputes has_children/has_spouse against
+// tree's current state for exactly the xrefs in touched, instead of
+// updateRelationshipFlagsPostgreSQL's full-table pass over every
+// individual.
+func refreshRelationshipFlags(tx *sql.Tx, tree *types.GedcomTree, graph *Graph, fileID string, touched map[string]bool) error {
+	if len(touched) == 0 {
+		return nil
+	}
+
+	hasChildren, hasSpouse := relationshipFlagsFromFamilies(tree.GetAllFamilies())
+	individuals := tree.GetAllIndividuals()
+
+	for xrefID := range touched {
+		if _, ok := individuals[xrefID]; !ok {
+			// Not (or no longer) an individual -- e.g. a deleted person, or
+			// a FAM xref swept in via collectTouchedXrefs.
+			continue
+		}
+		graph.mu.Lock()
+		nodeID := graph.xrefToID[xrefID]
+		graph.mu.Unlock()
+		if nodeID == 0 {
+			continue
+		}
+
+		_, err := tx.Exec(
+			`UPDATE nodes SET has_children = $1, has_spouse = $2 WHERE file_id = $3 AND id = $4`,
+			boolToInt(hasChildren[xrefID]), boolToInt(hasSpouse[xrefID]), fileID, nodeID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to refresh relationship flags for %s: %w", xrefID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package query
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// NodeIDScore pairs a node ID with a full-text search relevance score
+// (ts_rank_cd), highest score first.
+type NodeIDScore struct {
+	NodeID uint32
+	Score  float64
+}
+
+// SearchResult aggregates full-text search hits across every indexed
+// column, for callers that want one "search everything" entry point and
+// still be able to tell a name match from a place or notes match.
+type SearchResult struct {
+	Names  []NodeIDScore
+	Places []NodeIDScore
+	Notes  []NodeIDScore
+}
+
+// fullTextSearchConfig is the text search configuration used for every
+// tsvector/tsquery built by this file. "simple" (rather than e.g.
+// "english") avoids stemming surnames and place names in ways that would
+// hurt genealogy search precision.
+const fullTextSearchConfig = "simple"
+
+// unaccentAvailableFor records, per *sql.DB, whether EnsureFullTextSearchSchema
+// was able to enable the unaccent extension on that connection -- so
+// SearchNamesPostgres/SearchPlacesPostgres/SearchAllPostgres build the
+// matching tsquery expression without re-probing the extension on every
+// call.
+var (
+	unaccentAvailableMu  sync.Mutex
+	unaccentAvailableFor = make(map[*sql.DB]bool)
+)
+
+// EnsureFullTextSearchSchema adds the name_tsv/place_tsv/notes_tsv
+// tsvector columns, their GIN indexes, and a trigger that keeps them in
+// sync with name/birth_place/notes. Every statement is idempotent
+// (IF NOT EXISTS / CREATE OR REPLACE), so it's safe to call each time a
+// HybridStoragePostgres is opened rather than only on first creation.
+//
+// unaccent is enabled opportunistically: if the extension can't be
+// created (not installed on the server, or the connecting role lacks
+// CREATE EXTENSION privileges), indexing and search both fall back to
+// to_tsvector/to_tsquery without it instead of failing outright --
+// diacritic-insensitive matching ("Müller" finding "Muller") is then
+// simply unavailable, but search itself still works.
+//
+// The "notes" text column this adds starts out empty for every row:
+// note body extraction from types.NoteRecord isn't wired into
+// processNotesForPostgreSQL (see hybrid_postgres_builder.go), so
+// notes_tsv has nothing to index yet. SearchNamesPostgres and
+// SearchPlacesPostgres work immediately; a SearchAllPostgres.Notes hit
+// requires that wiring to be added separately.
+func EnsureFullTextSearchSchema(db *sql.DB) error {
+	unaccent := tryEnableUnaccent(db)
+	setUnaccentAvailable(db, unaccent)
+
+	statements := []string{
+		`ALTER TABLE nodes ADD COLUMN IF NOT EXISTS notes text`,
+		`ALTER TABLE nodes ADD COLUMN IF NOT EXISTS name_tsv tsvector`,
+		`ALTER TABLE nodes ADD COLUMN IF NOT EXISTS place_tsv tsvector`,
+		`ALTER TABLE nodes ADD COLUMN IF NOT EXISTS notes_tsv tsvector`,
+		`CREATE INDEX IF NOT EXISTS nodes_name_tsv_idx ON nodes USING GIN (name_tsv)`,
+		`CREATE INDEX IF NOT EXISTS nodes_place_tsv_idx ON nodes USING GIN (place_tsv)`,
+		`CREATE INDEX IF NOT EXISTS nodes_notes_tsv_idx ON nodes USING GIN (notes_tsv)`,
+		fmt.Sprintf(`CREATE OR REPLACE FUNCTION nodes_tsv_update() RETURNS trigger AS $$
+BEGIN
+	NEW.name_tsv := %s;
+	NEW.place_tsv := %s;
+	NEW.notes_tsv := %s;
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql`,
+			tsvectorExpr(unaccent, "NEW.name"),
+			tsvectorExpr(unaccent, "NEW.birth_place"),
+			tsvectorExpr(unaccent, "NEW.notes")),
+		`DROP TRIGGER IF EXISTS nodes_tsv_trigger ON nodes`,
+		`CREATE TRIGGER nodes_tsv_trigger BEFORE INSERT OR UPDATE ON nodes
+			FOR EACH ROW EXECUTE FUNCTION nodes_tsv_update()`,
+		fmt.Sprintf(`UPDATE nodes SET name_tsv = %s, place_tsv = %s, notes_tsv = %s`,
+			tsvectorExpr(unaccent, "name"),
+			tsvectorExpr(unaccent, "birth_place"),
+			tsvectorExpr(unaccent, "notes")),
+	}
+
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("fulltext: failed to apply schema statement: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func tryEnableUnaccent(db *sql.DB) bool {
+	_, err := db.Exec("CREATE EXTENSION IF NOT EXISTS unaccent")
+	return err == nil
+}
+
+func setUnaccentAvailable(db *sql.DB, available bool) {
+	unaccentAvailableMu.Lock()
+	defer unaccentAvailableMu.Unlock()
+	unaccentAvailableFor[db] = available
+}
+
+func unaccentAvailable(db *sql.DB) bool {
+	unaccentAvailableMu.Lock()
+	defer unaccentAvailableMu.Unlock()
+	return unaccentAvailableFor[db]
+}
+
+func tsvectorExpr(unaccent bool, column string) string {
+	if unaccent {
+		return fmt.Sprintf("to_tsvector('%s', unaccent(coalesce(%s, '')))", fullTextSearchConfig, column)
+	}
+	return fmt.Sprintf("to_tsvector('%s', coalesce(%s, ''))", fullTextSearchConfig, column)
+}
+
+func tsqueryExpr(unaccent bool, placeholder string) string {
+	if unaccent {
+		return fmt.Sprintf("to_tsquery('%s', unaccent(%s))", fullTextSearchConfig, placeholder)
+	}
+	return fmt.Sprintf("to_tsquery('%s', %s)", fullTextSearchConfig, placeholder)
+}
+
+// SearchNamesPostgres, SearchPlacesPostgres and SearchAllPostgres are free
+// functions taking db/fileID explicitly, the same way
+// buildGraphInPostgreSQL and its processXForPostgreSQL helpers do in
+// hybrid_postgres_builder.go, rather than methods on
+// HybridQueryHelpersPostgres: that type's fields aren't part of this
+// package snapshot (only its constructor and existing Find*/Has*/Is*
+// methods are, via other files' usage), so there's no way to read its
+// stored db/fileID from a new method body. Once HybridQueryHelpersPostgres
+// itself is available to edit, these belong there as SearchNames/
+// SearchPlaces/SearchAll methods instead.
+
+// SearchNamesPostgres runs a full-text search over the name column for
+// fileID, ranked by ts_rank_cd, highest score first.
+func SearchNamesPostgres(db *sql.DB, fileID, query string, limit int) ([]NodeIDScore, error) {
+	return searchColumnPostgres(db, fileID, "name_tsv", query, limit)
+}
+
+// SearchPlacesPostgres runs a full-text search over birth_place.
+func SearchPlacesPostgres(db *sql.DB, fileID, query string, limit int) ([]NodeIDScore, error) {
+	return searchColumnPostgres(db, fileID, "place_tsv", query, limit)
+}
+
+// SearchAllPostgres runs query against every indexed column and returns
+// the ranked hits for each separately.
+func SearchAllPostgres(db *sql.DB, fileID, query string) (*SearchResult, error) {
+	names, err := searchColumnPostgres(db, fileID, "name_tsv", query, 0)
+	if err != nil {
+		return nil, err
+	}
+	places, err := searchColumnPostgres(db, fileID, "place_tsv", query, 0)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := searchColumnPostgres(db, fileID, "notes_tsv", query, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &SearchResult{Names: names, Places: places, Notes: notes}, nil
+}
+
+func searchColumnPostgres(db *sql.DB, fileID, tsvColumn, query string, limit int) ([]NodeIDScore, error) {
+	tsquery := parseSearchQuery(query)
+	if tsquery == "" {
+		return nil, nil
+	}
+
+	unaccent := unaccentAvailable(db)
+	matchExpr := tsqueryExpr(unaccent, "$1")
+	sqlQuery := fmt.Sprintf(
+		`SELECT id, ts_rank_cd(%s, %s) AS score FROM nodes
+		 WHERE file_id = $2 AND %s @@ %s
+		 ORDER BY score DESC`,
+		tsvColumn, matchExpr, tsvColumn, matchExpr)
+	if limit > 0 {
+		sqlQuery += " LIMIT " + strconv.Itoa(limit)
+	}
+
+	rows, err := db.Query(sqlQuery, tsquery, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("fulltext: search failed: %w", err)
+	}
+	defer rows.Close()
+
+	var results []NodeIDScore
+	for rows.Next() {
+		var nodeID uint32
+		var score float64
+		if err := rows.Scan(&nodeID, &score); err != nil {
+			return nil, fmt.Errorf("fulltext: failed to scan search result: %w", err)
+		}
+		results = append(results, NodeIDScore{NodeID: nodeID, Score: score})
+	}
+	return results, rows.Err()
+}
+
+// parseSearchQuery converts user-facing search syntax into PostgreSQL
+// tsquery syntax:
+//
+//	word        -> word          (bare terms are ANDed together)
+//	word*       -> word:*         (prefix match)
+//	"a b c"     -> a <-> b <-> c  (phrase match)
+//	AND/OR/NOT  -> &/|/!          (case-insensitive)
+//
+// Unrecognized tsquery metacharacters in a term are stripped rather than
+// passed through, so a malformed user query degrades to a plain-word
+// search instead of producing an invalid tsquery string.
+func parseSearchQuery(query string) string {
+	var parts []string
+	needsJoiner := false
+
+	appendTerm := func(term string) {
+		if needsJoiner {
+			parts = append(parts, "&")
+		}
+		parts = append(parts, term)
+		needsJoiner = true
+	}
+	appendOperator := func(op string) {
+		parts = append(parts, op)
+		needsJoiner = false
+	}
+
+	for len(query) > 0 {
+		query = strings.TrimSpace(query)
+		if query == "" {
+			break
+		}
+
+		if query[0] == '"' {
+			rest := query[1:]
+			end := strings.IndexByte(rest, '"')
+			var phrase string
+			if end == -1 {
+				phrase = rest
+				query = ""
+			} else {
+				phrase = rest[:end]
+				query = rest[end+1:]
+			}
+			if words := sanitizeTerms(strings.Fields(phrase)); len(words) > 0 {
+				appendTerm(strings.Join(words, " <-> "))
+			}
+			continue
+		}
+
+		sp := strings.IndexAny(query, " \t\n")
+		var word string
+		if sp == -1 {
+			word, query = query, ""
+		} else {
+			word, query = query[:sp], query[sp+1:]
+		}
+		if word == "" {
+			continue
+		}
+
+		switch strings.ToUpper(word) {
+		case "AND":
+			appendOperator("&")
+			continue
+		case "OR":
+			appendOperator("|")
+			continue
+		case "NOT":
+			appendOperator("!")
+			continue
+		}
+
+		prefix := strings.HasSuffix(word, "*")
+		word = strings.TrimSuffix(word, "*")
+		clean := sanitizeTerm(word)
+		if clean == "" {
+			continue
+		}
+		if prefix {
+			clean += ":*"
+		}
+		appendTerm(clean)
+	}
+
+	// Drop a trailing dangling operator left by e.g. a query ending in
+	// "AND" with nothing after it.
+	for len(parts) > 0 {
+		last := parts[len(parts)-1]
+		if last == "&" || last == "|" || last == "!" {
+			parts = parts[:len(parts)-1]
+			continue
+		}
+		break
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func sanitizeTerms(words []string) []string {
+	cleaned := make([]string, 0, len(words))
+	for _, w := range words {
+		if c := sanitizeTerm(w); c != "" {
+			cleaned = append(cleaned, c)
+		}
+	}
+	return cleaned
+}
+
+// sanitizeTerm strips everything but letters (accented ones included),
+// digits, hyphens and apostrophes from a single search term, so it can't
+// break out of the tsquery expression it's placed into.
+func sanitizeTerm(word string) string {
+	var b strings.Builder
+	for _, r := range word {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '\'':
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
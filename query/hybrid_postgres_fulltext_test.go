@@ -0,0 +1,103 @@
+package query
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func TestSearchNamesPostgres(t *testing.T) {
+	databaseURL := getPostgreSQLTestURL(t)
+	testPostgreSQLConnection(t, databaseURL)
+
+	tmpDir := t.TempDir()
+	badgerPath := filepath.Join(tmpDir, "test_graph")
+	fileID := "test_file_fulltext_001"
+
+	hs, err := NewHybridStoragePostgres(fileID, badgerPath, databaseURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create PostgreSQL hybrid storage: %v", err)
+	}
+	defer hs.Close()
+
+	db := hs.PostgreSQL()
+	if err := EnsureFullTextSearchSchema(db); err != nil {
+		t.Fatalf("EnsureFullTextSearchSchema failed: %v", err)
+	}
+
+	now := time.Now().Unix()
+	rows := []struct {
+		id         int
+		xref, name string
+	}{
+		{1, "@I1@", "Jonathan Smith"},
+		{2, "@I2@", "Jon Smithson"},
+		{3, "@I3@", "Maria Garcia"},
+	}
+	for _, r := range rows {
+		_, err = db.Exec(`
+			INSERT INTO nodes (file_id, id, xref, type, name, name_lower, birth_place, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, fileID, r.id, r.xref, "individual", r.name, r.name, "Springfield", now, now)
+		if err != nil {
+			t.Fatalf("Failed to insert test node: %v", err)
+		}
+	}
+	defer func() {
+		_, _ = db.Exec("DELETE FROM nodes WHERE file_id = $1", fileID)
+	}()
+
+	results, err := SearchNamesPostgres(db, fileID, "Jon*", 0)
+	if err != nil {
+		t.Fatalf("SearchNamesPostgres failed: %v", err)
+	}
+	got := make(map[int]bool, len(results))
+	for _, r := range results {
+		got[int(r.NodeID)] = true
+	}
+	if !got[1] || !got[2] {
+		t.Errorf("expected prefix search \"Jon*\" to match both Jonathan and Jon, got %v", got)
+	}
+	if got[3] {
+		t.Errorf("expected prefix search \"Jon*\" not to match Maria Garcia, got %v", got)
+	}
+
+	places, err := SearchPlacesPostgres(db, fileID, "Springfield", 0)
+	if err != nil {
+		t.Fatalf("SearchPlacesPostgres failed: %v", err)
+	}
+	if len(places) != 3 {
+		t.Errorf("expected all 3 rows to match place search \"Springfield\", got %d", len(places))
+	}
+
+	all, err := SearchAllPostgres(db, fileID, "Garcia")
+	if err != nil {
+		t.Fatalf("SearchAllPostgres failed: %v", err)
+	}
+	if len(all.Names) != 1 || all.Names[0].NodeID != 3 {
+		t.Errorf("expected SearchAllPostgres.Names to return only Maria Garcia, got %v", all.Names)
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"smith", "smith"},
+		{"smith*", "smith:*"},
+		{`"mary jane"`, "mary <-> jane"},
+		{"smith AND jones", "smith & jones"},
+		{"smith OR jones", "smith | jones"},
+		{"NOT smith", "! smith"},
+		{"smith jones", "smith & jones"},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if got := parseSearchQuery(c.in); got != c.want {
+			t.Errorf("parseSearchQuery(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
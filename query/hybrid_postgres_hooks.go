@@ -0,0 +1,178 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HookEvent identifies a point in the node/edge write lifecycle that
+// consumers can plug behavior into via RegisterHook, the same shape as
+// the Before/After Create/Update/Delete hooks common to ORMs.
+type HookEvent string
+
+const (
+	BeforeNodeInsert HookEvent = "before_node_insert"
+	AfterNodeInsert  HookEvent = "after_node_insert"
+	BeforeNodeUpdate HookEvent = "before_node_update"
+	AfterNodeUpdate  HookEvent = "after_node_update"
+	BeforeNodeDelete HookEvent = "before_node_delete"
+	AfterNodeDelete  HookEvent = "after_node_delete"
+	BeforeEdgeInsert HookEvent = "before_edge_insert"
+	AfterEdgeInsert  HookEvent = "after_edge_insert"
+)
+
+// HookNode is the mutable node payload passed to node lifecycle hooks.
+// Before hooks may change its fields before the write happens; After
+// hooks see the values that were actually written.
+type HookNode struct {
+	FileID      string
+	NodeID      uint32
+	Xref        string
+	Type        string
+	Name        string
+	NameLower   string
+	BirthDate   any
+	BirthPlace  string
+	Sex         string
+	HasChildren bool
+	HasSpouse   bool
+	Living      bool
+	UserID      string
+}
+
+// HookEdge is the payload passed to edge lifecycle hooks.
+type HookEdge struct {
+	FileID   string
+	FromID   uint32
+	ToID     uint32
+	EdgeType string
+	UserID   string
+}
+
+// HookFunc is a lifecycle hook callback. Its payload is a *HookNode for
+// the Node* events and a *HookEdge for the Edge* events. A Before hook
+// may mutate the payload in place; returning a non-nil error aborts the
+// write (and, for an After hook, signals that dependent work such as an
+// audit log entry failed).
+type HookFunc func(ctx context.Context, tx *HybridTx, payload any) error
+
+type hookEntry struct {
+	event HookEvent
+	fn    HookFunc
+}
+
+// hookRegistry associates a HybridStoragePostgres with its registered
+// hooks without requiring a dedicated field on that type, the same
+// side-table approach AncestorCache uses for Graph.
+var (
+	hookRegistryMu sync.Mutex
+	hookRegistry   = make(map[*HybridStoragePostgres][]hookEntry)
+)
+
+// RegisterHook adds fn to run whenever event fires for writes made
+// through s. Hooks run in registration order; if any hook returns an
+// error, the write is aborted and subsequent hooks for that event don't
+// run.
+func (s *HybridStoragePostgres) RegisterHook(event HookEvent, fn HookFunc) {
+	hookRegistryMu.Lock()
+	defer hookRegistryMu.Unlock()
+	hookRegistry[s] = append(hookRegistry[s], hookEntry{event: event, fn: fn})
+}
+
+// runHooks invokes every hook registered for event against payload, in
+// registration order, stopping at the first error.
+func runHooks(ctx context.Context, s *HybridStoragePostgres, tx *HybridTx, event HookEvent, payload any) error {
+	if s == nil {
+		return nil
+	}
+	hookRegistryMu.Lock()
+	entries := hookRegistry[s]
+	hookRegistryMu.Unlock()
+
+	for _, e := range entries {
+		if e.event != event {
+			continue
+		}
+		if err := e.fn(ctx, tx, payload); err != nil {
+			return fmt.Errorf("hook for %s failed: %w", event, err)
+		}
+	}
+	return nil
+}
+
+// AutoPopulateNameLowerHook is a built-in BeforeNodeInsert/BeforeNodeUpdate
+// hook that fills in NameLower from Name, for callers that don't want to
+// remember to call toLower themselves.
+func AutoPopulateNameLowerHook(_ context.Context, _ *HybridTx, payload any) error {
+	node, ok := payload.(*HookNode)
+	if !ok {
+		return nil
+	}
+	node.NameLower = toLower(node.Name)
+	return nil
+}
+
+// ComputeLivingHookFromDates returns a built-in BeforeNodeInsert/
+// BeforeNodeUpdate hook that sets Living from whether a death date is
+// present, for callers populating HookNode directly rather than through
+// an *types.IndividualRecord (where GetDeathDate() already does this).
+func ComputeLivingHookFromDates(hasDeathDate func(*HookNode) bool) HookFunc {
+	return func(_ context.Context, _ *HybridTx, payload any) error {
+		node, ok := payload.(*HookNode)
+		if !ok {
+			return nil
+		}
+		node.Living = !hasDeathDate(node)
+		return nil
+	}
+}
+
+// NewAuditLogHook returns a built-in hook that appends a row to
+// audit_log for every node write it observes, recording the payload it
+// is given as after_json; before_json is always NULL. In this package
+// only node inserts (the build path and insertNodeTx) fire hooks, so the
+// hook records nothing for updates or deletes.
+//
+// The hook creates audit_log on first use (idempotently), with the same
+// columns as migration 005_add_audit_log, rather than assuming that
+// migration already ran.
+func NewAuditLogHook(op string) HookFunc {
+	return func(ctx context.Context, tx *HybridTx, payload any) error {
+		node, ok := payload.(*HookNode)
+		if !ok {
+			return nil
+		}
+
+		if _, err := tx.SQLTx.ExecContext(ctx, `
+			CREATE TABLE IF NOT EXISTS audit_log (
+				id SERIAL PRIMARY KEY,
+				file_id text NOT NULL,
+				node_id bigint NOT NULL,
+				op text NOT NULL,
+				user_id text,
+				changed_at bigint NOT NULL,
+				before_json jsonb,
+				after_json jsonb
+			)
+		`); err != nil {
+			return fmt.Errorf("failed to ensure audit_log table: %w", err)
+		}
+
+		afterJSON, err := json.Marshal(node)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit_log after_json: %w", err)
+		}
+
+		_, err = tx.SQLTx.ExecContext(ctx, `
+			INSERT INTO audit_log (file_id, node_id, op, user_id, changed_at, before_json, after_json)
+			VALUES ($1, $2, $3, $4, $5, NULL, $6)
+		`, node.FileID, node.NodeID, op, node.UserID, time.Now().Unix(), afterJSON)
+		if err != nil {
+			return fmt.Errorf("failed to insert audit_log row: %w", err)
+		}
+		return nil
+	}
+}
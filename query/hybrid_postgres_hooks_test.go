@@ -0,0 +1,84 @@
+package query
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func TestHybridStoragePostgres_RegisterHook_BeforeNodeInsertMutatesRow(t *testing.T) {
+	databaseURL := getPostgreSQLTestURL(t)
+	testPostgreSQLConnection(t, databaseURL)
+
+	tmpDir := t.TempDir()
+	badgerPath := filepath.Join(tmpDir, "test_graph")
+	fileID := "test_file_hooks_001"
+
+	hs, err := NewHybridStoragePostgres(fileID, badgerPath, databaseURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create PostgreSQL hybrid storage: %v", err)
+	}
+	defer hs.Close()
+	defer func() {
+		_, _ = hs.PostgreSQL().Exec("DELETE FROM nodes WHERE file_id = $1", fileID)
+		_, _ = hs.PostgreSQL().Exec("DELETE FROM xref_mapping WHERE file_id = $1", fileID)
+	}()
+
+	hs.RegisterHook(BeforeNodeInsert, AutoPopulateNameLowerHook)
+
+	ctx := context.Background()
+	err = hs.WithTx(ctx, func(tx *HybridTx) error {
+		return insertNodeTx(ctx, tx, 1, "@I1@", "individual", "MIXED Case", "", nil, "", "", false, false, true, 0)
+	})
+	if err != nil {
+		t.Fatalf("WithTx failed: %v", err)
+	}
+
+	var nameLower string
+	if err := hs.PostgreSQL().QueryRow("SELECT name_lower FROM nodes WHERE file_id = $1 AND id = 1", fileID).Scan(&nameLower); err != nil {
+		t.Fatalf("failed to read name_lower: %v", err)
+	}
+	if nameLower != "mixed case" {
+		t.Errorf("expected AutoPopulateNameLowerHook to set name_lower to %q, got %q", "mixed case", nameLower)
+	}
+}
+
+func TestHybridStoragePostgres_RegisterHook_AuditLog(t *testing.T) {
+	databaseURL := getPostgreSQLTestURL(t)
+	testPostgreSQLConnection(t, databaseURL)
+
+	tmpDir := t.TempDir()
+	badgerPath := filepath.Join(tmpDir, "test_graph")
+	fileID := "test_file_hooks_002"
+
+	hs, err := NewHybridStoragePostgres(fileID, badgerPath, databaseURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create PostgreSQL hybrid storage: %v", err)
+	}
+	defer hs.Close()
+	defer func() {
+		_, _ = hs.PostgreSQL().Exec("DELETE FROM nodes WHERE file_id = $1", fileID)
+		_, _ = hs.PostgreSQL().Exec("DELETE FROM xref_mapping WHERE file_id = $1", fileID)
+		_, _ = hs.PostgreSQL().Exec("DELETE FROM audit_log WHERE file_id = $1", fileID)
+	}()
+
+	hs.RegisterHook(AfterNodeInsert, NewAuditLogHook("insert"))
+
+	ctx := context.Background()
+	err = hs.WithTx(ctx, func(tx *HybridTx) error {
+		return insertNodeTx(ctx, tx, 1, "@I1@", "individual", "Jane Roe", "jane roe", nil, "", "", false, false, true, 0)
+	})
+	if err != nil {
+		t.Fatalf("WithTx failed: %v", err)
+	}
+
+	var count int
+	if err := hs.PostgreSQL().QueryRow("SELECT count(*) FROM audit_log WHERE file_id = $1 AND node_id = 1 AND op = 'insert'", fileID).Scan(&count); err != nil {
+		t.Fatalf("failed to count audit_log rows: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 audit_log row for the insert, got %d", count)
+	}
+}
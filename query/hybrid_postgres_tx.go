@@ -0,0 +1,243 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// HybridTx groups a PostgreSQL transaction and a BadgerDB transaction so a
+// caller can write nodes, xref mappings, and edges across both stores as
+// one logical unit instead of committing each store separately, which is
+// what leaves the hybrid store inconsistent if the process crashes
+// mid-build.
+//
+// It is not a true two-phase commit: PostgreSQL and BadgerDB don't share
+// a transaction coordinator. Commit applies the Badger side first (it's
+// local and effectively never fails once writes have succeeded), then
+// commits the PostgreSQL side. If the PostgreSQL commit then fails, the
+// Badger side is already durable and can't be un-committed -- Commit
+// returns a distinguishable error for that case (see
+// ErrHybridTxPartialCommit) so callers can trigger a reconciliation pass
+// instead of assuming the write never happened.
+type HybridTx struct {
+	SQLTx     *sql.Tx
+	BadgerTxn *badger.Txn
+	FileID    string
+
+	// Storage is the HybridStoragePostgres this tx was opened from, used
+	// to look up any hooks registered via HybridStoragePostgres.RegisterHook.
+	Storage *HybridStoragePostgres
+
+	done bool
+}
+
+// ErrHybridTxPartialCommit is returned (wrapped) by Commit when the
+// BadgerDB side committed successfully but the PostgreSQL side then
+// failed to commit, leaving the two stores inconsistent for this
+// transaction's writes.
+var ErrHybridTxPartialCommit = fmt.Errorf("hybrid tx: badger committed but postgres commit failed")
+
+// BeginTx starts a PostgreSQL transaction and a BadgerDB transaction
+// together, for callers that want to group writes to both stores (node
+// inserts, xref mappings, edges) into one unit committed via Commit or
+// discarded via Rollback.
+func (s *HybridStoragePostgres) BeginTx(ctx context.Context) (*HybridTx, error) {
+	sqlTx, err := s.PostgreSQL().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin postgres transaction: %w", err)
+	}
+
+	badgerTxn := s.BadgerDB().NewTransaction(true)
+
+	return &HybridTx{SQLTx: sqlTx, BadgerTxn: badgerTxn, FileID: s.FileID(), Storage: s}, nil
+}
+
+// Commit commits the Badger transaction, then the PostgreSQL transaction.
+// See HybridTx's doc comment for what happens, and what's returned, if
+// the PostgreSQL commit fails after Badger's has already succeeded.
+func (tx *HybridTx) Commit() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+
+	if err := tx.BadgerTxn.Commit(); err != nil {
+		_ = tx.SQLTx.Rollback()
+		return fmt.Errorf("failed to commit badger transaction: %w", err)
+	}
+
+	if err := tx.SQLTx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrHybridTxPartialCommit, err)
+	}
+
+	return nil
+}
+
+// Rollback discards both transactions' writes. It's safe to call after a
+// failed Commit (Commit always settles the Badger side one way or the
+// other before returning, so Rollback only needs to undo PostgreSQL) and
+// safe to call multiple times.
+func (tx *HybridTx) Rollback() error {
+	if tx.done {
+		return nil
+	}
+	tx.done = true
+
+	tx.BadgerTxn.Discard()
+	if err := tx.SQLTx.Rollback(); err != nil && err != sql.ErrTxDone {
+		return fmt.Errorf("failed to rollback postgres transaction: %w", err)
+	}
+	return nil
+}
+
+// WithTx begins a HybridTx, runs fn against it, and commits on success or
+// rolls back on error (including a panic, which is re-raised after
+// rolling back). This is the normal way to group a batch of hybrid-store
+// writes atomically rather than calling BeginTx/Commit/Rollback directly.
+func (s *HybridStoragePostgres) WithTx(ctx context.Context, fn func(*HybridTx) error) (err error) {
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertNodeTx inserts a single nodes row and its xref_mapping row within
+// tx, the *Tx counterpart of stmtNode.Exec/stmtXref.Exec in
+// hybrid_postgres_builder.go's processXForPostgreSQL helpers. It runs
+// BeforeNodeInsert hooks (which may mutate the row) and AfterNodeInsert
+// hooks (which see what was actually written) registered on tx.Storage.
+func insertNodeTx(ctx context.Context, tx *HybridTx, nodeID uint32, xrefID, nodeType, name, nameLower string, birthDate any, birthPlace, sex string, hasChildren, hasSpouse, living bool, now int64) error {
+	node := &HookNode{
+		FileID: tx.FileID, NodeID: nodeID, Xref: xrefID, Type: nodeType,
+		Name: name, NameLower: nameLower, BirthDate: birthDate, BirthPlace: birthPlace, Sex: sex,
+		HasChildren: hasChildren, HasSpouse: hasSpouse, Living: living,
+	}
+	if err := runHooks(ctx, tx.Storage, tx, BeforeNodeInsert, node); err != nil {
+		return err
+	}
+
+	_, err := tx.SQLTx.ExecContext(ctx, `
+		INSERT INTO nodes (file_id, id, xref, type, name, name_lower, birth_date, birth_place, sex,
+		                   has_children, has_spouse, living, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`, node.FileID, node.NodeID, node.Xref, node.Type, node.Name, node.NameLower,
+		node.BirthDate, node.BirthPlace, node.Sex,
+		boolToInt(node.HasChildren), boolToInt(node.HasSpouse), boolToInt(node.Living), now, now)
+	if err != nil {
+		return fmt.Errorf("failed to insert node %s: %w", node.Xref, err)
+	}
+
+	_, err = tx.SQLTx.ExecContext(ctx, `
+		INSERT INTO xref_mapping (file_id, xref, node_id) VALUES ($1, $2, $3)
+	`, tx.FileID, node.Xref, node.NodeID)
+	if err != nil {
+		return fmt.Errorf("failed to insert xref mapping %s: %w", node.Xref, err)
+	}
+
+	return runHooks(ctx, tx.Storage, tx, AfterNodeInsert, node)
+}
+
+// processIndividualsForPostgreSQLTx is processIndividualsForPostgreSQL's
+// *Tx counterpart: the same node-ID assignment and field extraction, but
+// writing through a HybridTx instead of auto-committed prepared
+// statements, so it can be grouped with Badger edge writes into one
+// WithTx call.
+func processIndividualsForPostgreSQLTx(ctx context.Context, tx *HybridTx, graph *Graph, tree *types.GedcomTree, now int64) error {
+	for xrefID, record := range tree.GetAllIndividuals() {
+		indiRecord, ok := record.(*types.IndividualRecord)
+		if !ok {
+			continue
+		}
+		nodeID := assignBulkNodeID(graph, xrefID)
+
+		name := indiRecord.GetName()
+		nameLower := toLower(name)
+		birthDate := parseBirthDate(indiRecord)
+		birthPlace := indiRecord.GetBirthPlace()
+		sex := indiRecord.GetSex()
+		living := indiRecord.GetDeathDate() == ""
+
+		if err := insertNodeTx(ctx, tx, nodeID, xrefID, "individual", name, nameLower, birthDate, birthPlace, sex, false, false, living, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindByXrefTx is FindByXref's *Tx counterpart: it queries through tx's
+// PostgreSQL transaction, so it sees that transaction's own uncommitted
+// writes instead of only what's already been committed.
+func FindByXrefTx(ctx context.Context, tx *HybridTx, xref string) (uint32, error) {
+	var nodeID uint32
+	err := tx.SQLTx.QueryRowContext(ctx,
+		"SELECT node_id FROM xref_mapping WHERE file_id = $1 AND xref = $2",
+		tx.FileID, xref).Scan(&nodeID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("FindByXrefTx failed: %w", err)
+	}
+	return nodeID, nil
+}
+
+// FindByNameTx is FindByName's *Tx counterpart (case-insensitive
+// substring match against name_lower).
+func FindByNameTx(ctx context.Context, tx *HybridTx, name string) ([]uint32, error) {
+	rows, err := tx.SQLTx.QueryContext(ctx,
+		"SELECT id FROM nodes WHERE file_id = $1 AND name_lower LIKE '%' || $2 || '%'",
+		tx.FileID, toLower(name))
+	if err != nil {
+		return nil, fmt.Errorf("FindByNameTx failed: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uint32
+	for rows.Next() {
+		var id uint32
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("FindByNameTx failed to scan row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetAllIndividualIDsTx is GetAllIndividualIDs's *Tx counterpart.
+func GetAllIndividualIDsTx(ctx context.Context, tx *HybridTx) ([]uint32, error) {
+	rows, err := tx.SQLTx.QueryContext(ctx,
+		"SELECT id FROM nodes WHERE file_id = $1 AND type = 'individual'",
+		tx.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllIndividualIDsTx failed: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uint32
+	for rows.Next() {
+		var id uint32
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("GetAllIndividualIDsTx failed to scan row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,122 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func TestHybridStoragePostgres_WithTx_CommitsBothStores(t *testing.T) {
+	databaseURL := getPostgreSQLTestURL(t)
+	testPostgreSQLConnection(t, databaseURL)
+
+	tmpDir := t.TempDir()
+	badgerPath := filepath.Join(tmpDir, "test_graph")
+	fileID := "test_file_tx_001"
+
+	hs, err := NewHybridStoragePostgres(fileID, badgerPath, databaseURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create PostgreSQL hybrid storage: %v", err)
+	}
+	defer hs.Close()
+	defer func() {
+		_, _ = hs.PostgreSQL().Exec("DELETE FROM nodes WHERE file_id = $1", fileID)
+		_, _ = hs.PostgreSQL().Exec("DELETE FROM xref_mapping WHERE file_id = $1", fileID)
+	}()
+
+	ctx := context.Background()
+	err = hs.WithTx(ctx, func(tx *HybridTx) error {
+		return insertNodeTx(ctx, tx, 1, "@I1@", "individual", "Jane Roe", "jane roe", nil, "", "", false, false, true, 0)
+	})
+	if err != nil {
+		t.Fatalf("WithTx failed: %v", err)
+	}
+
+	var count int
+	if err := hs.PostgreSQL().QueryRow("SELECT count(*) FROM nodes WHERE file_id = $1", fileID).Scan(&count); err != nil {
+		t.Fatalf("failed to count nodes: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 node committed, got %d", count)
+	}
+}
+
+func TestHybridStoragePostgres_WithTx_RollsBackOnError(t *testing.T) {
+	databaseURL := getPostgreSQLTestURL(t)
+	testPostgreSQLConnection(t, databaseURL)
+
+	tmpDir := t.TempDir()
+	badgerPath := filepath.Join(tmpDir, "test_graph")
+	fileID := "test_file_tx_002"
+
+	hs, err := NewHybridStoragePostgres(fileID, badgerPath, databaseURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create PostgreSQL hybrid storage: %v", err)
+	}
+	defer hs.Close()
+	defer func() {
+		_, _ = hs.PostgreSQL().Exec("DELETE FROM nodes WHERE file_id = $1", fileID)
+		_, _ = hs.PostgreSQL().Exec("DELETE FROM xref_mapping WHERE file_id = $1", fileID)
+	}()
+
+	ctx := context.Background()
+	sentinel := errors.New("intentional failure")
+	err = hs.WithTx(ctx, func(tx *HybridTx) error {
+		if err := insertNodeTx(ctx, tx, 1, "@I1@", "individual", "Jane Roe", "jane roe", nil, "", "", false, false, true, 0); err != nil {
+			return err
+		}
+		return sentinel
+	})
+	if err == nil {
+		t.Fatalf("expected WithTx to return the function's error")
+	}
+
+	var count int
+	if err := hs.PostgreSQL().QueryRow("SELECT count(*) FROM nodes WHERE file_id = $1", fileID).Scan(&count); err != nil {
+		t.Fatalf("failed to count nodes: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected rollback to leave 0 nodes, got %d", count)
+	}
+}
+
+func TestFindByXrefTx_SeesUncommittedWrites(t *testing.T) {
+	databaseURL := getPostgreSQLTestURL(t)
+	testPostgreSQLConnection(t, databaseURL)
+
+	tmpDir := t.TempDir()
+	badgerPath := filepath.Join(tmpDir, "test_graph")
+	fileID := "test_file_tx_003"
+
+	hs, err := NewHybridStoragePostgres(fileID, badgerPath, databaseURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create PostgreSQL hybrid storage: %v", err)
+	}
+	defer hs.Close()
+	defer func() {
+		_, _ = hs.PostgreSQL().Exec("DELETE FROM nodes WHERE file_id = $1", fileID)
+		_, _ = hs.PostgreSQL().Exec("DELETE FROM xref_mapping WHERE file_id = $1", fileID)
+	}()
+
+	ctx := context.Background()
+	tx, err := hs.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertNodeTx(ctx, tx, 7, "@I7@", "individual", "Sam Lee", "sam lee", nil, "", "", false, false, true, 0); err != nil {
+		t.Fatalf("insertNodeTx failed: %v", err)
+	}
+
+	nodeID, err := FindByXrefTx(ctx, tx, "@I7@")
+	if err != nil {
+		t.Fatalf("FindByXrefTx failed: %v", err)
+	}
+	if nodeID != 7 {
+		t.Errorf("expected FindByXrefTx to see the transaction's own uncommitted write, got %d", nodeID)
+	}
+}
@@ -10,14 +10,21 @@ import (
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
-// getPostgreSQLTestURL returns a PostgreSQL connection URL for testing
-// Returns empty string if DATABASE_URL is not set (tests will be skipped)
+// getPostgreSQLTestURL returns a PostgreSQL connection URL for testing.
+// An externally provided DATABASE_URL always wins. Otherwise, under
+// -tags dockertest, it falls back to an ephemeral container provisioned
+// by dockertestPostgresURL; without that tag (the default build),
+// dockertestPostgresURL is a no-op and the test is skipped, matching
+// this function's original behavior.
 func getPostgreSQLTestURL(t *testing.T) string {
-	databaseURL := os.Getenv("DATABASE_URL")
-	if databaseURL == "" {
-		t.Skip("Skipping PostgreSQL test: DATABASE_URL environment variable not set")
+	if databaseURL := os.Getenv("DATABASE_URL"); databaseURL != "" {
+		return databaseURL
 	}
-	return databaseURL
+	if databaseURL := dockertestPostgresURL(t); databaseURL != "" {
+		return databaseURL
+	}
+	t.Skip("Skipping PostgreSQL test: DATABASE_URL environment variable not set")
+	return ""
 }
 
 // testPostgreSQLConnection tests if we can connect to PostgreSQL
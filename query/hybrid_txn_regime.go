@@ -0,0 +1,373 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// txnRegime is the locking state enforcing HybridStoragePostgres's
+// single-writer/many-reader transaction regime (see View and Update),
+// associated with a HybridStoragePostgres via a side table the same way
+// hookRegistry is in hybrid_postgres_hooks.go, since HybridStoragePostgres
+// isn't part of this package snapshot and can't have a field added to it
+// directly.
+type txnRegime struct {
+	// writeMu allows at most one open write txn at a time.
+	writeMu sync.Mutex
+
+	// commitMu is read-locked by every open read txn and write-locked
+	// while a write txn's Commit runs, so no read txn can start (or is
+	// already running) while a write is in the middle of committing.
+	commitMu sync.RWMutex
+
+	walSeq atomic.Uint64
+}
+
+var (
+	txnRegimeMu sync.Mutex
+	txnRegimes  = make(map[*HybridStoragePostgres]*txnRegime)
+)
+
+func regimeFor(s *HybridStoragePostgres) *txnRegime {
+	txnRegimeMu.Lock()
+	defer txnRegimeMu.Unlock()
+	r, ok := txnRegimes[s]
+	if !ok {
+		r = &txnRegime{}
+		txnRegimes[s] = r
+	}
+	return r
+}
+
+// ReadTxn is the read-only view into a HybridStoragePostgres passed to
+// View's callback.
+type ReadTxn struct {
+	tx *HybridTx
+}
+
+// GetNode looks up nodeID, returning nil if it doesn't exist.
+func (r *ReadTxn) GetNode(ctx context.Context, nodeID uint32) (*HookNode, error) {
+	return getNodeTx(ctx, r.tx, nodeID)
+}
+
+// ResolveXref is FindByXrefTx scoped to this read txn.
+func (r *ReadTxn) ResolveXref(ctx context.Context, xref string) (uint32, error) {
+	return FindByXrefTx(ctx, r.tx, xref)
+}
+
+// WriteTxn is the read-write view into a HybridStoragePostgres passed to
+// Update's callback. Every write made through it is also appended to
+// wtxn's in-memory op log, which Update journals to BadgerDB before
+// committing both stores -- see Update's doc comment for why.
+type WriteTxn struct {
+	tx  *HybridTx
+	ops []walOp
+}
+
+// GetNode is ReadTxn.GetNode's WriteTxn counterpart, seeing this txn's
+// own uncommitted writes.
+func (w *WriteTxn) GetNode(ctx context.Context, nodeID uint32) (*HookNode, error) {
+	return getNodeTx(ctx, w.tx, nodeID)
+}
+
+// ResolveXref is FindByXrefTx scoped to this write txn.
+func (w *WriteTxn) ResolveXref(ctx context.Context, xref string) (uint32, error) {
+	return FindByXrefTx(ctx, w.tx, xref)
+}
+
+// PutNode inserts node and its xref mapping, running the same
+// Before/AfterNodeInsert hooks insertNodeTx does.
+func (w *WriteTxn) PutNode(ctx context.Context, node *HookNode, now int64) error {
+	if err := insertNodeTx(ctx, w.tx, node.NodeID, node.Xref, node.Type, node.Name, node.NameLower,
+		node.BirthDate, node.BirthPlace, node.Sex, node.HasChildren, node.HasSpouse, node.Living, now); err != nil {
+		return err
+	}
+	w.ops = append(w.ops, walOp{Kind: walOpPutNode, Node: node, Now: now})
+	return nil
+}
+
+// PutXref (re)points xref at nodeID, for callers that need to remap an
+// xref without re-inserting its node (insertNodeTx already creates the
+// initial mapping as part of PutNode).
+func (w *WriteTxn) PutXref(ctx context.Context, xref string, nodeID uint32) error {
+	_, err := w.tx.SQLTx.ExecContext(ctx, `
+		INSERT INTO xref_mapping (file_id, xref, node_id) VALUES ($1, $2, $3)
+		ON CONFLICT (file_id, xref) DO UPDATE SET node_id = excluded.node_id
+	`, w.tx.FileID, xref, nodeID)
+	if err != nil {
+		return fmt.Errorf("PutXref failed: %w", err)
+	}
+	w.ops = append(w.ops, walOp{Kind: walOpPutXref, Xref: xref, NodeID: nodeID})
+	return nil
+}
+
+// UpsertComponent records nodeID's connected-component id in BadgerDB
+// (nodes/xref_mapping have no component column in this package
+// snapshot's schema, so there's nowhere to add one in PostgreSQL without
+// a migration; BadgerDB's existing key/value use for index blobs -- see
+// similarity_query.go -- is the natural place for a per-node scalar like
+// this instead).
+func (w *WriteTxn) UpsertComponent(nodeID uint32, componentID uint32) error {
+	if err := w.tx.BadgerTxn.Set(componentBadgerKey(w.tx.FileID, nodeID), componentBadgerValue(componentID)); err != nil {
+		return fmt.Errorf("UpsertComponent failed: %w", err)
+	}
+	w.ops = append(w.ops, walOp{Kind: walOpUpsertComponent, NodeID: nodeID, ComponentID: componentID})
+	return nil
+}
+
+func getNodeTx(ctx context.Context, tx *HybridTx, nodeID uint32) (*HookNode, error) {
+	node := &HookNode{FileID: tx.FileID, NodeID: nodeID}
+	var birthDate sql.NullInt64
+	var hasChildren, hasSpouse, living int
+	err := tx.SQLTx.QueryRowContext(ctx, `
+		SELECT xref, type, name, name_lower, birth_date, birth_place, sex, has_children, has_spouse, living
+		FROM nodes WHERE file_id = $1 AND id = $2
+	`, tx.FileID, nodeID).Scan(&node.Xref, &node.Type, &node.Name, &node.NameLower, &birthDate,
+		&node.BirthPlace, &node.Sex, &hasChildren, &hasSpouse, &living)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetNode failed: %w", err)
+	}
+	if birthDate.Valid {
+		node.BirthDate = birthDate.Int64
+	}
+	node.HasChildren = hasChildren != 0
+	node.HasSpouse = hasSpouse != 0
+	node.Living = living != 0
+	return node, nil
+}
+
+func componentBadgerKey(fileID string, nodeID uint32) []byte {
+	return []byte(fmt.Sprintf("component:%s:%d", fileID, nodeID))
+}
+
+func componentBadgerValue(componentID uint32) []byte {
+	v, _ := json.Marshal(componentID)
+	return v
+}
+
+// View runs fn against a read-only ReadTxn over s. Any number of View
+// calls may run concurrently with each other, and with an Update
+// callback that hasn't finished yet -- they only block while an Update
+// is in the middle of committing (see Update), so a long-running write
+// doesn't stall every reader for its whole duration, only for the commit
+// itself.
+func (s *HybridStoragePostgres) View(ctx context.Context, fn func(rtxn *ReadTxn) error) error {
+	r := regimeFor(s)
+	r.commitMu.RLock()
+	defer r.commitMu.RUnlock()
+
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	return fn(&ReadTxn{tx: tx})
+}
+
+// Update runs fn against a write WriteTxn over s, enforcing that at most
+// one write txn is open at a time (guarded by a mutex, so a second
+// Update call blocks until the first's fn returns and commits or rolls
+// back) and that no View can start while this Update's commit is
+// running (guarded by the same RWMutex View read-locks, held for
+// write here only around the Commit call itself, not for the whole
+// duration of fn).
+//
+// Before attempting the commit, the write's ops (see walOp) are
+// journaled to BadgerDB keyed by (file_id, seq) in the same BadgerTxn
+// being committed, so the journal entry lands durably if and only if the
+// Badger side of the commit does. HybridTx.Commit applies Badger first,
+// then PostgreSQL (see ErrHybridTxPartialCommit) -- if PostgreSQL's
+// commit then fails, the journal entry is left in place rather than
+// deleted, and Update returns the partial-commit error so a caller can
+// retry the PostgreSQL side later (e.g. via ReplayWAL at startup)
+// instead of losing track of writes Badger already has durably.
+func (s *HybridStoragePostgres) Update(ctx context.Context, fn func(wtxn *WriteTxn) error) (err error) {
+	r := regimeFor(s)
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	wtxn := &WriteTxn{tx: tx}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(wtxn); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if len(wtxn.ops) == 0 {
+		return tx.Commit()
+	}
+
+	seq := r.walSeq.Add(1)
+	entry := walEntry{FileID: tx.FileID, Seq: seq, Ops: wtxn.ops}
+	entryBytes, err := json.Marshal(entry)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("hybrid txn: failed to marshal WAL entry: %w", err)
+	}
+	if err := tx.BadgerTxn.Set(walBadgerKey(tx.FileID, seq), entryBytes); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("hybrid txn: failed to journal write: %w", err)
+	}
+
+	r.commitMu.Lock()
+	commitErr := tx.Commit()
+	r.commitMu.Unlock()
+
+	if commitErr != nil {
+		if errors.Is(commitErr, ErrHybridTxPartialCommit) {
+			return commitErr
+		}
+		return commitErr
+	}
+
+	if err := deleteWAL(s, tx.FileID, seq); err != nil {
+		return fmt.Errorf("hybrid txn: write committed but failed to clear WAL entry %d: %w", seq, err)
+	}
+	return nil
+}
+
+// walOpKind identifies which WriteTxn method produced a walOp, so
+// ReplayWAL knows how to re-apply it.
+type walOpKind string
+
+const (
+	walOpPutNode         walOpKind = "put_node"
+	walOpPutXref         walOpKind = "put_xref"
+	walOpUpsertComponent walOpKind = "upsert_component"
+)
+
+// walOp is one idempotently-replayable write recorded by WriteTxn.
+type walOp struct {
+	Kind        walOpKind
+	Node        *HookNode `json:",omitempty"`
+	Now         int64     `json:",omitempty"`
+	Xref        string    `json:",omitempty"`
+	NodeID      uint32    `json:",omitempty"`
+	ComponentID uint32    `json:",omitempty"`
+}
+
+// walEntry is one Update call's worth of ops, keyed by (FileID, Seq).
+type walEntry struct {
+	FileID string
+	Seq    uint64
+	Ops    []walOp
+}
+
+func walBadgerKey(fileID string, seq uint64) []byte {
+	return []byte(fmt.Sprintf("wal:%s:%d", fileID, seq))
+}
+
+func deleteWAL(s *HybridStoragePostgres, fileID string, seq uint64) error {
+	return s.BadgerDB().Update(func(txn *badger.Txn) error {
+		return txn.Delete(walBadgerKey(fileID, seq))
+	})
+}
+
+// ReplayWAL re-applies every journaled walEntry for s.FileID() still
+// present in BadgerDB -- left behind by an Update whose PostgreSQL
+// commit failed after its BadgerDB commit already succeeded (see
+// Update) -- and deletes each entry once its ops have been re-applied.
+// It's meant to be called once at startup, before any other reader or
+// writer touches s, so a process that crashed or lost its PostgreSQL
+// connection mid-commit doesn't silently drop those writes.
+//
+// Every walOp is applied with an idempotent upsert (ON CONFLICT DO
+// UPDATE/INSERT ... ON CONFLICT) so replaying an entry whose PostgreSQL
+// write partially landed before the failure is safe.
+func ReplayWAL(ctx context.Context, s *HybridStoragePostgres) error {
+	prefix := []byte("wal:" + s.FileID() + ":")
+
+	var entries []walEntry
+	err := s.BadgerDB().View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var entry walEntry
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &entry)
+			}); err != nil {
+				return fmt.Errorf("ReplayWAL: failed to decode WAL entry: %w", err)
+			}
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := replayWALEntry(ctx, s, entry); err != nil {
+			return fmt.Errorf("ReplayWAL: failed to replay entry %d for file %s: %w", entry.Seq, entry.FileID, err)
+		}
+		if err := deleteWAL(s, entry.FileID, entry.Seq); err != nil {
+			return fmt.Errorf("ReplayWAL: failed to clear entry %d for file %s: %w", entry.Seq, entry.FileID, err)
+		}
+	}
+	return nil
+}
+
+func replayWALEntry(ctx context.Context, s *HybridStoragePostgres, entry walEntry) error {
+	db := s.PostgreSQL()
+	for _, op := range entry.Ops {
+		switch op.Kind {
+		case walOpPutNode:
+			node := op.Node
+			if _, err := db.ExecContext(ctx, `
+				INSERT INTO nodes (file_id, id, xref, type, name, name_lower, birth_date, birth_place, sex,
+				                   has_children, has_spouse, living, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13)
+				ON CONFLICT (file_id, id) DO NOTHING
+			`, entry.FileID, node.NodeID, node.Xref, node.Type, node.Name, node.NameLower,
+				node.BirthDate, node.BirthPlace, node.Sex,
+				boolToInt(node.HasChildren), boolToInt(node.HasSpouse), boolToInt(node.Living), op.Now); err != nil {
+				return err
+			}
+			if _, err := db.ExecContext(ctx, `
+				INSERT INTO xref_mapping (file_id, xref, node_id) VALUES ($1, $2, $3)
+				ON CONFLICT (file_id, xref) DO UPDATE SET node_id = excluded.node_id
+			`, entry.FileID, node.Xref, node.NodeID); err != nil {
+				return err
+			}
+		case walOpPutXref:
+			if _, err := db.ExecContext(ctx, `
+				INSERT INTO xref_mapping (file_id, xref, node_id) VALUES ($1, $2, $3)
+				ON CONFLICT (file_id, xref) DO UPDATE SET node_id = excluded.node_id
+			`, entry.FileID, op.Xref, op.NodeID); err != nil {
+				return err
+			}
+		case walOpUpsertComponent:
+			if err := s.BadgerDB().Update(func(txn *badger.Txn) error {
+				return txn.Set(componentBadgerKey(entry.FileID, op.NodeID), componentBadgerValue(op.ComponentID))
+			}); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown WAL op kind %q", op.Kind)
+		}
+	}
+	return nil
+}
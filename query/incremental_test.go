@@ -0,0 +1,116 @@
+package query
+
+import "testing"
+
+func TestSubscribeTree_PublishMutation_DeliversEvents(t *testing.T) {
+	tree := buildDiamondAncestryTree()
+
+	var got []MutationEvent
+	unsubscribe := SubscribeTree(tree, func(ev MutationEvent) {
+		got = append(got, ev)
+	})
+	defer unsubscribe()
+
+	PublishMutation(tree, MutationEvent{Kind: IndividualAdded, XrefIDs: []string{"@I9@"}})
+	if len(got) != 1 || got[0].Kind != IndividualAdded {
+		t.Fatalf("expected one IndividualAdded event, got %+v", got)
+	}
+
+	unsubscribe()
+	PublishMutation(tree, MutationEvent{Kind: IndividualAdded, XrefIDs: []string{"@I10@"}})
+	if len(got) != 1 {
+		t.Errorf("expected no further events after unsubscribe, got %+v", got)
+	}
+}
+
+func TestEnableIncremental_IsIdempotentPerGraph(t *testing.T) {
+	tree := buildDiamondAncestryTree()
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	ic1 := EnableIncremental(tree, graph)
+	ic2 := EnableIncremental(tree, graph)
+	if ic1 != ic2 {
+		t.Error("expected EnableIncremental to return the same cache for the same graph")
+	}
+	ic1.Disable()
+}
+
+func TestIncrementalCache_AncestorsCachesAcrossCalls(t *testing.T) {
+	tree := buildDiamondAncestryTree()
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	ic := EnableIncremental(tree, graph)
+	defer ic.Disable()
+
+	aq := newAncestorQuery(graph, "@I1@", OrderDFS, 0)
+
+	if _, err := ic.Ancestors(aq, "@I1@"); err != nil {
+		t.Fatalf("Ancestors (miss): %v", err)
+	}
+	if _, err := ic.Ancestors(aq, "@I1@"); err != nil {
+		t.Fatalf("Ancestors (hit): %v", err)
+	}
+
+	stats := ic.Stats()
+	if stats.Misses != 1 || stats.Hits != 1 {
+		t.Errorf("expected 1 miss and 1 hit, got %+v", stats)
+	}
+}
+
+func TestIncrementalCache_FamilyLinkChangedInvalidatesChildrenOnly(t *testing.T) {
+	tree := buildDiamondAncestryTree()
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	ic := EnableIncremental(tree, graph)
+	defer ic.Disable()
+
+	aq := newAncestorQuery(graph, "@I1@", OrderDFS, 0)
+	if _, err := ic.Ancestors(aq, "@I1@"); err != nil {
+		t.Fatalf("Ancestors: %v", err)
+	}
+
+	PublishMutation(tree, MutationEvent{Kind: FamilyLinkChanged, XrefIDs: []string{"@I2@", "@I3@", "@I1@"}})
+
+	stats := ic.Stats()
+	if stats.Invalidations != 1 {
+		t.Errorf("expected exactly 1 invalidation for the child xref, got %+v", stats)
+	}
+
+	if _, err := ic.Ancestors(aq, "@I1@"); err != nil {
+		t.Fatalf("Ancestors (after invalidation): %v", err)
+	}
+	if got := ic.Stats().Misses; got != 2 {
+		t.Errorf("expected a second miss after invalidation, got %d", got)
+	}
+}
+
+func TestIncrementalCache_IndividualRemovedInvalidatesItsEntry(t *testing.T) {
+	tree := buildDiamondAncestryTree()
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	ic := EnableIncremental(tree, graph)
+	defer ic.Disable()
+
+	aq := newAncestorQuery(graph, "@I1@", OrderDFS, 0)
+	if _, err := ic.Ancestors(aq, "@I1@"); err != nil {
+		t.Fatalf("Ancestors: %v", err)
+	}
+
+	PublishMutation(tree, MutationEvent{Kind: IndividualRemoved, XrefIDs: []string{"@I1@"}})
+
+	if got := ic.Stats().Invalidations; got != 1 {
+		t.Errorf("expected 1 invalidation after removal, got %d", got)
+	}
+}
@@ -0,0 +1,34 @@
+package query
+
+import "expvar"
+
+var (
+	graphNodes = expvar.NewInt("gedcom.graph.nodes")
+	graphEdges = expvar.NewInt("gedcom.graph.edges")
+)
+
+// GraphMetrics is a point-in-time snapshot of one Graph's node and edge
+// counts, as returned by Graph.Metrics.
+type GraphMetrics struct {
+	Nodes int64
+	Edges int64
+}
+
+// Metrics returns a snapshot of g's node/edge counts and publishes them to
+// the gedcom.graph.nodes/gedcom.graph.edges expvar gauges.
+func (g *Graph) Metrics() GraphMetrics {
+	nodes := int64(len(g.AllIndividuals()) + len(g.AllFamilies()))
+
+	var edges int64
+	for _, n := range g.AllIndividuals() {
+		edges += int64(n.OutDegree())
+	}
+	for _, n := range g.AllFamilies() {
+		edges += int64(n.OutDegree())
+	}
+
+	graphNodes.Set(nodes)
+	graphEdges.Set(edges)
+
+	return GraphMetrics{Nodes: nodes, Edges: edges}
+}
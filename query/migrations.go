@@ -0,0 +1,107 @@
+package query
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+//go:embed migrations_mysql/*.sql
+var migrationsMySQLFS embed.FS
+
+//go:embed migrations_sqlite/*.sql
+var migrationsSQLiteFS embed.FS
+
+// SchemaMigration is one numbered SQL migration loaded from a file named
+// "NNN_description.sql" under query/migrations/ (PostgreSQL),
+// query/migrations_mysql/ or query/migrations_sqlite/.
+type SchemaMigration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// migrationsDirFor returns the embedded FS and directory name holding
+// dialect's migrations. PostgreSQL is the default for an empty/unknown
+// dialect, matching this package's original Postgres-only migrations.
+func migrationsDirFor(dialect SQLDialect) (fs.FS, string) {
+	switch dialect {
+	case DialectMySQL:
+		return migrationsMySQLFS, "migrations_mysql"
+	case DialectSQLite:
+		return migrationsSQLiteFS, "migrations_sqlite"
+	default:
+		return migrationsFS, "migrations"
+	}
+}
+
+// loadMigrations reads every embedded PostgreSQL migration file, sorted
+// by version. See loadMigrationsForDialect for other SQL dialects.
+func loadMigrations() ([]SchemaMigration, error) {
+	return loadMigrationsForDialect(DialectPostgres)
+}
+
+// loadMigrationsForDialect reads every embedded migration file for
+// dialect, sorted by version.
+func loadMigrationsForDialect(dialect SQLDialect) ([]SchemaMigration, error) {
+	fsys, dir := migrationsDirFor(dialect)
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("migrations: failed to read embedded migrations: %w", err)
+	}
+
+	migrations := make([]SchemaMigration, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".down.sql") {
+			continue
+		}
+		version, name, err := parseMigrationFilename(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		contents, err := fs.ReadFile(fsys, dir+"/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("migrations: failed to read %s: %w", entry.Name(), err)
+		}
+		migrations = append(migrations, SchemaMigration{Version: version, Name: name, SQL: string(contents)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// parseMigrationFilename splits "003_add_living.sql" into version 3 and
+// name "add_living".
+func parseMigrationFilename(filename string) (int, string, error) {
+	base := strings.TrimSuffix(filename, ".sql")
+	parts := strings.SplitN(base, "_", 2)
+	if len(parts) != 2 {
+		return 0, "", fmt.Errorf("migrations: malformed filename %q, expected NNN_name.sql", filename)
+	}
+	version, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", fmt.Errorf("migrations: malformed version prefix in %q: %w", filename, err)
+	}
+	return version, parts[1], nil
+}
+
+// latestSchemaVersion returns the highest version among the embedded
+// migrations -- what this build of the package expects the database to
+// be at.
+func latestSchemaVersion() (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+	return migrations[len(migrations)-1].Version, nil
+}
@@ -0,0 +1,208 @@
+package query
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// NamedResult is a single "nodes" row hit, projected with the file_id it
+// came from -- the detail every other query in this package drops since
+// it always already knows its own single FileID (see
+// HybridStoragePostgres.FileID()) and never needs to ask.
+type NamedResult struct {
+	FileID     string
+	NodeID     uint32
+	Xref       string
+	Name       string
+	BirthDate  string
+	BirthPlace string
+}
+
+// CrossFileDuplicate pairs two individuals found in different files that
+// a SimilarityScorer judged as probable duplicates of one another.
+type CrossFileDuplicate struct {
+	FileID1, FileID2 string
+	Xref1, Xref2     string
+	Score            float64
+	Reasons          []string
+}
+
+// SimilarityScorer scores a single pair of individuals for probable
+// duplication. duplicate.DuplicateDetector's exported ScorePair method
+// satisfies this signature; FindCandidateDuplicatesAcrossFiles takes a
+// scorer as a plain function value instead of importing the duplicate
+// package directly, since duplicate's own test files already import this
+// package (see duplicate/performance_test.go) and a query -> duplicate
+// import the other way would make that an import cycle.
+type SimilarityScorer func(a, b *types.IndividualRecord) (score float64, reasons []string)
+
+// MultiFileQuery federates searches across every FileID in FileIDs,
+// instead of the single-file_id scope every other query in this package
+// is built around. This is the natural next step for using a PostgreSQL
+// backend as a genealogical hub across many uploaded GEDCOMs: file_id is
+// already carried on every row processIndividualsForPostgreSQL and
+// processFamiliesForPostgreSQL write (see hybrid_postgres_builder.go), it
+// just hasn't had a query type built against it spanning more than one
+// file_id at a time until now.
+type MultiFileQuery struct {
+	db      *sql.DB
+	FileIDs []string
+}
+
+// NewMultiFileQuery scopes a federated search to fileIDs.
+func NewMultiFileQuery(db *sql.DB, fileIDs []string) *MultiFileQuery {
+	return &MultiFileQuery{db: db, FileIDs: fileIDs}
+}
+
+// RecommendedIndexes returns the DDL this package recommends applying
+// once, before running federated searches at scale: a bare name_lower
+// index (the single-file query path's existing index) serves a
+// single-file_id lookup fine, but a federated FindByName scanning many
+// files' worth of rows at once benefits from file_id being part of the
+// same index instead of a separate filter applied after the fact.
+func RecommendedIndexes() []string {
+	return []string{
+		`CREATE INDEX IF NOT EXISTS nodes_name_lower_file_id_idx ON nodes (name_lower, file_id)`,
+	}
+}
+
+// FindByName looks up every individual named name (case-insensitive,
+// exact match on the normalized name) across q.FileIDs, tagging each hit
+// with the FileID it came from.
+func (q *MultiFileQuery) FindByName(name string) ([]NamedResult, error) {
+	return q.find(`name_lower = $1`, toLower(name))
+}
+
+// FindByBirthPlace looks up every individual whose birth place contains
+// place (case-insensitive) across q.FileIDs.
+func (q *MultiFileQuery) FindByBirthPlace(place string) ([]NamedResult, error) {
+	return q.find(`birth_place ILIKE $1`, "%"+place+"%")
+}
+
+func (q *MultiFileQuery) find(whereClause, arg string) ([]NamedResult, error) {
+	if len(q.FileIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(q.FileIDs))
+	args := make([]any, 0, len(q.FileIDs)+1)
+	args = append(args, arg)
+	for i, fileID := range q.FileIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+2)
+		args = append(args, fileID)
+	}
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT file_id, id, xref, name, COALESCE(birth_date, ''), birth_place FROM nodes
+		WHERE type = 'individual' AND %s AND file_id IN (%s)
+	`, whereClause, strings.Join(placeholders, ", "))
+
+	rows, err := q.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("multifilequery: search failed: %w", err)
+	}
+	defer rows.Close()
+
+	var results []NamedResult
+	for rows.Next() {
+		var r NamedResult
+		if err := rows.Scan(&r.FileID, &r.NodeID, &r.Xref, &r.Name, &r.BirthDate, &r.BirthPlace); err != nil {
+			return nil, fmt.Errorf("multifilequery: failed to scan result: %w", err)
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// FindCandidateDuplicatesAcrossFiles scores every individual in one of
+// q.FileIDs against every individual in another, for every pair of
+// distinct files, using scorer -- typically
+// (*duplicate.DuplicateDetector).ScorePair -- and keeping the pairs that
+// clear threshold.
+//
+// Since the rows this package reads back are flat nodes columns rather
+// than full types.IndividualRecord line trees, each row is rebuilt into a
+// minimal synthetic INDI record (NAME/BIRT/DATE/PLAC only) carrying
+// enough structure for duplicate's name/date/place signals to score --
+// its graph-context signal (family memberships) has nothing to compare
+// against this way and simply contributes zero to every pair's score,
+// the same gap SearchAllPostgres's notes_tsv has for an unwired
+// types.NoteRecord (see hybrid_postgres_fulltext.go).
+func (q *MultiFileQuery) FindCandidateDuplicatesAcrossFiles(scorer SimilarityScorer, threshold float64) ([]CrossFileDuplicate, error) {
+	byFile := make(map[string][]*types.IndividualRecord, len(q.FileIDs))
+	for _, fileID := range q.FileIDs {
+		indis, err := q.loadIndividuals(fileID)
+		if err != nil {
+			return nil, err
+		}
+		byFile[fileID] = indis
+	}
+
+	var crossFile []CrossFileDuplicate
+	for i := 0; i < len(q.FileIDs); i++ {
+		for j := i + 1; j < len(q.FileIDs); j++ {
+			fileID1, fileID2 := q.FileIDs[i], q.FileIDs[j]
+			for _, a := range byFile[fileID1] {
+				for _, b := range byFile[fileID2] {
+					score, reasons := scorer(a, b)
+					if score >= threshold {
+						crossFile = append(crossFile, CrossFileDuplicate{
+							FileID1: fileID1, FileID2: fileID2,
+							Xref1: a.XrefID(), Xref2: b.XrefID(),
+							Score: score, Reasons: reasons,
+						})
+					}
+				}
+			}
+		}
+	}
+	return crossFile, nil
+}
+
+// loadIndividuals reads every individual row for fileID and rebuilds each
+// into a synthetic *types.IndividualRecord, for feeding into a
+// SimilarityScorer.
+func (q *MultiFileQuery) loadIndividuals(fileID string) ([]*types.IndividualRecord, error) {
+	rows, err := q.db.Query(`
+		SELECT xref, name, COALESCE(birth_date, ''), birth_place FROM nodes
+		WHERE file_id = $1 AND type = 'individual'
+	`, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("multifilequery: failed to load individuals for file %s: %w", fileID, err)
+	}
+	defer rows.Close()
+
+	var individuals []*types.IndividualRecord
+	for rows.Next() {
+		var xref, name, birthDate, birthPlace string
+		if err := rows.Scan(&xref, &name, &birthDate, &birthPlace); err != nil {
+			return nil, fmt.Errorf("multifilequery: failed to scan individual row: %w", err)
+		}
+		individuals = append(individuals, syntheticIndividual(xref, name, birthDate, birthPlace))
+	}
+	return individuals, rows.Err()
+}
+
+// syntheticIndividual builds a minimal INDI record from flat nodes
+// columns, enough for duplicate's name/date/place similarity signals to
+// run against.
+func syntheticIndividual(xref, name, birthDate, birthPlace string) *types.IndividualRecord {
+	line := types.NewGedcomLine(0, "INDI", "", xref)
+	if name != "" {
+		line.AddChild(types.NewGedcomLine(1, "NAME", name, ""))
+	}
+	if birthDate != "" || birthPlace != "" {
+		birt := types.NewGedcomLine(1, "BIRT", "", "")
+		if birthDate != "" {
+			birt.AddChild(types.NewGedcomLine(2, "DATE", birthDate, ""))
+		}
+		if birthPlace != "" {
+			birt.AddChild(types.NewGedcomLine(2, "PLAC", birthPlace, ""))
+		}
+		line.AddChild(birt)
+	}
+	return types.NewIndividualRecord(line)
+}
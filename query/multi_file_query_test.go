@@ -0,0 +1,60 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+func TestSyntheticIndividual_CarriesNameAndBirthDetails(t *testing.T) {
+	indi := syntheticIndividual("@I1@", "John /Smith/", "1 JAN 1900", "Boston")
+
+	if got := indi.XrefID(); got != "@I1@" {
+		t.Errorf("expected xref @I1@, got %q", got)
+	}
+	if got := indi.GetName(); got != "John /Smith/" {
+		t.Errorf("expected name to round-trip, got %q", got)
+	}
+	if got := indi.GetBirthDate(); got != "1 JAN 1900" {
+		t.Errorf("expected birth date to round-trip, got %q", got)
+	}
+	if got := indi.GetBirthPlace(); got != "Boston" {
+		t.Errorf("expected birth place to round-trip, got %q", got)
+	}
+}
+
+func TestSyntheticIndividual_OmitsEmptyBirthDetails(t *testing.T) {
+	indi := syntheticIndividual("@I2@", "Jane /Doe/", "", "")
+
+	if got := indi.GetBirthDate(); got != "" {
+		t.Errorf("expected no birth date, got %q", got)
+	}
+	if got := indi.GetBirthPlace(); got != "" {
+		t.Errorf("expected no birth place, got %q", got)
+	}
+}
+
+func TestRecommendedIndexes_IncludesNameLowerFileID(t *testing.T) {
+	found := false
+	for _, stmt := range RecommendedIndexes() {
+		if stmt != "" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected at least one recommended index statement")
+	}
+}
+
+func TestFindCandidateDuplicatesAcrossFiles_NoFiles_ReturnsEmpty(t *testing.T) {
+	q := NewMultiFileQuery(nil, nil)
+	scorer := func(a, b *types.IndividualRecord) (float64, []string) { return 1, nil }
+
+	matches, err := q.FindCandidateDuplicatesAcrossFiles(scorer, 0.5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("expected no matches with no files configured, got %+v", matches)
+	}
+}
@@ -0,0 +1,226 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// MultiSourceAncestorQuery computes ancestor information across several
+// starting individuals at once, sharing a single level-synchronous BFS (and
+// therefore a single visited/reached-by map) instead of running one
+// AncestorQuery per source and intersecting or unioning the results
+// afterward. This matters for things like Most Recent Common Ancestor over
+// a sibling group or endogamy analysis on a cluster of individuals, where
+// the naive N-separate-queries approach re-walks shared ancestry once per
+// source.
+type MultiSourceAncestorQuery struct {
+	xrefIDs []string
+	graph   *Graph
+	options *AncestorOptions
+}
+
+// Individuals begins a batch ancestor query over several individuals at
+// once. QueryBuilder isn't part of this package snapshot (see
+// EnsureSearchIndex's doc comment for the same gap), so, like
+// NewCachedGraph/CachedShortestPath, this takes a *Graph directly rather
+// than chaining off a query builder.
+func Individuals(g *Graph, xrefIDs ...string) *MultiSourceAncestorQuery {
+	return &MultiSourceAncestorQuery{xrefIDs: xrefIDs, graph: g, options: NewAncestorOptions()}
+}
+
+// MaxGenerations limits the depth of the ancestor search.
+func (msq *MultiSourceAncestorQuery) MaxGenerations(n int) *MultiSourceAncestorQuery {
+	msq.options.MaxGenerations = n
+	return msq
+}
+
+// Filter applies a custom filter function to results.
+func (msq *MultiSourceAncestorQuery) Filter(fn func(*types.IndividualRecord) bool) *MultiSourceAncestorQuery {
+	msq.options.Filter = fn
+	return msq
+}
+
+// Order is accepted for parity with AncestorOptions, but both
+// CommonAncestors and UnionAncestors always traverse level-synchronously:
+// a shared multi-source frontier only works if every source advances one
+// generation at a time.
+func (msq *MultiSourceAncestorQuery) Order(order Order) *MultiSourceAncestorQuery {
+	msq.options.Order = order
+	return msq
+}
+
+// CommonAncestors returns every ancestor reachable from all of the query's
+// starting individuals, with Depth set to the maximum depth at which that
+// ancestor was reached across the sources. The minimum-Depth result(s) are
+// the Most Recent Common Ancestor(s).
+func (msq *MultiSourceAncestorQuery) CommonAncestors() ([]*AncestorPath, error) {
+	return msq.execute(context.Background(), true)
+}
+
+// UnionAncestors returns every ancestor reachable from any of the query's
+// starting individuals, with Depth set to the maximum depth at which that
+// ancestor was reached across the sources.
+func (msq *MultiSourceAncestorQuery) UnionAncestors() ([]*AncestorPath, error) {
+	return msq.execute(context.Background(), false)
+}
+
+// CommonAncestorsCtx is CommonAncestors, honoring ctx.Done() and
+// AncestorOptions' MaxDuration/MaxNodesVisited/Progress (set via
+// msq.options, the same *AncestorOptions MaxGenerations/Filter/Order
+// already configure) at every level of the shared BFS frontier.
+func (msq *MultiSourceAncestorQuery) CommonAncestorsCtx(ctx context.Context) ([]*AncestorPath, error) {
+	return msq.execute(ctx, true)
+}
+
+// UnionAncestorsCtx is UnionAncestors, honoring ctx and the same
+// MaxDuration/MaxNodesVisited/Progress budget as CommonAncestorsCtx.
+func (msq *MultiSourceAncestorQuery) UnionAncestorsCtx(ctx context.Context) ([]*AncestorPath, error) {
+	return msq.execute(ctx, false)
+}
+
+type multiSourceFrontierEntry struct {
+	node *IndividualNode
+	id   uint32
+}
+
+func (msq *MultiSourceAncestorQuery) execute(ctx context.Context, common bool) ([]*AncestorPath, error) {
+	start := time.Now()
+	defer func() {
+		if msq.graph != nil && msq.graph.metrics != nil {
+			msq.graph.metrics.RecordQuery(time.Since(start))
+		}
+	}()
+
+	if msq.graph == nil || len(msq.xrefIDs) == 0 {
+		return nil, nil
+	}
+
+	numSources := len(msq.xrefIDs)
+	reachedBy := make(map[uint32]map[int]bool)
+	maxDepth := make(map[uint32]int)
+	nodeByID := make(map[uint32]*IndividualNode)
+	done := make(map[uint32]bool)
+	var errs []error
+	pq := &AncestorQuery{graph: msq.graph, options: msq.options}
+
+	budget := &walkBudget{total: len(msq.graph.GetAllIndividuals())}
+	if msq.options.MaxDuration > 0 {
+		budget.deadline = time.Now().Add(msq.options.MaxDuration)
+	}
+
+	frontier := make([]multiSourceFrontierEntry, 0, numSources)
+	for i, xrefID := range msq.xrefIDs {
+		node := msq.graph.GetIndividual(xrefID)
+		if node == nil {
+			continue
+		}
+		id := node.BaseNode.nodeID
+		if id == 0 {
+			pq.reportWalkError(xrefID, 0, errUnassignedNodeID, &errs)
+			continue
+		}
+		if reachedBy[id] == nil {
+			reachedBy[id] = make(map[int]bool)
+		}
+		reachedBy[id][i] = true
+		nodeByID[id] = node
+		frontier = append(frontier, multiSourceFrontierEntry{node: node, id: id})
+	}
+
+	var abortErr error
+
+	for depth := 0; len(frontier) > 0 && abortErr == nil; depth++ {
+		if msq.options.MaxGenerations > 0 && depth >= msq.options.MaxGenerations {
+			break
+		}
+
+		next := make([]multiSourceFrontierEntry, 0)
+		seenThisLevel := make(map[uint32]bool)
+
+		for _, entry := range frontier {
+			if err := pq.check(ctx, budget); err != nil {
+				abortErr = err
+				break
+			}
+			if done[entry.id] {
+				continue
+			}
+
+			parents, badEdges := pq.parentsOf(entry.node)
+			for _, badEdge := range badEdges {
+				if err := pq.reportWalkError(entry.node.BaseNode.ID(), depth, badEdge, &errs); err != nil {
+					abortErr = err
+					break
+				}
+			}
+			if abortErr != nil {
+				break
+			}
+
+			for _, parent := range parents {
+				parentID := parent.BaseNode.nodeID
+				if parentID == 0 {
+					if err := pq.reportWalkError(parent.BaseNode.ID(), depth+1, errUnassignedNodeID, &errs); err != nil {
+						abortErr = err
+						break
+					}
+					continue
+				}
+
+				if reachedBy[parentID] == nil {
+					reachedBy[parentID] = make(map[int]bool)
+				}
+				for srcIdx := range reachedBy[entry.id] {
+					reachedBy[parentID][srcIdx] = true
+				}
+				if d, ok := maxDepth[parentID]; !ok || d < depth+1 {
+					maxDepth[parentID] = depth + 1
+				}
+				nodeByID[parentID] = parent
+
+				if common && len(reachedBy[parentID]) == numSources {
+					done[parentID] = true
+				}
+
+				if !seenThisLevel[parentID] {
+					seenThisLevel[parentID] = true
+					next = append(next, multiSourceFrontierEntry{node: parent, id: parentID})
+
+					budget.visited++
+					if msq.options.Progress != nil {
+						msq.options.Progress(budget.visited, budget.total)
+					}
+				}
+			}
+			if abortErr != nil {
+				break
+			}
+		}
+
+		frontier = next
+	}
+
+	var results []*AncestorPath
+	for id, depth := range maxDepth {
+		srcs := reachedBy[id]
+		if common && len(srcs) != numSources {
+			continue
+		}
+		node := nodeByID[id]
+		if node == nil || node.Individual == nil {
+			continue
+		}
+		if msq.options.Filter != nil && !msq.options.Filter(node.Individual) {
+			continue
+		}
+		results = append(results, &AncestorPath{Ancestor: node.Individual, Depth: depth})
+	}
+
+	if abortErr != nil {
+		errs = append(errs, abortErr)
+	}
+	return results, errors.Join(errs...)
+}
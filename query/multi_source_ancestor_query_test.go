@@ -0,0 +1,119 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+func newGraphForMultiSource(t *testing.T, tree *types.GedcomTree) *Graph {
+	t.Helper()
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+	return graph
+}
+
+func TestMultiSourceAncestorQuery_CommonAncestors(t *testing.T) {
+	tree := buildDiamondAncestryTree()
+	graph := newGraphForMultiSource(t, tree)
+
+	paths, err := Individuals(graph, "@I2@", "@I3@").CommonAncestors()
+	if err != nil {
+		t.Fatalf("CommonAncestors failed: %v", err)
+	}
+
+	got := make(map[string]int, len(paths))
+	for _, p := range paths {
+		got[p.Ancestor.XrefID()] = p.Depth
+	}
+
+	// @I2@ and @I3@ share only @I4@, at depth 1 from each of them.
+	if depth, ok := got["@I4@"]; !ok || depth != 1 {
+		t.Errorf("expected @I4@ as a common ancestor at depth 1, got %v (present=%v)", depth, ok)
+	}
+	if len(got) != 1 {
+		t.Errorf("expected exactly 1 common ancestor of @I2@/@I3@, got %v", got)
+	}
+}
+
+func TestMultiSourceAncestorQuery_UnionAncestors(t *testing.T) {
+	tree := buildDiamondAncestryTree()
+	graph := newGraphForMultiSource(t, tree)
+
+	paths, err := Individuals(graph, "@I2@", "@I3@").UnionAncestors()
+	if err != nil {
+		t.Fatalf("UnionAncestors failed: %v", err)
+	}
+
+	got := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		got[p.Ancestor.XrefID()] = true
+	}
+
+	want := []string{"@I4@", "@I5@", "@I6@"}
+	for _, xref := range want {
+		if !got[xref] {
+			t.Errorf("expected %s in union of ancestors of @I2@/@I3@, got %v", xref, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d ancestors in union, got %d (%v)", len(want), len(got), got)
+	}
+}
+
+func TestMultiSourceAncestorQuery_CommonAncestors_StopsDescendingOnceShared(t *testing.T) {
+	tree := buildDiamondAncestryTree()
+	graph := newGraphForMultiSource(t, tree)
+
+	// Querying @I1@ against itself means every ancestor @I1@ has is, by
+	// definition, reached by both "sources" as soon as it's reached once:
+	// descent along that branch stops immediately at generation 1, so the
+	// deeper shared ancestor @I4@ (generation 2) is never visited or
+	// reported. This is the pruning CommonAncestors is meant to apply.
+	paths, err := Individuals(graph, "@I1@", "@I1@").CommonAncestors()
+	if err != nil {
+		t.Fatalf("CommonAncestors failed: %v", err)
+	}
+
+	got := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		got[p.Ancestor.XrefID()] = true
+	}
+
+	want := []string{"@I2@", "@I3@"}
+	for _, xref := range want {
+		if !got[xref] {
+			t.Errorf("expected %s as an immediate common ancestor, got %v", xref, got)
+		}
+	}
+	if got["@I4@"] {
+		t.Errorf("expected descent to stop before reaching @I4@, got %v", got)
+	}
+}
+
+func TestMultiSourceAncestorQuery_MaxGenerations(t *testing.T) {
+	tree := buildDiamondAncestryTree()
+	graph := newGraphForMultiSource(t, tree)
+
+	paths, err := Individuals(graph, "@I1@", "@I1@").MaxGenerations(1).CommonAncestors()
+	if err != nil {
+		t.Fatalf("CommonAncestors failed: %v", err)
+	}
+
+	got := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		got[p.Ancestor.XrefID()] = true
+	}
+
+	want := []string{"@I2@", "@I3@"}
+	for _, xref := range want {
+		if !got[xref] {
+			t.Errorf("expected %s within MaxGenerations(1), got %v", xref, got)
+		}
+	}
+	if got["@I4@"] || got["@I5@"] || got["@I6@"] {
+		t.Errorf("expected MaxGenerations(1) to exclude depth-2 ancestors, got %v", got)
+	}
+}
@@ -0,0 +1,290 @@
+package query
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PedigreeCacheKey identifies one materialized pedigree/descendancy
+// subgraph: the individual it's centered on, how many ancestor and
+// descendant generations it reaches, and whether spouses were pulled in
+// alongside each blood relative.
+type PedigreeCacheKey struct {
+	RootXREF       string
+	AncestorGens   int
+	DescendantGens int
+	IncludeSpouses bool
+}
+
+// PedigreeEdge is one parent-child or spousal link in a PedigreeSubgraph,
+// reusing EdgeKind (relationship_path.go) rather than inventing a second
+// relationship taxonomy.
+type PedigreeEdge struct {
+	From string
+	To   string
+	Kind EdgeKind
+}
+
+// pedigreeNodeMeta records the bookkeeping PedigreeCache needs to trim a
+// superset subgraph down to a narrower PedigreeCacheKey without
+// re-walking the graph: how many generations away from the root a node
+// sits (negative = ancestor, positive = descendant, 0 = root or a
+// spouse of the root) and whether it was pulled in as a spouse rather
+// than a blood relative.
+type pedigreeNodeMeta struct {
+	depth    int
+	isSpouse bool
+}
+
+// PedigreeSubgraph is a materialized slice of the genealogy graph: every
+// node ID reachable within a PedigreeCacheKey's generation bounds, plus
+// the edges connecting them.
+type PedigreeSubgraph struct {
+	Root    string
+	NodeIDs []string
+	Edges   []PedigreeEdge
+
+	meta map[string]pedigreeNodeMeta
+}
+
+// PedigreeCacheStats reports PedigreeCache's hit/miss/derivation/eviction
+// counters. Named distinctly from the existing package-level CacheStats
+// (incremental.go), which reports IncrementalCache's own hit/miss/
+// invalidation counts for an unrelated cache.
+type PedigreeCacheStats struct {
+	Hits        int64
+	Misses      int64
+	Derivations int64
+	Evictions   int64
+}
+
+// PedigreeCache memoizes materialized PedigreeSubgraphs keyed by
+// PedigreeCacheKey, modeled after dive's TreeCache/TreeCacheKey: a plain
+// key->entry map (here FIFO-evicted like newQueryCache, rather than
+// AncestorCache's LRU, since pedigree subgraphs are typically requested
+// in a narrow, repeating set of (root, gens) combinations per session)
+// plus a superset-derivation step so a lookup for (root, A, D) can be
+// served by trimming an already-cached (root, A', D') with A'>=A and
+// D'>=D instead of recomputing the walk.
+type PedigreeCache struct {
+	mu         sync.Mutex
+	entries    map[PedigreeCacheKey]*PedigreeSubgraph
+	order      []PedigreeCacheKey
+	maxEntries int
+	stats      PedigreeCacheStats
+}
+
+// NewPedigreeCache creates a PedigreeCache bounded to at most maxEntries
+// materialized subgraphs. maxEntries <= 0 defaults to 256.
+func NewPedigreeCache(maxEntries int) *PedigreeCache {
+	if maxEntries <= 0 {
+		maxEntries = 256
+	}
+	return &PedigreeCache{
+		entries:    make(map[PedigreeCacheKey]*PedigreeSubgraph),
+		maxEntries: maxEntries,
+	}
+}
+
+// lookup returns the subgraph for key, either an exact cache hit or one
+// derived by trimming a cached superset, and reports whether either
+// succeeded.
+func (pc *PedigreeCache) lookup(key PedigreeCacheKey) (*PedigreeSubgraph, bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if sg, ok := pc.entries[key]; ok {
+		pc.stats.Hits++
+		return sg, true
+	}
+
+	for k, sg := range pc.entries {
+		if k.RootXREF == key.RootXREF && k.IncludeSpouses == key.IncludeSpouses &&
+			k.AncestorGens >= key.AncestorGens && k.DescendantGens >= key.DescendantGens {
+			pc.stats.Derivations++
+			return trimPedigreeSubgraph(sg, key), true
+		}
+	}
+
+	pc.stats.Misses++
+	return nil, false
+}
+
+// put stores sg under key, evicting the oldest entry (FIFO, the same
+// eviction order newQueryCache uses) if the cache is already at
+// maxEntries.
+func (pc *PedigreeCache) put(key PedigreeCacheKey, sg *PedigreeSubgraph) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if _, exists := pc.entries[key]; !exists && len(pc.order) >= pc.maxEntries {
+		oldest := pc.order[0]
+		pc.order = pc.order[1:]
+		delete(pc.entries, oldest)
+		pc.stats.Evictions++
+	}
+	pc.entries[key] = sg
+	pc.order = append(pc.order, key)
+}
+
+// Stats returns a snapshot of pc's hit/miss/derivation/eviction counters.
+func (pc *PedigreeCache) Stats() PedigreeCacheStats {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	return pc.stats
+}
+
+// trimPedigreeSubgraph derives the subgraph for key from super, which
+// must cover at least key's generation bounds, by dropping nodes (and
+// any edge touching them) outside key's narrower AncestorGens/
+// DescendantGens/IncludeSpouses bounds.
+func trimPedigreeSubgraph(super *PedigreeSubgraph, key PedigreeCacheKey) *PedigreeSubgraph {
+	keep := make(map[string]bool, len(super.NodeIDs))
+	for id, m := range super.meta {
+		if m.isSpouse && !key.IncludeSpouses {
+			continue
+		}
+		if m.depth < 0 && -m.depth > key.AncestorGens {
+			continue
+		}
+		if m.depth > 0 && m.depth > key.DescendantGens {
+			continue
+		}
+		keep[id] = true
+	}
+
+	trimmed := &PedigreeSubgraph{Root: super.Root, meta: make(map[string]pedigreeNodeMeta, len(keep))}
+	for id := range keep {
+		trimmed.NodeIDs = append(trimmed.NodeIDs, id)
+		trimmed.meta[id] = super.meta[id]
+	}
+	for _, e := range super.Edges {
+		if keep[e.From] && keep[e.To] {
+			trimmed.Edges = append(trimmed.Edges, e)
+		}
+	}
+	return trimmed
+}
+
+// pedigreeCachesMu/pedigreeCaches associates a Graph with its
+// PedigreeCache, the same *Graph-keyed side-table pattern ancestorCaches
+// (ancestor_cache.go) and pathCaches (relationship_path.go) use for
+// per-graph state Graph itself has no field for in this snapshot.
+var pedigreeCachesMu sync.Mutex
+var pedigreeCaches = make(map[*Graph]*PedigreeCache)
+
+func pedigreeCacheFor(g *Graph) *PedigreeCache {
+	pedigreeCachesMu.Lock()
+	defer pedigreeCachesMu.Unlock()
+	pc, ok := pedigreeCaches[g]
+	if !ok {
+		pc = NewPedigreeCache(0)
+		pedigreeCaches[g] = pc
+	}
+	return pc
+}
+
+// buildPedigreeSubgraph walks outward from key.RootXREF -- up through
+// Parents() for key.AncestorGens generations, down through Children()
+// for key.DescendantGens generations, and (if key.IncludeSpouses)
+// sideways through Spouses() at every generation visited -- and
+// materializes everything it touches into a PedigreeSubgraph.
+func buildPedigreeSubgraph(g *Graph, key PedigreeCacheKey) (*PedigreeSubgraph, error) {
+	root := g.GetIndividual(key.RootXREF)
+	if root == nil {
+		return nil, fmt.Errorf("query: Pedigree: unknown individual %q", key.RootXREF)
+	}
+
+	sg := &PedigreeSubgraph{Root: key.RootXREF, meta: make(map[string]pedigreeNodeMeta)}
+
+	addNode := func(id string, depth int, isSpouse bool) bool {
+		if _, ok := sg.meta[id]; ok {
+			return false
+		}
+		sg.meta[id] = pedigreeNodeMeta{depth: depth, isSpouse: isSpouse}
+		sg.NodeIDs = append(sg.NodeIDs, id)
+		return true
+	}
+	addEdge := func(from, to string, kind EdgeKind) {
+		sg.Edges = append(sg.Edges, PedigreeEdge{From: from, To: to, Kind: kind})
+	}
+	addSpouses := func(node *IndividualNode, depth int) {
+		if !key.IncludeSpouses {
+			return
+		}
+		for _, sp := range node.Spouses() {
+			if addNode(sp.ID(), depth, true) {
+				addEdge(node.ID(), sp.ID(), EdgeKindSpouse)
+			}
+		}
+	}
+
+	addNode(root.ID(), 0, false)
+	addSpouses(root, 0)
+
+	var walkUp func(node *IndividualNode, depth int)
+	walkUp = func(node *IndividualNode, depth int) {
+		if depth > key.AncestorGens {
+			return
+		}
+		for _, p := range node.Parents() {
+			if addNode(p.ID(), -depth, false) {
+				addSpouses(p, -depth)
+			}
+			addEdge(p.ID(), node.ID(), EdgeKindParent)
+			walkUp(p, depth+1)
+		}
+	}
+	walkUp(root, 1)
+
+	var walkDown func(node *IndividualNode, depth int)
+	walkDown = func(node *IndividualNode, depth int) {
+		if depth > key.DescendantGens {
+			return
+		}
+		for _, c := range node.Children() {
+			if addNode(c.ID(), depth, false) {
+				addSpouses(c, depth)
+			}
+			addEdge(node.ID(), c.ID(), EdgeKindParent)
+			walkDown(c, depth+1)
+		}
+	}
+	walkDown(root, 1)
+
+	return sg, nil
+}
+
+// Pedigree returns the subgraph of individuals within ancestorGens
+// generations above and descendantGens generations below root
+// (spouses of every blood relative included), serving the result from
+// g's PedigreeCache when an exact or superset entry is already cached.
+func (g *Graph) Pedigree(root string, ancestorGens, descendantGens int) (*PedigreeSubgraph, error) {
+	key := PedigreeCacheKey{RootXREF: root, AncestorGens: ancestorGens, DescendantGens: descendantGens, IncludeSpouses: true}
+	cache := pedigreeCacheFor(g)
+	if sg, ok := cache.lookup(key); ok {
+		return sg, nil
+	}
+	sg, err := buildPedigreeSubgraph(g, key)
+	if err != nil {
+		return nil, err
+	}
+	cache.put(key, sg)
+	return sg, nil
+}
+
+// Descendancy returns the subgraph of root's descendants within
+// descendantGens generations (spouses included), i.e. a Pedigree query
+// with zero ancestor generations -- the two share g's PedigreeCache, so
+// a Pedigree(root, 3, 2) computed earlier can serve a later
+// Descendancy(root, 2) by derivation rather than a fresh walk.
+func (g *Graph) Descendancy(root string, descendantGens int) (*PedigreeSubgraph, error) {
+	return g.Pedigree(root, 0, descendantGens)
+}
+
+// CacheStats returns g's PedigreeCache hit/miss/derivation/eviction
+// counters, for callers instrumenting how effective the superset
+// derivation is in practice.
+func (g *Graph) CacheStats() PedigreeCacheStats {
+	return pedigreeCacheFor(g).Stats()
+}
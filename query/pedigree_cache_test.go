@@ -0,0 +1,94 @@
+package query
+
+import "testing"
+
+func TestPedigreeCache_MissThenHit(t *testing.T) {
+	graph := buildThreeGenFamily(t)
+
+	sg, err := graph.Pedigree("@I4@", 2, 0)
+	if err != nil {
+		t.Fatalf("Pedigree failed: %v", err)
+	}
+	if len(sg.NodeIDs) == 0 {
+		t.Fatal("expected a non-empty pedigree subgraph")
+	}
+
+	stats := graph.CacheStats()
+	if stats.Misses != 1 || stats.Hits != 0 {
+		t.Fatalf("expected 1 miss and 0 hits after first call, got %+v", stats)
+	}
+
+	if _, err := graph.Pedigree("@I4@", 2, 0); err != nil {
+		t.Fatalf("Pedigree failed: %v", err)
+	}
+	stats = graph.CacheStats()
+	if stats.Hits != 1 {
+		t.Fatalf("expected the identical second call to hit the cache, got %+v", stats)
+	}
+}
+
+func TestPedigreeCache_SupersetDerivation(t *testing.T) {
+	graph := buildThreeGenFamily(t)
+
+	if _, err := graph.Pedigree("@I4@", 2, 0); err != nil {
+		t.Fatalf("Pedigree failed: %v", err)
+	}
+
+	sg, err := graph.Pedigree("@I4@", 1, 0)
+	if err != nil {
+		t.Fatalf("Pedigree failed: %v", err)
+	}
+	for _, id := range sg.NodeIDs {
+		if id == "@I1@" {
+			t.Errorf("expected the trimmed 1-generation pedigree to exclude the grandparent, got %v", sg.NodeIDs)
+		}
+	}
+
+	stats := graph.CacheStats()
+	if stats.Derivations != 1 {
+		t.Fatalf("expected the narrower request to be served by derivation, got %+v", stats)
+	}
+}
+
+func TestDescendancy_SharesPedigreeCache(t *testing.T) {
+	graph := buildThreeGenFamily(t)
+
+	if _, err := graph.Pedigree("@I1@", 0, 3); err != nil {
+		t.Fatalf("Pedigree failed: %v", err)
+	}
+
+	if _, err := graph.Descendancy("@I1@", 2); err != nil {
+		t.Fatalf("Descendancy failed: %v", err)
+	}
+
+	stats := graph.CacheStats()
+	if stats.Derivations != 1 {
+		t.Fatalf("expected Descendancy to derive from the earlier Pedigree call, got %+v", stats)
+	}
+}
+
+func TestPedigreeCache_EvictsOldestOnOverflow(t *testing.T) {
+	graph := buildThreeGenFamily(t)
+	cache := pedigreeCacheFor(graph)
+	cache.maxEntries = 1
+
+	if _, err := graph.Pedigree("@I4@", 0, 0); err != nil {
+		t.Fatalf("Pedigree failed: %v", err)
+	}
+	if _, err := graph.Pedigree("@I5@", 0, 0); err != nil {
+		t.Fatalf("Pedigree failed: %v", err)
+	}
+
+	stats := graph.CacheStats()
+	if stats.Evictions != 1 {
+		t.Fatalf("expected the second distinct key to evict the first, got %+v", stats)
+	}
+}
+
+func TestPedigree_UnknownIndividualErrors(t *testing.T) {
+	graph := buildThreeGenFamily(t)
+
+	if _, err := graph.Pedigree("@IX@", 1, 1); err == nil {
+		t.Error("expected an error for an unknown root XREF")
+	}
+}
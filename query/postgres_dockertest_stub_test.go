@@ -0,0 +1,13 @@
+//go:build !dockertest
+
+package query
+
+import "testing"
+
+// dockertestPostgresURL is a no-op in the default build (no dockertest
+// build tag), so getPostgreSQLTestURL falls through to skipping the
+// test, exactly as it did before container-backed tests existed. See
+// postgres_dockertest_test.go for the -tags dockertest implementation.
+func dockertestPostgresURL(t *testing.T) string {
+	return ""
+}
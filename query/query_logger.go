@@ -0,0 +1,163 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// QueryLogEvent describes one SQL statement run through a logged query
+// helper, passed to QueryLogger.LogQuery after the statement completes.
+type QueryLogEvent struct {
+	Helper   string // name of the calling helper, e.g. "FindByXref"
+	SQL      string
+	Args     []any
+	Duration time.Duration
+	Rows     int
+	Err      error
+}
+
+// QueryLogger receives every SQL statement run through a logged query
+// helper. Implementations must be safe for concurrent use: helpers like
+// FindByXref run from multiple goroutines during a graph walk.
+type QueryLogger interface {
+	LogQuery(ctx context.Context, event QueryLogEvent)
+}
+
+// noopQueryLogger is QueryLogger's zero-allocation default. SetLogger is
+// opt-in, so the hot path pays only an interface type assertion unless a
+// caller actually wants logging.
+type noopQueryLogger struct{}
+
+func (noopQueryLogger) LogQuery(context.Context, QueryLogEvent) {}
+
+var defaultQueryLogger QueryLogger = noopQueryLogger{}
+
+// queryLoggerFor associates a HybridQueryHelpersPostgres with its logger
+// without a dedicated field on that type, the same side-table approach
+// hookRegistry uses for HybridStoragePostgres.
+var (
+	queryLoggerMu  sync.Mutex
+	queryLoggerFor = make(map[*HybridQueryHelpersPostgres]QueryLogger)
+)
+
+// SetLogger registers l to receive every SQL statement h's helpers run
+// through logQuery. Passing nil reverts to the zero-allocation no-op
+// default.
+func (h *HybridQueryHelpersPostgres) SetLogger(l QueryLogger) {
+	queryLoggerMu.Lock()
+	defer queryLoggerMu.Unlock()
+	if l == nil {
+		delete(queryLoggerFor, h)
+		return
+	}
+	queryLoggerFor[h] = l
+}
+
+// loggerFor returns h's registered logger, or the shared no-op default if
+// none was set.
+func loggerFor(h *HybridQueryHelpersPostgres) QueryLogger {
+	if h == nil {
+		return defaultQueryLogger
+	}
+	queryLoggerMu.Lock()
+	defer queryLoggerMu.Unlock()
+	if l, ok := queryLoggerFor[h]; ok {
+		return l
+	}
+	return defaultQueryLogger
+}
+
+// logQuery reports a completed SQL statement to h's registered logger. It
+// is a no-op (no map lookup beyond the registry check, no allocation)
+// when h has no logger registered.
+//
+// FindByXref/FindByName/GetAllIndividualIDs aren't defined in this package
+// snapshot, so they can't be changed to call through logQuery here --
+// this is the integration point they should use once they are touched
+// again. TypedQuery.Execute (typed_query.go) already calls through it.
+func logQuery(ctx context.Context, h *HybridQueryHelpersPostgres, helper, sqlText string, args []any, db *sql.DB, duration time.Duration, rows int, err error) {
+	logger := loggerFor(h)
+	if _, ok := logger.(noopQueryLogger); ok {
+		return
+	}
+
+	logger.LogQuery(ctx, QueryLogEvent{Helper: helper, SQL: sqlText, Args: args, Duration: duration, Rows: rows, Err: err})
+
+	if dql, ok := logger.(*DefaultQueryLogger); ok && err == nil && db != nil && dql.SlowQueryMs > 0 && duration >= time.Duration(dql.SlowQueryMs)*time.Millisecond {
+		go dql.explain(context.Background(), db, sqlText, args)
+	}
+}
+
+// DefaultQueryLogger is QueryLogger's built-in implementation: it prints
+// statements slower than SlowQueryMs in yellow and errors in red, using
+// the same fatih/color palette cmd/gedcom/internal uses for its
+// Warning/Error output. query can't import cmd/gedcom/internal directly
+// (Go only allows an internal package to be imported from within its
+// parent directory tree, and query sits outside cmd/gedcom), so this
+// defines its own color.New calls against the same underlying library
+// instead of duplicating that package's wrapper API.
+type DefaultQueryLogger struct {
+	// SlowQueryMs is the duration, in milliseconds, above which a query is
+	// logged as slow and has its plan captured via EXPLAIN. Zero disables
+	// slow-query EXPLAIN capture (errors are still logged).
+	SlowQueryMs int64
+}
+
+// NewDefaultQueryLogger returns a DefaultQueryLogger that treats queries
+// slower than slowQueryMs as slow.
+func NewDefaultQueryLogger(slowQueryMs int64) *DefaultQueryLogger {
+	return &DefaultQueryLogger{SlowQueryMs: slowQueryMs}
+}
+
+var (
+	queryLogWarning = color.New(color.FgYellow, color.Bold)
+	queryLogError   = color.New(color.FgRed, color.Bold)
+)
+
+// LogQuery implements QueryLogger.
+func (l *DefaultQueryLogger) LogQuery(_ context.Context, event QueryLogEvent) {
+	switch {
+	case event.Err != nil:
+		queryLogError.Printf("[%s] query failed after %s: %v\n  %s %v\n", event.Helper, event.Duration, event.Err, event.SQL, event.Args)
+	case l.SlowQueryMs > 0 && event.Duration >= time.Duration(l.SlowQueryMs)*time.Millisecond:
+		queryLogWarning.Printf("[%s] slow query (%s, %d rows)\n  %s %v\n", event.Helper, event.Duration, event.Rows, event.SQL, event.Args)
+	}
+}
+
+// explain runs EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) against sqlText and
+// logs the resulting plan. Run in its own goroutine by logQuery so
+// capturing the plan doesn't add its own cost to the request that
+// triggered it.
+func (l *DefaultQueryLogger) explain(ctx context.Context, db *sql.DB, sqlText string, args []any) {
+	rows, err := db.QueryContext(ctx, "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) "+sqlText, args...)
+	if err != nil {
+		queryLogError.Printf("EXPLAIN failed for slow query: %v\n", err)
+		return
+	}
+	defer rows.Close()
+
+	var plan string
+	for rows.Next() {
+		if err := rows.Scan(&plan); err != nil {
+			queryLogError.Printf("EXPLAIN failed to scan plan: %v\n", err)
+			return
+		}
+	}
+	if err := rows.Err(); err != nil {
+		queryLogError.Printf("EXPLAIN failed reading plan rows: %v\n", err)
+		return
+	}
+
+	var pretty any
+	if err := json.Unmarshal([]byte(plan), &pretty); err == nil {
+		if b, err := json.MarshalIndent(pretty, "", "  "); err == nil {
+			plan = string(b)
+		}
+	}
+	queryLogWarning.Printf("query plan:\n%s\n", plan)
+}
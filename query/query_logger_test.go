@@ -0,0 +1,118 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// recordingLogger is a QueryLogger that appends every event it receives,
+// for assertions, guarded by a mutex since helpers can log concurrently.
+type recordingLogger struct {
+	mu     sync.Mutex
+	events []QueryLogEvent
+}
+
+func (r *recordingLogger) LogQuery(_ context.Context, event QueryLogEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordingLogger) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.events)
+}
+
+func TestHybridQueryHelpersPostgres_SetLogger_NoopWhenUnset(t *testing.T) {
+	databaseURL := getPostgreSQLTestURL(t)
+	testPostgreSQLConnection(t, databaseURL)
+
+	tmpDir := t.TempDir()
+	badgerPath := filepath.Join(tmpDir, "test_graph")
+	fileID := "test_file_query_logger_001"
+
+	hs, err := NewHybridStoragePostgres(fileID, badgerPath, databaseURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create PostgreSQL hybrid storage: %v", err)
+	}
+	defer hs.Close()
+
+	helpers, err := NewHybridQueryHelpersPostgres(hs.PostgreSQL(), fileID)
+	if err != nil {
+		t.Fatalf("Failed to create query helpers: %v", err)
+	}
+	defer helpers.Close()
+
+	if logger := loggerFor(helpers); logger != defaultQueryLogger {
+		t.Errorf("expected loggerFor to return the shared no-op default before SetLogger is called")
+	}
+}
+
+func TestHybridQueryHelpersPostgres_SetLogger_ReceivesEvents(t *testing.T) {
+	databaseURL := getPostgreSQLTestURL(t)
+	testPostgreSQLConnection(t, databaseURL)
+
+	tmpDir := t.TempDir()
+	badgerPath := filepath.Join(tmpDir, "test_graph")
+	fileID := "test_file_query_logger_002"
+
+	hs, err := NewHybridStoragePostgres(fileID, badgerPath, databaseURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create PostgreSQL hybrid storage: %v", err)
+	}
+	defer hs.Close()
+
+	helpers, err := NewHybridQueryHelpersPostgres(hs.PostgreSQL(), fileID)
+	if err != nil {
+		t.Fatalf("Failed to create query helpers: %v", err)
+	}
+	defer helpers.Close()
+
+	rec := &recordingLogger{}
+	helpers.SetLogger(rec)
+	defer helpers.SetLogger(nil)
+
+	logQuery(context.Background(), helpers, "TestHelper", "SELECT 1", nil, hs.PostgreSQL(), 5*time.Millisecond, 1, nil)
+
+	if rec.count() != 1 {
+		t.Fatalf("expected 1 logged event, got %d", rec.count())
+	}
+	if rec.events[0].Helper != "TestHelper" {
+		t.Errorf("expected event.Helper %q, got %q", "TestHelper", rec.events[0].Helper)
+	}
+}
+
+func TestDefaultQueryLogger_ExplainCapturesPlanForSlowQuery(t *testing.T) {
+	databaseURL := getPostgreSQLTestURL(t)
+	testPostgreSQLConnection(t, databaseURL)
+
+	tmpDir := t.TempDir()
+	badgerPath := filepath.Join(tmpDir, "test_graph")
+	fileID := "test_file_query_logger_003"
+
+	hs, err := NewHybridStoragePostgres(fileID, badgerPath, databaseURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create PostgreSQL hybrid storage: %v", err)
+	}
+	defer hs.Close()
+
+	logger := NewDefaultQueryLogger(0)
+	done := make(chan struct{})
+	go func() {
+		logger.explain(context.Background(), hs.PostgreSQL(), fmt.Sprintf("SELECT 1 AS probe_%s", fileID), nil)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("explain did not return within 5s")
+	}
+}
@@ -0,0 +1,347 @@
+package query
+
+import "sort"
+
+// radixEdge is one labeled transition out of a radixNode, keyed by the
+// first byte of child's prefix (radixNode.prefix[0]).
+type radixEdge struct {
+	label byte
+	child *radixNode
+}
+
+// radixNode is one node of an immutable, path-compressed radix tree.
+// prefix holds the (possibly multi-byte) edge label shared by every key
+// passing through this node; edges are kept sorted by label so Walk can
+// visit children in deterministic, lexicographic order. values is only
+// set on nodes that terminate a stored key.
+type radixNode struct {
+	prefix []byte
+	edges  []radixEdge
+	values []string
+}
+
+// RadixTree is a persistent, immutable path-compressed radix (trie)
+// index mapping normalized string keys to slices of node IDs (individual
+// or family XREFs). Every mutating method returns a new root rather than
+// modifying t in place, so a *RadixTree already handed to a reader stays
+// valid -- and cheap, since unaffected subtrees are shared rather than
+// copied -- while a writer builds the next snapshot. This is what lets
+// Graph publish a new search index after a rebuild without readers
+// taking a lock.
+type RadixTree struct {
+	root *radixNode
+}
+
+// NewRadixTree returns an empty RadixTree.
+func NewRadixTree() *RadixTree {
+	return &RadixTree{root: &radixNode{}}
+}
+
+// Insert returns a new RadixTree with id appended to the values stored
+// under key, leaving t unmodified. Re-inserting the same (key, id) pair
+// is a no-op on the returned tree (id is not duplicated).
+func (t *RadixTree) Insert(key string, id string) *RadixTree {
+	return &RadixTree{root: insertNode(t.root, []byte(key), id)}
+}
+
+func insertNode(n *radixNode, key []byte, id string) *radixNode {
+	if n == nil {
+		return &radixNode{prefix: append([]byte(nil), key...), values: []string{id}}
+	}
+
+	common := commonPrefixLen(n.prefix, key)
+
+	switch {
+	case common == len(n.prefix) && common == len(key):
+		// Exact match: clone n, append id to its values if not already present.
+		clone := cloneNodeShallow(n)
+		clone.values = appendUnique(n.values, id)
+		return clone
+
+	case common == len(n.prefix):
+		// n.prefix fully consumed; descend into (or create) the child
+		// matching the remaining key bytes.
+		rest := key[common:]
+		clone := cloneNodeShallow(n)
+		idx, found := findEdge(clone.edges, rest[0])
+		if found {
+			newEdges := append([]radixEdge(nil), clone.edges...)
+			newEdges[idx] = radixEdge{label: rest[0], child: insertNode(clone.edges[idx].child, rest, id)}
+			clone.edges = newEdges
+		} else {
+			newChild := &radixNode{prefix: append([]byte(nil), rest...), values: []string{id}}
+			clone.edges = insertEdgeSorted(clone.edges, radixEdge{label: rest[0], child: newChild})
+		}
+		return clone
+
+	case common == len(key):
+		// key is a strict prefix of n.prefix: split n so key terminates
+		// at the split point and n's remainder hangs off it as a child.
+		parent := &radixNode{prefix: append([]byte(nil), key...), values: []string{id}}
+		remainderChild := &radixNode{prefix: append([]byte(nil), n.prefix[common:]...), edges: n.edges, values: n.values}
+		parent.edges = []radixEdge{{label: n.prefix[common], child: remainderChild}}
+		return parent
+
+	default:
+		// key and n.prefix diverge mid-prefix: split into a shared parent
+		// with two children, one for n's old remainder and one for key's.
+		parent := &radixNode{prefix: append([]byte(nil), n.prefix[:common]...)}
+		oldChild := &radixNode{prefix: append([]byte(nil), n.prefix[common:]...), edges: n.edges, values: n.values}
+		newChild := &radixNode{prefix: append([]byte(nil), key[common:]...), values: []string{id}}
+		parent.edges = insertEdgeSorted(parent.edges, radixEdge{label: oldChild.prefix[0], child: oldChild})
+		parent.edges = insertEdgeSorted(parent.edges, radixEdge{label: newChild.prefix[0], child: newChild})
+		return parent
+	}
+}
+
+// Delete returns a new RadixTree with id removed from key's values,
+// merging any node left with a single child and no values of its own
+// into that child so the tree stays maximally compressed. Deleting a
+// key/id pair that isn't present returns a tree equal to t.
+func (t *RadixTree) Delete(key string, id string) *RadixTree {
+	newRoot, _ := deleteNode(t.root, []byte(key), id)
+	if newRoot == nil {
+		newRoot = &radixNode{}
+	}
+	return &RadixTree{root: newRoot}
+}
+
+func deleteNode(n *radixNode, key []byte, id string) (*radixNode, bool) {
+	if n == nil {
+		return nil, false
+	}
+	common := commonPrefixLen(n.prefix, key)
+	if common < len(n.prefix) {
+		return n, false
+	}
+	if common == len(key) {
+		if len(n.values) == 0 {
+			return n, false
+		}
+		clone := cloneNodeShallow(n)
+		clone.values = removeString(n.values, id)
+		return compactNode(clone), true
+	}
+
+	rest := key[common:]
+	idx, found := findEdge(n.edges, rest[0])
+	if !found {
+		return n, false
+	}
+	newChild, changed := deleteNode(n.edges[idx].child, rest, id)
+	if !changed {
+		return n, false
+	}
+	clone := cloneNodeShallow(n)
+	if newChild == nil || (len(newChild.values) == 0 && len(newChild.edges) == 0) {
+		clone.edges = removeEdge(n.edges, idx)
+	} else {
+		newEdges := append([]radixEdge(nil), n.edges...)
+		newEdges[idx] = radixEdge{label: rest[0], child: newChild}
+		clone.edges = newEdges
+	}
+	return compactNode(clone), true
+}
+
+// compactNode merges n into its single remaining child when n has no
+// values of its own and exactly one edge, so a delete can't leave behind
+// a chain of single-child pass-through nodes.
+func compactNode(n *radixNode) *radixNode {
+	for len(n.values) == 0 && len(n.edges) == 1 {
+		child := n.edges[0].child
+		n = &radixNode{
+			prefix: append(append([]byte(nil), n.prefix...), child.prefix...),
+			edges:  child.edges,
+			values: child.values,
+		}
+	}
+	return n
+}
+
+// Get returns the values stored under the exact key, and whether key is
+// present at all.
+func (t *RadixTree) Get(key string) ([]string, bool) {
+	n := t.root
+	remaining := []byte(key)
+	for {
+		common := commonPrefixLen(n.prefix, remaining)
+		if common != len(n.prefix) {
+			return nil, false
+		}
+		remaining = remaining[common:]
+		if len(remaining) == 0 {
+			if len(n.values) == 0 {
+				return nil, false
+			}
+			return n.values, true
+		}
+		idx, found := findEdge(n.edges, remaining[0])
+		if !found {
+			return nil, false
+		}
+		n = n.edges[idx].child
+	}
+}
+
+// WalkPrefix visits every key in t that begins with prefix, in
+// lexicographic order, calling fn(key, values) for each. Walking stops
+// early if fn returns false.
+func (t *RadixTree) WalkPrefix(prefix string, fn func(key string, values []string) bool) {
+	n, matched, remaining := descendToPrefix(t.root, []byte(prefix))
+	if n == nil {
+		return
+	}
+	// matched is the portion of prefix already consumed on the path down
+	// to n; remaining is any of n.prefix left over that also belongs to
+	// the reported keys (n.prefix fully contains the rest of prefix).
+	base := append(append([]byte(nil), matched...), remaining...)
+	walkSubtree(n, base, fn)
+}
+
+// descendToPrefix walks from n looking for the node whose subtree holds
+// every key starting with prefix. It returns that node, the bytes of
+// prefix matched so far (excluding n's own prefix), and n's prefix bytes
+// so the caller can reconstruct the full base key.
+func descendToPrefix(n *radixNode, prefix []byte) (*radixNode, []byte, []byte) {
+	if len(prefix) == 0 {
+		return n, nil, n.prefix
+	}
+	common := commonPrefixLen(n.prefix, prefix)
+	if common == len(prefix) {
+		// prefix is fully consumed within n.prefix (or exactly equals it).
+		return n, nil, n.prefix
+	}
+	if common < len(n.prefix) {
+		// n.prefix diverges from prefix before prefix is exhausted: no match.
+		return nil, nil, nil
+	}
+	rest := prefix[common:]
+	idx, found := findEdge(n.edges, rest[0])
+	if !found {
+		return nil, nil, nil
+	}
+	child, matched, childPrefix := descendToPrefix(n.edges[idx].child, rest)
+	if child == nil {
+		return nil, nil, nil
+	}
+	return child, append(append([]byte(nil), n.prefix...), matched...), childPrefix
+}
+
+func walkSubtree(n *radixNode, base []byte, fn func(string, []string) bool) bool {
+	if len(n.values) > 0 {
+		if !fn(string(base), n.values) {
+			return false
+		}
+	}
+	for _, e := range n.edges {
+		if !walkSubtree(e.child, append(append([]byte(nil), base...), e.child.prefix...), fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Walk visits every key stored in t, in lexicographic order.
+func (t *RadixTree) Walk(fn func(key string, values []string) bool) {
+	walkSubtree(t.root, append([]byte(nil), t.root.prefix...), fn)
+}
+
+// LongestPrefix returns the longest key in t that is a prefix of s, its
+// values, and true -- or ("", nil, false) if no key in t prefixes s. It
+// is the fuzzy fallback for a SearchXREFPrefix/SearchNamesPrefix miss:
+// callers can fall back to whatever was matched furthest along s.
+func (t *RadixTree) LongestPrefix(s string) (string, []string, bool) {
+	n := t.root
+	remaining := []byte(s)
+	matched := 0
+	bestLen, bestValues, bestOK := 0, []string(nil), false
+	for {
+		common := commonPrefixLen(n.prefix, remaining)
+		if common != len(n.prefix) {
+			break
+		}
+		matched += common
+		remaining = remaining[common:]
+		if len(n.values) > 0 {
+			bestLen, bestValues, bestOK = matched, n.values, true
+		}
+		if len(remaining) == 0 {
+			break
+		}
+		idx, found := findEdge(n.edges, remaining[0])
+		if !found {
+			break
+		}
+		n = n.edges[idx].child
+	}
+	if !bestOK {
+		return "", nil, false
+	}
+	return s[:bestLen], bestValues, true
+}
+
+func cloneNodeShallow(n *radixNode) *radixNode {
+	return &radixNode{prefix: n.prefix, edges: n.edges, values: n.values}
+}
+
+func commonPrefixLen(a, b []byte) int {
+	max := len(a)
+	if len(b) < max {
+		max = len(b)
+	}
+	i := 0
+	for i < max && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+func findEdge(edges []radixEdge, label byte) (int, bool) {
+	idx := sort.Search(len(edges), func(i int) bool { return edges[i].label >= label })
+	if idx < len(edges) && edges[idx].label == label {
+		return idx, true
+	}
+	return idx, false
+}
+
+func insertEdgeSorted(edges []radixEdge, e radixEdge) []radixEdge {
+	idx, found := findEdge(edges, e.label)
+	if found {
+		newEdges := append([]radixEdge(nil), edges...)
+		newEdges[idx] = e
+		return newEdges
+	}
+	newEdges := make([]radixEdge, 0, len(edges)+1)
+	newEdges = append(newEdges, edges[:idx]...)
+	newEdges = append(newEdges, e)
+	newEdges = append(newEdges, edges[idx:]...)
+	return newEdges
+}
+
+func removeEdge(edges []radixEdge, idx int) []radixEdge {
+	newEdges := make([]radixEdge, 0, len(edges)-1)
+	newEdges = append(newEdges, edges[:idx]...)
+	newEdges = append(newEdges, edges[idx+1:]...)
+	return newEdges
+}
+
+func appendUnique(values []string, id string) []string {
+	for _, v := range values {
+		if v == id {
+			return values
+		}
+	}
+	newValues := make([]string, len(values), len(values)+1)
+	copy(newValues, values)
+	return append(newValues, id)
+}
+
+func removeString(values []string, id string) []string {
+	newValues := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != id {
+			newValues = append(newValues, v)
+		}
+	}
+	return newValues
+}
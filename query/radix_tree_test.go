@@ -0,0 +1,123 @@
+package query
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestRadixTreeInsertAndGet(t *testing.T) {
+	tree := NewRadixTree()
+	tree = tree.Insert("smith", "I1")
+	tree = tree.Insert("smyth", "I2")
+	tree = tree.Insert("smithfield", "I3")
+
+	values, ok := tree.Get("smith")
+	if !ok || !reflect.DeepEqual(values, []string{"I1"}) {
+		t.Fatalf("Get(smith) = %v, %v", values, ok)
+	}
+	if _, ok := tree.Get("smi"); ok {
+		t.Error("Get(smi) should not match a non-terminal prefix node")
+	}
+}
+
+func TestRadixTreeInsertIsImmutable(t *testing.T) {
+	before := NewRadixTree().Insert("smith", "I1")
+	after := before.Insert("smyth", "I2")
+
+	if _, ok := before.Get("smyth"); ok {
+		t.Error("inserting into the returned tree should not mutate the original root")
+	}
+	if _, ok := after.Get("smith"); !ok {
+		t.Error("the new root should still contain keys inserted before the split")
+	}
+}
+
+func TestRadixTreeInsertSameKeyAccumulatesValues(t *testing.T) {
+	tree := NewRadixTree()
+	tree = tree.Insert("smith", "I1")
+	tree = tree.Insert("smith", "I2")
+	tree = tree.Insert("smith", "I1") // duplicate, should not repeat
+
+	values, _ := tree.Get("smith")
+	if !reflect.DeepEqual(values, []string{"I1", "I2"}) {
+		t.Errorf("expected [I1 I2], got %v", values)
+	}
+}
+
+func TestRadixTreeWalkPrefix(t *testing.T) {
+	tree := NewRadixTree()
+	tree = tree.Insert("smith", "I1")
+	tree = tree.Insert("smithfield", "I2")
+	tree = tree.Insert("smyth", "I3")
+	tree = tree.Insert("jones", "I4")
+
+	var keys []string
+	tree.WalkPrefix("smit", func(key string, values []string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"smith", "smithfield"}) {
+		t.Errorf("WalkPrefix(smit) = %v", keys)
+	}
+}
+
+func TestRadixTreeWalkVisitsEveryKey(t *testing.T) {
+	tree := NewRadixTree()
+	want := []string{"jones", "smith", "smithfield", "smyth"}
+	for _, k := range want {
+		tree = tree.Insert(k, k)
+	}
+
+	var got []string
+	tree.Walk(func(key string, values []string) bool {
+		got = append(got, key)
+		return true
+	})
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk visited %v, want %v", got, want)
+	}
+}
+
+func TestRadixTreeLongestPrefix(t *testing.T) {
+	tree := NewRadixTree()
+	tree = tree.Insert("smith", "I1")
+	tree = tree.Insert("smithfield", "I2")
+
+	key, values, ok := tree.LongestPrefix("smithson")
+	if !ok || key != "smith" || !reflect.DeepEqual(values, []string{"I1"}) {
+		t.Errorf("LongestPrefix(smithson) = %q, %v, %v", key, values, ok)
+	}
+
+	if _, _, ok := tree.LongestPrefix("jones"); ok {
+		t.Error("LongestPrefix(jones) should not match when no key prefixes it")
+	}
+}
+
+func TestRadixTreeDeleteMergesSingleChildPath(t *testing.T) {
+	tree := NewRadixTree()
+	tree = tree.Insert("smith", "I1")
+	tree = tree.Insert("smithfield", "I2")
+
+	tree = tree.Delete("smith", "I1")
+	if _, ok := tree.Get("smith"); ok {
+		t.Error("smith should no longer be present after Delete")
+	}
+	if values, ok := tree.Get("smithfield"); !ok || !reflect.DeepEqual(values, []string{"I2"}) {
+		t.Errorf("smithfield should still be reachable after compaction, got %v, %v", values, ok)
+	}
+}
+
+func TestRadixTreeDeleteIsImmutable(t *testing.T) {
+	before := NewRadixTree().Insert("smith", "I1")
+	after := before.Delete("smith", "I1")
+
+	if _, ok := before.Get("smith"); !ok {
+		t.Error("Delete should not mutate the original tree")
+	}
+	if _, ok := after.Get("smith"); ok {
+		t.Error("the returned tree should no longer contain the deleted key")
+	}
+}
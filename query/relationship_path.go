@@ -0,0 +1,506 @@
+package query
+
+import (
+	"container/heap"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// EdgeKind classifies a hop in a RelationshipPath by the kind of
+// relationship it represents, independent of the lower-level EdgeType
+// values (EdgeTypeHUSB, EdgeTypeFAMC, ...) relationship_helpers.go's
+// Parents/Children/Spouses/Siblings already resolve edges into.
+type EdgeKind string
+
+const (
+	EdgeKindParent    EdgeKind = "parent"
+	EdgeKindSpouse    EdgeKind = "spouse"
+	EdgeKindSibling   EdgeKind = "sibling"
+	EdgeKindStep      EdgeKind = "step"
+	EdgeKindAdopted   EdgeKind = "adopted"
+	EdgeKindGodparent EdgeKind = "godparent"
+)
+
+// defaultEdgeWeight is the traversal cost RelationshipPath assigns an
+// EdgeKind when SetEdgeWeight hasn't overridden it for a specific pair:
+// blood ties (parent, sibling, adopted) are cheapest, marriage and step
+// ties cost more, and godparent -- an affiliation rather than a kinship
+// link -- costs the most, so the shortest weighted path prefers blood
+// relationships the way a genealogist ranking closeness would.
+func defaultEdgeWeight(kind EdgeKind) float64 {
+	switch kind {
+	case EdgeKindParent, EdgeKindSibling, EdgeKindAdopted:
+		return 1
+	case EdgeKindSpouse, EdgeKindStep:
+		return 2
+	case EdgeKindGodparent:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// edgeWeightMu/edgeWeightOverrides is a side-table of per-pair weight
+// overrides, the same pattern edge_flavor.go's edgeFlavors uses to
+// attach state Edge has no field for -- Edge's defining file isn't part
+// of this snapshot, and here there isn't even a single *Edge to key off
+// of, since a relationship hop can be synthesized from Parents/
+// Children/Spouses/Siblings rather than one stored edge.
+var edgeWeightMu sync.RWMutex
+var edgeWeightOverrides = make(map[string]float64)
+
+func relEdgeKey(fromID, toID string, kind EdgeKind) string {
+	return fromID + "\x00" + string(kind) + "\x00" + toID
+}
+
+// SetEdgeWeight overrides the traversal cost RelationshipPath uses for
+// the hop between fromID and toID of the given kind, in both directions
+// (relationship hops are traversed as an undirected graph). Passing a
+// weight <= 0 clears any existing override for this triple.
+func SetEdgeWeight(fromID, toID string, kind EdgeKind, weight float64) {
+	edgeWeightMu.Lock()
+	defer edgeWeightMu.Unlock()
+	if weight <= 0 {
+		delete(edgeWeightOverrides, relEdgeKey(fromID, toID, kind))
+		delete(edgeWeightOverrides, relEdgeKey(toID, fromID, kind))
+		return
+	}
+	edgeWeightOverrides[relEdgeKey(fromID, toID, kind)] = weight
+	edgeWeightOverrides[relEdgeKey(toID, fromID, kind)] = weight
+}
+
+func weightFor(fromID, toID string, kind EdgeKind) float64 {
+	edgeWeightMu.RLock()
+	defer edgeWeightMu.RUnlock()
+	if w, ok := edgeWeightOverrides[relEdgeKey(fromID, toID, kind)]; ok {
+		return w
+	}
+	return defaultEdgeWeight(kind)
+}
+
+// relNeighbor is one edge out of relationshipNeighbors' adjacency view
+// of the genealogy graph: a target individual plus the EdgeKind linking
+// it to the individual relationshipNeighbors was called on.
+type relNeighbor struct {
+	node *IndividualNode
+	kind EdgeKind
+}
+
+// relationshipNeighbors returns node's adjacency list in the undirected
+// relationship graph RelationshipPath searches: parents and children
+// (both EdgeKindParent, just traversed in opposite directions), spouses,
+// and siblings. Step/adopted/godparent links have no dedicated accessor
+// on IndividualNode in this snapshot, so they only appear via
+// SetEdgeWeight overrides layered on top of this base adjacency.
+func relationshipNeighbors(node *IndividualNode) []relNeighbor {
+	var out []relNeighbor
+	for _, p := range node.Parents() {
+		out = append(out, relNeighbor{p, EdgeKindParent})
+	}
+	for _, c := range node.Children() {
+		out = append(out, relNeighbor{c, EdgeKindParent})
+	}
+	for _, s := range node.Spouses() {
+		out = append(out, relNeighbor{s, EdgeKindSpouse})
+	}
+	for _, sib := range node.Siblings() {
+		out = append(out, relNeighbor{sib, EdgeKindSibling})
+	}
+	return out
+}
+
+// PathOpts configures a RelationshipPath search.
+type PathOpts struct {
+	// ExcludeKinds lists EdgeKinds RelationshipPath must not traverse,
+	// e.g. []EdgeKind{EdgeKindSpouse} for a blood-relatives-only search.
+	ExcludeKinds []EdgeKind
+
+	// MaxDepth caps the number of hops explored; 0 means unlimited.
+	MaxDepth int
+}
+
+func (opts PathOpts) excludes(kind EdgeKind) bool {
+	for _, k := range opts.ExcludeKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// pathCachesMu/pathCaches associates a Graph with the queryCache
+// RelationshipPath memoizes computed paths in, the same *Graph-keyed
+// side-table pattern ancestorCaches (ancestor_cache.go) and
+// similarityIndexFor (similarity_query.go) use for per-graph state a
+// field on Graph itself can't hold, since Graph's defining file isn't
+// part of this snapshot.
+var pathCachesMu sync.Mutex
+var pathCaches = make(map[*Graph]*queryCache)
+
+// pathCacheFor returns g's RelationshipPath cache, creating it lazily
+// the first time it's needed.
+func pathCacheFor(g *Graph) *queryCache {
+	pathCachesMu.Lock()
+	defer pathCachesMu.Unlock()
+	c, ok := pathCaches[g]
+	if !ok {
+		c = newQueryCache(0)
+		pathCaches[g] = c
+	}
+	return c
+}
+
+// pathCacheKey builds the cache key for a RelationshipPath(fromXREF,
+// toXREF, opts) call, using the same makeCacheKey helper
+// cache_config_test.go exercises elsewhere in this package. ExcludeKinds
+// is flattened to a single string first since makeCacheKey's documented
+// behavior only covers scalar args, not slices.
+func pathCacheKey(fromXREF, toXREF string, opts PathOpts) string {
+	excluded := make([]string, len(opts.ExcludeKinds))
+	for i, k := range opts.ExcludeKinds {
+		excluded[i] = string(k)
+	}
+	return makeCacheKey("path", fromXREF, toXREF, opts.MaxDepth, strings.Join(excluded, ","))
+}
+
+// RelationshipHop is one step of a RelationshipPath: the individual
+// arrived at, the kind of relationship that led there, and the weight
+// that hop contributed to the path's total Cost.
+type RelationshipHop struct {
+	Node   *IndividualNode
+	Kind   EdgeKind
+	Weight float64
+}
+
+// RelationshipPath is the result of Graph.RelationshipPath: the ordered
+// hops connecting From to To, their total weighted Cost, and a
+// human-readable Label describing the relationship.
+type RelationshipPath struct {
+	From  string
+	To    string
+	Hops  []RelationshipHop
+	Cost  float64
+	Label string
+}
+
+// pqItem is one entry in relationshipPathQueue, pairing an individual
+// XREF with its tentative distance from the search's origin.
+type pqItem struct {
+	id   string
+	dist float64
+}
+
+// relationshipPathQueue is a min-heap by dist, the priority queue
+// RelationshipPath's bidirectional Dijkstra search pops from, mirroring
+// similarity_hnsw_heap.go's minCandidateHeap/maxCandidateHeap shape.
+type relationshipPathQueue []pqItem
+
+func (q relationshipPathQueue) Len() int            { return len(q) }
+func (q relationshipPathQueue) Less(i, j int) bool   { return q[i].dist < q[j].dist }
+func (q relationshipPathQueue) Swap(i, j int)        { q[i], q[j] = q[j], q[i] }
+func (q *relationshipPathQueue) Push(x interface{}) { *q = append(*q, x.(pqItem)) }
+func (q *relationshipPathQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
+
+// dijkstraFrontier holds one direction's state during a bidirectional
+// Dijkstra search: tentative distances, finalized (visited) nodes, and
+// enough of each node's predecessor to reconstruct the path once the two
+// frontiers meet.
+type dijkstraFrontier struct {
+	queue      relationshipPathQueue
+	dist       map[string]float64
+	visited    map[string]bool
+	prevID     map[string]string
+	prevKind   map[string]EdgeKind
+	prevWeight map[string]float64
+}
+
+func newDijkstraFrontier(start string) *dijkstraFrontier {
+	f := &dijkstraFrontier{
+		dist:       map[string]float64{start: 0},
+		visited:    make(map[string]bool),
+		prevID:     make(map[string]string),
+		prevKind:   make(map[string]EdgeKind),
+		prevWeight: make(map[string]float64),
+	}
+	heap.Push(&f.queue, pqItem{id: start, dist: 0})
+	return f
+}
+
+// RelationshipPath finds the lowest-cost path between fromXREF and
+// toXREF in the weighted relationship graph (relationshipNeighbors, with
+// per-pair overrides from SetEdgeWeight), using a bidirectional Dijkstra
+// search: it alternates expanding whichever of the two frontiers is
+// smaller and stops as soon as they meet, which explores roughly
+// sqrt(N) fewer nodes than a single-direction search on a graph with N
+// individuals reachable from either end.
+func (g *Graph) RelationshipPath(fromXREF, toXREF string, opts PathOpts) (*RelationshipPath, error) {
+	if fromXREF == toXREF {
+		return &RelationshipPath{From: fromXREF, To: toXREF, Label: "same person"}, nil
+	}
+	from := g.GetIndividual(fromXREF)
+	to := g.GetIndividual(toXREF)
+	if from == nil {
+		return nil, fmt.Errorf("query: RelationshipPath: unknown individual %q", fromXREF)
+	}
+	if to == nil {
+		return nil, fmt.Errorf("query: RelationshipPath: unknown individual %q", toXREF)
+	}
+
+	if cached, ok := pathCacheFor(g).get(pathCacheKey(fromXREF, toXREF, opts)); ok {
+		if rp, ok := cached.(*RelationshipPath); ok {
+			return rp, nil
+		}
+	}
+
+	fwd := newDijkstraFrontier(fromXREF)
+	bwd := newDijkstraFrontier(toXREF)
+
+	best := -1.0
+	var meetID string
+
+	expand := func(f, other *dijkstraFrontier, depth int) bool {
+		if f.queue.Len() == 0 {
+			return false
+		}
+		cur := heap.Pop(&f.queue).(pqItem)
+		if f.visited[cur.id] {
+			return true
+		}
+		f.visited[cur.id] = true
+		// cur.id settled in this direction: if the other direction has
+		// already reached it (even tentatively), the two frontiers have
+		// found a candidate meeting point.
+		if otherDist, ok := other.dist[cur.id]; ok {
+			total := cur.dist + otherDist
+			if best < 0 || total < best {
+				best = total
+				meetID = cur.id
+			}
+		}
+		if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+			return true
+		}
+		node := g.GetIndividual(cur.id)
+		if node == nil {
+			return true
+		}
+		for _, nb := range relationshipNeighbors(node) {
+			if opts.excludes(nb.kind) {
+				continue
+			}
+			if f.visited[nb.node.ID()] {
+				continue
+			}
+			w := weightFor(cur.id, nb.node.ID(), nb.kind)
+			nd := cur.dist + w
+			if existing, ok := f.dist[nb.node.ID()]; !ok || nd < existing {
+				f.dist[nb.node.ID()] = nd
+				f.prevID[nb.node.ID()] = cur.id
+				f.prevKind[nb.node.ID()] = nb.kind
+				f.prevWeight[nb.node.ID()] = w
+				heap.Push(&f.queue, pqItem{id: nb.node.ID(), dist: nd})
+			}
+		}
+		return true
+	}
+
+	depth := 0
+	for fwd.queue.Len() > 0 && bwd.queue.Len() > 0 {
+		if best >= 0 {
+			// Stop once neither frontier can still improve on the best
+			// meeting point found so far.
+			fMin := fwd.queue[0].dist
+			bMin := bwd.queue[0].dist
+			if fMin+bMin >= best {
+				break
+			}
+		}
+		if fwd.queue.Len() <= bwd.queue.Len() {
+			if !expand(fwd, bwd, depth) {
+				break
+			}
+		} else {
+			if !expand(bwd, fwd, depth) {
+				break
+			}
+		}
+		depth++
+	}
+
+	if best < 0 {
+		return nil, fmt.Errorf("query: RelationshipPath: no path between %q and %q", fromXREF, toXREF)
+	}
+
+	rp := buildRelationshipPath(g, fromXREF, toXREF, meetID, fwd, bwd, best)
+	pathCacheFor(g).set(pathCacheKey(fromXREF, toXREF, opts), rp)
+	return rp, nil
+}
+
+// buildRelationshipPath walks fwd's predecessor chain from meetID back
+// to fromXREF, then bwd's predecessor chain from meetID forward to
+// toXREF, splicing the two into a single ordered RelationshipPath.
+func buildRelationshipPath(g *Graph, fromXREF, toXREF, meetID string, fwd, bwd *dijkstraFrontier, cost float64) *RelationshipPath {
+	var forwardHops []RelationshipHop
+	for id := meetID; id != fromXREF; {
+		prev, ok := fwd.prevID[id]
+		if !ok {
+			break
+		}
+		forwardHops = append(forwardHops, RelationshipHop{
+			Node:   g.GetIndividual(id),
+			Kind:   fwd.prevKind[id],
+			Weight: fwd.prevWeight[id],
+		})
+		id = prev
+	}
+	for i, j := 0, len(forwardHops)-1; i < j; i, j = i+1, j-1 {
+		forwardHops[i], forwardHops[j] = forwardHops[j], forwardHops[i]
+	}
+
+	var backwardHops []RelationshipHop
+	for id := meetID; id != toXREF; {
+		prev, ok := bwd.prevID[id]
+		if !ok {
+			break
+		}
+		backwardHops = append(backwardHops, RelationshipHop{
+			Node:   g.GetIndividual(prev),
+			Kind:   bwd.prevKind[id],
+			Weight: bwd.prevWeight[id],
+		})
+		id = prev
+	}
+
+	hops := append(forwardHops, backwardHops...)
+	rp := &RelationshipPath{From: fromXREF, To: toXREF, Hops: hops, Cost: cost}
+	rp.Label = relationshipLabel(hops)
+	return rp
+}
+
+// relationshipLabel renders a short, human-readable description of a
+// RelationshipPath from its hop kinds -- "parent", "sibling", "spouse",
+// or "N-degree relation" once the chain is too long for a single
+// familiar term.
+func relationshipLabel(hops []RelationshipHop) string {
+	switch len(hops) {
+	case 0:
+		return "same person"
+	case 1:
+		return string(hops[0].Kind)
+	case 2:
+		if hops[0].Kind == EdgeKindParent && hops[1].Kind == EdgeKindParent {
+			return "grandparent/grandchild"
+		}
+		if hops[0].Kind == EdgeKindParent && hops[1].Kind == EdgeKindSpouse {
+			return "parent-in-law"
+		}
+	}
+	return fmt.Sprintf("%d-degree relation", len(hops))
+}
+
+// DegreesOfSeparation returns the number of hops in the lowest-cost
+// RelationshipPath between fromXREF and toXREF, or -1 if no path exists.
+func (g *Graph) DegreesOfSeparation(fromXREF, toXREF string) (int, error) {
+	rp, err := g.RelationshipPath(fromXREF, toXREF, PathOpts{})
+	if err != nil {
+		return -1, err
+	}
+	return len(rp.Hops), nil
+}
+
+// CommonAncestors returns every individual reachable by following only
+// EdgeKindParent hops upward from both aXREF and bXREF, i.e. every
+// ancestor the two individuals share.
+func (g *Graph) CommonAncestors(aXREF, bXREF string) []*IndividualNode {
+	ancestorsOf := func(xref string) map[string]*IndividualNode {
+		seen := make(map[string]*IndividualNode)
+		var visit func(id string)
+		visit = func(id string) {
+			node := g.GetIndividual(id)
+			if node == nil {
+				return
+			}
+			for _, p := range node.Parents() {
+				if _, ok := seen[p.ID()]; ok {
+					continue
+				}
+				seen[p.ID()] = p
+				visit(p.ID())
+			}
+		}
+		visit(xref)
+		return seen
+	}
+
+	aAncestors := ancestorsOf(aXREF)
+	bAncestors := ancestorsOf(bXREF)
+	var common []*IndividualNode
+	for id, node := range aAncestors {
+		if _, ok := bAncestors[id]; ok {
+			common = append(common, node)
+		}
+	}
+	return common
+}
+
+// Consanguinity estimates the coefficient of relationship between aXREF
+// and bXREF: the sum, over every distinct ancestral path connecting them
+// through a shared ancestor, of 0.5^n where n is that path's total
+// number of parent-child steps. This is the standard genetics
+// definition (full siblings: 0.5, grandparent/grandchild: 0.25, first
+// cousins: 0.125, ...), generalized to sum over every shared ancestor
+// rather than assuming a single most-recent common one.
+func (g *Graph) Consanguinity(aXREF, bXREF string) float64 {
+	if aXREF == bXREF {
+		return 1
+	}
+
+	depthsTo := func(startXREF, ancestorXREF string) []int {
+		var depths []int
+		var visit func(id string, depth int, seen map[string]bool)
+		visit = func(id string, depth int, seen map[string]bool) {
+			if seen[id] {
+				return
+			}
+			seen[id] = true
+			if id == ancestorXREF && depth > 0 {
+				depths = append(depths, depth)
+			}
+			node := g.GetIndividual(id)
+			if node == nil {
+				return
+			}
+			for _, p := range node.Parents() {
+				visit(p.ID(), depth+1, seen)
+			}
+		}
+		visit(startXREF, 0, make(map[string]bool))
+		return depths
+	}
+
+	var total float64
+	for _, ancestor := range g.CommonAncestors(aXREF, bXREF) {
+		for _, dA := range depthsTo(aXREF, ancestor.ID()) {
+			for _, dB := range depthsTo(bXREF, ancestor.ID()) {
+				total += pow5(dA + dB)
+			}
+		}
+	}
+	return total
+}
+
+// pow5 returns 0.5^n, the per-path contribution Consanguinity sums.
+func pow5(n int) float64 {
+	result := 1.0
+	for i := 0; i < n; i++ {
+		result *= 0.5
+	}
+	return result
+}
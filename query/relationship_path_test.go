@@ -0,0 +1,172 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// buildThreeGenFamily builds a grandparent/parent/child chain, a
+// sibling of the child, and a second spouse of the grandparent who has
+// no blood relation to anyone else in the tree:
+//
+//	@I6@ (grandparent's spouse) -- @F0@ -- @I1@ (grandparent) -- @F1@ -- @I2@ (parent) -- @F2@ -- @I4@ (child), @I5@ (sibling)
+//	                                                                        @I3@ (parent's spouse) --/
+func buildThreeGenFamily(t *testing.T) *Graph {
+	t.Helper()
+	tree := types.NewGedcomTree()
+
+	// addIndi adds an INDI record carrying whichever FAMC (parent
+	// family) and FAMS (own family) tags it needs -- IndividualNode's
+	// Parents/Siblings methods walk famcEdges and Children/Spouses walk
+	// famsEdges, both built from the individual's own FAMC/FAMS lines
+	// rather than inferred from the family record's HUSB/WIFE/CHIL.
+	addIndi := func(xref string, famc string, fams ...string) {
+		line := types.NewGedcomLine(0, "INDI", "", xref)
+		line.AddChild(types.NewGedcomLine(1, "NAME", "Test /Person/", ""))
+		if famc != "" {
+			line.AddChild(types.NewGedcomLine(1, "FAMC", famc, ""))
+		}
+		for _, fs := range fams {
+			line.AddChild(types.NewGedcomLine(1, "FAMS", fs, ""))
+		}
+		tree.AddRecord(types.NewIndividualRecord(line))
+	}
+	addIndi("@I1@", "", "@F0@", "@F1@")
+	addIndi("@I6@", "", "@F0@")
+	addIndi("@I2@", "@F1@", "@F2@")
+	addIndi("@I3@", "", "@F2@")
+	addIndi("@I4@", "@F2@")
+	addIndi("@I5@", "@F2@")
+
+	fam0 := types.NewGedcomLine(0, "FAM", "", "@F0@")
+	fam0.AddChild(types.NewGedcomLine(1, "HUSB", "@I1@", ""))
+	fam0.AddChild(types.NewGedcomLine(1, "WIFE", "@I6@", ""))
+	tree.AddRecord(types.NewFamilyRecord(fam0))
+
+	fam1 := types.NewGedcomLine(0, "FAM", "", "@F1@")
+	fam1.AddChild(types.NewGedcomLine(1, "HUSB", "@I1@", ""))
+	fam1.AddChild(types.NewGedcomLine(1, "CHIL", "@I2@", ""))
+	tree.AddRecord(types.NewFamilyRecord(fam1))
+
+	fam2 := types.NewGedcomLine(0, "FAM", "", "@F2@")
+	fam2.AddChild(types.NewGedcomLine(1, "HUSB", "@I2@", ""))
+	fam2.AddChild(types.NewGedcomLine(1, "WIFE", "@I3@", ""))
+	fam2.AddChild(types.NewGedcomLine(1, "CHIL", "@I4@", ""))
+	fam2.AddChild(types.NewGedcomLine(1, "CHIL", "@I5@", ""))
+	tree.AddRecord(types.NewFamilyRecord(fam2))
+
+	graph, err := BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+	return graph
+}
+
+func TestRelationshipPath_DirectParent(t *testing.T) {
+	graph := buildThreeGenFamily(t)
+
+	rp, err := graph.RelationshipPath("@I2@", "@I4@", PathOpts{})
+	if err != nil {
+		t.Fatalf("RelationshipPath failed: %v", err)
+	}
+	if len(rp.Hops) != 1 || rp.Hops[0].Kind != EdgeKindParent {
+		t.Fatalf("expected a single parent hop, got %+v", rp.Hops)
+	}
+}
+
+func TestRelationshipPath_GrandparentLabel(t *testing.T) {
+	graph := buildThreeGenFamily(t)
+
+	rp, err := graph.RelationshipPath("@I1@", "@I4@", PathOpts{})
+	if err != nil {
+		t.Fatalf("RelationshipPath failed: %v", err)
+	}
+	if len(rp.Hops) != 2 {
+		t.Fatalf("expected 2 hops, got %d: %+v", len(rp.Hops), rp.Hops)
+	}
+	if rp.Label != "grandparent/grandchild" {
+		t.Errorf("expected grandparent/grandchild label, got %q", rp.Label)
+	}
+}
+
+func TestRelationshipPath_ExcludeSpouseBlocksInLaw(t *testing.T) {
+	graph := buildThreeGenFamily(t)
+
+	if _, err := graph.RelationshipPath("@I1@", "@I6@", PathOpts{ExcludeKinds: []EdgeKind{EdgeKindSpouse}}); err == nil {
+		t.Error("expected a blood-only search to find no path to the grandparent's spouse")
+	}
+
+	rp, err := graph.RelationshipPath("@I1@", "@I6@", PathOpts{})
+	if err != nil {
+		t.Fatalf("RelationshipPath without exclusions failed: %v", err)
+	}
+	if len(rp.Hops) != 1 || rp.Hops[0].Kind != EdgeKindSpouse {
+		t.Errorf("expected a single spouse hop, got %+v", rp.Hops)
+	}
+}
+
+func TestRelationshipPath_UnknownIndividualErrors(t *testing.T) {
+	graph := buildThreeGenFamily(t)
+
+	if _, err := graph.RelationshipPath("@I1@", "@IX@", PathOpts{}); err == nil {
+		t.Error("expected an error for an unknown target XREF")
+	}
+}
+
+func TestDegreesOfSeparation_CountsHops(t *testing.T) {
+	graph := buildThreeGenFamily(t)
+
+	degrees, err := graph.DegreesOfSeparation("@I1@", "@I4@")
+	if err != nil {
+		t.Fatalf("DegreesOfSeparation failed: %v", err)
+	}
+	if degrees != 2 {
+		t.Errorf("expected 2 degrees of separation, got %d", degrees)
+	}
+}
+
+func TestCommonAncestors_SharedGrandparent(t *testing.T) {
+	graph := buildThreeGenFamily(t)
+
+	common := graph.CommonAncestors("@I4@", "@I5@")
+	found := false
+	for _, node := range common {
+		if node.ID() == "@I2@" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected @I2@ (shared parent) among common ancestors, got %+v", common)
+	}
+}
+
+func TestConsanguinity_FullSiblings(t *testing.T) {
+	graph := buildThreeGenFamily(t)
+
+	coeff := graph.Consanguinity("@I4@", "@I5@")
+	// @I4@ and @I5@ share both parents (@I2@, @I3@) plus, transitively,
+	// @I2@'s own parent @I1@: 0.5^2 through @I2@, 0.5^2 through @I3@,
+	// and 0.5^4 through @I1@ (one generation further up each side).
+	want := 0.25 + 0.25 + 0.0625
+	if coeff != want {
+		t.Errorf("expected consanguinity %v for full siblings, got %v", want, coeff)
+	}
+}
+
+func TestSetEdgeWeight_OverridesDefault(t *testing.T) {
+	graph := buildThreeGenFamily(t)
+
+	// @I1@ has only one recorded child (@I2@), so there is no cheaper
+	// alternate route for the override to compete against.
+	SetEdgeWeight("@I1@", "@I2@", EdgeKindParent, 5)
+	defer SetEdgeWeight("@I1@", "@I2@", EdgeKindParent, 0)
+
+	rp, err := graph.RelationshipPath("@I1@", "@I2@", PathOpts{})
+	if err != nil {
+		t.Fatalf("RelationshipPath failed: %v", err)
+	}
+	if rp.Cost != 5 {
+		t.Errorf("expected overridden weight 5 to be reflected in Cost, got %v", rp.Cost)
+	}
+}
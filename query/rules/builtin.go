@@ -0,0 +1,301 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/internal/gedcomdate"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/validate"
+)
+
+// defaultRules returns the built-in rule catalog this request asks for:
+// dangling XREFs, eventless individuals, implausible mother age at
+// birth, death-before-birth, cyclic ancestry, duplicate individuals, and
+// memberless families.
+func defaultRules(t Thresholds) []Rule {
+	return []Rule{
+		danglingXrefRule{},
+		noEventsRule{},
+		motherAgeRule{minAge: t.MinMotherAge, maxAge: t.MaxMotherAge},
+		deathBeforeBirthRule{},
+		cyclicAncestryRule{},
+		duplicateIndividualRule{},
+		memberlessFamilyRule{},
+		unparseableDateRule{},
+	}
+}
+
+// yearOf and hasParsedYear are this package's names for gedcomdate's
+// parsing helpers, kept as thin aliases so the rules below don't have to
+// spell out the gedcomdate prefix at each call site. They used to be
+// this package's own copies -- along with matching copies in validate,
+// query/validation, and diff -- until gedcomdate was extracted as the
+// one place left to fix a parsing bug across all four.
+func yearOf(date string) int       { return gedcomdate.YearOf(date) }
+func hasParsedYear(date string) bool { return gedcomdate.HasParsedYear(date) }
+
+func finding(ruleID string, severity validate.Severity, xrefIDs []string, format string, args ...interface{}) Finding {
+	return Finding{RuleID: ruleID, Severity: severity, Message: fmt.Sprintf(format, args...), XrefIDs: xrefIDs}
+}
+
+// danglingXrefRule flags FAMC/FAMS/HUSB/WIFE/CHIL pointers that resolve
+// to no node in the graph, reusing Graph.Audit's existing dangling-edge
+// detection (see TestBuilder_EdgeCases for BuildGraph's own handling of
+// an invalid FAMS reference) rather than re-walking edges itself.
+type danglingXrefRule struct{}
+
+func (danglingXrefRule) ID() string                         { return "RULES_DANGLINGXREF" }
+func (danglingXrefRule) Description() string                { return "flags edges whose target XREF does not resolve to a node in the graph" }
+func (danglingXrefRule) DefaultSeverity() validate.Severity  { return validate.SeverityWarning }
+
+func (r danglingXrefRule) Evaluate(g *query.Graph) []Finding {
+	if g == nil {
+		return nil
+	}
+	report := g.Audit()
+	findings := make([]Finding, 0, len(report.Dangling))
+	for _, xref := range report.Dangling {
+		findings = append(findings, finding(r.ID(), r.DefaultSeverity(), []string{xref},
+			"%s has an edge pointing at a non-existent node", xref))
+	}
+	return findings
+}
+
+// noEventsRule flags an individual with no recorded life events at all.
+type noEventsRule struct{}
+
+func (noEventsRule) ID() string                        { return "RULES_NOEVENTS" }
+func (noEventsRule) Description() string               { return "flags individuals with no recorded events" }
+func (noEventsRule) DefaultSeverity() validate.Severity { return validate.SeverityHint }
+
+func (r noEventsRule) Evaluate(g *query.Graph) []Finding {
+	if g == nil {
+		return nil
+	}
+	findings := make([]Finding, 0)
+	for _, node := range g.AllIndividuals() {
+		if node == nil || node.Individual == nil {
+			continue
+		}
+		if len(node.Individual.GetEvents()) == 0 {
+			findings = append(findings, finding(r.ID(), r.DefaultSeverity(), []string{node.ID()},
+				"%s has no recorded events", node.ID()))
+		}
+	}
+	return findings
+}
+
+// motherAgeRule flags a mother recorded as younger than minAge or older
+// than maxAge at a child's birth.
+type motherAgeRule struct {
+	minAge int
+	maxAge int
+}
+
+func (motherAgeRule) ID() string                        { return "RULES_MOTHERAGE" }
+func (motherAgeRule) Description() string               { return "flags a mother implausibly young or old at a child's birth" }
+func (motherAgeRule) DefaultSeverity() validate.Severity { return validate.SeverityWarning }
+
+func (r motherAgeRule) Evaluate(g *query.Graph) []Finding {
+	if g == nil {
+		return nil
+	}
+	findings := make([]Finding, 0)
+	for _, fam := range g.AllFamilies() {
+		if fam == nil {
+			continue
+		}
+		mother := fam.Wife()
+		if mother == nil || mother.Individual == nil {
+			continue
+		}
+		motherBirth := yearOf(mother.Individual.GetBirthDate())
+		if motherBirth == 0 {
+			continue
+		}
+		for _, child := range fam.Children() {
+			if child == nil || child.Individual == nil {
+				continue
+			}
+			childBirth := yearOf(child.Individual.GetBirthDate())
+			if childBirth == 0 {
+				continue
+			}
+			age := childBirth - motherBirth
+			if age < r.minAge || age > r.maxAge {
+				findings = append(findings, finding(r.ID(), r.DefaultSeverity(), []string{mother.ID(), child.ID()},
+					"%s was %d at %s's birth", mother.ID(), age, child.ID()))
+			}
+		}
+	}
+	return findings
+}
+
+// deathBeforeBirthRule flags an individual recorded as dying before they
+// were born.
+type deathBeforeBirthRule struct{}
+
+func (deathBeforeBirthRule) ID() string                        { return "RULES_DEATHBEFOREBIRTH" }
+func (deathBeforeBirthRule) Description() string               { return "flags an individual recorded as dying before they were born" }
+func (deathBeforeBirthRule) DefaultSeverity() validate.Severity { return validate.SeveritySevere }
+
+func (r deathBeforeBirthRule) Evaluate(g *query.Graph) []Finding {
+	if g == nil {
+		return nil
+	}
+	findings := make([]Finding, 0)
+	for _, node := range g.AllIndividuals() {
+		if node == nil || node.Individual == nil {
+			continue
+		}
+		birth := yearOf(node.Individual.GetBirthDate())
+		death := yearOf(node.Individual.GetDeathDate())
+		if birth == 0 || death == 0 || death >= birth {
+			continue
+		}
+		findings = append(findings, finding(r.ID(), r.DefaultSeverity(), []string{node.ID()},
+			"%s died (%d) before birth (%d)", node.ID(), death, birth))
+	}
+	return findings
+}
+
+// cyclicAncestryRule flags a cycle in the parent -> child graph, which
+// would otherwise send a naive ancestor/descendant traversal into an
+// infinite loop. Mirrors validate.cycleRule's DFS-coloring approach,
+// adapted to Evaluate's graph-only signature.
+type cyclicAncestryRule struct{}
+
+func (cyclicAncestryRule) ID() string                        { return "RULES_CYCLE" }
+func (cyclicAncestryRule) Description() string               { return "flags a cycle in the parent-child graph" }
+func (cyclicAncestryRule) DefaultSeverity() validate.Severity { return validate.SeveritySevere }
+
+func (r cyclicAncestryRule) Evaluate(g *query.Graph) []Finding {
+	if g == nil {
+		return nil
+	}
+	const (
+		white = 0
+		gray  = 1
+		black = 2
+	)
+	color := make(map[string]int)
+	findings := make([]Finding, 0)
+	var visit func(xref string, path []string)
+	visit = func(xref string, path []string) {
+		switch color[xref] {
+		case black:
+			return
+		case gray:
+			cycle := append(append([]string{}, path...), xref)
+			findings = append(findings, finding(r.ID(), r.DefaultSeverity(), cycle,
+				"cyclic ancestry detected: %s", strings.Join(cycle, " -> ")))
+			return
+		}
+		color[xref] = gray
+		if node := g.GetIndividual(xref); node != nil {
+			for _, child := range node.Children() {
+				visit(child.ID(), append(path, xref))
+			}
+		}
+		color[xref] = black
+	}
+	for _, node := range g.AllIndividuals() {
+		if node == nil || color[node.ID()] != white {
+			continue
+		}
+		visit(node.ID(), nil)
+	}
+	return findings
+}
+
+// duplicateIndividualRule flags individuals sharing the same name and
+// birth date, the same name+birthdate fingerprint diff.NameBirthIdentity
+// uses to correlate records across a renumbered XREF.
+type duplicateIndividualRule struct{}
+
+func (duplicateIndividualRule) ID() string                        { return "RULES_DUPLICATE" }
+func (duplicateIndividualRule) Description() string               { return "flags individuals with the same name and birth date" }
+func (duplicateIndividualRule) DefaultSeverity() validate.Severity { return validate.SeverityWarning }
+
+func (r duplicateIndividualRule) Evaluate(g *query.Graph) []Finding {
+	if g == nil {
+		return nil
+	}
+	groups := make(map[string][]string)
+	for _, node := range g.AllIndividuals() {
+		if node == nil || node.Individual == nil {
+			continue
+		}
+		birth := strings.TrimSpace(node.Individual.GetBirthDate())
+		if birth == "" {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(node.Individual.GetName())) + "|" + birth
+		groups[key] = append(groups[key], node.ID())
+	}
+	findings := make([]Finding, 0)
+	for _, xrefIDs := range groups {
+		if len(xrefIDs) < 2 {
+			continue
+		}
+		findings = append(findings, finding(r.ID(), r.DefaultSeverity(), xrefIDs,
+			"%d individuals share the same name and birth date", len(xrefIDs)))
+	}
+	return findings
+}
+
+// memberlessFamilyRule flags a family with no husband, wife, or
+// children at all.
+type memberlessFamilyRule struct{}
+
+func (memberlessFamilyRule) ID() string                        { return "RULES_NOMEMBERS" }
+func (memberlessFamilyRule) Description() string               { return "flags a family with no husband, wife, or children" }
+func (memberlessFamilyRule) DefaultSeverity() validate.Severity { return validate.SeverityWarning }
+
+func (r memberlessFamilyRule) Evaluate(g *query.Graph) []Finding {
+	if g == nil {
+		return nil
+	}
+	findings := make([]Finding, 0)
+	for _, fam := range g.AllFamilies() {
+		if fam == nil {
+			continue
+		}
+		if fam.Husband() == nil && fam.Wife() == nil && len(fam.Children()) == 0 {
+			findings = append(findings, finding(r.ID(), r.DefaultSeverity(), []string{fam.ID()},
+				"%s has no husband, wife, or children", fam.ID()))
+		}
+	}
+	return findings
+}
+
+// unparseableDateRule flags a recorded birth or death date that carries
+// no scannable year at all, i.e. one that would fail the same parse
+// step query's unexported matchesDate relies on (see hasParsedYear).
+type unparseableDateRule struct{}
+
+func (unparseableDateRule) ID() string                        { return "RULES_BADDATE" }
+func (unparseableDateRule) Description() string               { return "flags a birth or death date with no scannable year" }
+func (unparseableDateRule) DefaultSeverity() validate.Severity { return validate.SeverityHint }
+
+func (r unparseableDateRule) Evaluate(g *query.Graph) []Finding {
+	if g == nil {
+		return nil
+	}
+	findings := make([]Finding, 0)
+	for _, node := range g.AllIndividuals() {
+		if node == nil || node.Individual == nil {
+			continue
+		}
+		if birth := node.Individual.GetBirthDate(); birth != "" && !hasParsedYear(birth) {
+			findings = append(findings, finding(r.ID(), r.DefaultSeverity(), []string{node.ID()},
+				"%s has an unparseable birth date %q", node.ID(), birth))
+		}
+		if death := node.Individual.GetDeathDate(); death != "" && !hasParsedYear(death) {
+			findings = append(findings, finding(r.ID(), r.DefaultSeverity(), []string{node.ID()},
+				"%s has an unparseable death date %q", node.ID(), death))
+		}
+	}
+	return findings
+}
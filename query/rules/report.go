@@ -0,0 +1,104 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/validate"
+)
+
+// Report is the structured result of a Validate run, suitable for JSON,
+// JUnit XML, or a human-readable summary.
+type Report struct {
+	Findings []Finding
+}
+
+// HasErrors reports whether report contains any validate.SeveritySevere
+// finding, the condition a CI pipeline gates on (graph.Validate(ctx)
+// wants this spelled as g.Validate(ctx).HasErrors(), but g.Validate
+// would need package query to import query/rules while this package
+// already imports query -- an import cycle. Validate below is the entry
+// point instead, the same split query/validation.NewRuleSet(thresholds)
+// and validate.NewValidator(tree, g, cfg) already use rather than
+// methods on *Graph).
+func (report *Report) HasErrors() bool {
+	for _, f := range report.Findings {
+		if f.Severity == validate.SeveritySevere {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate runs a RuleSet built from cfg over g and returns the
+// resulting Report. ctx is accepted (and not currently consulted mid-run)
+// so a future long-running rule -- e.g. one backed by a remote
+// similarity service -- can check ctx.Done() without changing this
+// signature; every built-in rule here runs in-process and returns
+// promptly regardless.
+func Validate(ctx context.Context, g *query.Graph, cfg *Config) *Report {
+	rs := NewRuleSet(cfg)
+	return &Report{Findings: rs.Run(g)}
+}
+
+// ToJSON renders report as indented JSON.
+func (report *Report) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(report.Findings, "", "  ")
+}
+
+// junitTestsuite/junitTestcase/junitFailure mirror the minimal JUnit XML
+// schema most CI dashboards (GitHub Actions, GitLab, Jenkins) render:
+// one <testcase> per finding, a nested <failure> when its severity is
+// severe or warning (info/hint findings report as passing testcases, so
+// they still show up in a run without failing the build).
+type junitTestsuite struct {
+	XMLName   xml.Name       `xml:"testsuite"`
+	Name      string         `xml:"name,attr"`
+	Tests     int            `xml:"tests,attr"`
+	Failures  int            `xml:"failures,attr"`
+	Testcases []junitTestcase `xml:"testcase"`
+}
+
+type junitTestcase struct {
+	Name    string         `xml:"name,attr"`
+	Failure *junitFailure  `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// ToJUnitXML renders report as a JUnit XML test suite, for CI systems
+// that already understand that format.
+func (report *Report) ToJUnitXML() ([]byte, error) {
+	suite := junitTestsuite{Name: "gedcom-rules", Tests: len(report.Findings)}
+	for _, f := range report.Findings {
+		tc := junitTestcase{Name: fmt.Sprintf("%s/%s", f.RuleID, strings.Join(f.XrefIDs, ","))}
+		if f.Severity == validate.SeveritySevere || f.Severity == validate.SeverityWarning {
+			tc.Failure = &junitFailure{Message: f.Message, Text: f.Message}
+			suite.Failures++
+		}
+		suite.Testcases = append(suite.Testcases, tc)
+	}
+	out, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// Summary renders report as a short human-readable summary, one line
+// per finding plus a trailing total.
+func (report *Report) Summary() string {
+	var b strings.Builder
+	for _, f := range report.Findings {
+		fmt.Fprintf(&b, "[%s] %s: %s\n", f.Severity, f.RuleID, f.Message)
+	}
+	fmt.Fprintf(&b, "%d finding(s)\n", len(report.Findings))
+	return b.String()
+}
@@ -0,0 +1,174 @@
+// Package rules runs a pluggable catalog of graph-quality/consistency
+// rules against a *query.Graph and emits a structured Report, the way
+// dive's runtime/ci evaluator scores a container build. It's a sibling
+// to query/validation (predicate-driven, graph-node-aware violations)
+// and validate (GEDCHECK-style tree+graph plausibility checks): this
+// package is deliberately the simplest of the three, with a Rule being
+// nothing more than Evaluate(g) []Finding plus an ID/Description/
+// DefaultSeverity, so a CI pipeline can register a custom Rule with a
+// single call to Register and get it included in every future Validate
+// run without touching this package's source.
+package rules
+
+import (
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/validate"
+)
+
+// Finding describes a single rule violation found while evaluating a
+// Graph. It intentionally matches validate.Finding's shape (RuleID,
+// Severity, Message, XrefIDs) so callers already formatting one kind of
+// finding for display can reuse the same code for the other.
+type Finding struct {
+	RuleID   string
+	Severity validate.Severity
+	Message  string
+	XrefIDs  []string
+}
+
+// Rule is a single graph-quality or consistency check run once per
+// Validate call.
+type Rule interface {
+	// ID is the stable, short identifier reported on every Finding this
+	// rule produces (e.g. "RULES_DANGLINGXREF").
+	ID() string
+
+	// Description is a one-line human-readable explanation of what the
+	// rule checks, for --list-rules style CLI output and JUnit test
+	// names.
+	Description() string
+
+	// DefaultSeverity is the severity a Finding from this rule carries
+	// when Config doesn't override it via Settings.
+	DefaultSeverity() validate.Severity
+
+	// Evaluate runs the rule over g and returns every Finding it
+	// produces. An empty/nil Graph should return no findings rather
+	// than panicking.
+	Evaluate(g *query.Graph) []Finding
+}
+
+// registry accumulates Rules registered globally via Register, in
+// addition to the built-in catalog defaultRules returns. It mirrors
+// image.RegisterFormat/database/sql.Register's package-level
+// registration pattern: a rule package only needs to call
+// rules.Register(myRule{}) from an init() to be picked up by every
+// future Validate/NewRuleSet call, without this package importing it.
+var registry []Rule
+
+// Register adds rule to the set every NewRuleSet/Validate call includes
+// alongside the built-in catalog. Register is typically called from an
+// init() function in a package that defines a custom Rule.
+func Register(rule Rule) {
+	registry = append(registry, rule)
+}
+
+// RegisteredRules returns the rules added via Register, in registration
+// order. It does not include the built-in catalog (see defaultRules).
+func RegisteredRules() []Rule {
+	out := make([]Rule, len(registry))
+	copy(out, registry)
+	return out
+}
+
+// Mode controls whether a rule runs at all, and if so, at what severity.
+type Mode string
+
+const (
+	ModeError Mode = "error" // Run the rule; every Finding is reported as validate.SeveritySevere.
+	ModeWarn  Mode = "warn"  // Run the rule at its DefaultSeverity (or lower, never raised to severe).
+	ModeOff   Mode = "off"   // Skip the rule entirely.
+)
+
+// Config controls which rules a RuleSet runs and at what severity, plus
+// any numeric thresholds a built-in rule reads (e.g. the mother-age
+// rule's min/max years).
+//
+// The request this satisfies asks to extend query.Config (see
+// TestConfig_LoadConfig in query/cache_config_test.go) with this
+// section directly. query.Config has no defining file in this snapshot
+// -- only BuildGraphHybrid/BuildGraphHybridPostgres/etc. referencing
+// config.Cache.* and config.Timeout.* fields -- so adding a field to it
+// here would mean guessing at a struct this package can't see, the same
+// gap SimilarityIndex's doc comment describes for FilterQuery. Config
+// below is this package's own standalone settings type instead; once
+// query.Config's real definition is available, a `Rules Config` field
+// added to it there is a drop-in fit for NewRuleSet's cfg parameter.
+type Config struct {
+	// Settings maps a rule ID to the Mode it should run at. A rule ID
+	// missing from Settings runs at ModeWarn with its DefaultSeverity.
+	Settings map[string]Mode
+
+	// Thresholds holds per-rule numeric overrides, keyed the same way
+	// validate.Thresholds/query/validation.Thresholds are: by field name
+	// (MinMotherAge, MaxMotherAge, ...). Missing keys fall back to
+	// DefaultThresholds().
+	Thresholds map[string]int
+}
+
+// DefaultConfig returns a Config with every built-in rule enabled at
+// ModeWarn and DefaultThresholds().
+func DefaultConfig() *Config {
+	return &Config{Settings: make(map[string]Mode), Thresholds: DefaultThresholds().Map()}
+}
+
+// modeFor returns the Mode cfg assigns ruleID, defaulting to ModeWarn.
+func (cfg *Config) modeFor(ruleID string) Mode {
+	if cfg == nil || cfg.Settings == nil {
+		return ModeWarn
+	}
+	if m, ok := cfg.Settings[ruleID]; ok && m != "" {
+		return m
+	}
+	return ModeWarn
+}
+
+// RuleSet runs a catalog of Rules over a Graph, applying Config to
+// decide which findings to keep and at what severity.
+type RuleSet struct {
+	rules []Rule
+	cfg   *Config
+}
+
+// NewRuleSet creates a RuleSet over the built-in rule catalog plus every
+// Rule added via Register, using cfg to gate and re-severity findings.
+// If cfg is nil, DefaultConfig() is used.
+func NewRuleSet(cfg *Config) *RuleSet {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	rs := &RuleSet{cfg: cfg}
+	rs.rules = append(rs.rules, defaultRules(ThresholdsFromMap(cfg.Thresholds))...)
+	rs.rules = append(rs.rules, registry...)
+	return rs
+}
+
+// AddRule registers an additional rule on rs only, without affecting the
+// package-level registry other RuleSets pick up.
+func (rs *RuleSet) AddRule(r Rule) {
+	rs.rules = append(rs.rules, r)
+}
+
+// Rules returns the rules rs will run.
+func (rs *RuleSet) Rules() []Rule {
+	return rs.rules
+}
+
+// Run executes every enabled rule over g and returns the combined,
+// mode-adjusted findings.
+func (rs *RuleSet) Run(g *query.Graph) []Finding {
+	findings := make([]Finding, 0)
+	for _, r := range rs.rules {
+		mode := rs.cfg.modeFor(r.ID())
+		if mode == ModeOff {
+			continue
+		}
+		for _, f := range r.Evaluate(g) {
+			if mode == ModeError {
+				f.Severity = validate.SeveritySevere
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
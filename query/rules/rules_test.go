@@ -0,0 +1,175 @@
+package rules
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/validate"
+)
+
+// buildIndi adds an INDI record with an optional FAMC link to tree.
+func buildIndi(tree *types.GedcomTree, xref, name, birthDate, deathDate, famc string) {
+	line := types.NewGedcomLine(0, "INDI", "", xref)
+	line.AddChild(types.NewGedcomLine(1, "NAME", name, ""))
+	if birthDate != "" {
+		birt := types.NewGedcomLine(1, "BIRT", "", "")
+		birt.AddChild(types.NewGedcomLine(2, "DATE", birthDate, ""))
+		line.AddChild(birt)
+	}
+	if deathDate != "" {
+		deat := types.NewGedcomLine(1, "DEAT", "", "")
+		deat.AddChild(types.NewGedcomLine(2, "DATE", deathDate, ""))
+		line.AddChild(deat)
+	}
+	if famc != "" {
+		line.AddChild(types.NewGedcomLine(1, "FAMC", famc, ""))
+	}
+	tree.AddRecord(types.NewIndividualRecord(line))
+}
+
+// buildFam adds a FAM record with an optional husband/wife and any number
+// of children to tree.
+func buildFam(tree *types.GedcomTree, xref, husb, wife string, children ...string) {
+	line := types.NewGedcomLine(0, "FAM", "", xref)
+	if husb != "" {
+		line.AddChild(types.NewGedcomLine(1, "HUSB", husb, ""))
+	}
+	if wife != "" {
+		line.AddChild(types.NewGedcomLine(1, "WIFE", wife, ""))
+	}
+	for _, child := range children {
+		line.AddChild(types.NewGedcomLine(1, "CHIL", child, ""))
+	}
+	tree.AddRecord(types.NewFamilyRecord(line))
+}
+
+func buildGraph(t *testing.T, tree *types.GedcomTree) *query.Graph {
+	t.Helper()
+	graph, err := query.BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+	return graph
+}
+
+func TestDeathBeforeBirthRule_FlagsReversedDates(t *testing.T) {
+	tree := types.NewGedcomTree()
+	buildIndi(tree, "@I1@", "Old /Person/", "1 JAN 1900", "1 JAN 1850", "")
+	graph := buildGraph(t, tree)
+
+	findings := deathBeforeBirthRule{}.Evaluate(graph)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d: %+v", len(findings), findings)
+	}
+	if findings[0].RuleID != "RULES_DEATHBEFOREBIRTH" {
+		t.Errorf("unexpected RuleID %q", findings[0].RuleID)
+	}
+}
+
+func TestMotherAgeRule_FlagsImplausibleAge(t *testing.T) {
+	tree := types.NewGedcomTree()
+	buildIndi(tree, "@I1@", "Mom /Person/", "1 JAN 1990", "", "")
+	buildIndi(tree, "@I2@", "Child /Person/", "1 JAN 1995", "", "")
+	buildFam(tree, "@F1@", "", "@I1@", "@I2@")
+	graph := buildGraph(t, tree)
+
+	findings := motherAgeRule{minAge: 12, maxAge: 60}.Evaluate(graph)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding for a 5-year-old mother, got %d: %+v", len(findings), findings)
+	}
+}
+
+func TestDuplicateIndividualRule_FlagsSameNameAndBirthDate(t *testing.T) {
+	tree := types.NewGedcomTree()
+	buildIndi(tree, "@I1@", "Jane /Doe/", "1 JAN 1900", "", "")
+	buildIndi(tree, "@I2@", "Jane /Doe/", "1 JAN 1900", "", "")
+	buildIndi(tree, "@I3@", "John /Doe/", "1 JAN 1900", "", "")
+	graph := buildGraph(t, tree)
+
+	findings := duplicateIndividualRule{}.Evaluate(graph)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 duplicate group, got %d: %+v", len(findings), findings)
+	}
+	if len(findings[0].XrefIDs) != 2 {
+		t.Errorf("expected 2 xrefs in the duplicate group, got %v", findings[0].XrefIDs)
+	}
+}
+
+func TestMemberlessFamilyRule_FlagsEmptyFamily(t *testing.T) {
+	tree := types.NewGedcomTree()
+	buildFam(tree, "@F1@", "", "")
+	graph := buildGraph(t, tree)
+
+	findings := memberlessFamilyRule{}.Evaluate(graph)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d: %+v", len(findings), findings)
+	}
+}
+
+func TestCyclicAncestryRule_FlagsSelfReferencingParent(t *testing.T) {
+	tree := types.NewGedcomTree()
+	buildIndi(tree, "@I1@", "Loop /Person/", "1 JAN 1900", "", "@F1@")
+	buildFam(tree, "@F1@", "@I1@", "", "@I1@")
+	graph := buildGraph(t, tree)
+
+	findings := cyclicAncestryRule{}.Evaluate(graph)
+	if len(findings) == 0 {
+		t.Fatal("expected at least 1 cycle finding")
+	}
+}
+
+func TestRuleSet_ConfigModeOffSkipsRule(t *testing.T) {
+	tree := types.NewGedcomTree()
+	buildIndi(tree, "@I1@", "Old /Person/", "1 JAN 1900", "1 JAN 1850", "")
+	graph := buildGraph(t, tree)
+
+	cfg := DefaultConfig()
+	cfg.Settings["RULES_DEATHBEFOREBIRTH"] = ModeOff
+	rs := NewRuleSet(cfg)
+	for _, f := range rs.Run(graph) {
+		if f.RuleID == "RULES_DEATHBEFOREBIRTH" {
+			t.Fatalf("expected RULES_DEATHBEFOREBIRTH to be skipped, got %+v", f)
+		}
+	}
+}
+
+func TestRuleSet_ConfigModeErrorEscalatesSeverity(t *testing.T) {
+	tree := types.NewGedcomTree()
+	buildFam(tree, "@F1@", "", "")
+	graph := buildGraph(t, tree)
+
+	cfg := DefaultConfig()
+	cfg.Settings["RULES_NOMEMBERS"] = ModeError
+	rs := NewRuleSet(cfg)
+	findings := rs.Run(graph)
+	if len(findings) != 1 || findings[0].Severity != validate.SeveritySevere {
+		t.Fatalf("expected RULES_NOMEMBERS escalated to severe, got %+v", findings)
+	}
+}
+
+func TestReport_HasErrors(t *testing.T) {
+	report := &Report{Findings: []Finding{{Severity: validate.SeverityWarning}}}
+	if report.HasErrors() {
+		t.Error("a warning-only report should not HasErrors")
+	}
+	report.Findings = append(report.Findings, Finding{Severity: validate.SeveritySevere})
+	if !report.HasErrors() {
+		t.Error("a report with a severe finding should HasErrors")
+	}
+}
+
+func TestValidate_ReturnsReport(t *testing.T) {
+	tree := types.NewGedcomTree()
+	buildIndi(tree, "@I1@", "Old /Person/", "1 JAN 1900", "1 JAN 1850", "")
+	graph := buildGraph(t, tree)
+
+	report := Validate(context.Background(), graph, nil)
+	if report == nil {
+		t.Fatal("expected non-nil report")
+	}
+	if !report.HasErrors() {
+		t.Error("expected the death-before-birth finding to surface as an error")
+	}
+}
@@ -0,0 +1,46 @@
+package rules
+
+// Thresholds holds the configurable ages this package's built-in rule
+// catalog checks against. Mirrors query/validation.Thresholds's
+// field-map convention so Config.Thresholds can be keyed by field name
+// the same way.
+type Thresholds struct {
+	MinMotherAge int // Min plausible mother age at child birth. Default 12.
+	MaxMotherAge int // Max plausible mother age at child birth. Default 60.
+}
+
+// DefaultThresholds returns this package's default age thresholds,
+// matching the request's literal "mothers younger than 12 / older than
+// 60" wording.
+func DefaultThresholds() Thresholds {
+	return Thresholds{MinMotherAge: 12, MaxMotherAge: 60}
+}
+
+func (t *Thresholds) fields() map[string]*int {
+	return map[string]*int{
+		"MinMotherAge": &t.MinMotherAge,
+		"MaxMotherAge": &t.MaxMotherAge,
+	}
+}
+
+// Map renders t as the map[string]int Config.Thresholds stores.
+func (t Thresholds) Map() map[string]int {
+	out := make(map[string]int, 2)
+	for name, p := range t.fields() {
+		out[name] = *p
+	}
+	return out
+}
+
+// ThresholdsFromMap overlays m onto DefaultThresholds(). Unknown keys
+// are ignored.
+func ThresholdsFromMap(m map[string]int) Thresholds {
+	t := DefaultThresholds()
+	fields := t.fields()
+	for k, v := range m {
+		if p, ok := fields[k]; ok {
+			*p = v
+		}
+	}
+	return t
+}
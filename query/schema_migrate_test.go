@@ -0,0 +1,99 @@
+package query
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// TestSchemaCompat spins up a database pinned to an older historical
+// migration version (every migration except the latest), confirms
+// EnsureSchemaVersion reports that mismatch as a structured
+// ErrSchemaOutOfDate rather than leaving the caller to hit a confusing
+// column-not-found error, then confirms MigrateSchema/EnsureSchemaVersion
+// with autoMigrate brings it cleanly up to date.
+func TestSchemaCompat(t *testing.T) {
+	databaseURL := getPostgreSQLTestURL(t)
+	testPostgreSQLConnection(t, databaseURL)
+
+	db, err := sql.Open("pgx", databaseURL)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+	// Pin to a single connection so SET search_path (session state) stays
+	// in effect for every statement this test runs.
+	db.SetMaxOpenConns(1)
+
+	const schemaName = "test_schema_compat"
+	if _, err := db.Exec("DROP SCHEMA IF EXISTS " + schemaName + " CASCADE"); err != nil {
+		t.Fatalf("failed to drop schema: %v", err)
+	}
+	if _, err := db.Exec("CREATE SCHEMA " + schemaName); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	defer db.Exec("DROP SCHEMA IF EXISTS " + schemaName + " CASCADE")
+	if _, err := db.Exec("SET search_path TO " + schemaName); err != nil {
+		t.Fatalf("failed to set search_path: %v", err)
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations failed: %v", err)
+	}
+	if len(migrations) < 2 {
+		t.Fatalf("expected at least 2 migrations to exercise an historical version, got %d", len(migrations))
+	}
+	historical := migrations[:len(migrations)-1]
+
+	if err := ensureMigrationsTable(db); err != nil {
+		t.Fatalf("ensureMigrationsTable failed: %v", err)
+	}
+	for _, m := range historical {
+		if _, err := db.Exec(m.SQL); err != nil {
+			t.Fatalf("failed to apply historical migration %d_%s: %v", m.Version, m.Name, err)
+		}
+		if _, err := db.Exec("INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)", m.Version, time.Now().Unix()); err != nil {
+			t.Fatalf("failed to record historical migration %d: %v", m.Version, err)
+		}
+	}
+
+	have, want, err := CheckSchemaVersion(db)
+	if err != nil {
+		t.Fatalf("CheckSchemaVersion failed: %v", err)
+	}
+	if have != historical[len(historical)-1].Version {
+		t.Fatalf("expected have=%d after applying historical migrations, got %d", historical[len(historical)-1].Version, have)
+	}
+	if have >= want {
+		t.Fatalf("expected have (%d) < want (%d) for a database missing the latest migration", have, want)
+	}
+
+	err = EnsureSchemaVersion(db, false)
+	var outOfDate *ErrSchemaOutOfDate
+	if !errors.As(err, &outOfDate) {
+		t.Fatalf("expected *ErrSchemaOutOfDate, got %v", err)
+	}
+	if outOfDate.Have != have || outOfDate.Want != want {
+		t.Errorf("expected ErrSchemaOutOfDate{%d,%d}, got %+v", have, want, outOfDate)
+	}
+
+	if err := EnsureSchemaVersion(db, true); err != nil {
+		t.Fatalf("EnsureSchemaVersion with autoMigrate failed: %v", err)
+	}
+
+	haveAfter, wantAfter, err := CheckSchemaVersion(db)
+	if err != nil {
+		t.Fatalf("CheckSchemaVersion after migrate failed: %v", err)
+	}
+	if haveAfter != wantAfter {
+		t.Errorf("expected schema fully upgraded, have=%d want=%d", haveAfter, wantAfter)
+	}
+
+	if err := EnsureSchemaVersion(db, false); err != nil {
+		t.Errorf("expected no error once schema is up to date, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package query
+
+import "strings"
+
+// searchDiacriticFold maps common Latin letters with diacritics to their
+// plain ASCII base letter. There's no golang.org/x/text/unicode/norm
+// dependency available in this snapshot (nothing else in the tree
+// imports golang.org/x/text), so "NFC-normalize" here means this
+// explicit fold table rather than a real Unicode normalization -- the
+// same deliberately approximate approach search's own foldDiacritics
+// takes, duplicated here rather than imported since search's copy is
+// unexported.
+var searchDiacriticFold = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ā': 'a',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e', 'ė': 'e', 'ę': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ī': 'i',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o', 'ō': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u',
+	'ý': 'y', 'ÿ': 'y',
+	'ñ': 'n', 'ń': 'n',
+	'ç': 'c', 'ć': 'c', 'č': 'c',
+	'š': 's', 'ś': 's',
+	'ž': 'z', 'ź': 'z', 'ż': 'z',
+	'ł': 'l',
+	'đ': 'd', 'ď': 'd',
+	'ř': 'r',
+	'ť': 't',
+}
+
+// normalizeSearchKey case-folds and diacritic-folds s, for use as a
+// RadixTree key: SearchNamesPrefix("smit") and SearchNamesPrefix("Smit")
+// must find the same "Smith" entry regardless of how either was typed.
+func normalizeSearchKey(s string) string {
+	s = strings.ToLower(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if folded, ok := searchDiacriticFold[r]; ok {
+			r = folded
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isSearchTokenRune reports whether r can appear within an indexed name
+// or place token: letters and digits, nothing else (GEDCOM punctuation
+// like "/" surname delimiters and "," in place names are separators).
+func isSearchTokenRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+}
+
+// searchTokens case-folds and diacritic-folds s, then splits it into
+// contiguous runs of letters/digits -- the same tokenization search's
+// own tokenize performs, duplicated here for the same unexported-symbol
+// reason as normalizeSearchKey.
+func searchTokens(s string) []string {
+	s = normalizeSearchKey(s)
+	var tokens []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range s {
+		if isSearchTokenRune(r) {
+			cur.WriteRune(r)
+		} else {
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
@@ -0,0 +1,190 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/crc32"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ShardConfig configures a ShardedPostgres.
+type ShardConfig struct {
+	// DSNs is one connection string per physical PostgreSQL database.
+	// If ShardCount is larger than len(DSNs), shards are assigned to
+	// DSNs round-robin (shard % len(DSNs)), so multiple logical shards
+	// can share one physical database.
+	DSNs []string
+
+	// ShardCount is the number of logical shards fileIDs are hashed
+	// into. Defaults to len(DSNs) if zero.
+	ShardCount int
+
+	// MaxOpenConnections and MaxIdleConnections are applied to every
+	// shard's *sql.DB via SetMaxOpenConns/SetMaxIdleConns. Zero leaves
+	// database/sql's defaults in place.
+	MaxOpenConnections int
+	MaxIdleConnections int
+}
+
+// ShardedPostgres routes file_id-scoped queries to one of several
+// PostgreSQL databases, hashing file_id (crc32) modulo the shard count to
+// pick the target, so a single "nodes" table (see
+// TestHybridStoragePostgres_FileIDIsolation) doesn't become a hotspot for
+// a service ingesting many GEDCOM files.
+//
+// HybridStoragePostgres.PostgreSQL() returns a concrete *sql.DB, not an
+// interface, and its defining file isn't part of this package snapshot,
+// so ShardedPostgres can't be dropped in as a substitute return value the
+// way the request describes. Instead it's used directly: callers route
+// their own file_id-scoped queries through DBForFileID (or the
+// QueryFileID/ExecFileID convenience wrappers below), and fan cross-shard
+// scans out themselves via AllShardDBs or FanOutRows.
+type ShardedPostgres struct {
+	cfg ShardConfig
+
+	mu     sync.Mutex
+	shards []*sql.DB // lazily opened, one per logical shard
+}
+
+// NewShardedPostgres creates a ShardedPostgres from cfg. Connections
+// aren't opened until a shard is first needed.
+func NewShardedPostgres(cfg ShardConfig) (*ShardedPostgres, error) {
+	if len(cfg.DSNs) == 0 {
+		return nil, fmt.Errorf("sharded postgres: at least one DSN is required")
+	}
+	if cfg.ShardCount <= 0 {
+		cfg.ShardCount = len(cfg.DSNs)
+	}
+	return &ShardedPostgres{
+		cfg:    cfg,
+		shards: make([]*sql.DB, cfg.ShardCount),
+	}, nil
+}
+
+// ShardOf returns the logical shard index that owns fileID.
+func (s *ShardedPostgres) ShardOf(fileID string) int {
+	return int(crc32.ChecksumIEEE([]byte(fileID)) % uint32(s.cfg.ShardCount))
+}
+
+func (s *ShardedPostgres) dsnForShard(shard int) string {
+	return s.cfg.DSNs[shard%len(s.cfg.DSNs)]
+}
+
+func (s *ShardedPostgres) shardDB(shard int) (*sql.DB, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if db := s.shards[shard]; db != nil {
+		return db, nil
+	}
+
+	db, err := sql.Open("pgx", s.dsnForShard(shard))
+	if err != nil {
+		return nil, fmt.Errorf("sharded postgres: failed to open shard %d: %w", shard, err)
+	}
+	if s.cfg.MaxOpenConnections > 0 {
+		db.SetMaxOpenConns(s.cfg.MaxOpenConnections)
+	}
+	if s.cfg.MaxIdleConnections > 0 {
+		db.SetMaxIdleConns(s.cfg.MaxIdleConnections)
+	}
+
+	s.shards[shard] = db
+	return db, nil
+}
+
+// DBForFileID returns (lazily opening it if needed) the *sql.DB for the
+// shard that owns fileID.
+func (s *ShardedPostgres) DBForFileID(fileID string) (*sql.DB, error) {
+	return s.shardDB(s.ShardOf(fileID))
+}
+
+// AllShardDBs opens (if needed) and returns every shard's *sql.DB, for
+// callers that need to fan a cross-shard scan out themselves.
+func (s *ShardedPostgres) AllShardDBs() ([]*sql.DB, error) {
+	dbs := make([]*sql.DB, s.cfg.ShardCount)
+	for i := range dbs {
+		db, err := s.shardDB(i)
+		if err != nil {
+			return nil, err
+		}
+		dbs[i] = db
+	}
+	return dbs, nil
+}
+
+// QueryFileID runs query against the shard that owns fileID, passing
+// fileID as the first argument ($1), matching this package's existing
+// "WHERE file_id = $1" convention, followed by the rest of args.
+func (s *ShardedPostgres) QueryFileID(ctx context.Context, fileID, query string, args ...interface{}) (*sql.Rows, error) {
+	db, err := s.DBForFileID(fileID)
+	if err != nil {
+		return nil, err
+	}
+	return db.QueryContext(ctx, query, append([]interface{}{fileID}, args...)...)
+}
+
+// ExecFileID runs an exec statement against the shard that owns fileID,
+// passing fileID as the first argument ($1), followed by the rest of
+// args.
+func (s *ShardedPostgres) ExecFileID(ctx context.Context, fileID, query string, args ...interface{}) (sql.Result, error) {
+	db, err := s.DBForFileID(fileID)
+	if err != nil {
+		return nil, err
+	}
+	return db.ExecContext(ctx, query, append([]interface{}{fileID}, args...)...)
+}
+
+// FanOutRows runs query (not scoped to a single file_id) against every
+// shard concurrently and returns each shard's *sql.Rows in shard order.
+// Callers are responsible for closing each returned *sql.Rows and
+// merging them as needed.
+func (s *ShardedPostgres) FanOutRows(ctx context.Context, query string, args ...interface{}) ([]*sql.Rows, error) {
+	dbs, err := s.AllShardDBs()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*sql.Rows, len(dbs))
+	g, gctx := errgroup.WithContext(ctx)
+	for i, db := range dbs {
+		i, db := i, db
+		g.Go(func() error {
+			rows, err := db.QueryContext(gctx, query, args...)
+			if err != nil {
+				return fmt.Errorf("sharded postgres: shard %d query failed: %w", i, err)
+			}
+			results[i] = rows
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		for _, rows := range results {
+			if rows != nil {
+				rows.Close()
+			}
+		}
+		return nil, err
+	}
+	return results, nil
+}
+
+// Close closes every shard's *sql.DB that has been opened so far.
+func (s *ShardedPostgres) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	for _, db := range s.shards {
+		if db == nil {
+			continue
+		}
+		if err := db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,106 @@
+package query
+
+import (
+	"hash/fnv"
+	"math"
+	"strings"
+)
+
+// Embedder turns a string into a fixed-length vector for the HNSW
+// similarity index in similarity_hnsw.go to index and search. It is
+// pluggable so ByNameSimilar/ByPlaceSimilar work without any external
+// embedding model: the defaults below are cheap, deterministic hashing
+// schemes.
+type Embedder interface {
+	Embed(s string) []float32
+	Dims() int
+}
+
+// ngramEmbedder embeds a string as a bag of lowercase character n-grams,
+// hashed into a fixed number of dimensions (the hashing trick) and
+// L2-normalized, so two strings sharing most of their n-grams end up
+// with similar vectors -- useful for catching misspellings like "Jon
+// Smyth" vs "John Smith".
+type ngramEmbedder struct {
+	n    int
+	dims int
+}
+
+// NewNGramEmbedder builds an Embedder over character n-grams of length n,
+// hashed into a dims-dimensional vector.
+func NewNGramEmbedder(n, dims int) Embedder {
+	return &ngramEmbedder{n: n, dims: dims}
+}
+
+func (e *ngramEmbedder) Dims() int { return e.dims }
+
+func (e *ngramEmbedder) Embed(s string) []float32 {
+	vec := make([]float32, e.dims)
+	normalized := strings.ToLower(strings.Join(strings.Fields(s), " "))
+	padded := strings.Repeat("_", e.n-1) + normalized + strings.Repeat("_", e.n-1)
+	runes := []rune(padded)
+	for i := 0; i+e.n <= len(runes); i++ {
+		gram := string(runes[i : i+e.n])
+		vec[hashToDim(gram, e.dims)]++
+	}
+	return normalizeVector(vec)
+}
+
+// tokenEmbedder embeds a string as a bag of whitespace/comma-separated
+// tokens, hashed the same way as ngramEmbedder -- a simple,
+// geocoder-free stand-in for place names ("Paris, FR" / "Paris, France"),
+// where whole-token overlap matters more than shared character shingles.
+type tokenEmbedder struct {
+	dims int
+}
+
+// NewTokenEmbedder builds an Embedder over whitespace/comma-separated
+// tokens, hashed into a dims-dimensional vector.
+func NewTokenEmbedder(dims int) Embedder {
+	return &tokenEmbedder{dims: dims}
+}
+
+func (e *tokenEmbedder) Dims() int { return e.dims }
+
+func (e *tokenEmbedder) Embed(s string) []float32 {
+	vec := make([]float32, e.dims)
+	normalized := strings.ToLower(s)
+	for _, token := range strings.FieldsFunc(normalized, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	}) {
+		if token == "" {
+			continue
+		}
+		vec[hashToDim(token, e.dims)]++
+	}
+	return normalizeVector(vec)
+}
+
+// DefaultNameEmbedder is the Embedder ByNameSimilar uses when none is
+// configured explicitly: trigrams hashed into 64 dimensions.
+var DefaultNameEmbedder = NewNGramEmbedder(3, 64)
+
+// DefaultPlaceEmbedder is the Embedder ByPlaceSimilar uses when none is
+// configured explicitly.
+var DefaultPlaceEmbedder = NewTokenEmbedder(64)
+
+func hashToDim(s string, dims int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return int(h.Sum32() % uint32(dims))
+}
+
+func normalizeVector(vec []float32) []float32 {
+	var sumSquares float64
+	for _, v := range vec {
+		sumSquares += float64(v) * float64(v)
+	}
+	if sumSquares == 0 {
+		return vec
+	}
+	norm := float32(math.Sqrt(sumSquares))
+	for i := range vec {
+		vec[i] /= norm
+	}
+	return vec
+}
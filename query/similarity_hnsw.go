@@ -0,0 +1,276 @@
+package query
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// hnswNode is one indexed point: its vector plus, per layer, the IDs of
+// its connected neighbors (neighbors[0] is layer 0, the layer every node
+// belongs to).
+type hnswNode struct {
+	id        string
+	vector    []float32
+	neighbors [][]string // neighbors[layer] = neighbor IDs at that layer
+}
+
+// hnswIndex is an in-memory HNSW (Hierarchical Navigable Small World)
+// approximate nearest-neighbor index, built per the algorithm in Malkov &
+// Yashunin, "Efficient and robust approximate nearest neighbor search
+// using Hierarchical Navigable Small World graphs". It backs
+// ByNameSimilar/ByPlaceSimilar (similarity_query.go).
+type hnswIndex struct {
+	mu sync.RWMutex
+
+	nodes          map[string]*hnswNode
+	entryPoint     string
+	topLevel       int
+	m              int // neighbors added per node per layer
+	mMax           int // max neighbors allowed per node per layer before pruning
+	efConstruction int
+	mL             float64 // level-generation normalization factor
+	rng            *rand.Rand
+}
+
+// newHNSWIndex builds an empty index. m is the target number of
+// connections per node per layer (commonly 8-16); efConstruction is the
+// candidate-list size used while inserting (commonly 100-200).
+func newHNSWIndex(m, efConstruction int) *hnswIndex {
+	return &hnswIndex{
+		nodes:          make(map[string]*hnswNode),
+		m:              m,
+		mMax:           m,
+		efConstruction: efConstruction,
+		mL:             1 / math.Log(float64(m)),
+		rng:            rand.New(rand.NewSource(1)),
+	}
+}
+
+// randomLevel draws this node's top layer from the geometric
+// distribution floor(-ln(U)*mL) the HNSW paper uses to keep the expected
+// number of nodes per layer shrinking geometrically with height.
+func (h *hnswIndex) randomLevel() int {
+	u := h.rng.Float64()
+	for u == 0 {
+		u = h.rng.Float64()
+	}
+	return int(math.Floor(-math.Log(u) * h.mL))
+}
+
+// Insert adds id/vector to the index, or replaces vector if id is
+// already present.
+func (h *hnswIndex) Insert(id string, vector []float32) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	level := h.randomLevel()
+	node := &hnswNode{id: id, vector: vector, neighbors: make([][]string, level+1)}
+
+	if h.entryPoint == "" {
+		h.nodes[id] = node
+		h.entryPoint = id
+		h.topLevel = level
+		return
+	}
+	if existing, ok := h.nodes[id]; ok {
+		existing.vector = vector
+		return
+	}
+
+	ep := h.entryPoint
+	for lc := h.topLevel; lc > level; lc-- {
+		nearest := h.searchLayer(vector, []string{ep}, 1, lc)
+		if len(nearest) > 0 {
+			ep = nearest[0].id
+		}
+	}
+
+	entryPoints := []string{ep}
+	for lc := minInt(h.topLevel, level); lc >= 0; lc-- {
+		candidates := h.searchLayer(vector, entryPoints, h.efConstruction, lc)
+		chosen := h.selectNeighborsHeuristic(vector, candidates, h.m)
+
+		node.neighbors[lc] = make([]string, len(chosen))
+		for i, c := range chosen {
+			node.neighbors[lc][i] = c.id
+		}
+
+		for _, c := range chosen {
+			neighbor := h.nodes[c.id]
+			h.ensureLayer(neighbor, lc)
+			neighbor.neighbors[lc] = append(neighbor.neighbors[lc], id)
+			if len(neighbor.neighbors[lc]) > h.mMax {
+				neighborCandidates := make([]hnswCandidate, 0, len(neighbor.neighbors[lc]))
+				for _, nid := range neighbor.neighbors[lc] {
+					if other := h.nodes[nid]; other != nil {
+						neighborCandidates = append(neighborCandidates, hnswCandidate{id: nid, dist: squaredDistance(neighbor.vector, other.vector)})
+					}
+				}
+				pruned := h.selectNeighborsHeuristic(neighbor.vector, neighborCandidates, h.mMax)
+				neighbor.neighbors[lc] = make([]string, len(pruned))
+				for i, p := range pruned {
+					neighbor.neighbors[lc][i] = p.id
+				}
+			}
+		}
+
+		entryPoints = make([]string, len(candidates))
+		for i, c := range candidates {
+			entryPoints[i] = c.id
+		}
+	}
+
+	h.nodes[id] = node
+	if level > h.topLevel {
+		h.topLevel = level
+		h.entryPoint = id
+	}
+}
+
+// ensureLayer grows node.neighbors so layer lc exists; node was created at
+// a lower top level than lc when it was first linked down from a higher
+// layer during a later insertion's pass over entry points.
+func (h *hnswIndex) ensureLayer(node *hnswNode, lc int) {
+	for len(node.neighbors) <= lc {
+		node.neighbors = append(node.neighbors, nil)
+	}
+}
+
+// Search returns the IDs of the k nearest indexed vectors to query, using
+// ef as the candidate-list size at layer 0 (efSearch in the paper).
+func (h *hnswIndex) Search(query []float32, k, ef int) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.entryPoint == "" {
+		return nil
+	}
+
+	ep := h.entryPoint
+	for lc := h.topLevel; lc > 0; lc-- {
+		nearest := h.searchLayer(query, []string{ep}, 1, lc)
+		if len(nearest) > 0 {
+			ep = nearest[0].id
+		}
+	}
+
+	candidates := h.searchLayer(query, []string{ep}, ef, 0)
+	if len(candidates) > k {
+		candidates = candidates[:k]
+	}
+	ids := make([]string, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.id
+	}
+	return ids
+}
+
+// searchLayer is SEARCH-LAYER from the paper: a greedy beam search over
+// layer lc starting from entryPoints, keeping the ef best candidates
+// found. Callers must hold h.mu (read or write).
+func (h *hnswIndex) searchLayer(query []float32, entryPoints []string, ef int, lc int) []hnswCandidate {
+	visited := make(map[string]bool)
+	candidates := &minCandidateHeap{}
+	results := &maxCandidateHeap{}
+
+	for _, id := range entryPoints {
+		node := h.nodes[id]
+		if node == nil || visited[id] {
+			continue
+		}
+		visited[id] = true
+		d := squaredDistance(query, node.vector)
+		c := hnswCandidate{id: id, dist: d}
+		heap.Push(candidates, c)
+		heap.Push(results, c)
+	}
+
+	for candidates.Len() > 0 {
+		nearest := heap.Pop(candidates).(hnswCandidate)
+		if results.Len() > 0 && nearest.dist > (*results)[0].dist {
+			break
+		}
+		node := h.nodes[nearest.id]
+		if node == nil || lc >= len(node.neighbors) {
+			continue
+		}
+		for _, neighborID := range node.neighbors[lc] {
+			if visited[neighborID] {
+				continue
+			}
+			visited[neighborID] = true
+			neighbor := h.nodes[neighborID]
+			if neighbor == nil {
+				continue
+			}
+			d := squaredDistance(query, neighbor.vector)
+			if results.Len() < ef || d < (*results)[0].dist {
+				c := hnswCandidate{id: neighborID, dist: d}
+				heap.Push(candidates, c)
+				heap.Push(results, c)
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	out := make([]hnswCandidate, results.Len())
+	copy(out, *results)
+	sortCandidatesAscending(out)
+	return out
+}
+
+// selectNeighborsHeuristic is SELECT-NEIGHBORS-HEURISTIC: rather than
+// simply keeping the m closest candidates, it prefers candidates that are
+// closer to q than to any neighbor already chosen, so the resulting
+// connections point in diverse directions instead of clustering in one.
+func (h *hnswIndex) selectNeighborsHeuristic(query []float32, candidates []hnswCandidate, m int) []hnswCandidate {
+	working := make([]hnswCandidate, len(candidates))
+	copy(working, candidates)
+	sortCandidatesAscending(working)
+
+	result := make([]hnswCandidate, 0, m)
+	for _, c := range working {
+		if len(result) >= m {
+			break
+		}
+		node := h.nodes[c.id]
+		if node == nil {
+			continue
+		}
+		closerToExisting := false
+		for _, r := range result {
+			existing := h.nodes[r.id]
+			if existing == nil {
+				continue
+			}
+			if squaredDistance(node.vector, existing.vector) < c.dist {
+				closerToExisting = true
+				break
+			}
+		}
+		if !closerToExisting {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func squaredDistance(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		d := float64(a[i]) - float64(b[i])
+		sum += d * d
+	}
+	return sum
+}
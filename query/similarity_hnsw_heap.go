@@ -0,0 +1,50 @@
+package query
+
+import "sort"
+
+// hnswCandidate is one point under consideration during a SEARCH-LAYER
+// pass: its ID plus its (squared) distance to the query vector.
+type hnswCandidate struct {
+	id   string
+	dist float64
+}
+
+// minCandidateHeap is the C candidate queue in SEARCH-LAYER: a min-heap
+// by distance, so the nearest unexplored candidate is always popped
+// first.
+type minCandidateHeap []hnswCandidate
+
+func (h minCandidateHeap) Len() int            { return len(h) }
+func (h minCandidateHeap) Less(i, j int) bool   { return h[i].dist < h[j].dist }
+func (h minCandidateHeap) Swap(i, j int)        { h[i], h[j] = h[j], h[i] }
+func (h *minCandidateHeap) Push(x interface{}) { *h = append(*h, x.(hnswCandidate)) }
+func (h *minCandidateHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// maxCandidateHeap is the W result set in SEARCH-LAYER: a max-heap by
+// distance, so the current furthest result -- the one to evict once the
+// set grows past ef -- is always at the root.
+type maxCandidateHeap []hnswCandidate
+
+func (h maxCandidateHeap) Len() int            { return len(h) }
+func (h maxCandidateHeap) Less(i, j int) bool   { return h[i].dist > h[j].dist }
+func (h maxCandidateHeap) Swap(i, j int)        { h[i], h[j] = h[j], h[i] }
+func (h *maxCandidateHeap) Push(x interface{}) { *h = append(*h, x.(hnswCandidate)) }
+func (h *maxCandidateHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// sortCandidatesAscending orders candidates by distance, nearest first,
+// for returning ranked results after a beam search.
+func sortCandidatesAscending(candidates []hnswCandidate) {
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].dist < candidates[j].dist })
+}
@@ -0,0 +1,85 @@
+package query
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// hnswSnapshot is the gob-serializable form of an hnswIndex, used to
+// persist it to BadgerDB and reload it without re-embedding and
+// re-inserting every individual on every graph build.
+type hnswSnapshot struct {
+	Nodes          map[string]hnswNodeSnapshot
+	EntryPoint     string
+	TopLevel       int
+	M              int
+	MMax           int
+	EfConstruction int
+	ML             float64
+}
+
+// hnswNodeSnapshot is the serializable form of hnswNode.
+type hnswNodeSnapshot struct {
+	Vector    []float32
+	Neighbors [][]string
+}
+
+func (h *hnswIndex) snapshot() hnswSnapshot {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	nodes := make(map[string]hnswNodeSnapshot, len(h.nodes))
+	for id, n := range h.nodes {
+		nodes[id] = hnswNodeSnapshot{Vector: n.vector, Neighbors: n.neighbors}
+	}
+	return hnswSnapshot{
+		Nodes:          nodes,
+		EntryPoint:     h.entryPoint,
+		TopLevel:       h.topLevel,
+		M:              h.m,
+		MMax:           h.mMax,
+		EfConstruction: h.efConstruction,
+		ML:             h.mL,
+	}
+}
+
+// restoreHNSWIndex rebuilds an hnswIndex from a snapshot produced by
+// snapshot(). The rebuilt index shares the same rng state as a fresh one
+// (it is only consulted on Insert, and a restored index is queried, not
+// inserted into, until new individuals are added).
+func restoreHNSWIndex(snap hnswSnapshot) *hnswIndex {
+	h := newHNSWIndex(snap.M, snap.EfConstruction)
+	h.mMax = snap.MMax
+	h.ml(snap.ML)
+	h.entryPoint = snap.EntryPoint
+	h.topLevel = snap.TopLevel
+	h.nodes = make(map[string]*hnswNode, len(snap.Nodes))
+	for id, n := range snap.Nodes {
+		h.nodes[id] = &hnswNode{id: id, vector: n.Vector, neighbors: n.Neighbors}
+	}
+	return h
+}
+
+// ml sets mL directly, for restoreHNSWIndex -- newHNSWIndex derives mL
+// from m, which is only an approximation of the value a persisted index
+// was actually built with if M changes between releases.
+func (h *hnswIndex) ml(value float64) { h.mL = value }
+
+// marshal gob-encodes the index for storage.
+func (h *hnswIndex) marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(h.snapshot()); err != nil {
+		return nil, fmt.Errorf("similarity index: failed to encode: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalHNSWIndex reverses marshal.
+func unmarshalHNSWIndex(data []byte) (*hnswIndex, error) {
+	var snap hnswSnapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("similarity index: failed to decode: %w", err)
+	}
+	return restoreHNSWIndex(snap), nil
+}
@@ -0,0 +1,77 @@
+package query
+
+import "testing"
+
+func TestHNSWIndexInsertAndSearchFindsExactMatch(t *testing.T) {
+	idx := newHNSWIndex(8, 50)
+	idx.Insert("a", []float32{1, 0, 0, 0})
+	idx.Insert("b", []float32{0, 1, 0, 0})
+	idx.Insert("c", []float32{0, 0, 1, 0})
+	idx.Insert("d", []float32{0.9, 0.1, 0, 0})
+
+	results := idx.Search([]float32{1, 0, 0, 0}, 2, 50)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d: %v", len(results), results)
+	}
+	if results[0] != "a" {
+		t.Errorf("expected closest match to be %q, got %q", "a", results[0])
+	}
+}
+
+func TestHNSWIndexInsertReplacesExistingID(t *testing.T) {
+	idx := newHNSWIndex(8, 50)
+	idx.Insert("a", []float32{1, 0})
+	idx.Insert("a", []float32{0, 1})
+
+	if len(idx.nodes) != 1 {
+		t.Fatalf("expected re-inserting an existing ID to replace it, got %d nodes", len(idx.nodes))
+	}
+	results := idx.Search([]float32{0, 1}, 1, 50)
+	if len(results) != 1 || results[0] != "a" {
+		t.Errorf("expected replaced vector to be searchable, got %v", results)
+	}
+}
+
+func TestHNSWIndexSnapshotRoundTrip(t *testing.T) {
+	idx := newHNSWIndex(8, 50)
+	idx.Insert("a", []float32{1, 0, 0})
+	idx.Insert("b", []float32{0, 1, 0})
+	idx.Insert("c", []float32{0, 0, 1})
+
+	data, err := idx.marshal()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	restored, err := unmarshalHNSWIndex(data)
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	want := idx.Search([]float32{1, 0, 0}, 2, 50)
+	got := restored.Search([]float32{1, 0, 0}, 2, 50)
+	if len(want) != len(got) || len(got) == 0 || got[0] != want[0] {
+		t.Errorf("expected restored index to reproduce the original search, want %v got %v", want, got)
+	}
+}
+
+func TestNGramEmbedderSimilarStringsAreCloser(t *testing.T) {
+	e := NewNGramEmbedder(3, 64)
+	jane := e.Embed("Jane Doe")
+	jayne := e.Embed("Jayne Doe")
+	bob := e.Embed("Bob Smith")
+
+	if squaredDistance(jane, jayne) >= squaredDistance(jane, bob) {
+		t.Errorf("expected a near-misspelling to embed closer than an unrelated name")
+	}
+}
+
+func TestTokenEmbedderSharedTokensAreCloser(t *testing.T) {
+	e := NewTokenEmbedder(64)
+	a := e.Embed("Paris, France")
+	b := e.Embed("Paris, FR")
+	c := e.Embed("Berlin, Germany")
+
+	if squaredDistance(a, b) >= squaredDistance(a, c) {
+		t.Errorf("expected places sharing a token to embed closer than unrelated places")
+	}
+}
@@ -0,0 +1,180 @@
+package query
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+const (
+	hnswNameIndexBadgerKey  = "query:similarity:name_index"
+	hnswPlaceIndexBadgerKey = "query:similarity:place_index"
+)
+
+// SimilarityIndex provides HNSW-backed fuzzy name/place lookups over a
+// Graph's individuals (ByNameSimilar/ByPlaceSimilar below). FilterQuery,
+// the chainable predicate builder ByName/ByBirthDate/ByBirthPlace/etc.
+// belong to, has no defining file in this snapshot, so its Execute
+// pipeline can't be extended to run an HNSW search inline the way
+// FilterQuery.ByName composes into it; SimilarityIndex is a sibling,
+// standalone entry point over the same Graph instead of a new FilterQuery
+// predicate.
+type SimilarityIndex struct {
+	graph      *Graph
+	nameIndex  *hnswIndex
+	placeIndex *hnswIndex
+}
+
+var (
+	similarityIndexMu  sync.Mutex
+	similarityIndexFor = make(map[*Graph]*SimilarityIndex)
+)
+
+// EnsureSimilarityIndex returns the SimilarityIndex for graph, building
+// and caching it from graph.GetAllIndividuals() on first use.
+func EnsureSimilarityIndex(graph *Graph) *SimilarityIndex {
+	similarityIndexMu.Lock()
+	defer similarityIndexMu.Unlock()
+	if sim, ok := similarityIndexFor[graph]; ok {
+		return sim
+	}
+	sim := buildSimilarityIndex(graph)
+	similarityIndexFor[graph] = sim
+	return sim
+}
+
+func buildSimilarityIndex(graph *Graph) *SimilarityIndex {
+	sim := &SimilarityIndex{graph: graph, nameIndex: newHNSWIndex(16, 200), placeIndex: newHNSWIndex(16, 200)}
+	for xrefID, node := range graph.GetAllIndividuals() {
+		if node == nil || node.Individual == nil {
+			continue
+		}
+		sim.nameIndex.Insert(xrefID, DefaultNameEmbedder.Embed(node.Individual.GetName()))
+		if place := node.Individual.GetBirthPlace(); place != "" {
+			sim.placeIndex.Insert(xrefID, DefaultPlaceEmbedder.Embed(place))
+		}
+	}
+	return sim
+}
+
+// ByNameSimilar returns the k individuals whose name is the closest
+// fuzzy match to pattern, found via the HNSW index over character-
+// trigram name embeddings -- so "Jon Smyth" finds "John Smith".
+func (s *SimilarityIndex) ByNameSimilar(pattern string, k int) []*types.IndividualRecord {
+	return s.search(s.nameIndex, DefaultNameEmbedder.Embed(pattern), k)
+}
+
+// ByPlaceSimilar returns the k individuals whose birth place is the
+// closest fuzzy match to pattern, found via the HNSW index over
+// tokenized place-name embeddings -- so "Paris, FR" finds "Paris,
+// France".
+func (s *SimilarityIndex) ByPlaceSimilar(pattern string, k int) []*types.IndividualRecord {
+	return s.search(s.placeIndex, DefaultPlaceEmbedder.Embed(pattern), k)
+}
+
+func (s *SimilarityIndex) search(index *hnswIndex, queryVec []float32, k int) []*types.IndividualRecord {
+	ef := k * 4
+	if ef < 50 {
+		ef = 50
+	}
+	results := make([]*types.IndividualRecord, 0, k)
+	for _, xrefID := range index.Search(queryVec, k, ef) {
+		if node := s.graph.GetIndividual(xrefID); node != nil && node.Individual != nil {
+			results = append(results, node.Individual)
+		}
+	}
+	return results
+}
+
+// persistSimilarityIndex builds (if not already cached) graph's
+// SimilarityIndex and saves it into db alongside the rest of the
+// hybrid-built graph data, so a later EnsureSimilarityIndexPersisted call
+// against the same BadgerDB path can reload it instead of re-embedding
+// and re-inserting every individual.
+func persistSimilarityIndex(db *badger.DB, graph *Graph) error {
+	sim := EnsureSimilarityIndex(graph)
+	nameBytes, err := sim.nameIndex.marshal()
+	if err != nil {
+		return err
+	}
+	placeBytes, err := sim.placeIndex.marshal()
+	if err != nil {
+		return err
+	}
+	return db.Update(func(txn *badger.Txn) error {
+		if err := txn.Set([]byte(hnswNameIndexBadgerKey), nameBytes); err != nil {
+			return fmt.Errorf("similarity index: failed to persist name index: %w", err)
+		}
+		if err := txn.Set([]byte(hnswPlaceIndexBadgerKey), placeBytes); err != nil {
+			return fmt.Errorf("similarity index: failed to persist place index: %w", err)
+		}
+		return nil
+	})
+}
+
+// loadSimilarityIndex reloads a previously persisted SimilarityIndex for
+// graph from db, returning (nil, nil) if none has been persisted yet.
+func loadSimilarityIndex(graph *Graph, db *badger.DB) (*SimilarityIndex, error) {
+	var nameBytes, placeBytes []byte
+	err := db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(hnswNameIndexBadgerKey))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if nameBytes, err = item.ValueCopy(nil); err != nil {
+			return err
+		}
+		item, err = txn.Get([]byte(hnswPlaceIndexBadgerKey))
+		if err != nil {
+			return err
+		}
+		placeBytes, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("similarity index: failed to load: %w", err)
+	}
+	if nameBytes == nil {
+		return nil, nil
+	}
+	nameIndex, err := unmarshalHNSWIndex(nameBytes)
+	if err != nil {
+		return nil, err
+	}
+	placeIndex, err := unmarshalHNSWIndex(placeBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &SimilarityIndex{graph: graph, nameIndex: nameIndex, placeIndex: placeIndex}, nil
+}
+
+// EnsureSimilarityIndexPersisted is the integration point BuildGraphHybrid
+// calls after building a graph: it reloads a previously persisted
+// SimilarityIndex for graph from db if one exists, or builds one fresh
+// and persists it, either way caching the result so later
+// EnsureSimilarityIndex(graph) calls reuse it.
+func EnsureSimilarityIndexPersisted(db *badger.DB, graph *Graph) error {
+	similarityIndexMu.Lock()
+	if _, ok := similarityIndexFor[graph]; ok {
+		similarityIndexMu.Unlock()
+		return nil
+	}
+	similarityIndexMu.Unlock()
+
+	sim, err := loadSimilarityIndex(graph, db)
+	if err != nil {
+		return err
+	}
+	if sim != nil {
+		similarityIndexMu.Lock()
+		similarityIndexFor[graph] = sim
+		similarityIndexMu.Unlock()
+		return nil
+	}
+	return persistSimilarityIndex(db, graph)
+}
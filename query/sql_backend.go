@@ -0,0 +1,201 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// SQLDialect identifies which SQL database a SQLBackend talks to.
+type SQLDialect string
+
+const (
+	DialectPostgres SQLDialect = "postgres"
+	DialectMySQL    SQLDialect = "mysql"
+	DialectSQLite   SQLDialect = "sqlite"
+)
+
+// SQLBackend abstracts the metadata store behind a single interface so
+// the hybrid storage layer's SQL doesn't have to hardcode pgx-flavored
+// syntax ($1 placeholders, pg_indexes/information_schema checks) to
+// support more than PostgreSQL.
+//
+// HybridStorage and HybridStoragePostgres (and their constructors
+// NewHybridStorage/NewHybridStoragePostgres) aren't defined in this
+// package snapshot -- they're referenced from hybrid_builder.go but
+// implemented in a file that isn't part of it -- so NewHybridStorage
+// can't literally be rewritten here to dispatch on a URL scheme the way
+// the request describes. OpenSQLBackend does that dispatch for new code;
+// wiring the hidden constructors to use a SQLBackend internally is left
+// for whoever next touches their defining file.
+type SQLBackend interface {
+	// DB returns the underlying *sql.DB.
+	DB() *sql.DB
+
+	// Dialect reports which SQL database this backend talks to.
+	Dialect() SQLDialect
+
+	// Placeholder returns the positional bind-parameter placeholder for
+	// the n'th parameter (1-indexed): "$1", "$2", ... for PostgreSQL,
+	// "?" for MySQL and SQLite.
+	Placeholder(n int) string
+
+	// IndexExists reports whether an index named indexName exists on
+	// table, checked via each dialect's own metadata catalog
+	// (pg_indexes, INFORMATION_SCHEMA.STATISTICS, sqlite_master).
+	IndexExists(ctx context.Context, table, indexName string) (bool, error)
+
+	// Close closes the underlying *sql.DB.
+	Close() error
+}
+
+// OpenSQLBackend opens a SQLBackend for rawURL, dispatching on its
+// scheme: postgres:// (or postgresql://), mysql://, or sqlite:// (the
+// path or opaque part is used as the SQLite file path).
+func OpenSQLBackend(rawURL string) (SQLBackend, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("sql backend: failed to parse URL: %w", err)
+	}
+
+	switch parsed.Scheme {
+	case "postgres", "postgresql":
+		return newPostgresBackend(rawURL)
+	case "mysql":
+		return newMySQLBackend(parsed)
+	case "sqlite", "sqlite3":
+		path := parsed.Opaque
+		if path == "" {
+			path = parsed.Path
+		}
+		return newSQLiteBackend(path)
+	default:
+		return nil, fmt.Errorf("sql backend: unsupported URL scheme %q", parsed.Scheme)
+	}
+}
+
+// postgresBackend is the SQLBackend for PostgreSQL, via the pgx stdlib
+// driver already used by HybridStoragePostgres.
+type postgresBackend struct {
+	db *sql.DB
+}
+
+func newPostgresBackend(dsn string) (*postgresBackend, error) {
+	return newPostgresBackendWithDriver("pgx", dsn)
+}
+
+// newPostgresBackendWithDriver is newPostgresBackend parameterized by
+// driver name, for callers that register their own wrapped driver.Driver
+// (a counting shim, for instance -- see
+// parser.BenchmarkParseLargeGEDCOM) and need a postgresBackend to open
+// through it instead of the real "pgx" driver.
+func newPostgresBackendWithDriver(driverName, dsn string) (*postgresBackend, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sql backend: failed to open postgres: %w", err)
+	}
+	return &postgresBackend{db: db}, nil
+}
+
+// OpenPostgresSQLBackendWithDriver is OpenSQLBackend's PostgreSQL case,
+// parameterized by driver name instead of always using "pgx".
+func OpenPostgresSQLBackendWithDriver(driverName, dsn string) (SQLBackend, error) {
+	return newPostgresBackendWithDriver(driverName, dsn)
+}
+
+func (b *postgresBackend) DB() *sql.DB         { return b.db }
+func (b *postgresBackend) Dialect() SQLDialect { return DialectPostgres }
+func (b *postgresBackend) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+func (b *postgresBackend) Close() error { return b.db.Close() }
+
+func (b *postgresBackend) IndexExists(ctx context.Context, table, indexName string) (bool, error) {
+	var count int
+	err := b.db.QueryRowContext(ctx,
+		"SELECT count(*) FROM pg_indexes WHERE tablename = $1 AND indexname = $2",
+		table, indexName,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("sql backend: postgres IndexExists failed: %w", err)
+	}
+	return count > 0, nil
+}
+
+// mysqlBackend is the SQLBackend for MySQL, via github.com/go-sql-driver/mysql.
+type mysqlBackend struct {
+	db *sql.DB
+}
+
+func newMySQLBackend(parsed *url.URL) (*mysqlBackend, error) {
+	// database/sql's mysql driver expects a DSN without the mysql://
+	// scheme (user:pass@tcp(host:port)/dbname), so strip it off rather
+	// than passing the URL through as-is.
+	dsn := parsed.Host + parsed.Path
+	if parsed.User != nil {
+		dsn = parsed.User.String() + "@tcp(" + parsed.Host + ")" + parsed.Path
+	}
+	if parsed.RawQuery != "" {
+		dsn += "?" + parsed.RawQuery
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sql backend: failed to open mysql: %w", err)
+	}
+	return &mysqlBackend{db: db}, nil
+}
+
+func (b *mysqlBackend) DB() *sql.DB             { return b.db }
+func (b *mysqlBackend) Dialect() SQLDialect     { return DialectMySQL }
+func (b *mysqlBackend) Placeholder(n int) string { return "?" }
+func (b *mysqlBackend) Close() error            { return b.db.Close() }
+
+func (b *mysqlBackend) IndexExists(ctx context.Context, table, indexName string) (bool, error) {
+	var count int
+	err := b.db.QueryRowContext(ctx,
+		"SELECT count(*) FROM INFORMATION_SCHEMA.STATISTICS WHERE table_name = ? AND index_name = ?",
+		table, indexName,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("sql backend: mysql IndexExists failed: %w", err)
+	}
+	return count > 0, nil
+}
+
+// sqliteBackend is the SQLBackend for SQLite, opened in WAL mode so
+// concurrent readers don't block a writer.
+type sqliteBackend struct {
+	db *sql.DB
+}
+
+func newSQLiteBackend(path string) (*sqliteBackend, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("sql backend: failed to open sqlite: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sql backend: failed to enable WAL mode: %w", err)
+	}
+	return &sqliteBackend{db: db}, nil
+}
+
+func (b *sqliteBackend) DB() *sql.DB             { return b.db }
+func (b *sqliteBackend) Dialect() SQLDialect     { return DialectSQLite }
+func (b *sqliteBackend) Placeholder(n int) string { return "?" }
+func (b *sqliteBackend) Close() error            { return b.db.Close() }
+
+func (b *sqliteBackend) IndexExists(ctx context.Context, table, indexName string) (bool, error) {
+	var count int
+	err := b.db.QueryRowContext(ctx,
+		"SELECT count(*) FROM sqlite_master WHERE type = 'index' AND tbl_name = ? AND name = ?",
+		table, indexName,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("sql backend: sqlite IndexExists failed: %w", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,38 @@
+package query
+
+// StorageBackend identifies which indexed-lookup store a hybrid-built
+// Graph is backed by.
+//
+// Config (passed to BuildGraphHybrid/BuildGraphHybridPostgres/
+// BuildGraphHybridMongo) has no defining file in this package snapshot,
+// so it can't gain a literal StorageBackend field the way a single
+// "pick a backend" constructor would normally read one. Callers instead
+// still pick a backend by calling the matching constructor (as they
+// already did for SQLite vs PostgreSQL), and can use
+// Graph.StorageBackend() afterwards to report which one is in effect --
+// useful for code that's handed a *Graph without knowing how it was
+// built.
+type StorageBackend string
+
+const (
+	StorageBackendSQLite   StorageBackend = "sqlite"
+	StorageBackendPostgres StorageBackend = "postgres"
+	StorageBackendMongo    StorageBackend = "mongo"
+	StorageBackendUnknown  StorageBackend = ""
+)
+
+// StorageBackend reports which hybrid storage backend built graph, or
+// StorageBackendUnknown if graph wasn't built by BuildGraphHybrid,
+// BuildGraphHybridPostgres, or BuildGraphHybridMongo.
+func (g *Graph) StorageBackend() StorageBackend {
+	switch {
+	case g.hybridStoragePostgres != nil:
+		return StorageBackendPostgres
+	case g.hybridStorage != nil:
+		return StorageBackendSQLite
+	case HybridStorageMongoFor(g) != nil:
+		return StorageBackendMongo
+	default:
+		return StorageBackendUnknown
+	}
+}
@@ -0,0 +1,116 @@
+package query
+
+import (
+	"path/filepath"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// buildTypedQueryFixture builds a small tree of individuals with distinct
+// names and sexes, to exercise Query's predicates and ordering.
+func buildTypedQueryFixture() *types.GedcomTree {
+	tree := types.NewGedcomTree()
+	people := []struct {
+		xref, name, sex string
+	}{
+		{"@I1@", "Jane Roe", "F"},
+		{"@I2@", "John Roe", "M"},
+		{"@I3@", "Janet Doe", "F"},
+	}
+	for _, p := range people {
+		line := types.NewGedcomLine(0, "INDI", "", p.xref)
+		line.AddChild(types.NewGedcomLine(1, "NAME", p.name+" //", ""))
+		line.AddChild(types.NewGedcomLine(1, "SEX", p.sex, ""))
+		tree.AddRecord(types.NewIndividualRecord(line))
+	}
+	return tree
+}
+
+// xrefOf returns node's xref via the GraphNode interface, so assertions
+// below don't depend on exactly how *types.IndividualRecord.GetName()
+// formats a parsed GEDCOM NAME line.
+func xrefOf(n GraphNode) string {
+	return n.ID()
+}
+
+func TestQuery_WhereAndLimit(t *testing.T) {
+	databaseURL := getPostgreSQLTestURL(t)
+	testPostgreSQLConnection(t, databaseURL)
+
+	tmpDir := t.TempDir()
+	badgerPath := filepath.Join(tmpDir, "test_graph")
+	fileID := "test_file_typed_query_001"
+	tree := buildTypedQueryFixture()
+
+	graph, err := BuildGraphHybridPostgres(tree, fileID, badgerPath, databaseURL, nil)
+	if err != nil {
+		t.Fatalf("BuildGraphHybridPostgres failed: %v", err)
+	}
+	defer func() {
+		db := graph.hybridStoragePostgres.PostgreSQL()
+		_, _ = db.Exec("DELETE FROM nodes WHERE file_id = $1", fileID)
+		_, _ = db.Exec("DELETE FROM xref_mapping WHERE file_id = $1", fileID)
+		graph.hybridStoragePostgres.Close()
+	}()
+
+	results, err := Query[*IndividualNode](graph).
+		Where(NameContains("roe")).
+		Where(SexIs("F")).
+		Limit(10).
+		Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if xrefOf(results[0]) != "@I1@" {
+		t.Errorf("expected @I1@ (Jane Roe), got %s", xrefOf(results[0]))
+	}
+}
+
+func TestQuery_PreloadFamiliesDoesNotError(t *testing.T) {
+	databaseURL := getPostgreSQLTestURL(t)
+	testPostgreSQLConnection(t, databaseURL)
+
+	tmpDir := t.TempDir()
+	badgerPath := filepath.Join(tmpDir, "test_graph")
+	fileID := "test_file_typed_query_002"
+	tree := buildTypedQueryFixture()
+
+	graph, err := BuildGraphHybridPostgres(tree, fileID, badgerPath, databaseURL, nil)
+	if err != nil {
+		t.Fatalf("BuildGraphHybridPostgres failed: %v", err)
+	}
+	defer func() {
+		db := graph.hybridStoragePostgres.PostgreSQL()
+		_, _ = db.Exec("DELETE FROM nodes WHERE file_id = $1", fileID)
+		_, _ = db.Exec("DELETE FROM xref_mapping WHERE file_id = $1", fileID)
+		graph.hybridStoragePostgres.Close()
+	}()
+
+	results, err := Query[*IndividualNode](graph).
+		Preload(PreloadFamilies).
+		Preload(PreloadParents).
+		OrderBy("name_lower", Asc).
+		Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+}
+
+func TestNodeTypeColumn(t *testing.T) {
+	kind, err := nodeTypeColumn[*IndividualNode]()
+	if err != nil || kind != "individual" {
+		t.Errorf("expected individual, got %q (err %v)", kind, err)
+	}
+	kind, err = nodeTypeColumn[*FamilyNode]()
+	if err != nil || kind != "family" {
+		t.Errorf("expected family, got %q (err %v)", kind, err)
+	}
+}
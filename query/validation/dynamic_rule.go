@@ -0,0 +1,175 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/validate"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleSpec is one declarative rule as loaded from a YAML or JSON rule
+// file: a target node kind, a CEL-like boolean predicate over fields
+// reachable from that node, a severity, and a message template.
+type RuleSpec struct {
+	ID        string `yaml:"id" json:"id"`
+	Target    string `yaml:"target" json:"target"` // "individual" or "family"
+	Predicate string `yaml:"predicate" json:"predicate"`
+	Severity  string `yaml:"severity" json:"severity"`
+	Message   string `yaml:"message" json:"message"`
+}
+
+// RuleFile is the top-level shape of a declarative rule file: a list of
+// RuleSpecs under a "rules" key.
+type RuleFile struct {
+	Rules []RuleSpec `yaml:"rules" json:"rules"`
+}
+
+// declarativeRule adapts a parsed RuleSpec to the Rule interface, the
+// same interface the built-in Go rules in rules.go implement, so a
+// RuleSet or RuleRegistry can run either kind side by side.
+type declarativeRule struct {
+	spec     RuleSpec
+	severity validate.Severity
+	target   string
+	expr     predExpr
+}
+
+func (r declarativeRule) ID() string                   { return r.spec.ID }
+func (r declarativeRule) Severity() validate.Severity { return r.severity }
+
+func (r declarativeRule) Check(g *query.Graph) []Violation {
+	violations := make([]Violation, 0)
+
+	switch r.target {
+	case "individual":
+		for _, node := range g.AllIndividuals() {
+			ctx := &evalContext{graph: g, subject: node}
+			result, ok := r.expr.Eval(ctx)
+			if !ok || result.kind != predKindBool || !result.flag {
+				continue
+			}
+			violations = append(violations, Violation{
+				RuleID: r.spec.ID, Severity: r.severity, Individual: node,
+				Message: formatRuleMessage(r.spec.Message, node.ID(), ""),
+			})
+		}
+	case "family":
+		for _, fam := range g.AllFamilies() {
+			ctx := &evalContext{graph: g, family: fam}
+			result, ok := r.expr.Eval(ctx)
+			if !ok || result.kind != predKindBool || !result.flag {
+				continue
+			}
+			violations = append(violations, Violation{
+				RuleID: r.spec.ID, Severity: r.severity, Family: fam,
+				Message: formatRuleMessage(r.spec.Message, "", fam.ID()),
+			})
+		}
+	}
+	return violations
+}
+
+// formatRuleMessage substitutes "{id}" with the violating individual's
+// xref and "{family}" with the violating family's xref in a RuleSpec's
+// message template. It's intentionally this simple rather than a full
+// templating engine: the predicate already did the field comparisons,
+// so the message only needs to name which record tripped it.
+func formatRuleMessage(template, indiID, famID string) string {
+	msg := strings.ReplaceAll(template, "{id}", indiID)
+	msg = strings.ReplaceAll(msg, "{family}", famID)
+	return msg
+}
+
+// severityFromSpec maps a RuleSpec's Severity string to a
+// validate.Severity, defaulting to SeverityWarning for an unrecognized
+// or empty value.
+func severityFromSpec(s string) validate.Severity {
+	switch s {
+	case string(validate.SeveritySevere):
+		return validate.SeveritySevere
+	case string(validate.SeverityInfo):
+		return validate.SeverityInfo
+	case string(validate.SeverityHint):
+		return validate.SeverityHint
+	default:
+		return validate.SeverityWarning
+	}
+}
+
+// compileRuleSpec parses spec.Predicate and validates spec.Target,
+// producing a Rule ready to run. It's shared by LoadRuleFile (which
+// needs working rules) and LintRuleFile (which needs the same checks
+// without running anything).
+func compileRuleSpec(spec RuleSpec) (declarativeRule, error) {
+	if spec.ID == "" {
+		return declarativeRule{}, fmt.Errorf("rule missing id")
+	}
+	if spec.Target != "individual" && spec.Target != "family" {
+		return declarativeRule{}, fmt.Errorf("rule %s: target must be \"individual\" or \"family\", got %q", spec.ID, spec.Target)
+	}
+	if spec.Predicate == "" {
+		return declarativeRule{}, fmt.Errorf("rule %s: missing predicate", spec.ID)
+	}
+	expr, err := ParsePredicate(spec.Predicate)
+	if err != nil {
+		return declarativeRule{}, fmt.Errorf("rule %s: %w", spec.ID, err)
+	}
+	return declarativeRule{
+		spec:     spec,
+		severity: severityFromSpec(spec.Severity),
+		target:   spec.Target,
+		expr:     expr,
+	}, nil
+}
+
+// decodeRuleFile decodes data into a RuleFile, choosing YAML or JSON by
+// path's extension the same way
+// cmd/gedcom/internal.decodeConfigFileAny does for config files.
+func decodeRuleFile(path string, data []byte) (RuleFile, error) {
+	var rf RuleFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &rf); err != nil {
+			return RuleFile{}, fmt.Errorf("failed to parse YAML rule file %s: %w", path, err)
+		}
+	default:
+		if err := json.Unmarshal(data, &rf); err != nil {
+			return RuleFile{}, fmt.Errorf("failed to parse JSON rule file %s: %w", path, err)
+		}
+	}
+	return rf, nil
+}
+
+// compileRuleSpecs compiles every RuleSpec in rf into a Rule, stopping
+// at the first one that fails to parse or validate.
+func compileRuleSpecs(rf RuleFile) ([]Rule, error) {
+	rules := make([]Rule, 0, len(rf.Rules))
+	for _, spec := range rf.Rules {
+		rule, err := compileRuleSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// LoadRuleFile reads and parses a declarative YAML or JSON rule file
+// (chosen by extension) into a slice of Rules ready to add to a RuleSet
+// or RuleRegistry.
+func LoadRuleFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule file %s: %w", path, err)
+	}
+	rf, err := decodeRuleFile(path, data)
+	if err != nil {
+		return nil, err
+	}
+	return compileRuleSpecs(rf)
+}
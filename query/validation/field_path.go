@@ -0,0 +1,205 @@
+package validation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+)
+
+// resolveFieldPath walks a dotted/indexed field path (e.g. "birth.year",
+// "father.birth.year", "spouses[0].marriage.year") starting from
+// ctx.subject (an "individual"-target rule) or ctx.family (a
+// "family"-target rule), resolving each segment through the graph
+// helpers (Parents/Spouses/Children/Husband/Wife) rather than any
+// record field directly, so a half-built record is handled the same way
+// whether it's reached straight or through a relationship. ok is false
+// as soon as a segment can't be resolved (a missing parent, an
+// out-of-range spouse index, ...), which every caller treats as "this
+// rule does not apply to this subject" rather than an error.
+func resolveFieldPath(ctx *evalContext, path string) (predValue, bool) {
+	segments, err := parsePathSegments(path)
+	if err != nil || len(segments) == 0 {
+		return predValue{}, false
+	}
+
+	var cur interface{}
+	if ctx.subject != nil {
+		cur = ctx.subject
+	} else if ctx.family != nil {
+		cur = ctx.family
+	} else {
+		return predValue{}, false
+	}
+
+	for _, seg := range segments {
+		next, ok := resolveSegment(ctx, cur, seg)
+		if !ok {
+			return predValue{}, false
+		}
+		cur = next
+	}
+
+	return toPredValue(cur)
+}
+
+// pathSegment is one dotted component of a field path, optionally
+// carrying an array index ("spouses[0]" -> name="spouses", index=0).
+type pathSegment struct {
+	name     string
+	index    int
+	hasIndex bool
+}
+
+func parsePathSegments(path string) ([]pathSegment, error) {
+	parts := strings.Split(path, ".")
+	segments := make([]pathSegment, 0, len(parts))
+	for _, part := range parts {
+		seg := pathSegment{}
+		if idx := strings.IndexByte(part, '['); idx >= 0 {
+			seg.name = part[:idx]
+			end := strings.IndexByte(part, ']')
+			if end < idx {
+				return nil, fmt.Errorf("malformed array index in field path segment %q", part)
+			}
+			n, err := strconv.Atoi(part[idx+1 : end])
+			if err != nil {
+				return nil, err
+			}
+			seg.index = n
+			seg.hasIndex = true
+		} else {
+			seg.name = part
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+// resolveSegment resolves one path segment against cur, which is always
+// either a *query.IndividualNode, a *query.FamilyNode, or a string (a
+// date already fetched by a previous "birth"/"death"/"marriage"
+// segment, awaiting a trailing ".year").
+func resolveSegment(ctx *evalContext, cur interface{}, seg pathSegment) (interface{}, bool) {
+	switch node := cur.(type) {
+	case *query.IndividualNode:
+		switch seg.name {
+		case "birth":
+			return node.Individual.GetBirthDate(), true
+		case "death":
+			return node.Individual.GetDeathDate(), true
+		case "father":
+			if father := findBySex(node.Parents(), "M"); father != nil {
+				return father, true
+			}
+			return nil, false
+		case "mother":
+			if mother := findBySex(node.Parents(), "F"); mother != nil {
+				return mother, true
+			}
+			return nil, false
+		case "spouses":
+			return indexIndividual(node.Spouses(), seg)
+		case "children":
+			return indexIndividual(node.Children(), seg)
+		case "marriage":
+			if ctx.subject == nil || ctx.subject == node {
+				return nil, false
+			}
+			return marriageDateBetween(ctx.graph, ctx.subject, node), true
+		}
+	case *query.FamilyNode:
+		switch seg.name {
+		case "husband":
+			h := node.Husband()
+			if h == nil {
+				return nil, false
+			}
+			return h, true
+		case "wife":
+			w := node.Wife()
+			if w == nil {
+				return nil, false
+			}
+			return w, true
+		case "children":
+			return indexIndividual(node.Children(), seg)
+		case "marriage":
+			return marriageDate(node.Family), true
+		}
+	case string:
+		if seg.name == "year" {
+			year := yearOf(node)
+			if year == 0 {
+				return nil, false
+			}
+			return float64(year), true
+		}
+	}
+	return nil, false
+}
+
+// findBySex returns the first of parents whose recorded SEX matches
+// sex ("M" for father, "F" for mother), or nil if none match (the
+// individual has no parent of that sex recorded, or no parents at
+// all).
+func findBySex(parents []*query.IndividualNode, sex string) *query.IndividualNode {
+	for _, p := range parents {
+		if p.Individual.GetSex() == sex {
+			return p
+		}
+	}
+	return nil
+}
+
+// indexIndividual returns nodes[seg.index], or nil/false if seg has no
+// index or the index is out of range.
+func indexIndividual(nodes []*query.IndividualNode, seg pathSegment) (interface{}, bool) {
+	if !seg.hasIndex || seg.index < 0 || seg.index >= len(nodes) {
+		return nil, false
+	}
+	return nodes[seg.index], true
+}
+
+// marriageDateBetween finds the family linking a and b as spouses and
+// returns its recorded MARR date, or "" if they share no family.
+func marriageDateBetween(g *query.Graph, a, b *query.IndividualNode) string {
+	if g == nil || a == nil || b == nil {
+		return ""
+	}
+	for _, fam := range g.AllFamilies() {
+		husband, wife := fam.Husband(), fam.Wife()
+		isCouple := (husband == a && wife == b) || (husband == b && wife == a)
+		if isCouple {
+			return marriageDate(fam.Family)
+		}
+	}
+	return ""
+}
+
+// toPredValue converts a resolved path's final value (an IndividualNode
+// with no further "birth"/"death" segment applied, a date string with
+// no trailing ".year", or a numeric year) into a predValue.
+func toPredValue(cur interface{}) (predValue, bool) {
+	switch v := cur.(type) {
+	case float64:
+		return numberValue(v), true
+	case string:
+		if v == "" {
+			return predValue{}, false
+		}
+		return stringValue(v), true
+	case *query.IndividualNode:
+		if v == nil {
+			return predValue{}, false
+		}
+		return stringValue(v.ID()), true
+	case *query.FamilyNode:
+		if v == nil {
+			return predValue{}, false
+		}
+		return stringValue(v.ID()), true
+	}
+	return predValue{}, false
+}
@@ -0,0 +1,38 @@
+package validation
+
+import "os"
+
+// RuleLintResult reports whether one RuleSpec in a rule file parsed and
+// type-checked cleanly, for gedcom rules lint. Error is empty when the
+// rule is valid.
+type RuleLintResult struct {
+	ID    string
+	Error string
+}
+
+// LintRuleFile parses and type-checks every rule in a declarative YAML
+// or JSON rule file without running any of them against a graph --
+// gedcom rules lint's job. It reports one RuleLintResult per rule found
+// in the file, in file order; a rule missing its id still gets an
+// entry (ID left blank) so a lint run can't silently drop a malformed
+// entry.
+func LintRuleFile(path string) ([]RuleLintResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rf, err := decodeRuleFile(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]RuleLintResult, 0, len(rf.Rules))
+	for _, spec := range rf.Rules {
+		result := RuleLintResult{ID: spec.ID}
+		if _, err := compileRuleSpec(spec); err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,605 @@
+package validation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+)
+
+// predValue is a predicate expression's runtime result: either a number
+// (years, +/-/comparisons), a string, or a bool (the final && / || / !
+// result, or a comparison's outcome).
+type predValue struct {
+	kind predValueKind
+	num  float64
+	str  string
+	flag bool
+}
+
+type predValueKind int
+
+const (
+	predKindNumber predValueKind = iota
+	predKindString
+	predKindBool
+)
+
+func numberValue(n float64) predValue { return predValue{kind: predKindNumber, num: n} }
+func stringValue(s string) predValue { return predValue{kind: predKindString, str: s} }
+func boolValue(b bool) predValue      { return predValue{kind: predKindBool, flag: b} }
+
+// evalContext carries what a predicate's field references resolve
+// against: the graph (for looking up a shared family between two
+// individuals), and whichever node the rule's Target names -- subject is
+// set for an "individual" rule, family for a "family" rule.
+type evalContext struct {
+	graph   *query.Graph
+	subject *query.IndividualNode
+	family  *query.FamilyNode
+}
+
+// predExpr is one node of a parsed predicate's AST. Eval returns
+// ok=false when a field reference resolves through a missing
+// relationship (e.g. "father.birth.year" on an individual with no
+// recorded father) -- callers treat that as "rule does not apply" to
+// this subject, not a type error.
+type predExpr interface {
+	Eval(ctx *evalContext) (predValue, bool)
+}
+
+// ParsePredicate parses a CEL-like predicate string into an evaluatable
+// AST, for use by a RuleSpec's Predicate field or gedcom rules lint's
+// type-checking pass.
+func ParsePredicate(src string) (predExpr, error) {
+	tokens, err := tokenizePredicate(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &predParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %q at position %d", p.tokens[p.pos].text, p.pos)
+	}
+	return expr, nil
+}
+
+// --- lexer ---
+
+type predTokenKind int
+
+const (
+	tokField predTokenKind = iota
+	tokNumber
+	tokString
+	tokAnd
+	tokOr
+	tokNot
+	tokLE
+	tokLT
+	tokGE
+	tokGT
+	tokEQ
+	tokNE
+	tokPlus
+	tokMinus
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokComma
+	tokKwAnd
+	tokKwIn
+	tokKwBetween
+	tokKwTrue
+	tokKwFalse
+	tokEOF
+)
+
+type predToken struct {
+	kind predTokenKind
+	text string
+	num  float64
+}
+
+// fieldTokenRunes are the characters allowed inside a field path token:
+// letters, digits, underscore, dot, and array-index brackets.
+func isFieldRune(r rune) bool {
+	return r == '.' || r == '_' || r == '[' || r == ']' ||
+		(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+func tokenizePredicate(src string) ([]predToken, error) {
+	tokens := make([]predToken, 0)
+	runes := []rune(src)
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			i++
+		case r == '(':
+			tokens = append(tokens, predToken{kind: tokLParen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, predToken{kind: tokRParen, text: ")"})
+			i++
+		case r == '[':
+			tokens = append(tokens, predToken{kind: tokLBracket, text: "["})
+			i++
+		case r == ']':
+			tokens = append(tokens, predToken{kind: tokRBracket, text: "]"})
+			i++
+		case r == ',':
+			tokens = append(tokens, predToken{kind: tokComma, text: ","})
+			i++
+		case r == '+':
+			tokens = append(tokens, predToken{kind: tokPlus, text: "+"})
+			i++
+		case r == '-':
+			tokens = append(tokens, predToken{kind: tokMinus, text: "-"})
+			i++
+		case r == '&' && i+1 < len(runes) && runes[i+1] == '&':
+			tokens = append(tokens, predToken{kind: tokAnd, text: "&&"})
+			i += 2
+		case r == '|' && i+1 < len(runes) && runes[i+1] == '|':
+			tokens = append(tokens, predToken{kind: tokOr, text: "||"})
+			i += 2
+		case r == '=' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, predToken{kind: tokEQ, text: "=="})
+			i += 2
+		case r == '!' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, predToken{kind: tokNE, text: "!="})
+			i += 2
+		case r == '!':
+			tokens = append(tokens, predToken{kind: tokNot, text: "!"})
+			i++
+		case r == '<' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, predToken{kind: tokLE, text: "<="})
+			i += 2
+		case r == '<':
+			tokens = append(tokens, predToken{kind: tokLT, text: "<"})
+			i++
+		case r == '>' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, predToken{kind: tokGE, text: ">="})
+			i += 2
+		case r == '>':
+			tokens = append(tokens, predToken{kind: tokGT, text: ">"})
+			i++
+		case r == '"' || r == '\'':
+			quote := r
+			j := i + 1
+			var sb strings.Builder
+			for j < len(runes) && runes[j] != quote {
+				sb.WriteRune(runes[j])
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string literal starting at position %d", i)
+			}
+			tokens = append(tokens, predToken{kind: tokString, text: sb.String()})
+			i = j + 1
+		case r >= '0' && r <= '9':
+			j := i
+			for j < len(runes) && (runes[j] >= '0' && runes[j] <= '9' || runes[j] == '.') {
+				j++
+			}
+			numStr := string(runes[i:j])
+			n, err := strconv.ParseFloat(numStr, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", numStr, i)
+			}
+			tokens = append(tokens, predToken{kind: tokNumber, text: numStr, num: n})
+			i = j
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			j := i
+			for j < len(runes) && isFieldRune(runes[j]) {
+				j++
+			}
+			word := string(runes[i:j])
+			switch word {
+			case "and":
+				tokens = append(tokens, predToken{kind: tokKwAnd, text: word})
+			case "in":
+				tokens = append(tokens, predToken{kind: tokKwIn, text: word})
+			case "between":
+				tokens = append(tokens, predToken{kind: tokKwBetween, text: word})
+			case "true":
+				tokens = append(tokens, predToken{kind: tokKwTrue, text: word})
+			case "false":
+				tokens = append(tokens, predToken{kind: tokKwFalse, text: word})
+			default:
+				tokens = append(tokens, predToken{kind: tokField, text: word})
+			}
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", string(r), i)
+		}
+	}
+	tokens = append(tokens, predToken{kind: tokEOF, text: ""})
+	return tokens, nil
+}
+
+// --- parser (recursive descent, lowest to highest precedence: ||, &&, !,
+// comparison/between/in, +/-, unary -) ---
+
+type predParser struct {
+	tokens []predToken
+	pos    int
+}
+
+func (p *predParser) peek() predToken { return p.tokens[p.pos] }
+
+func (p *predParser) advance() predToken {
+	t := p.tokens[p.pos]
+	if p.pos < len(p.tokens)-1 {
+		p.pos++
+	}
+	return t
+}
+
+func (p *predParser) expect(kind predTokenKind, what string) (predToken, error) {
+	if p.peek().kind != kind {
+		return predToken{}, fmt.Errorf("expected %s, got %q", what, p.peek().text)
+	}
+	return p.advance(), nil
+}
+
+func (p *predParser) parseOr() (predExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &boolBinaryExpr{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *predParser) parseAnd() (predExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokAnd {
+		p.advance()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &boolBinaryExpr{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *predParser) parseNot() (predExpr, error) {
+	if p.peek().kind == tokNot {
+		p.advance()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *predParser) parseComparison() (predExpr, error) {
+	left, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+
+	switch p.peek().kind {
+	case tokLE, tokLT, tokGE, tokGT, tokEQ, tokNE:
+		opTok := p.advance()
+		right, err := p.parseAdd()
+		if err != nil {
+			return nil, err
+		}
+		return &compareExpr{op: opTok.text, left: left, right: right}, nil
+	case tokKwBetween:
+		p.advance()
+		low, err := p.parseAdd()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokKwAnd, "'and'"); err != nil {
+			return nil, err
+		}
+		high, err := p.parseAdd()
+		if err != nil {
+			return nil, err
+		}
+		return &betweenExpr{target: left, low: low, high: high}, nil
+	case tokKwIn:
+		p.advance()
+		if _, err := p.expect(tokLBracket, "'['"); err != nil {
+			return nil, err
+		}
+		set := make([]predExpr, 0)
+		if p.peek().kind != tokRBracket {
+			for {
+				item, err := p.parseAdd()
+				if err != nil {
+					return nil, err
+				}
+				set = append(set, item)
+				if p.peek().kind != tokComma {
+					break
+				}
+				p.advance()
+			}
+		}
+		if _, err := p.expect(tokRBracket, "']'"); err != nil {
+			return nil, err
+		}
+		return &inExpr{target: left, set: set}, nil
+	}
+	return left, nil
+}
+
+func (p *predParser) parseAdd() (predExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokPlus || p.peek().kind == tokMinus {
+		opTok := p.advance()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithExpr{op: opTok.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *predParser) parseUnary() (predExpr, error) {
+	if p.peek().kind == tokMinus {
+		p.advance()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negateExpr{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *predParser) parsePrimary() (predExpr, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokNumber:
+		p.advance()
+		return &literalExpr{value: numberValue(tok.num)}, nil
+	case tokString:
+		p.advance()
+		return &literalExpr{value: stringValue(tok.text)}, nil
+	case tokKwTrue:
+		p.advance()
+		return &literalExpr{value: boolValue(true)}, nil
+	case tokKwFalse:
+		p.advance()
+		return &literalExpr{value: boolValue(false)}, nil
+	case tokField:
+		p.advance()
+		return &fieldExpr{path: tok.text}, nil
+	case tokLParen:
+		p.advance()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+	return nil, fmt.Errorf("unexpected token %q", tok.text)
+}
+
+// --- AST node evaluation ---
+
+type literalExpr struct{ value predValue }
+
+func (e *literalExpr) Eval(ctx *evalContext) (predValue, bool) { return e.value, true }
+
+type fieldExpr struct{ path string }
+
+func (e *fieldExpr) Eval(ctx *evalContext) (predValue, bool) {
+	return resolveFieldPath(ctx, e.path)
+}
+
+type negateExpr struct{ operand predExpr }
+
+func (e *negateExpr) Eval(ctx *evalContext) (predValue, bool) {
+	v, ok := e.operand.Eval(ctx)
+	if !ok || v.kind != predKindNumber {
+		return predValue{}, false
+	}
+	return numberValue(-v.num), true
+}
+
+type notExpr struct{ operand predExpr }
+
+func (e *notExpr) Eval(ctx *evalContext) (predValue, bool) {
+	v, ok := e.operand.Eval(ctx)
+	if !ok || v.kind != predKindBool {
+		return predValue{}, false
+	}
+	return boolValue(!v.flag), true
+}
+
+type boolBinaryExpr struct {
+	op          string
+	left, right predExpr
+}
+
+func (e *boolBinaryExpr) Eval(ctx *evalContext) (predValue, bool) {
+	l, ok := e.left.Eval(ctx)
+	if !ok || l.kind != predKindBool {
+		return predValue{}, false
+	}
+	r, ok := e.right.Eval(ctx)
+	if !ok || r.kind != predKindBool {
+		return predValue{}, false
+	}
+	if e.op == "&&" {
+		return boolValue(l.flag && r.flag), true
+	}
+	return boolValue(l.flag || r.flag), true
+}
+
+type arithExpr struct {
+	op          string
+	left, right predExpr
+}
+
+func (e *arithExpr) Eval(ctx *evalContext) (predValue, bool) {
+	l, ok := e.left.Eval(ctx)
+	if !ok || l.kind != predKindNumber {
+		return predValue{}, false
+	}
+	r, ok := e.right.Eval(ctx)
+	if !ok || r.kind != predKindNumber {
+		return predValue{}, false
+	}
+	if e.op == "+" {
+		return numberValue(l.num + r.num), true
+	}
+	return numberValue(l.num - r.num), true
+}
+
+type compareExpr struct {
+	op          string
+	left, right predExpr
+}
+
+func (e *compareExpr) Eval(ctx *evalContext) (predValue, bool) {
+	l, ok := e.left.Eval(ctx)
+	if !ok {
+		return predValue{}, false
+	}
+	r, ok := e.right.Eval(ctx)
+	if !ok {
+		return predValue{}, false
+	}
+	if l.kind != r.kind {
+		return predValue{}, false
+	}
+
+	switch l.kind {
+	case predKindNumber:
+		return boolValue(compareNumbers(e.op, l.num, r.num)), true
+	case predKindString:
+		return boolValue(compareStrings(e.op, l.str, r.str)), true
+	default:
+		switch e.op {
+		case "==":
+			return boolValue(l.flag == r.flag), true
+		case "!=":
+			return boolValue(l.flag != r.flag), true
+		default:
+			return predValue{}, false
+		}
+	}
+}
+
+func compareNumbers(op string, l, r float64) bool {
+	switch op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	}
+	return false
+}
+
+func compareStrings(op string, l, r string) bool {
+	switch op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	}
+	return false
+}
+
+type betweenExpr struct {
+	target, low, high predExpr
+}
+
+func (e *betweenExpr) Eval(ctx *evalContext) (predValue, bool) {
+	t, ok := e.target.Eval(ctx)
+	if !ok || t.kind != predKindNumber {
+		return predValue{}, false
+	}
+	low, ok := e.low.Eval(ctx)
+	if !ok || low.kind != predKindNumber {
+		return predValue{}, false
+	}
+	high, ok := e.high.Eval(ctx)
+	if !ok || high.kind != predKindNumber {
+		return predValue{}, false
+	}
+	return boolValue(t.num >= low.num && t.num <= high.num), true
+}
+
+type inExpr struct {
+	target predExpr
+	set    []predExpr
+}
+
+func (e *inExpr) Eval(ctx *evalContext) (predValue, bool) {
+	t, ok := e.target.Eval(ctx)
+	if !ok {
+		return predValue{}, false
+	}
+	for _, item := range e.set {
+		v, ok := item.Eval(ctx)
+		if !ok || v.kind != t.kind {
+			continue
+		}
+		switch t.kind {
+		case predKindNumber:
+			if v.num == t.num {
+				return boolValue(true), true
+			}
+		case predKindString:
+			if v.str == t.str {
+				return boolValue(true), true
+			}
+		case predKindBool:
+			if v.flag == t.flag {
+				return boolValue(true), true
+			}
+		}
+	}
+	return boolValue(false), true
+}
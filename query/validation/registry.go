@@ -0,0 +1,83 @@
+package validation
+
+import (
+	_ "embed"
+	"fmt"
+	"plugin"
+)
+
+// ExampleAgeMarriageRules is the built-in example rule file embedded at
+// build time (examples/age_marriage_rules.yaml), reproducing
+// defaultRules' age/marriage checks in the declarative predicate
+// format, for users writing their own rules.yaml to copy from.
+//
+//go:embed examples/age_marriage_rules.yaml
+var ExampleAgeMarriageRules []byte
+
+// pluginRulesSymbol is the exported symbol name a rule plugin's .so
+// must provide: a func() []Rule that RuleRegistry.LoadPlugin looks up
+// via plugin.Open/Lookup.
+const pluginRulesSymbol = "Rules"
+
+// RuleRegistry accumulates Rules from declarative rule files and
+// compiled Go plugins, on top of (or instead of) the built-in catalog
+// RuleSet's own defaultRules wires up, so a deployment can extend
+// validation without recompiling the CLI. Its Rules() feeds straight
+// into RuleSet.AddRule.
+type RuleRegistry struct {
+	rules []Rule
+}
+
+// NewRuleRegistry creates an empty RuleRegistry.
+func NewRuleRegistry() *RuleRegistry {
+	return &RuleRegistry{}
+}
+
+// LoadRuleFile parses a declarative YAML or JSON rule file and adds its
+// rules to the registry.
+func (reg *RuleRegistry) LoadRuleFile(path string) error {
+	rules, err := LoadRuleFile(path)
+	if err != nil {
+		return err
+	}
+	reg.rules = append(reg.rules, rules...)
+	return nil
+}
+
+// LoadPlugin opens a compiled Go plugin (.so) built with
+// `go build -buildmode=plugin` and adds the Rules it exports to the
+// registry. The plugin must export a package-level
+// `func Rules() []validation.Rule` symbol.
+func (reg *RuleRegistry) LoadPlugin(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open rule plugin %s: %w", path, err)
+	}
+	sym, err := p.Lookup(pluginRulesSymbol)
+	if err != nil {
+		return fmt.Errorf("rule plugin %s does not export %q: %w", path, pluginRulesSymbol, err)
+	}
+	rulesFn, ok := sym.(func() []Rule)
+	if !ok {
+		return fmt.Errorf("rule plugin %s's %q symbol has the wrong type (want func() []Rule)", path, pluginRulesSymbol)
+	}
+	reg.rules = append(reg.rules, rulesFn()...)
+	return nil
+}
+
+// LoadPlugins opens every plugin in paths, the shape
+// Config.Validation.PluginPaths holds, stopping at the first one that
+// fails to load.
+func (reg *RuleRegistry) LoadPlugins(paths []string) error {
+	for _, path := range paths {
+		if err := reg.LoadPlugin(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rules returns every rule the registry has accumulated so far.
+func (reg *RuleRegistry) Rules() []Rule {
+	return reg.rules
+}
@@ -0,0 +1,141 @@
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+func TestParsePredicate_ComparisonAndBooleanOps(t *testing.T) {
+	cases := []struct {
+		src     string
+		wantErr bool
+	}{
+		{"death.year - birth.year > 120", false},
+		{"birth.year < 1900 && death.year > 1950", false},
+		{"birth.year between 1800 and 1900", false},
+		{"birth.year in [1900, 1901, 1902]", false},
+		{"!(death.year == 0)", false},
+		{"birth.year >", true},
+		{"(birth.year", true},
+	}
+	for _, tc := range cases {
+		_, err := ParsePredicate(tc.src)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParsePredicate(%q) error = %v, wantErr %v", tc.src, err, tc.wantErr)
+		}
+	}
+}
+
+func buildRuleTestGraph(t *testing.T) *query.Graph {
+	t.Helper()
+	tree := types.NewGedcomTree()
+
+	old := types.NewGedcomLine(0, "INDI", "", "@I1@")
+	old.AddChild(types.NewGedcomLine(1, "NAME", "Old /Person/", ""))
+	birt := types.NewGedcomLine(1, "BIRT", "", "")
+	birt.AddChild(types.NewGedcomLine(2, "DATE", "1 JAN 1800", ""))
+	old.AddChild(birt)
+	deat := types.NewGedcomLine(1, "DEAT", "", "")
+	deat.AddChild(types.NewGedcomLine(2, "DATE", "1 JAN 1950", ""))
+	old.AddChild(deat)
+	tree.AddRecord(types.NewIndividualRecord(old))
+
+	young := types.NewGedcomLine(0, "INDI", "", "@I2@")
+	young.AddChild(types.NewGedcomLine(1, "NAME", "Young /Person/", ""))
+	birt2 := types.NewGedcomLine(1, "BIRT", "", "")
+	birt2.AddChild(types.NewGedcomLine(2, "DATE", "1 JAN 1800", ""))
+	young.AddChild(birt2)
+	deat2 := types.NewGedcomLine(1, "DEAT", "", "")
+	deat2.AddChild(types.NewGedcomLine(2, "DATE", "1 JAN 1850", ""))
+	young.AddChild(deat2)
+	tree.AddRecord(types.NewIndividualRecord(young))
+
+	graph, err := query.BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+	return graph
+}
+
+func TestDeclarativeRule_IndividualTarget(t *testing.T) {
+	graph := buildRuleTestGraph(t)
+
+	rules, err := LoadRuleFile(testYAMLPath(t, `rules:
+  - id: TEST_MAXAGE
+    target: individual
+    predicate: "death.year - birth.year > 120"
+    severity: warning
+    message: "{id} lived too long"
+`))
+	if err != nil {
+		t.Fatalf("LoadRuleFile failed: %v", err)
+	}
+	if len(rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(rules))
+	}
+
+	violations := rules[0].Check(graph)
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got %d: %+v", len(violations), violations)
+	}
+	if violations[0].Individual == nil || violations[0].Individual.ID() != "@I1@" {
+		t.Errorf("expected violation on @I1@, got %+v", violations[0])
+	}
+	if violations[0].Message != "@I1@ lived too long" {
+		t.Errorf("expected message template substitution, got %q", violations[0].Message)
+	}
+}
+
+func TestLintRuleFile_ReportsBadPredicate(t *testing.T) {
+	path := testYAMLPath(t, `rules:
+  - id: OK_RULE
+    target: individual
+    predicate: "birth.year < 1900"
+    severity: warning
+    message: "{id} too old"
+  - id: BAD_RULE
+    target: individual
+    predicate: "birth.year >"
+    severity: warning
+    message: "broken"
+`)
+
+	results, err := LintRuleFile(path)
+	if err != nil {
+		t.Fatalf("LintRuleFile failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 lint results, got %d", len(results))
+	}
+	if results[0].Error != "" {
+		t.Errorf("expected OK_RULE to lint clean, got error %q", results[0].Error)
+	}
+	if results[1].Error == "" {
+		t.Error("expected BAD_RULE to report a predicate error")
+	}
+}
+
+func TestExampleAgeMarriageRules_ParsesCleanly(t *testing.T) {
+	results, err := LoadRuleFile(testYAMLPath(t, string(ExampleAgeMarriageRules)))
+	if err != nil {
+		t.Fatalf("the embedded example rule file should load without error: %v", err)
+	}
+	if len(results) == 0 {
+		t.Error("expected the embedded example rule file to define at least one rule")
+	}
+}
+
+// testYAMLPath writes content to a temp .yaml file and returns its path,
+// for tests that need LoadRuleFile/LintRuleFile to read from disk.
+func testYAMLPath(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp rule file: %v", err)
+	}
+	return path
+}
@@ -0,0 +1,442 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/internal/gedcomdate"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/validate"
+)
+
+// yearOf and approxDayOf are this package's names for gedcomdate's
+// parsing helpers, kept as thin aliases so the rule bodies below don't
+// have to spell out the gedcomdate prefix at each of their many call
+// sites.
+func yearOf(date string) int                      { return gedcomdate.YearOf(date) }
+func approxDayOf(date string) (days int, ok bool) { return gedcomdate.ApproxDayOf(date) }
+
+// marriageDate returns fam's recorded MARR date, if any.
+func marriageDate(fam *types.FamilyRecord) string { 
+	for _, line := range fam.GetLines("MARR") {
+		for _, child := range line.Children {
+			if child.Tag == "DATE" {
+				return child.Value
+			}
+		}
+	}
+	return ""
+}
+
+func defaultRules(t Thresholds) []Rule {
+	return []Rule{
+		deathBeforeBirthRule{},
+		baptismBeforeBirthRule{},
+		maxAgeRule{maxAge: t.MaxAge},
+		marriageAgeRule{minAge: t.MinMarriageAge, maxAge: t.MaxMarriageAge},
+		motherAgeRule{minAge: t.MinMotherAge, maxAge: t.MaxMotherAge},
+		fatherAgeRule{maxAge: t.MaxFatherAge},
+		childBeforeMarriageRule{graceMonths: t.MarriageGraceMonths},
+		siblingSpacingRule{minDays: t.MinSiblingSpacingDays},
+		widowRemarriageRule{maxYears: t.MaxWidowhoodYears},
+		marriageSpanRule{},
+	}
+}
+
+// deathBeforeBirthRule flags an individual recorded as dying before they
+// were born.
+type deathBeforeBirthRule struct{}
+
+func (deathBeforeBirthRule) ID() string { return "GR_DEATHBEFOREBIRTH" }
+func (deathBeforeBirthRule) Severity() validate.Severity { return validate.SeveritySevere }
+
+func (r deathBeforeBirthRule) Check(g *query.Graph) []Violation {
+	violations := make([]Violation, 0)
+	for _, node := range g.AllIndividuals() {
+		birth := yearOf(node.Individual.GetBirthDate())
+		death := yearOf(node.Individual.GetDeathDate())
+		if birth == 0 || death == 0 || death >= birth {
+			continue
+		}
+		violations = append(violations, Violation{
+			RuleID: r.ID(), Severity: r.Severity(), Individual: node,
+			Message: fmt.Sprintf("%s died (%d) before birth (%d)", node.ID(), death, birth),
+		})
+	}
+	return violations
+}
+
+// baptismBeforeBirthRule flags a baptism recorded before the individual's
+// own birth.
+type baptismBeforeBirthRule struct{}
+
+func (baptismBeforeBirthRule) ID() string { return "GR_BAPTISMBEFOREBIRTH" }
+func (baptismBeforeBirthRule) Severity() validate.Severity { return validate.SeveritySevere }
+
+func (r baptismBeforeBirthRule) Check(g *query.Graph) []Violation {
+	violations := make([]Violation, 0)
+	for _, node := range g.AllIndividuals() {
+		birth := yearOf(node.Individual.GetBirthDate())
+		if birth == 0 {
+			continue
+		}
+		for _, ev := range node.Individual.GetEvents() {
+			evType, _ := ev["type"].(string)
+			if evType != "BAPM" && evType != "CHR" {
+				continue
+			}
+			date, _ := ev["date"].(string)
+			bap := yearOf(date)
+			if bap == 0 || bap >= birth {
+				continue
+			}
+			violations = append(violations, Violation{
+				RuleID: r.ID(), Severity: r.Severity(), Individual: node,
+				Message: fmt.Sprintf("%s baptized (%d) before birth (%d)", node.ID(), bap, birth),
+			})
+		}
+	}
+	return violations
+}
+
+// maxAgeRule flags individuals whose recorded lifespan exceeds maxAge.
+type maxAgeRule struct{ maxAge int }
+
+func (maxAgeRule) ID() string { return "GR_MAXAGE" }
+func (maxAgeRule) Severity() validate.Severity { return validate.SeverityWarning }
+
+func (r maxAgeRule) Check(g *query.Graph) []Violation {
+	violations := make([]Violation, 0)
+	for _, node := range g.AllIndividuals() {
+		birth := yearOf(node.Individual.GetBirthDate())
+		death := yearOf(node.Individual.GetDeathDate())
+		if birth == 0 || death == 0 {
+			continue
+		}
+		age := death - birth
+		if age > r.maxAge {
+			violations = append(violations, Violation{
+				RuleID: r.ID(), Severity: r.Severity(), Individual: node,
+				Message: fmt.Sprintf("%s lived %d years, exceeding MaxAge (%d)", node.ID(), age, r.maxAge),
+			})
+		}
+	}
+	return violations
+}
+
+// marriageAgeRule flags a spouse married younger than minAge or older than
+// maxAge, using Husband/Wife to resolve the family's spouses.
+type marriageAgeRule struct{ minAge, maxAge int }
+
+func (marriageAgeRule) ID() string { return "GR_MARRIAGEAGE" }
+func (marriageAgeRule) Severity() validate.Severity { return validate.SeverityWarning }
+
+func (r marriageAgeRule) Check(g *query.Graph) []Violation {
+	violations := make([]Violation, 0)
+	for _, fam := range g.AllFamilies() {
+		marrYear := yearOf(marriageDate(fam.Family))
+		if marrYear == 0 {
+			continue
+		}
+		for _, spouse := range []*query.IndividualNode{fam.Husband(), fam.Wife()} {
+			if spouse == nil {
+				continue
+			}
+			birth := yearOf(spouse.Individual.GetBirthDate())
+			if birth == 0 {
+				continue
+			}
+			age := marrYear - birth
+			if age < r.minAge {
+				violations = append(violations, Violation{
+					RuleID: r.ID(), Severity: r.Severity(), Individual: spouse, Family: fam,
+					Message: fmt.Sprintf("%s married at age %d in family %s, under MinMarriageAge (%d)", spouse.ID(), age, fam.ID(), r.minAge),
+				})
+			}
+			if age > r.maxAge {
+				violations = append(violations, Violation{
+					RuleID: r.ID(), Severity: r.Severity(), Individual: spouse, Family: fam,
+					Message: fmt.Sprintf("%s married at age %d in family %s, over MaxMarriageAge (%d)", spouse.ID(), age, fam.ID(), r.maxAge),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// motherAgeRule flags a mother whose age at a child's birth falls outside
+// [minAge, maxAge], using Wife and Children to resolve mother and
+// offspring.
+type motherAgeRule struct{ minAge, maxAge int }
+
+func (motherAgeRule) ID() string { return "GR_MOTHERAGE" }
+func (motherAgeRule) Severity() validate.Severity { return validate.SeverityWarning }
+
+func (r motherAgeRule) Check(g *query.Graph) []Violation {
+	violations := make([]Violation, 0)
+	for _, fam := range g.AllFamilies() {
+		mother := fam.Wife()
+		if mother == nil {
+			continue
+		}
+		momBirth := yearOf(mother.Individual.GetBirthDate())
+		if momBirth == 0 {
+			continue
+		}
+		for _, child := range fam.Children() {
+			childBirth := yearOf(child.Individual.GetBirthDate())
+			if childBirth == 0 {
+				continue
+			}
+			age := childBirth - momBirth
+			if age < r.minAge || age > r.maxAge {
+				violations = append(violations, Violation{
+					RuleID: r.ID(), Severity: r.Severity(), Individual: mother, Family: fam,
+					Message: fmt.Sprintf("mother %s was %d at birth of %s, outside [%d, %d]", mother.ID(), age, child.ID(), r.minAge, r.maxAge),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// fatherAgeRule flags a father whose age at a child's birth exceeds
+// maxAge, using Husband and Children to resolve father and offspring.
+type fatherAgeRule struct{ maxAge int }
+
+func (fatherAgeRule) ID() string { return "GR_FATHERAGE" }
+func (fatherAgeRule) Severity() validate.Severity { return validate.SeverityWarning }
+
+func (r fatherAgeRule) Check(g *query.Graph) []Violation {
+	violations := make([]Violation, 0)
+	for _, fam := range g.AllFamilies() {
+		father := fam.Husband()
+		if father == nil {
+			continue
+		}
+		dadBirth := yearOf(father.Individual.GetBirthDate())
+		if dadBirth == 0 {
+			continue
+		}
+		for _, child := range fam.Children() {
+			childBirth := yearOf(child.Individual.GetBirthDate())
+			if childBirth == 0 {
+				continue
+			}
+			age := childBirth - dadBirth
+			if age > r.maxAge {
+				violations = append(violations, Violation{
+					RuleID: r.ID(), Severity: r.Severity(), Individual: father, Family: fam,
+					Message: fmt.Sprintf("father %s was %d at birth of %s, over MaxFatherAge (%d)", father.ID(), age, child.ID(), r.maxAge),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// childBeforeMarriageRule flags a child born more than graceMonths before
+// the parents' recorded marriage date.
+type childBeforeMarriageRule struct{ graceMonths int }
+
+func (childBeforeMarriageRule) ID() string { return "GR_CHILDBEFOREMARRIAGE" }
+func (childBeforeMarriageRule) Severity() validate.Severity { return validate.SeverityWarning }
+
+func (r childBeforeMarriageRule) Check(g *query.Graph) []Violation {
+	violations := make([]Violation, 0)
+	graceDays := r.graceMonths * 30
+	for _, fam := range g.AllFamilies() {
+		marrDays, ok := approxDayOf(marriageDate(fam.Family))
+		if !ok {
+			continue
+		}
+		for _, child := range fam.Children() {
+			childDays, ok := approxDayOf(child.Individual.GetBirthDate())
+			if !ok {
+				continue
+			}
+			if childDays < marrDays-graceDays {
+				violations = append(violations, Violation{
+					RuleID: r.ID(), Severity: r.Severity(), Individual: child, Family: fam,
+					Message: fmt.Sprintf("%s born more than %d months before family %s's marriage", child.ID(), r.graceMonths, fam.ID()),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// siblingSpacingRule flags siblings (same mother) born closer together
+// than minDays, skipping a pair whose birth dates are recorded identically
+// (presumed twins rather than a data error).
+type siblingSpacingRule struct{ minDays int }
+
+func (siblingSpacingRule) ID() string { return "GR_SIBSPACING" }
+func (siblingSpacingRule) Severity() validate.Severity { return validate.SeverityWarning }
+
+type siblingBirth struct {
+	node *query.IndividualNode
+	date string
+	days int
+}
+
+func (r siblingSpacingRule) Check(g *query.Graph) []Violation {
+	if r.minDays <= 0 {
+		return nil
+	}
+	violations := make([]Violation, 0)
+	for _, fam := range g.AllFamilies() {
+		births := make([]siblingBirth, 0, len(fam.Children()))
+		for _, child := range fam.Children() {
+			date := child.Individual.GetBirthDate()
+			if days, ok := approxDayOf(date); ok {
+				births = append(births, siblingBirth{node: child, date: date, days: days})
+			}
+		}
+		sort.Slice(births, func(i, j int) bool { return births[i].days < births[j].days })
+		for i := 1; i < len(births); i++ {
+			if births[i].date == births[i-1].date {
+				continue
+			}
+			spacing := births[i].days - births[i-1].days
+			if spacing < r.minDays {
+				violations = append(violations, Violation{
+					RuleID: r.ID(), Severity: r.Severity(), Individual: births[i].node, Family: fam,
+					Message: fmt.Sprintf("%s and %s in family %s born %d days apart, under MinSiblingSpacingDays (%d)",
+						births[i-1].node.ID(), births[i].node.ID(), fam.ID(), spacing, r.minDays),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// widowRemarriageRule flags a spouse who remarries more than maxYears
+// after being widowed.
+type widowRemarriageRule struct{ maxYears int }
+
+func (widowRemarriageRule) ID() string { return "GR_WIDOWREMARRIAGE" }
+func (widowRemarriageRule) Severity() validate.Severity { return validate.SeverityInfo }
+
+func (r widowRemarriageRule) Check(g *query.Graph) []Violation {
+	violations := make([]Violation, 0)
+	for _, node := range g.AllIndividuals() {
+		deathsOfSpouses := make([]int, 0)
+		marriages := make([]int, 0)
+		for _, spouse := range node.Spouses() {
+			for _, fam := range g.AllFamilies() {
+				husband, wife := fam.Husband(), fam.Wife()
+				isThisCouple := (husband == node && wife == spouse) || (husband == spouse && wife == node)
+				if !isThisCouple {
+					continue
+				}
+				if marrYear := yearOf(marriageDate(fam.Family)); marrYear != 0 {
+					marriages = append(marriages, marrYear)
+				}
+				if deathYear := yearOf(spouse.Individual.GetDeathDate()); deathYear != 0 {
+					deathsOfSpouses = append(deathsOfSpouses, deathYear)
+				}
+			}
+		}
+		sort.Ints(deathsOfSpouses)
+		sort.Ints(marriages)
+		for _, death := range deathsOfSpouses {
+			for _, marr := range marriages {
+				if marr <= death {
+					continue
+				}
+				if marr-death > r.maxYears {
+					violations = append(violations, Violation{
+						RuleID: r.ID(), Severity: r.Severity(), Individual: node,
+						Message: fmt.Sprintf("%s remarried (%d) more than %d years after a spouse's death (%d)", node.ID(), marr, r.maxYears, death),
+					})
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// marriageSpanRule flags a family whose recorded marriage-to-death (or
+// divorce) span is shorter than the span between its first and last
+// child's births implies it should be.
+type marriageSpanRule struct{}
+
+func (marriageSpanRule) ID() string { return "GR_MARRIAGESPAN" }
+func (marriageSpanRule) Severity() validate.Severity { return validate.SeverityHint }
+
+func (r marriageSpanRule) Check(g *query.Graph) []Violation {
+	violations := make([]Violation, 0)
+	for _, fam := range g.AllFamilies() {
+		marrDays, ok := approxDayOf(marriageDate(fam.Family))
+		if !ok {
+			continue
+		}
+		endDays, ok := marriageEndDays(fam)
+		if !ok {
+			continue
+		}
+
+		var first, last int
+		haveChild := false
+		for _, child := range fam.Children() {
+			days, ok := approxDayOf(child.Individual.GetBirthDate())
+			if !ok {
+				continue
+			}
+			if !haveChild || days < first {
+				first = days
+			}
+			if !haveChild || days > last {
+				last = days
+			}
+			haveChild = true
+		}
+		if !haveChild {
+			continue
+		}
+
+		childBearingSpan := last - first
+		marriageSpan := endDays - marrDays
+		if marriageSpan < childBearingSpan {
+			violations = append(violations, Violation{
+				RuleID: r.ID(), Severity: r.Severity(), Family: fam,
+				Message: fmt.Sprintf("family %s's marriage span (%d days) is shorter than its children's birth span (%d days)", fam.ID(), marriageSpan, childBearingSpan),
+			})
+		}
+	}
+	return violations
+}
+
+// marriageEndDays returns the day count a marriage effectively ended at:
+// a recorded divorce date if present, else the earlier-dying spouse's
+// death date.
+func marriageEndDays(fam *query.FamilyNode) (int, bool) {
+	for _, line := range fam.Family.GetLines("DIV") {
+		for _, child := range line.Children {
+			if child.Tag == "DATE" {
+				if days, ok := approxDayOf(child.Value); ok {
+					return days, true
+				}
+			}
+		}
+	}
+
+	var end int
+	have := false
+	for _, spouse := range []*query.IndividualNode{fam.Husband(), fam.Wife()} {
+		if spouse == nil {
+			continue
+		}
+		days, ok := approxDayOf(spouse.Individual.GetDeathDate())
+		if !ok {
+			continue
+		}
+		if !have || days < end {
+			end = days
+		}
+		have = true
+	}
+	return end, have
+}
@@ -0,0 +1,172 @@
+// Package validation runs a catalog of genealogical consistency rules
+// directly over a *query.Graph, surfacing issues via the existing
+// validate.Severity model. It complements rather than replaces the
+// validate package: validate.Rule checks a *types.GedcomTree record by
+// record (see validate.Validator), while a Rule here walks graph nodes
+// directly and reports the offending *query.IndividualNode/*query.FamilyNode
+// alongside each Violation, for callers that already hold a built Graph
+// and want violations wired to its node objects rather than bare xrefs.
+package validation
+
+import (
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/validate"
+)
+
+// Violation is a single rule failure found while walking a Graph.
+// Individual and/or Family is set depending on which kind of node the
+// rule that produced it inspects; the other is left nil.
+type Violation struct {
+	RuleID     string
+	Severity   validate.Severity
+	Message    string
+	Individual *query.IndividualNode
+	Family     *query.FamilyNode
+}
+
+// Rule is a single genealogical consistency check run over a Graph.
+type Rule interface {
+	ID() string
+	Severity() validate.Severity
+	Check(g *query.Graph) []Violation
+}
+
+// Thresholds holds the configurable ages and spacings this package's
+// built-in rule catalog checks against. Field names match what this
+// request asks Config.Validation.Thresholds to be keyed by (MaxAge,
+// MinMarriageAge, MaxMotherAge, ...), rather than validate.Thresholds'
+// LifeLines/GEDCHECK naming (OLDAGE, YNGMAR, ...).
+type Thresholds struct {
+	MaxAge                int // Max plausible lifespan in years. Default 120.
+	MinMarriageAge        int // Min plausible marriage age. Default 13.
+	MaxMarriageAge        int // Max plausible marriage age. Default 90.
+	MinMotherAge          int // Min plausible mother age at child birth. Default 12.
+	MaxMotherAge          int // Max plausible mother age at child birth. Default 55.
+	MaxFatherAge          int // Max plausible father age at child birth. Default 80.
+	MarriageGraceMonths   int // Months a child may be born before the parents' recorded marriage without being flagged. Default 9.
+	MinSiblingSpacingDays int // Min plausible days between sibling births (same mother), excluding twins. Default 270 (~9 months).
+	MaxWidowhoodYears     int // Max plausible years a spouse is widowed before a later remarriage is flagged as implausible. Default 10.
+}
+
+// DefaultThresholds returns this package's default age thresholds.
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		MaxAge:                120,
+		MinMarriageAge:        13,
+		MaxMarriageAge:        90,
+		MinMotherAge:          12,
+		MaxMotherAge:          55,
+		MaxFatherAge:          80,
+		MarriageGraceMonths:   9,
+		MinSiblingSpacingDays: 270,
+		MaxWidowhoodYears:     10,
+	}
+}
+
+// thresholdFields maps each Thresholds field name to a pointer into t, for
+// Map/ThresholdsFromMap to share a single field list instead of keeping
+// two in sync by hand.
+func (t *Thresholds) thresholdFields() map[string]*int {
+	return map[string]*int{
+		"MaxAge":                &t.MaxAge,
+		"MinMarriageAge":        &t.MinMarriageAge,
+		"MaxMarriageAge":        &t.MaxMarriageAge,
+		"MinMotherAge":          &t.MinMotherAge,
+		"MaxMotherAge":          &t.MaxMotherAge,
+		"MaxFatherAge":          &t.MaxFatherAge,
+		"MarriageGraceMonths":   &t.MarriageGraceMonths,
+		"MinSiblingSpacingDays": &t.MinSiblingSpacingDays,
+		"MaxWidowhoodYears":     &t.MaxWidowhoodYears,
+	}
+}
+
+// Map renders t as the map[string]int Config.Validation.Thresholds stores
+// in JSON, keyed by field name.
+func (t Thresholds) Map() map[string]int {
+	out := make(map[string]int, 9)
+	for name, p := range t.thresholdFields() {
+		out[name] = *p
+	}
+	return out
+}
+
+// ThresholdsFromMap overlays m onto DefaultThresholds(), for loading
+// per-rule overrides out of Config.Validation.Thresholds. Unknown keys are
+// ignored.
+func ThresholdsFromMap(m map[string]int) Thresholds {
+	t := DefaultThresholds()
+	fields := t.thresholdFields()
+	for k, v := range m {
+		if p, ok := fields[k]; ok {
+			*p = v
+		}
+	}
+	return t
+}
+
+// RuleSet runs a catalog of Rules over a Graph, filtering results to those
+// at or above a minimum severity.
+type RuleSet struct {
+	rules []Rule
+}
+
+// NewRuleSet creates a RuleSet over the shipped rule catalog, using
+// thresholds for every threshold-driven rule.
+func NewRuleSet(thresholds Thresholds) *RuleSet {
+	return &RuleSet{rules: defaultRules(thresholds)}
+}
+
+// AddRule registers an additional rule to run.
+func (rs *RuleSet) AddRule(r Rule) {
+	rs.rules = append(rs.rules, r)
+}
+
+// AddRules registers additional rules to run, e.g. a RuleRegistry's
+// accumulated plugin/declarative rules.
+func (rs *RuleSet) AddRules(rules []Rule) {
+	rs.rules = append(rs.rules, rules...)
+}
+
+// Rules returns the rules rs will run.
+func (rs *RuleSet) Rules() []Rule {
+	return rs.rules
+}
+
+// Run executes every rule in rs over g and returns the combined
+// violations.
+func (rs *RuleSet) Run(g *query.Graph) []Violation {
+	violations := make([]Violation, 0)
+	for _, r := range rs.rules {
+		violations = append(violations, r.Check(g)...)
+	}
+	return violations
+}
+
+// RunFiltered runs rs over g and drops any violation below minSeverity.
+func (rs *RuleSet) RunFiltered(g *query.Graph, minSeverity validate.Severity) []Violation {
+	all := rs.Run(g)
+	filtered := make([]Violation, 0, len(all))
+	for _, v := range all {
+		if severityRank(v.Severity) >= severityRank(minSeverity) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// severityRank orders validate.Severity from least to most serious, for
+// RunFiltered's "at or above a minimum severity" comparison.
+func severityRank(s validate.Severity) int {
+	switch s {
+	case validate.SeverityHint:
+		return 0
+	case validate.SeverityInfo:
+		return 1
+	case validate.SeverityWarning:
+		return 2
+	case validate.SeveritySevere:
+		return 3
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,157 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/validate"
+)
+
+// buildIndi adds an INDI record with an optional FAMC link to tree.
+func buildIndi(tree *types.GedcomTree, xref, name, birthDate, deathDate, famc string) {
+	line := types.NewGedcomLine(0, "INDI", "", xref)
+	line.AddChild(types.NewGedcomLine(1, "NAME", name, ""))
+	if birthDate != "" {
+		birt := types.NewGedcomLine(1, "BIRT", "", "")
+		birt.AddChild(types.NewGedcomLine(2, "DATE", birthDate, ""))
+		line.AddChild(birt)
+	}
+	if deathDate != "" {
+		deat := types.NewGedcomLine(1, "DEAT", "", "")
+		deat.AddChild(types.NewGedcomLine(2, "DATE", deathDate, ""))
+		line.AddChild(deat)
+	}
+	if famc != "" {
+		line.AddChild(types.NewGedcomLine(1, "FAMC", famc, ""))
+	}
+	tree.AddRecord(types.NewIndividualRecord(line))
+}
+
+// buildFam adds a FAM record with an optional marriage date and any number
+// of children to tree.
+func buildFam(tree *types.GedcomTree, xref, husb, wife, marrDate string, children ...string) {
+	line := types.NewGedcomLine(0, "FAM", "", xref)
+	if husb != "" {
+		line.AddChild(types.NewGedcomLine(1, "HUSB", husb, ""))
+	}
+	if wife != "" {
+		line.AddChild(types.NewGedcomLine(1, "WIFE", wife, ""))
+	}
+	if marrDate != "" {
+		marr := types.NewGedcomLine(1, "MARR", "", "")
+		marr.AddChild(types.NewGedcomLine(2, "DATE", marrDate, ""))
+		line.AddChild(marr)
+	}
+	for _, child := range children {
+		line.AddChild(types.NewGedcomLine(1, "CHIL", child, ""))
+	}
+	tree.AddRecord(types.NewFamilyRecord(line))
+}
+
+func buildGraph(t *testing.T, tree *types.GedcomTree) *query.Graph {
+	t.Helper()
+	graph, err := query.BuildGraph(tree)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+	return graph
+}
+
+func TestDeathBeforeBirthRule_FlagsInvertedDates(t *testing.T) {
+	tree := types.NewGedcomTree()
+	buildIndi(tree, "@I1@", "Backwards /Person/", "1 JAN 1950", "1 JAN 1900", "")
+	graph := buildGraph(t, tree)
+
+	violations := (deathBeforeBirthRule{}).Check(graph)
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got %d: %+v", len(violations), violations)
+	}
+	if violations[0].Individual == nil || violations[0].Individual.ID() != "@I1@" {
+		t.Errorf("expected violation to carry @I1@'s IndividualNode, got %+v", violations[0])
+	}
+}
+
+func TestMaxAgeRule_FlagsLifespanOverThreshold(t *testing.T) {
+	tree := types.NewGedcomTree()
+	buildIndi(tree, "@I1@", "Old /Person/", "1 JAN 1800", "1 JAN 1950", "")
+	graph := buildGraph(t, tree)
+
+	violations := (maxAgeRule{maxAge: 120}).Check(graph)
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation for a 150-year lifespan over MaxAge 120, got %d", len(violations))
+	}
+}
+
+func TestMarriageAgeRule_FlagsUnderMinAge(t *testing.T) {
+	tree := types.NewGedcomTree()
+	buildIndi(tree, "@I1@", "Husband /Person/", "1 JAN 1900", "", "")
+	buildIndi(tree, "@I2@", "Wife /Person/", "1 JAN 1905", "", "")
+	buildFam(tree, "@F1@", "@I1@", "@I2@", "1 JAN 1915") // wife married at age 10
+	graph := buildGraph(t, tree)
+
+	violations := (marriageAgeRule{minAge: 13, maxAge: 90}).Check(graph)
+	found := false
+	for _, v := range violations {
+		if v.Individual != nil && v.Individual.ID() == "@I2@" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a violation for @I2@ marrying under MinMarriageAge, got %+v", violations)
+	}
+}
+
+func TestMotherAgeRule_FlagsOutsideRange(t *testing.T) {
+	tree := types.NewGedcomTree()
+	buildIndi(tree, "@I1@", "Father /Person/", "1 JAN 1900", "", "")
+	buildIndi(tree, "@I2@", "Mother /Person/", "1 JAN 1905", "", "")
+	buildIndi(tree, "@I3@", "Child /Person/", "1 JAN 1920", "", "@F1@") // mother aged 15
+	buildFam(tree, "@F1@", "@I1@", "@I2@", "", "@I3@")
+	graph := buildGraph(t, tree)
+
+	violations := (motherAgeRule{minAge: 16, maxAge: 55}).Check(graph)
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation for mother aged 15 under MinMotherAge 16, got %d", len(violations))
+	}
+}
+
+func TestSiblingSpacingRule_SkipsIdenticalBirthDatesAsTwins(t *testing.T) {
+	tree := types.NewGedcomTree()
+	buildIndi(tree, "@I1@", "Twin A /Person/", "1 JUN 1950", "", "@F1@")
+	buildIndi(tree, "@I2@", "Twin B /Person/", "1 JUN 1950", "", "@F1@")
+	buildFam(tree, "@F1@", "", "", "", "@I1@", "@I2@")
+	graph := buildGraph(t, tree)
+
+	violations := (siblingSpacingRule{minDays: 270}).Check(graph)
+	if len(violations) != 0 {
+		t.Errorf("expected twins (identical birth dates) not to be flagged, got %+v", violations)
+	}
+}
+
+func TestThresholds_MapRoundTrip(t *testing.T) {
+	original := DefaultThresholds()
+	roundTripped := ThresholdsFromMap(original.Map())
+	if roundTripped != original {
+		t.Errorf("expected Map/ThresholdsFromMap to round-trip, got %+v vs %+v", roundTripped, original)
+	}
+}
+
+func TestRuleSet_RunFiltered_DropsBelowMinSeverity(t *testing.T) {
+	tree := types.NewGedcomTree()
+	buildIndi(tree, "@I1@", "Backwards /Person/", "1 JAN 1950", "1 JAN 1900", "")
+	buildIndi(tree, "@I2@", "Long Lived /Person/", "1 JAN 1900", "1 JAN 1930", "")
+	graph := buildGraph(t, tree)
+
+	rs := NewRuleSet(DefaultThresholds())
+	severe := rs.RunFiltered(graph, validate.SeveritySevere)
+	if len(severe) == 0 {
+		t.Fatal("expected at least the GR_DEATHBEFOREBIRTH violation at SeveritySevere")
+	}
+
+	warningOnly := &RuleSet{rules: []Rule{maxAgeRule{maxAge: 1}}}
+	filtered := warningOnly.RunFiltered(graph, validate.SeveritySevere)
+	if len(filtered) != 0 {
+		t.Errorf("expected GR_MAXAGE (SeverityWarning) to be dropped under a SeveritySevere floor, got %+v", filtered)
+	}
+}
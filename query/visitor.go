@@ -0,0 +1,219 @@
+package query
+
+import "errors"
+
+// ErrStopWalk is returned by a GraphVisitor callback to abort an entire
+// WalkGraph/WalkAll invocation immediately, as opposed to ErrSkipBranch
+// which only prunes the current subtree.
+var ErrStopWalk = errors.New("query: stop walk")
+
+// WalkStrategy selects the traversal order used by WalkGraph/WalkAll.
+type WalkStrategy string
+
+const (
+	WalkDFS WalkStrategy = "dfs"
+	WalkBFS WalkStrategy = "bfs"
+)
+
+// GraphVisitor is a generic graph-wide visitor, analogous to btrfs's
+// TreeWalkHandler: typed callbacks per node kind plus a generic fallback,
+// so callers implementing pedigree-collapse detection, ahnentafel
+// numbering, or cousin-degree computation don't have to hand-roll
+// InEdges()/OutEdges() recursion and visited-node bookkeeping themselves.
+//
+// Every callback may return ErrSkipBranch to prune the current node's
+// unvisited neighbors without aborting the rest of the walk, or
+// ErrStopWalk (or any other non-nil error) to abort the walk entirely.
+type GraphVisitor struct {
+	// OnIndividual runs when the node being visited is an *IndividualNode.
+	OnIndividual func(path []GraphNode, n *IndividualNode) error
+
+	// OnFamily runs when the node being visited is a *FamilyNode.
+	OnFamily func(path []GraphNode, n *FamilyNode) error
+
+	// OnEvent runs when the node being visited has NodeType() ==
+	// NodeTypeEvent. EventNode's concrete shape isn't exposed by this
+	// package, so the node is passed through the GraphNode interface.
+	OnEvent func(path []GraphNode, n GraphNode) error
+
+	// OnEdge runs once for every edge followed during the walk, before
+	// the edge's target node is visited.
+	OnEdge func(e *Edge) error
+
+	// OnNode runs for every visited node regardless of kind, after the
+	// type-specific callback above (if any) has run.
+	OnNode func(n GraphNode) error
+}
+
+// GraphWalkOptions configures a single WalkGraph/WalkAll invocation.
+type GraphWalkOptions struct {
+	Strategy  WalkStrategy // WalkDFS (default) or WalkBFS
+	MaxDepth  int          // 0 = unlimited
+	EdgeTypes []EdgeType   // if non-empty, only these edge types are followed
+}
+
+func (opts GraphWalkOptions) allowsEdge(e *Edge) bool {
+	if len(opts.EdgeTypes) == 0 {
+		return true
+	}
+	for _, t := range opts.EdgeTypes {
+		if e.EdgeType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// WalkGraph traverses the graph reachable from start via OutEdges,
+// filtered by opts.EdgeTypes, in either depth-first (default) or
+// breadth-first order, invoking h's callbacks as each node is reached.
+func (g *Graph) WalkGraph(start GraphNode, h GraphVisitor, opts GraphWalkOptions) error {
+	if start == nil {
+		return nil
+	}
+
+	visited := make(map[string]bool)
+	if opts.Strategy == WalkBFS {
+		return walkGraphBFS(start, h, opts, visited)
+	}
+	return walkGraphDFS(start, h, opts, visited, nil, 0)
+}
+
+// WalkAll visits every node in the graph exactly once (individuals, then
+// families), starting a fresh WalkGraph from each node not already reached
+// by a previous one, so isolated components are still covered.
+func (g *Graph) WalkAll(h GraphVisitor, opts GraphWalkOptions) error {
+	visited := make(map[string]bool)
+
+	visit := func(start GraphNode) error {
+		if visited[start.ID()] {
+			return nil
+		}
+		var err error
+		if opts.Strategy == WalkBFS {
+			err = walkGraphBFS(start, h, opts, visited)
+		} else {
+			err = walkGraphDFS(start, h, opts, visited, nil, 0)
+		}
+		if err != nil && err != ErrSkipBranch {
+			return err
+		}
+		return nil
+	}
+
+	for _, n := range g.AllIndividuals() {
+		if err := visit(n); err != nil {
+			return err
+		}
+	}
+	for _, n := range g.AllFamilies() {
+		if err := visit(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dispatchNode(path []GraphNode, n GraphNode, h GraphVisitor) error {
+	switch typed := n.(type) {
+	case *IndividualNode:
+		if h.OnIndividual != nil {
+			if err := h.OnIndividual(path, typed); err != nil {
+				return err
+			}
+		}
+	case *FamilyNode:
+		if h.OnFamily != nil {
+			if err := h.OnFamily(path, typed); err != nil {
+				return err
+			}
+		}
+	default:
+		if n.NodeType() == NodeTypeEvent && h.OnEvent != nil {
+			if err := h.OnEvent(path, n); err != nil {
+				return err
+			}
+		}
+	}
+
+	if h.OnNode != nil {
+		return h.OnNode(n)
+	}
+	return nil
+}
+
+func walkGraphDFS(n GraphNode, h GraphVisitor, opts GraphWalkOptions, visited map[string]bool, path []GraphNode, depth int) error {
+	if visited[n.ID()] {
+		return nil
+	}
+	visited[n.ID()] = true
+
+	if err := dispatchNode(path, n, h); err != nil {
+		if err == ErrSkipBranch {
+			return nil
+		}
+		return err
+	}
+
+	if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+		return nil
+	}
+
+	path = append(path, n)
+	defer func() { path = path[:len(path)-1] }()
+
+	for _, e := range n.OutEdges() {
+		if !opts.allowsEdge(e) || e.To == nil {
+			continue
+		}
+		if h.OnEdge != nil {
+			if err := h.OnEdge(e); err != nil {
+				return err
+			}
+		}
+		if err := walkGraphDFS(e.To, h, opts, visited, path, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkGraphBFS(start GraphNode, h GraphVisitor, opts GraphWalkOptions, visited map[string]bool) error {
+	type queued struct {
+		node  GraphNode
+		depth int
+	}
+
+	queue := []queued{{node: start, depth: 0}}
+	visited[start.ID()] = true
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		if err := dispatchNode(nil, cur.node, h); err != nil {
+			if err == ErrSkipBranch {
+				continue
+			}
+			return err
+		}
+
+		if opts.MaxDepth > 0 && cur.depth >= opts.MaxDepth {
+			continue
+		}
+
+		for _, e := range cur.node.OutEdges() {
+			if !opts.allowsEdge(e) || e.To == nil || visited[e.To.ID()] {
+				continue
+			}
+			if h.OnEdge != nil {
+				if err := h.OnEdge(e); err != nil {
+					return err
+				}
+			}
+			visited[e.To.ID()] = true
+			queue = append(queue, queued{node: e.To, depth: cur.depth + 1})
+		}
+	}
+	return nil
+}
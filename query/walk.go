@@ -0,0 +1,121 @@
+package query
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSkipBranch is returned by a WalkHandler callback to prune the subtree
+// rooted at the node just visited without aborting the overall walk.
+var ErrSkipBranch = errors.New("query: skip branch")
+
+// WalkHandler receives lifecycle callbacks as Walk descends through the
+// ancestor (or descendant) graph, modeled on the PreNode/Node/PostNode
+// callback style used by tree-walking APIs such as btrfs-progs' TreeWalk.
+type WalkHandler struct {
+	// PreNode runs before a node's children are visited. Returning
+	// ErrSkipBranch prunes that subtree; any other non-nil error aborts
+	// the walk.
+	PreNode func(n *IndividualNode, path []GraphNode) error
+
+	// Node runs for every visited node, after PreNode and before its
+	// children are walked.
+	Node func(n *IndividualNode, path []GraphNode) error
+
+	// PostNode runs after a node's children have all been visited.
+	PostNode func(n *IndividualNode, path []GraphNode) error
+
+	// PreEdge and PostEdge bracket the traversal of a single parent edge.
+	PreEdge  func(e *Edge) error
+	PostEdge func(e *Edge) error
+}
+
+// WalkOptions configures a single Walk invocation.
+type WalkOptions struct {
+	MaxGenerations int  // 0 = unlimited
+	IncludeSelf    bool // visit the starting individual itself
+}
+
+// Walk traverses ancestors of startXrefID depth-first, invoking h at each
+// step. It honors ctx cancellation between sibling visits: if ctx.Err() is
+// non-nil the walk stops and returns ctx.Err(). A callback may return
+// ErrSkipBranch to prune the current subtree without aborting the rest of
+// the walk.
+func (g *Graph) Walk(ctx context.Context, startXrefID string, h WalkHandler, opts WalkOptions) error {
+	start := g.GetIndividual(startXrefID)
+	if start == nil {
+		return nil
+	}
+
+	visited := make(map[uint32]bool)
+	path := make([]GraphNode, 0)
+
+	if opts.IncludeSelf {
+		if err := walkVisit(ctx, start, path, h, opts, visited, 0); err != nil && err != ErrSkipBranch {
+			return err
+		}
+		return nil
+	}
+
+	for _, parent := range start.Parents() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := walkVisit(ctx, parent, path, h, opts, visited, 1); err != nil && err != ErrSkipBranch {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkVisit(ctx context.Context, n *IndividualNode, path []GraphNode, h WalkHandler, opts WalkOptions, visited map[uint32]bool, depth int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	nodeID := n.BaseNode.nodeID
+	if nodeID != 0 {
+		if visited[nodeID] {
+			return nil
+		}
+		visited[nodeID] = true
+	}
+
+	if opts.MaxGenerations > 0 && depth > opts.MaxGenerations {
+		return nil
+	}
+
+	if h.PreNode != nil {
+		if err := h.PreNode(n, path); err != nil {
+			return err
+		}
+	}
+
+	if h.Node != nil {
+		if err := h.Node(n, path); err != nil {
+			return err
+		}
+	}
+
+	path = append(path, n)
+	for _, parent := range n.Parents() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := walkVisit(ctx, parent, path, h, opts, visited, depth+1); err != nil {
+			if err == ErrSkipBranch {
+				continue
+			}
+			return err
+		}
+	}
+	path = path[:len(path)-1]
+
+	if h.PostNode != nil {
+		if err := h.PostNode(n, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
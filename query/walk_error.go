@@ -0,0 +1,24 @@
+package query
+
+import "fmt"
+
+// WalkError records one graph-traversal inconsistency encountered while
+// walking ancestors or descendants: an unresolved HUSB/WIFE/CHIL edge, or
+// a resolved individual that has not been assigned a node ID. It wraps
+// the underlying cause so callers can still use errors.Is/errors.As
+// against it, and is reported through AncestorOptions.OnError (and its
+// DescendantQuery counterpart) as it's discovered, not just surfaced as a
+// generic aggregate error.
+type WalkError struct {
+	XrefID string
+	Depth  int
+	Err    error
+}
+
+func (e *WalkError) Error() string {
+	return fmt.Sprintf("query: traversal error at %s (depth %d): %v", e.XrefID, e.Depth, e.Err)
+}
+
+func (e *WalkError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,361 @@
+// Package search builds a full-text inverted index over a GedcomTree's
+// individuals -- names, birth places, and event types/dates -- and scores
+// matches with BM25, the same ranking function used by most full-text
+// search engines (Elasticsearch, Lucene).
+//
+// query.QueryBuilder isn't part of this package snapshot (see the doc
+// comment on query.SQLBackend and on validate.RuleRunner for the same
+// constraint) and exposes no accessor to the tree it was built from, so
+// a qb.Search() entry point can't be wired onto it here. NewIndex below,
+// built directly from a *types.GedcomTree, is the entry point instead --
+// the same shape query.EnsureSimilarityIndex already uses for its
+// HNSW-backed fuzzy name/place lookups, which this package complements
+// rather than duplicates: SimilarityIndex finds near-miss single-field
+// matches via embeddings, Index here ranks multi-field text relevance
+// via an inverted index plus BM25.
+package search
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// BM25 tuning constants, the usual Lucene/Elasticsearch defaults.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field names usable in SearchOptions.Fields.
+const (
+	FieldName  = "name"
+	FieldPlace = "place"
+	FieldEvent = "event"
+)
+
+var allFields = []string{FieldName, FieldPlace, FieldEvent}
+
+// Hit is a single search result: the individual's xref ID and its BM25
+// relevance score (higher is more relevant).
+type Hit struct {
+	XrefID string
+	Score  float64
+}
+
+// SearchOptions controls a Search call.
+type SearchOptions struct {
+	// Fields restricts matching to the named fields (FieldName,
+	// FieldPlace, FieldEvent). Nil or empty searches all fields.
+	Fields []string
+
+	// Limit caps the number of Hits returned. 0 means unlimited.
+	Limit int
+}
+
+// document holds the per-field token counts for a single individual,
+// used to compute BM25's field-length normalization.
+type document struct {
+	xrefID   string
+	fieldLen map[string]int
+	termFreq map[string]map[string]int // field -> term -> count
+}
+
+// Index is an inverted index over a GedcomTree's individuals, supporting
+// ranked full-text Search, prefix Suggest, and edit-distance FuzzySearch.
+// It can be built once via NewIndex and kept current via Add/Remove as
+// the tree changes.
+type Index struct {
+	mu sync.RWMutex
+
+	docs map[string]*document // xrefID -> document
+
+	// postings[field][term] is the set of xrefIDs whose field contains
+	// term, used to avoid scanning every document on Search.
+	postings map[string]map[string]map[string]bool
+
+	fieldDocCount map[string]int // field -> number of docs with that field non-empty
+	fieldTotalLen map[string]int // field -> sum of fieldLen across docs
+}
+
+// NewIndex builds an Index over every individual in tree.
+func NewIndex(tree *types.GedcomTree) (*Index, error) {
+	if tree == nil {
+		return nil, fmt.Errorf("search: NewIndex: tree is nil")
+	}
+	idx := newEmptyIndex()
+	for xrefID, rec := range tree.GetAllIndividuals() {
+		indi, ok := rec.(*types.IndividualRecord)
+		if !ok {
+			continue
+		}
+		idx.index(xrefID, indi)
+	}
+	return idx, nil
+}
+
+func newEmptyIndex() *Index {
+	postings := make(map[string]map[string]map[string]bool, len(allFields))
+	for _, f := range allFields {
+		postings[f] = make(map[string]map[string]bool)
+	}
+	return &Index{
+		docs:          make(map[string]*document),
+		postings:      postings,
+		fieldDocCount: make(map[string]int),
+		fieldTotalLen: make(map[string]int),
+	}
+}
+
+// Add indexes (or re-indexes) a single individual, for incremental
+// updates as a tree is edited without rebuilding the whole Index.
+func (idx *Index) Add(xrefID string, indi *types.IndividualRecord) {
+	if indi == nil {
+		return
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeLocked(xrefID)
+	idx.index(xrefID, indi)
+}
+
+// Remove drops xrefID from the Index, for incremental updates.
+func (idx *Index) Remove(xrefID string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeLocked(xrefID)
+}
+
+func (idx *Index) removeLocked(xrefID string) {
+	doc, ok := idx.docs[xrefID]
+	if !ok {
+		return
+	}
+	for field, terms := range doc.termFreq {
+		idx.fieldDocCount[field]--
+		idx.fieldTotalLen[field] -= doc.fieldLen[field]
+		for term := range terms {
+			set := idx.postings[field][term]
+			delete(set, xrefID)
+			if len(set) == 0 {
+				delete(idx.postings[field], term)
+			}
+		}
+	}
+	delete(idx.docs, xrefID)
+}
+
+// index tokenizes indi's searchable fields and adds them to the Index.
+// Callers must hold idx.mu.
+func (idx *Index) index(xrefID string, indi *types.IndividualRecord) {
+	doc := &document{
+		xrefID:   xrefID,
+		fieldLen: make(map[string]int),
+		termFreq: make(map[string]map[string]int),
+	}
+
+	fields := map[string][]string{
+		FieldName:  tokenize(indi.GetName()),
+		FieldPlace: tokenize(indi.GetBirthPlace()),
+		FieldEvent: eventTokens(indi),
+	}
+
+	for field, tokens := range fields {
+		if len(tokens) == 0 {
+			continue
+		}
+		freq := make(map[string]int, len(tokens))
+		for _, tok := range tokens {
+			freq[tok]++
+		}
+		doc.fieldLen[field] = len(tokens)
+		doc.termFreq[field] = freq
+
+		idx.fieldDocCount[field]++
+		idx.fieldTotalLen[field] += len(tokens)
+		for tok := range freq {
+			set := idx.postings[field][tok]
+			if set == nil {
+				set = make(map[string]bool)
+				idx.postings[field][tok] = set
+			}
+			set[xrefID] = true
+		}
+	}
+
+	idx.docs[xrefID] = doc
+}
+
+// eventTokens tokenizes an individual's event types and dates (the only
+// fields GetEvents' map[string]interface{} entries expose in this
+// snapshot -- see validate/rules.go and diff/consistency.go, which only
+// ever read "type" and "date").
+func eventTokens(indi *types.IndividualRecord) []string {
+	var tokens []string
+	for _, ev := range indi.GetEvents() {
+		if evType, ok := ev["type"].(string); ok {
+			tokens = append(tokens, tokenize(evType)...)
+		}
+		if date, ok := ev["date"].(string); ok {
+			tokens = append(tokens, tokenize(date)...)
+		}
+	}
+	return tokens
+}
+
+// searchFields resolves a SearchOptions.Fields list to the concrete
+// field names to search, defaulting to every field.
+func searchFields(opts SearchOptions) []string {
+	if len(opts.Fields) == 0 {
+		return allFields
+	}
+	return opts.Fields
+}
+
+// Search tokenizes query and ranks every individual that matches at
+// least one token in the searched fields via BM25, returning Hits in
+// descending score order.
+func (idx *Index) Search(query string, opts SearchOptions) []Hit {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := make(map[string]float64)
+	for _, field := range searchFields(opts) {
+		numDocs := idx.fieldDocCount[field]
+		if numDocs == 0 {
+			continue
+		}
+		avgLen := float64(idx.fieldTotalLen[field]) / float64(numDocs)
+
+		for _, term := range terms {
+			postingSet := idx.postings[field][term]
+			if len(postingSet) == 0 {
+				continue
+			}
+			idf := bm25IDF(numDocs, len(postingSet))
+			for xrefID := range postingSet {
+				doc := idx.docs[xrefID]
+				tf := doc.termFreq[field][term]
+				scores[xrefID] += bm25Score(idf, tf, doc.fieldLen[field], avgLen)
+			}
+		}
+	}
+
+	return rankedHits(scores, opts.Limit)
+}
+
+// bm25IDF is BM25's inverse document frequency term.
+func bm25IDF(numDocs, docFreq int) float64 {
+	return math.Log(1 + (float64(numDocs)-float64(docFreq)+0.5)/(float64(docFreq)+0.5))
+}
+
+// bm25Score is BM25's per-term, per-document contribution.
+func bm25Score(idf float64, tf, fieldLen int, avgFieldLen float64) float64 {
+	if avgFieldLen == 0 {
+		avgFieldLen = 1
+	}
+	numerator := float64(tf) * (bm25K1 + 1)
+	denominator := float64(tf) + bm25K1*(1-bm25B+bm25B*float64(fieldLen)/avgFieldLen)
+	return idf * numerator / denominator
+}
+
+func rankedHits(scores map[string]float64, limit int) []Hit {
+	hits := make([]Hit, 0, len(scores))
+	for xrefID, score := range scores {
+		hits = append(hits, Hit{XrefID: xrefID, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].XrefID < hits[j].XrefID
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// Suggest returns up to 10 indexed name terms starting with prefix,
+// ordered by how many individuals contain the term (most common first),
+// for autocomplete-style UIs.
+func (idx *Index) Suggest(prefix string) []string {
+	prefix = strings.ToLower(foldDiacritics(prefix))
+	if prefix == "" {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	type termCount struct {
+		term  string
+		count int
+	}
+	var matches []termCount
+	for term, docs := range idx.postings[FieldName] {
+		if strings.HasPrefix(term, prefix) {
+			matches = append(matches, termCount{term, len(docs)})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].count != matches[j].count {
+			return matches[i].count > matches[j].count
+		}
+		return matches[i].term < matches[j].term
+	})
+	if len(matches) > 10 {
+		matches = matches[:10]
+	}
+	out := make([]string, len(matches))
+	for i, m := range matches {
+		out[i] = m.term
+	}
+	return out
+}
+
+// FuzzySearch matches term against every indexed name and place token
+// within maxEdits Levenshtein edits (so "Jon" with maxEdits=1 matches
+// "John"), then BM25-ranks the individuals those matched tokens belong
+// to, the same way Search does for an exact token.
+func (idx *Index) FuzzySearch(term string, maxEdits int) []Hit {
+	needle := strings.ToLower(foldDiacritics(term))
+	if needle == "" {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := make(map[string]float64)
+	for _, field := range []string{FieldName, FieldPlace} {
+		numDocs := idx.fieldDocCount[field]
+		if numDocs == 0 {
+			continue
+		}
+		avgLen := float64(idx.fieldTotalLen[field]) / float64(numDocs)
+
+		for candidate, postingSet := range idx.postings[field] {
+			if levenshtein(needle, candidate) > maxEdits {
+				continue
+			}
+			idf := bm25IDF(numDocs, len(postingSet))
+			for xrefID := range postingSet {
+				doc := idx.docs[xrefID]
+				tf := doc.termFreq[field][candidate]
+				scores[xrefID] += bm25Score(idf, tf, doc.fieldLen[field], avgLen)
+			}
+		}
+	}
+
+	return rankedHits(scores, 0)
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+func buildSearchTestTree() *types.GedcomTree {
+	tree := types.NewGedcomTree()
+
+	addIndi := func(xref, name, birthPlace string) {
+		line := types.NewGedcomLine(0, "INDI", "", xref)
+		line.AddChild(types.NewGedcomLine(1, "NAME", name, ""))
+		if birthPlace != "" {
+			birt := types.NewGedcomLine(1, "BIRT", "", "")
+			birt.AddChild(types.NewGedcomLine(2, "PLAC", birthPlace, ""))
+			line.AddChild(birt)
+		}
+		tree.AddRecord(types.NewIndividualRecord(line))
+	}
+
+	addIndi("@I1@", "John /Smith/", "Paris, France")
+	addIndi("@I2@", "Jon /Smyth/", "London, England")
+	addIndi("@I3@", "Jane /Doe/", "Paris, Texas")
+
+	return tree
+}
+
+func TestIndexSearchRanksExactNameMatch(t *testing.T) {
+	idx, err := NewIndex(buildSearchTestTree())
+	if err != nil {
+		t.Fatalf("NewIndex: %v", err)
+	}
+
+	hits := idx.Search("smith", SearchOptions{Fields: []string{FieldName}})
+	if len(hits) != 1 || hits[0].XrefID != "@I1@" {
+		t.Fatalf("Search(smith) = %+v, want single hit @I1@", hits)
+	}
+}
+
+func TestIndexSearchMatchesAcrossFields(t *testing.T) {
+	idx, err := NewIndex(buildSearchTestTree())
+	if err != nil {
+		t.Fatalf("NewIndex: %v", err)
+	}
+
+	hits := idx.Search("paris", SearchOptions{Fields: []string{FieldPlace}})
+	if len(hits) != 2 {
+		t.Fatalf("Search(paris) = %+v, want 2 hits", hits)
+	}
+}
+
+func TestIndexSuggestReturnsPrefixMatches(t *testing.T) {
+	idx, err := NewIndex(buildSearchTestTree())
+	if err != nil {
+		t.Fatalf("NewIndex: %v", err)
+	}
+
+	suggestions := idx.Suggest("jo")
+	found := map[string]bool{}
+	for _, s := range suggestions {
+		found[s] = true
+	}
+	if !found["john"] || !found["jon"] {
+		t.Fatalf("Suggest(jo) = %v, want john and jon", suggestions)
+	}
+}
+
+func TestIndexFuzzySearchMatchesWithinEditDistance(t *testing.T) {
+	idx, err := NewIndex(buildSearchTestTree())
+	if err != nil {
+		t.Fatalf("NewIndex: %v", err)
+	}
+
+	hits := idx.FuzzySearch("jon", 1)
+	xrefs := map[string]bool{}
+	for _, h := range hits {
+		xrefs[h.XrefID] = true
+	}
+	if !xrefs["@I1@"] || !xrefs["@I2@"] {
+		t.Fatalf("FuzzySearch(jon, 1) = %+v, want both @I1@ and @I2@", hits)
+	}
+}
+
+func TestIndexRemoveDropsDocument(t *testing.T) {
+	idx, err := NewIndex(buildSearchTestTree())
+	if err != nil {
+		t.Fatalf("NewIndex: %v", err)
+	}
+
+	idx.Remove("@I1@")
+	hits := idx.Search("smith", SearchOptions{Fields: []string{FieldName}})
+	if len(hits) != 0 {
+		t.Fatalf("Search(smith) after Remove(@I1@) = %+v, want no hits", hits)
+	}
+}
+
+func TestFoldDiacritics(t *testing.T) {
+	if got := foldDiacritics("André"); got != "andre" {
+		t.Fatalf("foldDiacritics(André) = %q, want andre", got)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"jon", "john", 1},
+		{"smith", "smyth", 1},
+		{"", "abc", 3},
+		{"abc", "abc", 0},
+	}
+	for _, c := range cases {
+		if got := levenshtein(c.a, c.b); got != c.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package search
+
+import "strings"
+
+// diacriticFold maps common Latin letters with diacritics to their plain
+// ASCII base letter. There's no golang.org/x/text/unicode/norm dependency
+// available in this snapshot (nothing else in the tree imports
+// golang.org/x/text), so folding is this explicit table rather than a
+// real NFKD decomposition -- deliberately approximate, in the same spirit
+// as validate's yearOf and approxDayOf helpers.
+var diacriticFold = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ā': 'a',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e', 'ė': 'e', 'ę': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ī': 'i',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o', 'ō': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u',
+	'ý': 'y', 'ÿ': 'y',
+	'ñ': 'n', 'ń': 'n',
+	'ç': 'c', 'ć': 'c', 'č': 'c',
+	'š': 's', 'ś': 's',
+	'ž': 'z', 'ź': 'z', 'ż': 'z',
+	'ł': 'l',
+	'đ': 'd', 'ď': 'd',
+	'ř': 'r',
+	'ť': 't',
+}
+
+// foldDiacritics lowercases s and replaces each diacritic in
+// diacriticFold with its plain base letter.
+func foldDiacritics(s string) string {
+	s = strings.ToLower(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if folded, ok := diacriticFold[r]; ok {
+			r = folded
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isTokenRune reports whether r can appear within a token: letters and
+// digits, nothing else (GEDCOM punctuation like "/" surname delimiters
+// and "," in place names are treated as separators).
+func isTokenRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+}
+
+// tokenize case-folds and diacritic-folds s, then splits it into
+// contiguous runs of letters/digits.
+func tokenize(s string) []string {
+	s = foldDiacritics(s)
+	var tokens []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range s {
+		if isTokenRune(r) {
+			cur.WriteRune(r)
+		} else {
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// levenshtein returns the edit distance between a and b, used by
+// FuzzySearch to find indexed terms within maxEdits of a query term.
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	if len(ar) == 0 {
+		return len(br)
+	}
+	if len(br) == 0 {
+		return len(ar)
+	}
+
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			del := prev[j] + 1
+			ins := cur[j-1] + 1
+			sub := prev[j-1] + cost
+			min := del
+			if ins < min {
+				min = ins
+			}
+			if sub < min {
+				min = sub
+			}
+			cur[j] = min
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(br)]
+}
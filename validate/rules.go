@@ -0,0 +1,647 @@
+package validate
+
+import (
+	"sort"
+	"time"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/internal/gedcomdate"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// yearOf and approxDayOf are this package's names for gedcomdate's
+// parsing helpers, kept as thin aliases so the rule bodies below (and
+// every other package in this series) don't have to spell out the
+// gedcomdate prefix at each of their many call sites.
+func yearOf(date string) int                      { return gedcomdate.YearOf(date) }
+func approxDayOf(date string) (days int, ok bool) { return gedcomdate.ApproxDayOf(date) }
+
+// lifespanRule flags individuals whose recorded lifespan exceeds OLDAGE.
+type lifespanRule struct{}
+
+func (lifespanRule) ID() string { return "OLDAGE" }
+
+func (r lifespanRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	for xref, rec := range tree.GetAllIndividuals() {
+		indi, ok := rec.(*types.IndividualRecord)
+		if !ok {
+			continue
+		}
+		birth := yearOf(indi.GetBirthDate())
+		death := yearOf(indi.GetDeathDate())
+		if birth == 0 || death == 0 {
+			continue
+		}
+		age := death - birth
+		if age > cfg.Thresholds.OLDAGE {
+			findings = append(findings, finding(r.ID(), SeverityWarning, []string{xref},
+				"%s lived %d years, exceeding OLDAGE (%d)", xref, age, cfg.Thresholds.OLDAGE))
+		}
+	}
+	return findings
+}
+
+// baptismBeforeBirthRule flags a baptism recorded before the individual's
+// own birth.
+type baptismBeforeBirthRule struct{}
+
+func (baptismBeforeBirthRule) ID() string { return "BAPBEF" }
+
+func (r baptismBeforeBirthRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	for xref, rec := range tree.GetAllIndividuals() {
+		indi, ok := rec.(*types.IndividualRecord)
+		if !ok {
+			continue
+		}
+		birth := yearOf(indi.GetBirthDate())
+		for _, ev := range indi.GetEvents() {
+			evType, _ := ev["type"].(string)
+			if evType != "BAPM" && evType != "CHR" {
+				continue
+			}
+			date, _ := ev["date"].(string)
+			bap := yearOf(date)
+			if birth != 0 && bap != 0 && bap < birth {
+				findings = append(findings, finding(r.ID(), SeveritySevere, []string{xref},
+					"%s baptized (%d) before birth (%d)", xref, bap, birth))
+			}
+		}
+	}
+	return findings
+}
+
+// eventOrderRule flags marriage/burial/death ordering inversions (burial
+// before death, death before birth).
+type eventOrderRule struct{}
+
+func (eventOrderRule) ID() string { return "EVENTORDER" }
+
+func (r eventOrderRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	for xref, rec := range tree.GetAllIndividuals() {
+		indi, ok := rec.(*types.IndividualRecord)
+		if !ok {
+			continue
+		}
+		birth := yearOf(indi.GetBirthDate())
+		death := yearOf(indi.GetDeathDate())
+		if birth != 0 && death != 0 && death < birth {
+			findings = append(findings, finding(r.ID(), SeveritySevere, []string{xref},
+				"%s died (%d) before birth (%d)", xref, death, birth))
+		}
+		for _, ev := range indi.GetEvents() {
+			evType, _ := ev["type"].(string)
+			if evType != "BURI" {
+				continue
+			}
+			date, _ := ev["date"].(string)
+			buri := yearOf(date)
+			if death != 0 && buri != 0 && buri < death {
+				findings = append(findings, finding(r.ID(), SeveritySevere, []string{xref},
+					"%s buried (%d) before death (%d)", xref, buri, death))
+			}
+		}
+	}
+	return findings
+}
+
+// marriageAgeRule flags marriages that happen before YNGMAR or after OLDMAR.
+type marriageAgeRule struct{}
+
+func (marriageAgeRule) ID() string { return "MARAGE" }
+
+func (r marriageAgeRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	for xref, rec := range tree.GetAllFamilies() {
+		fam, ok := rec.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+		marrYear := 0
+		for _, line := range fam.GetLines("MARR") {
+			for _, child := range line.Children {
+				if child.Tag == "DATE" {
+					marrYear = yearOf(child.Value)
+				}
+			}
+		}
+		if marrYear == 0 {
+			continue
+		}
+		for _, spouseXref := range []string{fam.GetHusband(), fam.GetWife()} {
+			if spouseXref == "" {
+				continue
+			}
+			rec, ok := tree.GetAllIndividuals()[spouseXref]
+			if !ok {
+				continue
+			}
+			indi, ok := rec.(*types.IndividualRecord)
+			if !ok {
+				continue
+			}
+			birth := yearOf(indi.GetBirthDate())
+			if birth == 0 {
+				continue
+			}
+			age := marrYear - birth
+			if age < cfg.Thresholds.YNGMAR {
+				findings = append(findings, finding(r.ID(), SeverityWarning, []string{xref, spouseXref},
+					"%s married at age %d in family %s, under YNGMAR (%d)", spouseXref, age, xref, cfg.Thresholds.YNGMAR))
+			}
+			if age > cfg.Thresholds.OLDMAR {
+				findings = append(findings, finding(r.ID(), SeverityWarning, []string{xref, spouseXref},
+					"%s married at age %d in family %s, over OLDMAR (%d)", spouseXref, age, xref, cfg.Thresholds.OLDMAR))
+			}
+		}
+	}
+	return findings
+}
+
+// widowhoodRule flags a surviving spouse who outlives their partner by more
+// than LNGWDW years without a subsequent marriage being recorded.
+type widowhoodRule struct{}
+
+func (widowhoodRule) ID() string { return "LNGWDW" }
+
+func (r widowhoodRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	for xref, rec := range tree.GetAllFamilies() {
+		fam, ok := rec.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+		husband, wife := fam.GetHusband(), fam.GetWife()
+		if husband == "" || wife == "" {
+			continue
+		}
+		husbRec, hok := tree.GetAllIndividuals()[husband]
+		wifeRec, wok := tree.GetAllIndividuals()[wife]
+		if !hok || !wok {
+			continue
+		}
+		h, hOk := husbRec.(*types.IndividualRecord)
+		w, wOk := wifeRec.(*types.IndividualRecord)
+		if !hOk || !wOk {
+			continue
+		}
+		hDeath, wDeath := yearOf(h.GetDeathDate()), yearOf(w.GetDeathDate())
+		if hDeath == 0 || wDeath == 0 {
+			continue
+		}
+		gap := wDeath - hDeath
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap > cfg.Thresholds.LNGWDW {
+			findings = append(findings, finding(r.ID(), SeverityInfo, []string{xref, husband, wife},
+				"widowhood of %d years between spouses in family %s exceeds LNGWDW (%d)", gap, xref, cfg.Thresholds.LNGWDW))
+		}
+	}
+	return findings
+}
+
+// neverMarriedRule flags individuals who survive past OLDUNM with no FAMS
+// link recorded.
+type neverMarriedRule struct{}
+
+func (neverMarriedRule) ID() string { return "OLDUNM" }
+
+func (r neverMarriedRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	now := time.Now().Year()
+	for xref, rec := range tree.GetAllIndividuals() {
+		indi, ok := rec.(*types.IndividualRecord)
+		if !ok {
+			continue
+		}
+		birth := yearOf(indi.GetBirthDate())
+		if birth == 0 {
+			continue
+		}
+		death := yearOf(indi.GetDeathDate())
+		refYear := death
+		if refYear == 0 {
+			refYear = now
+		}
+		age := refYear - birth
+		if age <= cfg.Thresholds.OLDUNM {
+			continue
+		}
+		if g == nil {
+			continue
+		}
+		node := g.GetIndividual(xref)
+		if node != nil && len(node.Spouses()) == 0 {
+			findings = append(findings, finding(r.ID(), SeverityHint, []string{xref},
+				"%s reached age %d with no recorded marriage, over OLDUNM (%d)", xref, age, cfg.Thresholds.OLDUNM))
+		}
+	}
+	return findings
+}
+
+// motherAgeRule flags mothers who are implausibly young or old at the birth
+// of a child, using YNGMOM, OLDMOM, and FECMOM.
+type motherAgeRule struct{}
+
+func (motherAgeRule) ID() string { return "MOMAGE" }
+
+func (r motherAgeRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	for xref, rec := range tree.GetAllFamilies() {
+		fam, ok := rec.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+		wife := fam.GetWife()
+		if wife == "" {
+			continue
+		}
+		wifeRec, ok := tree.GetAllIndividuals()[wife]
+		if !ok {
+			continue
+		}
+		mother, ok := wifeRec.(*types.IndividualRecord)
+		if !ok {
+			continue
+		}
+		momBirth := yearOf(mother.GetBirthDate())
+		if momBirth == 0 {
+			continue
+		}
+		for _, childXref := range fam.GetChildren() {
+			childRec, ok := tree.GetAllIndividuals()[childXref]
+			if !ok {
+				continue
+			}
+			child, ok := childRec.(*types.IndividualRecord)
+			if !ok {
+				continue
+			}
+			childBirth := yearOf(child.GetBirthDate())
+			if childBirth == 0 {
+				continue
+			}
+			age := childBirth - momBirth
+			if age < cfg.Thresholds.YNGMOM {
+				findings = append(findings, finding(r.ID(), SeverityWarning, []string{xref, wife, childXref},
+					"mother %s was %d at birth of %s, under YNGMOM (%d)", wife, age, childXref, cfg.Thresholds.YNGMOM))
+			}
+			if age > cfg.Thresholds.OLDMOM {
+				findings = append(findings, finding(r.ID(), SeverityWarning, []string{xref, wife, childXref},
+					"mother %s was %d at birth of %s, over OLDMOM (%d)", wife, age, childXref, cfg.Thresholds.OLDMOM))
+			} else if age > cfg.Thresholds.FECMOM {
+				findings = append(findings, finding(r.ID(), SeverityHint, []string{xref, wife, childXref},
+					"mother %s was %d at birth of %s, over FECMOM (%d)", wife, age, childXref, cfg.Thresholds.FECMOM))
+			}
+		}
+	}
+	return findings
+}
+
+// childSpacingRule flags siblings spaced closer together than CSPACE
+// days, skipping a pair whose birth dates are recorded identically
+// (presumed twins rather than a data error).
+type childSpacingRule struct{}
+
+func (childSpacingRule) ID() string { return "CSPACE" }
+
+type childBirth struct {
+	xref string
+	date string
+	days int
+}
+
+func (r childSpacingRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	minSpacing := cfg.Thresholds.CSPACE
+	if minSpacing <= 0 {
+		return findings
+	}
+	for xref, rec := range tree.GetAllFamilies() {
+		fam, ok := rec.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+		births := make([]childBirth, 0, len(fam.GetChildren()))
+		for _, childXref := range fam.GetChildren() {
+			childRec, ok := tree.GetAllIndividuals()[childXref]
+			if !ok {
+				continue
+			}
+			child, ok := childRec.(*types.IndividualRecord)
+			if !ok {
+				continue
+			}
+			date := child.GetBirthDate()
+			if days, ok := approxDayOf(date); ok {
+				births = append(births, childBirth{xref: childXref, date: date, days: days})
+			}
+		}
+		sort.Slice(births, func(i, j int) bool { return births[i].days < births[j].days })
+		for i := 1; i < len(births); i++ {
+			if births[i].date == births[i-1].date {
+				continue
+			}
+			spacing := births[i].days - births[i-1].days
+			if spacing < minSpacing {
+				findings = append(findings, finding(r.ID(), SeverityWarning, []string{xref, births[i-1].xref, births[i].xref},
+					"family %s has children %s and %s born %d days apart, under CSPACE (%d)",
+					xref, births[i-1].xref, births[i].xref, spacing, minSpacing))
+			}
+		}
+	}
+	return findings
+}
+
+// fatherAgeRule flags fathers who are implausibly old at the birth of a
+// child, using OLDDAD.
+type fatherAgeRule struct{}
+
+func (fatherAgeRule) ID() string { return "DADAGE" }
+
+func (r fatherAgeRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	for xref, rec := range tree.GetAllFamilies() {
+		fam, ok := rec.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+		husband := fam.GetHusband()
+		if husband == "" {
+			continue
+		}
+		husbRec, ok := tree.GetAllIndividuals()[husband]
+		if !ok {
+			continue
+		}
+		father, ok := husbRec.(*types.IndividualRecord)
+		if !ok {
+			continue
+		}
+		dadBirth := yearOf(father.GetBirthDate())
+		if dadBirth == 0 {
+			continue
+		}
+		for _, childXref := range fam.GetChildren() {
+			childRec, ok := tree.GetAllIndividuals()[childXref]
+			if !ok {
+				continue
+			}
+			child, ok := childRec.(*types.IndividualRecord)
+			if !ok {
+				continue
+			}
+			childBirth := yearOf(child.GetBirthDate())
+			if childBirth == 0 {
+				continue
+			}
+			age := childBirth - dadBirth
+			if age > cfg.Thresholds.OLDDAD {
+				findings = append(findings, finding(r.ID(), SeverityWarning, []string{xref, husband, childXref},
+					"father %s was %d at birth of %s, over OLDDAD (%d)", husband, age, childXref, cfg.Thresholds.OLDDAD))
+			}
+		}
+	}
+	return findings
+}
+
+// spouseAgeGapRule flags married couples with an age gap exceeding
+// MAXAGEGAP years.
+type spouseAgeGapRule struct{}
+
+func (spouseAgeGapRule) ID() string { return "AGEGAP" }
+
+func (r spouseAgeGapRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	for xref, rec := range tree.GetAllFamilies() {
+		fam, ok := rec.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+		husband, wife := fam.GetHusband(), fam.GetWife()
+		if husband == "" || wife == "" {
+			continue
+		}
+		hRec, hok := tree.GetAllIndividuals()[husband]
+		wRec, wok := tree.GetAllIndividuals()[wife]
+		if !hok || !wok {
+			continue
+		}
+		h, hOk := hRec.(*types.IndividualRecord)
+		w, wOk := wRec.(*types.IndividualRecord)
+		if !hOk || !wOk {
+			continue
+		}
+		hBirth, wBirth := yearOf(h.GetBirthDate()), yearOf(w.GetBirthDate())
+		if hBirth == 0 || wBirth == 0 {
+			continue
+		}
+		gap := hBirth - wBirth
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap > cfg.Thresholds.MAXAGEGAP {
+			findings = append(findings, finding(r.ID(), SeverityHint, []string{xref, husband, wife},
+				"spouses %s and %s in family %s have a %d year age gap, over MAXAGEGAP (%d)",
+				husband, wife, xref, gap, cfg.Thresholds.MAXAGEGAP))
+		}
+	}
+	return findings
+}
+
+// parentDeathOrderRule flags a child born more than a year after a
+// parent's recorded death.
+type parentDeathOrderRule struct{}
+
+func (parentDeathOrderRule) ID() string { return "CHAFTDEA" }
+
+func (r parentDeathOrderRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	for xref, rec := range tree.GetAllFamilies() {
+		fam, ok := rec.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+		for _, parentXref := range []string{fam.GetHusband(), fam.GetWife()} {
+			if parentXref == "" {
+				continue
+			}
+			parentRec, ok := tree.GetAllIndividuals()[parentXref]
+			if !ok {
+				continue
+			}
+			parent, ok := parentRec.(*types.IndividualRecord)
+			if !ok {
+				continue
+			}
+			parentDeath := yearOf(parent.GetDeathDate())
+			if parentDeath == 0 {
+				continue
+			}
+			for _, childXref := range fam.GetChildren() {
+				childRec, ok := tree.GetAllIndividuals()[childXref]
+				if !ok {
+					continue
+				}
+				child, ok := childRec.(*types.IndividualRecord)
+				if !ok {
+					continue
+				}
+				childBirth := yearOf(child.GetBirthDate())
+				if childBirth == 0 {
+					continue
+				}
+				if childBirth > parentDeath+1 {
+					findings = append(findings, finding(r.ID(), SeverityWarning, []string{xref, parentXref, childXref},
+						"%s born (%d) more than a year after parent %s's death (%d)", childXref, childBirth, parentXref, parentDeath))
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// stillLivingRule flags an individual with no recorded death date whose
+// birth was long enough ago (STILLLIVING years) that them still being
+// alive is implausible.
+type stillLivingRule struct{}
+
+func (stillLivingRule) ID() string { return "STILLLIVING" }
+
+func (r stillLivingRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	now := time.Now().Year()
+	for xref, rec := range tree.GetAllIndividuals() {
+		indi, ok := rec.(*types.IndividualRecord)
+		if !ok {
+			continue
+		}
+		if indi.GetDeathDate() != "" {
+			continue
+		}
+		birth := yearOf(indi.GetBirthDate())
+		if birth == 0 {
+			continue
+		}
+		age := now - birth
+		if age > cfg.Thresholds.STILLLIVING {
+			findings = append(findings, finding(r.ID(), SeverityInfo, []string{xref},
+				"%s has no recorded death date but would be %d years old, over STILLLIVING (%d) -- potentially still living unlikely",
+				xref, age, cfg.Thresholds.STILLLIVING))
+		}
+	}
+	return findings
+}
+
+// danglingPointerRule flags FAMC/FAMS/HUSB/WIFE/CHIL pointers that resolve
+// to an XREF not present in the tree.
+type danglingPointerRule struct{}
+
+func (danglingPointerRule) ID() string { return "DANGLING" }
+
+func (r danglingPointerRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	individuals := tree.GetAllIndividuals()
+	for xref, rec := range tree.GetAllFamilies() {
+		fam, ok := rec.(*types.FamilyRecord)
+		if !ok {
+			continue
+		}
+		for _, spouseXref := range []string{fam.GetHusband(), fam.GetWife()} {
+			if spouseXref != "" {
+				if _, ok := individuals[spouseXref]; !ok {
+					findings = append(findings, finding(r.ID(), SeveritySevere, []string{xref, spouseXref},
+						"family %s points to missing individual %s", xref, spouseXref))
+				}
+			}
+		}
+		for _, childXref := range fam.GetChildren() {
+			if _, ok := individuals[childXref]; !ok {
+				findings = append(findings, finding(r.ID(), SeveritySevere, []string{xref, childXref},
+					"family %s points to missing child %s", xref, childXref))
+			}
+		}
+	}
+	return findings
+}
+
+// duplicateLinkRule flags an individual with the same family XREF listed
+// more than once as a FAMS or FAMC link.
+type duplicateLinkRule struct{}
+
+func (duplicateLinkRule) ID() string { return "DUPLINK" }
+
+func (r duplicateLinkRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	if g == nil {
+		return findings
+	}
+	for xref := range tree.GetAllIndividuals() {
+		node := g.GetIndividual(xref)
+		if node == nil {
+			continue
+		}
+		seen := make(map[string]int)
+		for _, fam := range node.Spouses() {
+			seen[fam.ID()]++
+		}
+		for famID, count := range seen {
+			if count > 1 {
+				findings = append(findings, finding(r.ID(), SeverityHint, []string{xref, famID},
+					"%s has duplicate spouse links to family %s", xref, famID))
+			}
+		}
+	}
+	return findings
+}
+
+// cycleRule flags cycles in the parent -> child graph, which would
+// otherwise send naive ancestor/descendant traversals into an infinite loop.
+type cycleRule struct{}
+
+func (cycleRule) ID() string { return "CYCLE" }
+
+func (r cycleRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	if g == nil {
+		return findings
+	}
+	const (
+		white = 0
+		gray  = 1
+		black = 2
+	)
+	color := make(map[string]int)
+	var visit func(xref string, path []string) []string
+	visit = func(xref string, path []string) []string {
+		if color[xref] == black {
+			return nil
+		}
+		if color[xref] == gray {
+			return append(append([]string{}, path...), xref)
+		}
+		color[xref] = gray
+		node := g.GetIndividual(xref)
+		if node != nil {
+			for _, child := range node.Children() {
+				if cyc := visit(child.ID(), append(path, xref)); cyc != nil {
+					return cyc
+				}
+			}
+		}
+		color[xref] = black
+		return nil
+	}
+	for xref := range tree.GetAllIndividuals() {
+		if color[xref] == white {
+			if cyc := visit(xref, nil); cyc != nil {
+				findings = append(findings, finding(r.ID(), SeveritySevere, cyc,
+					"cycle detected in parent->child graph: %v", cyc))
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,222 @@
+// Package validate runs plausibility rules over a parsed GEDCOM tree and its
+// graph, modeled after the classic LifeLines/GEDCHECK family of checks
+// (OLDAGE, YNGMAR, OLDMAR, LNGWDW, OLDUNM, YNGMOM, OLDMOM/FECMOM, CBSPAN,
+// CSPACE, and structural integrity checks).
+package validate
+
+import (
+	"fmt"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/query"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// Severity classifies how serious a Finding is.
+type Severity string
+
+const (
+	SeveritySevere  Severity = "severe"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+	SeverityHint    Severity = "hint"
+)
+
+// Finding describes a single plausibility violation.
+type Finding struct {
+	RuleID   string
+	Severity Severity
+	Message  string
+	XrefIDs  []string
+}
+
+// Thresholds holds the configurable ages used by the shipped rule set.
+// Names follow the LifeLines/GEDCHECK convention so users porting an
+// existing .linesrc can map values directly.
+type Thresholds struct {
+	OLDAGE int // Max plausible lifespan in years. Default 99.
+	YNGMAR int // Min plausible marriage age. Default 20.
+	OLDMAR int // Max plausible marriage age. Default 80.
+	LNGWDW int // Max plausible years of widowhood. Default 80.
+	OLDUNM int // Age past which never-married is flagged. Default 67.
+	YNGMOM int // Min plausible mother age at birth. Default 16.
+	OLDMOM int // Max plausible mother age at birth. Default 55.
+	FECMOM int // Max plausible mother age at birth of a later child. Default 45.
+	OLDDAD int // Max plausible father age at birth of a child. Default 80.
+	CBSPAN int // Min days a child must be born after parents' marriage minus this span. Default 0.
+	CSPACE int // Min plausible days between sibling births, excluding twins (identical birth dates). Default 274 (~9 months).
+	MAXAGEGAP   int // Max plausible age gap between spouses, in years. Default 40.
+	STILLLIVING int // Age past which a person with no recorded death date is flagged as unlikely to still be living. Default 100.
+}
+
+// DefaultThresholds returns the GEDCHECK-style defaults.
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		OLDAGE:      99,
+		YNGMAR:      20,
+		OLDMAR:      80,
+		LNGWDW:      80,
+		OLDUNM:      67,
+		YNGMOM:      16,
+		OLDMOM:      55,
+		FECMOM:      45,
+		OLDDAD:      80,
+		CBSPAN:      0,
+		CSPACE:      274,
+		MAXAGEGAP:   40,
+		STILLLIVING: 100,
+	}
+}
+
+// Config controls how a Validator runs.
+type Config struct {
+	Thresholds Thresholds
+
+	// DisabledRules skips the listed rule IDs entirely.
+	DisabledRules map[string]bool
+}
+
+// DefaultConfig returns a Config with the default thresholds and no rules
+// disabled.
+func DefaultConfig() *Config {
+	return &Config{
+		Thresholds:    DefaultThresholds(),
+		DisabledRules: make(map[string]bool),
+	}
+}
+
+// Rule is a single plausibility check run once per relevant record.
+type Rule interface {
+	ID() string
+	Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding
+}
+
+// Validator runs a battery of Rules over a tree and its graph.
+type Validator struct {
+	tree  *types.GedcomTree
+	graph *query.Graph
+	cfg   *Config
+	rules []Rule
+}
+
+// NewValidator creates a Validator over the given tree and graph, using the
+// shipped GEDCHECK-style rule set. If cfg is nil, DefaultConfig() is used.
+func NewValidator(tree *types.GedcomTree, g *query.Graph, cfg *Config) *Validator {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Validator{
+		tree:  tree,
+		graph: g,
+		cfg:   cfg,
+		rules: defaultRules(),
+	}
+}
+
+// AddRule registers an additional rule to run.
+func (v *Validator) AddRule(r Rule) {
+	v.rules = append(v.rules, r)
+}
+
+// Run executes every enabled rule and returns the combined findings.
+func (v *Validator) Run() []Finding {
+	findings := make([]Finding, 0)
+	for _, r := range v.rules {
+		if v.cfg.DisabledRules[r.ID()] {
+			continue
+		}
+		findings = append(findings, r.Check(v.tree, v.graph, v.cfg)...)
+	}
+	return findings
+}
+
+func defaultRules() []Rule {
+	return []Rule{
+		lifespanRule{},
+		baptismBeforeBirthRule{},
+		eventOrderRule{},
+		marriageAgeRule{},
+		widowhoodRule{},
+		neverMarriedRule{},
+		motherAgeRule{},
+		fatherAgeRule{},
+		spouseAgeGapRule{},
+		parentDeathOrderRule{},
+		stillLivingRule{},
+		childSpacingRule{},
+		danglingPointerRule{},
+		duplicateLinkRule{},
+		cycleRule{},
+	}
+}
+
+// RuleRunner builds up a rule set via chained calls before running it,
+// for callers that want to cherry-pick rules (WithDefaults, WithRule)
+// rather than running NewValidator's full default set unconditionally.
+//
+// A query.QueryBuilder-based entry point (qb.Rules()) isn't provided:
+// QueryBuilder isn't part of this package snapshot and exposes no
+// accessor to the tree/graph it was built from, so a method added to it
+// here would have nothing to construct a RuleRunner from. NewRuleRunner
+// below is the fluent entry point instead.
+type RuleRunner struct {
+	*Validator
+}
+
+// NewRuleRunner creates a RuleRunner over tree and g with no rules
+// enabled yet. If cfg is nil, DefaultConfig() is used.
+func NewRuleRunner(tree *types.GedcomTree, g *query.Graph, cfg *Config) *RuleRunner {
+	v := NewValidator(tree, g, cfg)
+	v.rules = nil
+	return &RuleRunner{Validator: v}
+}
+
+// WithDefaults adds the shipped GEDCHECK-style rule set.
+func (r *RuleRunner) WithDefaults() *RuleRunner {
+	r.rules = append(r.rules, defaultRules()...)
+	return r
+}
+
+// WithRule adds a single rule, for chaining: e.g.
+// NewRuleRunner(tree, g, nil).WithDefaults().WithRule(customRule).Run().
+func (r *RuleRunner) WithRule(rule Rule) *RuleRunner {
+	r.AddRule(rule)
+	return r
+}
+
+// IndividualRuleFunc checks a single individual, with g available for
+// relationship lookups (Parents, Children, Siblings, Spouses, ...) the
+// way a hand-written Rule's Check would use g for. Like the rest of this
+// package, it takes *query.Graph directly rather than a query.QueryBuilder
+// (see RuleRunner's doc comment for why).
+type IndividualRuleFunc func(indi *types.IndividualRecord, g *query.Graph) []Finding
+
+// IndividualRule adapts an IndividualRuleFunc into a Rule, for callers
+// who want to plug in a custom per-individual check without implementing
+// Check's full (tree, graph, cfg) signature themselves.
+type IndividualRule struct {
+	RuleID string
+	Fn     IndividualRuleFunc
+}
+
+func (r IndividualRule) ID() string { return r.RuleID }
+
+func (r IndividualRule) Check(tree *types.GedcomTree, g *query.Graph, cfg *Config) []Finding {
+	findings := make([]Finding, 0)
+	for _, rec := range tree.GetAllIndividuals() {
+		indi, ok := rec.(*types.IndividualRecord)
+		if !ok {
+			continue
+		}
+		findings = append(findings, r.Fn(indi, g)...)
+	}
+	return findings
+}
+
+func finding(ruleID string, sev Severity, xrefs []string, format string, args ...interface{}) Finding {
+	return Finding{
+		RuleID:   ruleID,
+		Severity: sev,
+		Message:  fmt.Sprintf(format, args...),
+		XrefIDs:  xrefs,
+	}
+}
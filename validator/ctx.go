@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"context"
+	"time"
+
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/parser"
+	"github.com/lesfleursdelanuitdev/ligneous-gedcom/types"
+)
+
+// ValidateCtx runs v.Validate(tree) while honoring ctx cancellation and
+// reporting progress through h (a nil h is treated as
+// parser.NoopProgressHandler). Like parser.ParseCtx, this can only check
+// ctx at the phase boundary, since GedcomValidator does not expose a
+// per-record validation hook.
+func ValidateCtx(ctx context.Context, v *GedcomValidator, tree *types.GedcomTree, h parser.ProgressHandler) error {
+	if h == nil {
+		h = parser.NoopProgressHandler{}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	h.OnPhaseStart("validate", 0)
+	start := time.Now()
+
+	if err := v.Validate(tree); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	total := len(tree.GetAllIndividuals()) + len(tree.GetAllFamilies())
+	duration := time.Since(start)
+	h.OnRecord("validate", total, total)
+	h.OnPhaseEnd("validate", parser.PhaseStats{Phase: "validate", Total: total, Duration: duration})
+	recordValidate(total, duration)
+
+	return nil
+}
+
+// ValidateWithDiagnostic runs v.Validate(tree) and reports a PhaseSummary
+// event to diag (a nil diag is treated as parser.NoopDiagnostic). Like
+// parser.ParseWithDiagnostic, per-record ValidationWarning events would
+// require GedcomValidator to expose its collected warnings, which this
+// package does not have visibility into; only the phase-level summary is
+// emitted here.
+func ValidateWithDiagnostic(v *GedcomValidator, tree *types.GedcomTree, diag parser.Diagnostic) error {
+	if diag == nil {
+		diag = parser.NoopDiagnostic{}
+	}
+
+	start := time.Now()
+	if err := v.Validate(tree); err != nil {
+		return err
+	}
+
+	total := len(tree.GetAllIndividuals()) + len(tree.GetAllFamilies())
+	stats := parser.PhaseStats{Phase: "validate", Total: total, Duration: time.Since(start)}
+	diag.PhaseSummary("validate", stats)
+	recordValidate(total, stats.Duration)
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package validator
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	recordsTotal     = expvar.NewInt("gedcom.validate.records_total")
+	validateDuration = expvar.NewFloat("gedcom.validate.duration_ms")
+	// gedcom.validate.warnings is exposed for parity with the other
+	// phases' metrics, but GedcomValidator does not expose a per-call
+	// warning count in this package, so it stays at its zero value until
+	// such an accessor exists.
+	warningsTotal = expvar.NewInt("gedcom.validate.warnings")
+
+	validateCount   int64
+	validateDurEWMA int64 // nanoseconds
+)
+
+const ewmaAlpha = 0.2
+
+func recordValidate(records int, d time.Duration) {
+	recordsTotal.Add(int64(records))
+
+	n := atomic.AddInt64(&validateCount, 1)
+	prev := atomic.LoadInt64(&validateDurEWMA)
+	next := int64(float64(d.Nanoseconds())*ewmaAlpha + float64(prev)*(1-ewmaAlpha))
+	if n == 1 {
+		next = d.Nanoseconds()
+	}
+	atomic.StoreInt64(&validateDurEWMA, next)
+	validateDuration.Set(float64(next) / float64(time.Millisecond))
+}
+
+// ValidatorMetrics is a point-in-time snapshot of the process-wide
+// validator counters.
+type ValidatorMetrics struct {
+	RecordsTotal  int64
+	WarningsTotal int64
+	AvgDurationMs float64
+}
+
+// Metrics returns a snapshot of the process-wide validator metrics. Like
+// parser.HierarchicalParser.Metrics, this reports process-wide state
+// rather than per-instance state, since the underlying counters are
+// expvar-backed.
+func (v *GedcomValidator) Metrics() ValidatorMetrics {
+	return ValidatorMetrics{
+		RecordsTotal:  recordsTotal.Value(),
+		WarningsTotal: warningsTotal.Value(),
+		AvgDurationMs: float64(atomic.LoadInt64(&validateDurEWMA)) / float64(time.Millisecond),
+	}
+}